@@ -28,6 +28,7 @@ import (
 	"gopkg.in/urfave/cli.v1"
 
 	"github.com/UltronGlow/UltronGlow-Origin/cmd/utils"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus/alien"
 	"github.com/UltronGlow/UltronGlow-Origin/eth/catalyst"
 	"github.com/UltronGlow/UltronGlow-Origin/eth/ethconfig"
 	"github.com/UltronGlow/UltronGlow-Origin/internal/ethapi"
@@ -80,6 +81,7 @@ type gethConfig struct {
 	Node     node.Config
 	Ethstats ethstatsConfig
 	Metrics  metrics.Config
+	Watchdog alien.WatchdogConfig
 }
 
 func loadConfig(file string, cfg *gethConfig) error {
@@ -133,6 +135,7 @@ func makeConfigNode(ctx *cli.Context) (*node.Node, gethConfig) {
 	if ctx.GlobalIsSet(utils.EthStatsURLFlag.Name) {
 		cfg.Ethstats.URL = ctx.GlobalString(utils.EthStatsURLFlag.Name)
 	}
+	utils.SetWatchdogConfig(ctx, &cfg.Watchdog)
 	applyMetricConfig(ctx, &cfg)
 
 	return stack, cfg
@@ -164,6 +167,13 @@ func makeFullNode(ctx *cli.Context) (*node.Node, ethapi.Backend) {
 	if cfg.Ethstats.URL != "" {
 		utils.RegisterEthStatsService(stack, backend, cfg.Ethstats.URL)
 	}
+	// Add the operator watchdog if requested.
+	if len(cfg.Watchdog.Addresses) > 0 {
+		if eth == nil {
+			utils.Fatalf("Operator watchdog does not work in light client mode.")
+		}
+		utils.RegisterWatchdogService(stack, eth, cfg.Watchdog)
+	}
 	return stack, backend
 }
 
@@ -48,6 +48,8 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.GCModeFlag,
 			utils.TxLookupLimitFlag,
 			utils.EthStatsURLFlag,
+			utils.WatchdogAddressesFlag,
+			utils.WatchdogWebhookFlag,
 			utils.IdentityFlag,
 			utils.LightKDFFlag,
 			utils.WhitelistFlag,
@@ -100,6 +102,9 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.TxPoolGlobalSlotsFlag,
 			utils.TxPoolAccountQueueFlag,
 			utils.TxPoolGlobalQueueFlag,
+			utils.TxPoolPrioritySlotsFlag,
+			utils.TxPoolPriorityGlobalSlotsFlag,
+			utils.TxPoolCustomLimitsFlag,
 			utils.TxPoolLifetimeFlag,
 		},
 	},
@@ -181,6 +186,7 @@ var AppHelpFlagGroups = []flags.FlagGroup{
 			utils.MinerGasPriceFlag,
 			utils.MinerGasTargetFlag,
 			utils.MinerGasLimitFlag,
+			utils.MinerReserveGasFlag,
 			utils.MinerEtherbaseFlag,
 			utils.MinerExtraDataFlag,
 			utils.MinerRecommitIntervalFlag,
@@ -19,6 +19,7 @@ package utils
 
 import (
 	"crypto/ecdsa"
+	"errors"
 	"fmt"
 	"io"
 	"io/ioutil"
@@ -44,6 +45,7 @@ import (
 	"github.com/UltronGlow/UltronGlow-Origin/consensus/ethash"
 	"github.com/UltronGlow/UltronGlow-Origin/core"
 	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
 	"github.com/UltronGlow/UltronGlow-Origin/core/vm"
 	"github.com/UltronGlow/UltronGlow-Origin/crypto"
 	"github.com/UltronGlow/UltronGlow-Origin/eth"
@@ -53,6 +55,7 @@ import (
 	"github.com/UltronGlow/UltronGlow-Origin/eth/tracers"
 	"github.com/UltronGlow/UltronGlow-Origin/ethdb"
 	"github.com/UltronGlow/UltronGlow-Origin/ethstats"
+	"github.com/UltronGlow/UltronGlow-Origin/event"
 	"github.com/UltronGlow/UltronGlow-Origin/graphql"
 	"github.com/UltronGlow/UltronGlow-Origin/internal/ethapi"
 	"github.com/UltronGlow/UltronGlow-Origin/internal/flags"
@@ -366,6 +369,20 @@ var (
 		Usage: "Maximum number of non-executable transaction slots for all accounts",
 		Value: ethconfig.Defaults.TxPool.GlobalQueue,
 	}
+	TxPoolPrioritySlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.priorityslots",
+		Usage: "Maximum number of priority lane transaction slots per account",
+		Value: ethconfig.Defaults.TxPool.PrioritySlots,
+	}
+	TxPoolPriorityGlobalSlotsFlag = cli.Uint64Flag{
+		Name:  "txpool.priorityglobalslots",
+		Usage: "Maximum number of priority lane transaction slots for all accounts",
+		Value: ethconfig.Defaults.TxPool.PriorityGlobalSlots,
+	}
+	TxPoolCustomLimitsFlag = cli.StringFlag{
+		Name:  "txpool.customlimits",
+		Usage: "Comma separated limits of the custom transactions as category=perblock/accountpending/pricemultiplier (e.g. UTG:1:stRent=2/3/0)",
+	}
 	TxPoolLifetimeFlag = cli.DurationFlag{
 		Name:  "txpool.lifetime",
 		Usage: "Maximum amount of time non-executable transaction are queued",
@@ -443,6 +460,11 @@ var (
 		Usage: "Target gas ceiling for mined blocks",
 		Value: ethconfig.Defaults.Miner.GasCeil,
 	}
+	MinerReserveGasFlag = cli.Uint64Flag{
+		Name:  "miner.reservegas",
+		Usage: "Gas reserved in mined blocks for the consensus transactions of the txpool priority lane (0 = disabled)",
+		Value: ethconfig.Defaults.Miner.ReserveGas,
+	}
 	MinerGasPriceFlag = BigFlag{
 		Name:  "miner.gasprice",
 		Usage: "Minimum gas price for mining a transaction",
@@ -505,6 +527,14 @@ var (
 		Name:  "ethstats",
 		Usage: "Reporting URL of a ethstats service (nodename:secret@host:port)",
 	}
+	WatchdogAddressesFlag = cli.StringFlag{
+		Name:  "watchdog.addresses",
+		Usage: "Comma separated operator addresses to watch for signer and storage penalties",
+	}
+	WatchdogWebhookFlag = cli.StringFlag{
+		Name:  "watchdog.webhook",
+		Usage: "Local URL the operator watchdog posts its alerts to",
+	}
 	FakePoWFlag = cli.BoolFlag{
 		Name:  "fakepow",
 		Usage: "Disables proof-of-work verification",
@@ -1316,6 +1346,23 @@ func setGPO(ctx *cli.Context, cfg *gasprice.Config, light bool) {
 	}
 }
 
+// SetWatchdogConfig applies the operator watchdog flags to the config.
+func SetWatchdogConfig(ctx *cli.Context, cfg *alien.WatchdogConfig) {
+	if ctx.GlobalIsSet(WatchdogAddressesFlag.Name) {
+		cfg.Addresses = nil
+		for _, account := range strings.Split(ctx.GlobalString(WatchdogAddressesFlag.Name), ",") {
+			if trimmed := strings.TrimSpace(account); !common.IsHexAddress(trimmed) {
+				Fatalf("Invalid account in --watchdog.addresses: %s", trimmed)
+			} else {
+				cfg.Addresses = append(cfg.Addresses, common.HexToAddress(trimmed))
+			}
+		}
+	}
+	if ctx.GlobalIsSet(WatchdogWebhookFlag.Name) {
+		cfg.Webhook = ctx.GlobalString(WatchdogWebhookFlag.Name)
+	}
+}
+
 func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolLocalsFlag.Name) {
 		locals := strings.Split(ctx.GlobalString(TxPoolLocalsFlag.Name), ",")
@@ -1358,11 +1405,50 @@ func setTxPool(ctx *cli.Context, cfg *core.TxPoolConfig) {
 	if ctx.GlobalIsSet(TxPoolGlobalQueueFlag.Name) {
 		cfg.GlobalQueue = ctx.GlobalUint64(TxPoolGlobalQueueFlag.Name)
 	}
+	if ctx.GlobalIsSet(TxPoolPrioritySlotsFlag.Name) {
+		cfg.PrioritySlots = ctx.GlobalUint64(TxPoolPrioritySlotsFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolPriorityGlobalSlotsFlag.Name) {
+		cfg.PriorityGlobalSlots = ctx.GlobalUint64(TxPoolPriorityGlobalSlotsFlag.Name)
+	}
+	if ctx.GlobalIsSet(TxPoolCustomLimitsFlag.Name) {
+		cfg.CustomLimits = make(map[string]core.CustomTxLimit)
+		for _, entry := range strings.Split(ctx.GlobalString(TxPoolCustomLimitsFlag.Name), ",") {
+			category, limit, err := parseCustomTxLimit(strings.TrimSpace(entry))
+			if err != nil {
+				Fatalf("Invalid limit in --txpool.customlimits: %s (%v)", entry, err)
+			}
+			cfg.CustomLimits[category] = limit
+		}
+	}
 	if ctx.GlobalIsSet(TxPoolLifetimeFlag.Name) {
 		cfg.Lifetime = ctx.GlobalDuration(TxPoolLifetimeFlag.Name)
 	}
 }
 
+// parseCustomTxLimit parses a custom transaction limit given as
+// category=perblock/accountpending/pricemultiplier.
+func parseCustomTxLimit(entry string) (string, core.CustomTxLimit, error) {
+	var limit core.CustomTxLimit
+	parts := strings.SplitN(entry, "=", 2)
+	if len(parts) != 2 || parts[0] == "" {
+		return "", limit, errors.New("expected category=perblock/accountpending/pricemultiplier")
+	}
+	values := strings.Split(parts[1], "/")
+	if len(values) != 3 {
+		return "", limit, errors.New("expected three limits")
+	}
+	fields := []*uint64{&limit.PerBlock, &limit.AccountPending, &limit.PriceMultiplier}
+	for i, value := range values {
+		n, err := strconv.ParseUint(strings.TrimSpace(value), 10, 64)
+		if err != nil {
+			return "", limit, err
+		}
+		*fields[i] = n
+	}
+	return parts[0], limit, nil
+}
+
 func setEthash(ctx *cli.Context, cfg *ethconfig.Config) {
 	if ctx.GlobalIsSet(EthashCacheDirFlag.Name) {
 		cfg.Ethash.CacheDir = ctx.GlobalString(EthashCacheDirFlag.Name)
@@ -1404,6 +1490,9 @@ func setMiner(ctx *cli.Context, cfg *miner.Config) {
 	if ctx.GlobalIsSet(MinerGasLimitFlag.Name) {
 		cfg.GasCeil = ctx.GlobalUint64(MinerGasLimitFlag.Name)
 	}
+	if ctx.GlobalIsSet(MinerReserveGasFlag.Name) {
+		cfg.ReserveGas = ctx.GlobalUint64(MinerReserveGasFlag.Name)
+	}
 	cfg.GasPrice = big.NewInt(params.GGasPrice)
 	if ctx.GlobalIsSet(MinerGasPriceFlag.Name) {
 		//cfg.GasPrice = GlobalBig(ctx, MinerGasPriceFlag.Name)
@@ -1738,6 +1827,45 @@ func RegisterEthStatsService(stack *node.Node, backend ethapi.Backend, url strin
 	}
 }
 
+// RegisterWatchdogService configures the operator watchdog and adds it to the
+// given node.
+func RegisterWatchdogService(stack *node.Node, backend *eth.Ethereum, cfg alien.WatchdogConfig) {
+	watchdog, err := alien.NewWatchdog(&watchdogChain{backend.BlockChain()}, backend.Engine(), cfg)
+	if err != nil {
+		Fatalf("Failed to register the operator watchdog: %v", err)
+	}
+	stack.RegisterLifecycle(watchdog)
+}
+
+// watchdogChain feeds the heads of the blockchain to the operator watchdog.
+type watchdogChain struct {
+	*core.BlockChain
+}
+
+// SubscribeNewHead implements alien.WatchdogChain, forwarding the header of
+// each chain head event.
+func (c *watchdogChain) SubscribeNewHead(ch chan<- *types.Header) event.Subscription {
+	return event.NewSubscription(func(quit <-chan struct{}) error {
+		headCh := make(chan core.ChainHeadEvent, cap(ch))
+		sub := c.SubscribeChainHeadEvent(headCh)
+		defer sub.Unsubscribe()
+		for {
+			select {
+			case ev := <-headCh:
+				select {
+				case ch <- ev.Block.Header():
+				case <-quit:
+					return nil
+				}
+			case err := <-sub.Err():
+				return err
+			case <-quit:
+				return nil
+			}
+		}
+	})
+}
+
 // RegisterGraphQLService is a utility function to construct a new service and register it against a node.
 func RegisterGraphQLService(stack *node.Node, backend ethapi.Backend, cfg node.Config) {
 	if err := graphql.New(stack, backend, cfg.GraphQLCors, cfg.GraphQLVirtualHosts); err != nil {
@@ -20,6 +20,8 @@ package utils
 import (
 	"reflect"
 	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/core"
 )
 
 func Test_SplitTagsFlag(t *testing.T) {
@@ -62,3 +64,18 @@ func Test_SplitTagsFlag(t *testing.T) {
 		})
 	}
 }
+
+func Test_parseCustomTxLimit(t *testing.T) {
+	category, limit, err := parseCustomTxLimit("UTG:1:stRent=2/3/0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if want := (core.CustomTxLimit{PerBlock: 2, AccountPending: 3}); category != "UTG:1:stRent" || limit != want {
+		t.Errorf("parseCustomTxLimit() = %s %+v, want UTG:1:stRent %+v", category, limit, want)
+	}
+	for _, entry := range []string{"UTG:1:stRent", "=1/1/1", "UTG:1:stRent=1/1", "UTG:1:stRent=1/x/1"} {
+		if _, _, err := parseCustomTxLimit(entry); err == nil {
+			t.Errorf("parseCustomTxLimit(%q) accepted", entry)
+		}
+	}
+}
@@ -163,6 +163,9 @@ type Alien struct {
 	signTxFn   SignTxFn            // Sign transaction function to sign tx
 	lock       sync.RWMutex        // Protects the signer fields
 	lcsc       uint64              // Last confirmed side chain
+	watchdog   *Watchdog           // Operator watchdog serving alien_getAlerts
+
+	supplyDeltas *lru.ARCCache // Balance changes of recent blocks awaiting the supply indexer
 }
 
 // SignerFn hashes and signs the data to be signed by a backing account.
@@ -253,12 +256,14 @@ func New(config *params.AlienConfig, db ethdb.Database) *Alien {
 	// Allocate the snapshot caches and create the engine
 	recents, _ := lru.NewARC(inMemorySnapshots)
 	signatures, _ := lru.NewARC(inMemorySignatures)
+	supplyDeltas, _ := lru.NewARC(inMemorySupplyDeltas)
 
 	return &Alien{
-		config:     &conf,
-		db:         db,
-		recents:    recents,
-		signatures: signatures,
+		config:       &conf,
+		db:           db,
+		recents:      recents,
+		signatures:   signatures,
+		supplyDeltas: supplyDeltas,
 	}
 }
 
@@ -697,6 +702,16 @@ func (a *Alien) verifySeal(chain consensus.ChainHeaderReader, state *state.State
 						}
 					}
 				}
+				if isGEBridgeEffect(a.config, header.Number.Uint64()) {
+					if len(notice.CurrentBridge) != len(currentHeaderExtra.SideChainBridgeMint) {
+						return errMCBridgeMintInvalid
+					}
+					for _, mint := range currentHeaderExtra.SideChainBridgeMint {
+						if lock, ok := notice.CurrentBridge[mint.Hash]; !ok || mint.Amount == nil || !lock.equal(&mint) {
+							return errMCBridgeMintInvalid
+						}
+					}
+				}
 
 			}
 		}
@@ -785,6 +800,9 @@ func (a *Alien) parseNoticeInfo(notice *CCNotice) string {
 		for hash := range notice.CurrentCharging {
 			charging = append(charging, hash.Hex())
 		}
+		for hash := range notice.CurrentBridge {
+			charging = append(charging, hash.Hex())
+		}
 		return strings.Join(charging, "#")
 	}
 	return ""
@@ -820,7 +838,7 @@ func (a *Alien) getLastLoopInfo(chain consensus.ChainHeaderReader, header *types
 	return "", errGetLastLoopInfoFail
 }
 
-func (a *Alien) mcConfirmBlock(chain consensus.ChainHeaderReader, header *types.Header, notice *CCNotice) {
+func (a *Alien) mcConfirmBlock(chain consensus.ChainHeaderReader, header *types.Header, notice *CCNotice, snap *Snapshot) {
 
 	a.lock.RLock()
 	signer, signTxFn := a.signer, a.signTxFn
@@ -842,8 +860,12 @@ func (a *Alien) mcConfirmBlock(chain consensus.ChainHeaderReader, header *types.
 			}
 
 			chargingInfo := a.parseNoticeInfo(notice)
+			burnInfo := ""
+			if isGEBridgeEffect(snap.config, header.Number.Uint64()) {
+				burnInfo = a.buildBridgeBurnInfo(snap)
+			}
 
-			txData := a.buildSCEventConfirmData(chain.GetHeaderByNumber(0).ParentHash, header.Number, new(big.Int).SetUint64(header.Time), lastLoopInfo, chargingInfo)
+			txData := a.buildSCEventConfirmData(chain.GetHeaderByNumber(0).ParentHash, header.Number, new(big.Int).SetUint64(header.Time), lastLoopInfo, chargingInfo, burnInfo)
 			tx := types.NewTransaction(nonce, header.Coinbase, big.NewInt(0), mcTxDefaultGasLimit, mcTxDefaultGasPrice, txData)
 
 			if mcNetVersion == 0 {
@@ -892,12 +914,17 @@ func (a *Alien) GrantProfit(chain consensus.ChainHeaderReader, header *types.Hea
 		log.Info("alien Finalize exit 3")
 		return nil, nil
 	}
+	if journalOf(state) == nil {
+		beginBalanceJournal(state)
+	}
+	setBalanceReason(state, balanceReasonGrantProfit, "")
 	timeNow := time.Now()
 	var playGrantProfit []consensus.GrantProfitRecord
 	var currentGrantProfit []consensus.GrantProfitRecord
 	payAddressAll := make(map[common.Address]*big.Int)
+	splits := snap.revenueSplits(number)
 	for address, item := range snap.CandidatePledge {
-		result, amount := paymentPledge(false, item, state, header, payAddressAll)
+		result, amount := paymentPledge(false, item, state, header, payAddressAll, splits)
 		if 0 == result {
 			playGrantProfit = append(playGrantProfit, consensus.GrantProfitRecord{
 				Which:           sscEnumCndLock,
@@ -922,7 +949,7 @@ func (a *Alien) GrantProfit(chain consensus.ChainHeaderReader, header *types.Hea
 		}
 	}
 	for address, item := range snap.FlowPledge {
-		result, amount := paymentPledge(true, item, state, header, payAddressAll)
+		result, amount := paymentPledge(true, item, state, header, payAddressAll, splits)
 		if 0 == result {
 			playGrantProfit = append(playGrantProfit, consensus.GrantProfitRecord{
 				Which:           sscEnumFlwLock,
@@ -945,10 +972,12 @@ func (a *Alien) GrantProfit(chain consensus.ChainHeaderReader, header *types.Hea
 			})
 		}
 	}
-	currentGrantProfit, playGrantProfit, err = snap.FlowRevenue.payProfit(a.db, chain.Config().Alien.Period, number, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+	currentGrantProfit, playGrantProfit, err = snap.FlowRevenue.payProfit(a.db, chain.Config().Alien.Period, number, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	if err != nil {
 		log.Warn("worker GrantProfit payProfit", "err", err)
 	}
+	playGrantProfit = append(playGrantProfit, splits.grantProfit()...)
+	setBalanceReason(state, balanceReasonGrantProfit, "")
 	toPayAddressBalance(header, payAddressAll, state)
 	log.Info("payProfit payAddressAll", "len(payAddressAll)", len(payAddressAll), "elapsed", time.Since(timeNow), "number", header.Number.Uint64())
 	return currentGrantProfit, playGrantProfit
@@ -972,6 +1001,14 @@ func (a *Alien) Finalize(chain consensus.ChainHeaderReader, header *types.Header
 		header.Time = uint64(time.Now().Unix())
 	}
 
+	// Record the balance changes, continuing the journal started by GrantProfit
+	journal := journalOf(state)
+	if journal == nil {
+		journal = beginBalanceJournal(state)
+	}
+	defer state.SetBalanceHook(nil)
+	var lockRewards []LockRewardRecord
+
 	// Ensure the extra data has all it's components
 	if len(header.Extra) < extraVanity {
 		header.Extra = append(header.Extra, bytes.Repeat([]byte{0x00}, extraVanity-len(header.Extra))...)
@@ -1088,6 +1125,9 @@ func (a *Alien) Finalize(chain consensus.ChainHeaderReader, header *types.Header
 			currentHeaderExtra.GrantProfitHash = snap.calGrantProfitHash(currentHeaderExtra.GrantProfit)
 			currentHeaderExtra.GrantProfit = []consensus.GrantProfitRecord{}
 		}
+		if isGEAutoCompoundEffect(snap.config, number) {
+			currentHeaderExtra.Compounded = snap.compoundRewards(grantProfit, currentHeaderExtra, state, number)
+		}
 		flowHarvest := big.NewInt(0)
 		// Accumulate any block rewards and commit the final state root
 		currentHeaderExtra.LockReward, flowHarvest = accumulateRewards(currentHeaderExtra.LockReward, chain.Config(), state, header, snap, refundGas, gasReward)
@@ -1097,15 +1137,20 @@ func (a *Alien) Finalize(chain consensus.ChainHeaderReader, header *types.Header
 			currentHeaderExtra.FlowHarvest = new(big.Int).Add(currentHeaderExtra.FlowHarvest, flowHarvest)
 		}
 
+		setBalanceReason(state, balanceReasonProposalRefund, "")
 		for proposer, refund := range snap.calculateProposalRefund() {
 			state.AddBalance(proposer, refund)
 		}
+		if isGEBridgeEffect(snap.config, number) {
+			payBridgeTransfers(state, snap, balanceReasonBridgeUnlock)
+		}
 		if es != nil {
 			harvest := big.NewInt(0)
 			var revertSrt []ExchangeSRTRecord
 			snap1 := snap.copy()
 			leftAmount := common.Big0
 			curLeaseSpace := common.Big0
+			setBalanceReason(state, balanceReasonStorageVerification, "")
 			currentHeaderExtra.LockReward, revertSrt, harvest, err, leftAmount, curLeaseSpace = snap1.storageVerificationCheck(header.Number.Uint64(), snap1.getBlockPreDay(), a.db, currentHeaderExtra.LockReward, state)
 			if err != nil {
 				return err
@@ -1121,6 +1166,7 @@ func (a *Alien) Finalize(chain consensus.ChainHeaderReader, header *types.Header
 				}
 			}
 			if isGEInitStorageManagerNumber(number) {
+				setBalanceReason(state, balanceReasonSpExit, "")
 				snap1.accumulateSpExitBurnAmount(header.Number, state)
 				if isSpVerificationCheck(header.Number.Uint64(), snap.Period) {
 					snap1.spAccumulatePublish(header.Number)
@@ -1130,6 +1176,7 @@ func (a *Alien) Finalize(chain consensus.ChainHeaderReader, header *types.Header
 				}
 			}
 
+			lockRewards = currentHeaderExtra.LockReward
 			currentHeaderExtra.LockReward, err = es.AddLockReward(currentHeaderExtra.LockReward, snap1, a.db, number)
 			if err != nil {
 				return err
@@ -1152,6 +1199,8 @@ func (a *Alien) Finalize(chain consensus.ChainHeaderReader, header *types.Header
 				currentHeaderExtra.SpDataRoot=snap1.SpData.Hash
 			}
 			if leftAmount != nil && leftAmount.Cmp(common.Big0) > 0 {
+				setBalanceReason(state, balanceReasonStorageLeftover, "")
+				releaseBurns(state)
 				state.AddBalance(common.BigToAddress(big.NewInt(0)), leftAmount)
 			}
 			log.Info("extrastate commit", "number", number, "esstateRoot", stateRoot, "lockaccountsRoot", lockAccountRoot)
@@ -1159,6 +1208,7 @@ func (a *Alien) Finalize(chain consensus.ChainHeaderReader, header *types.Header
 				currentHeaderExtra.SRTDataRoot = snap1.SRT.Root()
 			}
 			if isGEPOSNewEffect(number) {
+				setBalanceReason(state, balanceReasonCandidateExit, "")
 				currentHeaderExtra.CandidateAutoExit, currentHeaderExtra.CandidatePEntrustExit = snap1.checkCandidateAutoExit(header.Number.Uint64(), currentHeaderExtra.CandidateAutoExit, state, currentHeaderExtra.CandidatePEntrustExit)
 			}
 			if isGEPoCrsAccCalNumber(number) {
@@ -1172,7 +1222,12 @@ func (a *Alien) Finalize(chain consensus.ChainHeaderReader, header *types.Header
 			}
 		}
 
+		if es == nil {
+			lockRewards = currentHeaderExtra.LockReward
+		}
+		setBalanceReason(state, balanceReasonRepair, "")
 		a.RepairBal(state, number)
+		setBalanceReason(state, balanceReasonEngine, "")
 		if number%(snap.config.MaxSignerCount*snap.LCRS) == (snap.config.MaxSignerCount*snap.LCRS - 1) {
 			if number > tallyRevenueEffectBlockNumber {
 				if number < PosNewEffectNumber {
@@ -1208,6 +1263,7 @@ func (a *Alien) Finalize(chain consensus.ChainHeaderReader, header *types.Header
 	header.Extra = append(header.Extra, make([]byte, extraSeal)...)
 
 	header.Root = state.IntermediateRoot(chain.Config().IsEIP158(header.Number))
+	a.noteSupplyDelta(header, state, journal, lockRewards)
 	return nil
 }
 
@@ -1220,6 +1276,22 @@ func (a *Alien) FinalizeAndAssemble(chain consensus.ChainHeaderReader, header *t
 	return types.NewBlock(header, txs, nil, receipts, trie.NewStackTrie(nil)), nil
 }
 
+// TraceFinalize implements consensus.BalanceTracer, returning the balance
+// changes made while granting the profits and finalising the block. The block
+// is finalised by a trace engine on a copy of the header, so tracing leaves
+// the database, the caches of the engine and the header untouched.
+func (a *Alien) TraceFinalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt, gasReward *big.Int) ([]consensus.BalanceChange, error) {
+	tracer := a.traceEngine()
+	header = types.CopyHeader(header)
+
+	journal := beginBalanceJournal(state)
+	_, payProfit := tracer.GrantProfit(chain, header, state)
+	if err := tracer.Finalize(chain, header, state, txs, uncles, receipts, payProfit, gasReward); err != nil {
+		return nil, err
+	}
+	return journal.changes, nil
+}
+
 // Authorize injects a private key into the consensus engine to mint new blocks with.
 func (a *Alien) Authorize(signer common.Address, signFn SignerFn, signTxFn SignTxFn) {
 	a.lock.Lock()
@@ -1306,6 +1378,9 @@ func (a *Alien) Seal(chain consensus.ChainHeaderReader, block *types.Block, resu
 				for _, charge := range notice.CurrentCharging {
 					currentHeaderExtra.SideChainCharging = append(currentHeaderExtra.SideChainCharging, charge)
 				}
+				if isGEBridgeEffect(a.config, number) {
+					currentHeaderExtra.SideChainBridgeMint = bridgeTransferList(notice.CurrentBridge)
+				}
 				currentHeaderExtraEnc, err := encodeHeaderExtra(a.config, header.Number, currentHeaderExtra)
 				if err != nil {
 					return err
@@ -1315,7 +1390,7 @@ func (a *Alien) Seal(chain consensus.ChainHeaderReader, block *types.Block, resu
 				header.Extra = append(header.Extra, make([]byte, extraSeal)...)
 			}
 			// send tx to main chain to confirm this block
-			a.mcConfirmBlock(chain, header, notice)
+			a.mcConfirmBlock(chain, header, notice, snap)
 		}
 	}
 
@@ -1426,13 +1501,19 @@ func encodeSigHeader(w io.Writer, header *types.Header) {
 func sideChainRewards(config *params.ChainConfig, state *state.StateDB, header *types.Header, snap *Snapshot) {
 	// vanish gas fee
 	gasUsed := new(big.Int).SetUint64(header.GasUsed)
+	setBalanceReason(state, balanceReasonSideChainGas, "")
 	if state.GetBalance(header.Coinbase).Cmp(gasUsed) >= 0 {
 		state.SubBalance(header.Coinbase, gasUsed)
 	}
 	// gas charging
+	setBalanceReason(state, balanceReasonSideChainCharging, "")
 	for target, volume := range snap.calculateGasCharging() {
 		state.AddBalance(target, volume)
 	}
+	// bridge locks minted
+	if isGEBridgeEffect(snap.config, header.Number.Uint64()) {
+		payBridgeTransfers(state, snap, balanceReasonBridgeMint)
+	}
 }
 
 func paymentReward(minerAddress common.Address, amount *big.Int, state *state.StateDB, snap *Snapshot) {
@@ -1466,7 +1547,7 @@ func caclPayPeriodAmount(pledge *PledgeItem, headerNumber *big.Int) *big.Int {
 	return amount
 }
 
-func paymentPledge(hasContract bool, pledge *PledgeItem, state *state.StateDB, header *types.Header, payAddressAll map[common.Address]*big.Int) (int, *big.Int) {
+func paymentPledge(hasContract bool, pledge *PledgeItem, state *state.StateDB, header *types.Header, payAddressAll map[common.Address]*big.Int, splits *revenueSplits) (int, *big.Int) {
 	nilHash := common.Address{}
 	if 0 == pledge.StartHigh {
 		return -1, nil
@@ -1490,16 +1571,21 @@ func paymentPledge(hasContract bool, pledge *PledgeItem, state *state.StateDB, h
 		} else {
 			payAddress = pledge.MultiSignature
 		}
+		reason := lockReasonName(pledge.PledgeType)
 		if isGrantProfitOneTimeBlockNumber(header) {
 			payAmount := new(big.Int).Set(amount)
 			burnAmount := calBurnAmount(pledge, amount)
 			if burnAmount.Cmp(common.Big0) > 0 {
+				attributeBalance(state, pledge.BurnAddress, burnAmount, balanceReasonPenaltyBurn, reason+":"+pledge.TargetAddress.Hex())
 				addPayAddressBalance(pledge.BurnAddress, payAddressAll, burnAmount)
 				payAmount = new(big.Int).Sub(payAmount, burnAmount)
 			}
+			payAmount = splits.pay(pledge, payAddress, payAmount, state, payAddressAll, reason)
+			attributeBalance(state, payAddress, payAmount, reason, pledge.TargetAddress.Hex())
 			addPayAddressBalance(payAddress, payAddressAll, payAmount)
 			return 0, amount
 		} else {
+			setBalanceReason(state, reason, pledge.TargetAddress.Hex())
 			state.AddBalance(payAddress, amount)
 			log.Info("pay", "Address", payAddress, "amount", amount)
 			return 0, amount
@@ -1620,12 +1706,14 @@ func accumulateRewards(currentLockReward []LockRewardRecord, config *params.Chai
 	rewardScale, _ := big.NewFloat(1e+18 * 0.5 * math.Pow(0.96, float64(n.Int64()-1))).Int64()
 	minerReward := big.NewInt(rewardScale)
 	// refund gas for custom txs
+	setBalanceReason(state, balanceReasonGasRefund, "")
 	for sender, gas := range refundGas {
 		state.AddBalance(sender, gas)
 		if 0 < minerReward.Cmp(gas) {
 			minerReward.Sub(minerReward, gas)
 		}
 	}
+	setBalanceReason(state, balanceReasonGasFee, "")
 	balance := state.GetBalance(header.Coinbase)
 	if nil == gasReward {
 		currentLockReward = append(currentLockReward, LockRewardRecord{
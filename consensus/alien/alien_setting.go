@@ -1,6 +1,10 @@
 package alien
 
-import "math/big"
+import (
+	"math/big"
+
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
 
 const (
 	checkpointInterval = 360 //360        // About N hours if config.period is N
@@ -237,6 +241,58 @@ func isGEPoCrsAccCalNumber(number uint64) bool {
 func isGEInitStorageManagerNumber(number uint64) bool {
 	return number >= initStorageManagerNumber
 }
+// isGEUpgradeEffect returns whether number is at or past the block of an engine
+// upgrade. An unset block never activates the upgrade.
+func isGEUpgradeEffect(block *big.Int, number uint64) bool {
+	if block == nil {
+		return false
+	}
+	return number >= block.Uint64()
+}
+// isGEMultiSignatureManageEffect returns whether multi-signature addresses are
+// managed at number, which comes with the multi-signature spend fork.
+func isGEMultiSignatureManageEffect(config *params.ChainConfig, number uint64) bool {
+	return config.IsMultiSigSpend(new(big.Int).SetUint64(number))
+}
+func isGELeaseAutoRenewEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.LeaseAutoRenewBlock, number)
+}
+func isGELeaseTransferEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.LeaseTransferBlock, number)
+}
+func isGELeaseChallengeEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.LeaseChallengeBlock, number)
+}
+func isGERedelegateEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.RedelegateBlock, number)
+}
+func isGERevenueSplitEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.RevenueSplitBlock, number)
+}
+func isGEAutoCompoundEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.AutoCompoundBlock, number)
+}
+func isGEConfigProposalEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.ConfigProposalBlock, number)
+}
+func isGEConfigQueueEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.ConfigQueueBlock, number)
+}
+func isGEManagerMultiSignEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.ManagerMultiSignBlock, number)
+}
+func isGESignerRotateEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.SignerRotateBlock, number)
+}
+func isGECommissionNoticeEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.CommissionNoticeBlock, number)
+}
+func isGEBridgeEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.BridgeBlock, number)
+}
+func isGESideChainHistoryEffect(config *params.AlienConfig, number uint64) bool {
+	return isGEUpgradeEffect(config.SideChainHistoryBlock, number)
+}
 func isPaySTPEntrustExit(number uint64, period uint64) bool {
 	if number < initStorageManagerNumber {
 		return false
@@ -0,0 +1,66 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+// upgradeTestNumber is the block the engine upgrades are switched on at by
+// upgradedConfig. It lies above the blocks of the earlier upgrades, whose
+// effect numbers are fixed, and is a multiple of the signer count of the
+// test configs.
+const upgradeTestNumber = 6000000
+
+// upgradedConfig switches on the engine upgrades of the config at
+// upgradeTestNumber.
+func upgradedConfig(config *params.AlienConfig) *params.AlienConfig {
+	block := new(big.Int).SetUint64(upgradeTestNumber)
+	config.LeaseAutoRenewBlock = block
+	config.LeaseTransferBlock = block
+	config.LeaseChallengeBlock = block
+	config.RedelegateBlock = block
+	config.RevenueSplitBlock = block
+	config.AutoCompoundBlock = block
+	config.ConfigProposalBlock = block
+	config.ConfigQueueBlock = block
+	config.ManagerMultiSignBlock = block
+	config.SignerRotateBlock = block
+	config.CommissionNoticeBlock = block
+	config.BridgeBlock = block
+	config.SideChainHistoryBlock = block
+	return config
+}
+
+// TestUpgradeEffect checks that an engine upgrade is active from its block on
+// and never if the block is unset.
+func TestUpgradeEffect(t *testing.T) {
+	config := &params.AlienConfig{}
+	if isGERedelegateEffect(config, 1<<62) {
+		t.Errorf("upgrade without a block active")
+	}
+	upgradedConfig(config)
+	if isGERedelegateEffect(config, upgradeTestNumber-1) {
+		t.Errorf("upgrade active before its block")
+	}
+	if !isGERedelegateEffect(config, upgradeTestNumber) {
+		t.Errorf("upgrade inactive at its block")
+	}
+}
@@ -23,6 +23,7 @@ import (
 	"errors"
 	"github.com/UltronGlow/UltronGlow-Origin/common"
 	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
 	"github.com/UltronGlow/UltronGlow-Origin/core/types"
 	"github.com/UltronGlow/UltronGlow-Origin/ethdb"
 	"github.com/UltronGlow/UltronGlow-Origin/log"
@@ -36,6 +37,7 @@ import (
 
 var (
 	errNumberTooSmall = errors.New("block number too small")
+	errSupplyNotTracked = errors.New("supply not tracked at block")
 )
 
 // API is a user facing RPC API to allow controlling the signer and voting
@@ -114,6 +116,7 @@ func (api *API) GetSnapshotSignerAtNumber(number uint64) (*SnapshotSign, error)
 				TotalAmount: new(big.Int).Set(item.TotalAmount),
 				LastPunish:item.LastPunish,
 				DisRate:new(big.Int).Set(item.DisRate),
+				PendingRate:item.PendingRate.copy(),
 			}
 		}
 	}
@@ -131,6 +134,7 @@ type SignPledgeItem struct {
 	TotalAmount *big.Int                      `json:"totalamount"`
 	LastPunish  uint64                        `json:"lastpunish"`
 	DisRate     *big.Int                      `json:"distributerate"`
+	PendingRate *CommissionChange             `json:"pendingrate,omitempty"`
 }
 
 func (api *API) GetSnapshotReleaseAtNumber(number uint64,part string) (*SnapshotRelease, error) {
@@ -517,6 +521,7 @@ func (api *API) GetSPledgeAtNumber(number uint64) (*SnapshotSPledge, error) {
 			snapshotSPledge.StoragePledge[pledgeAddr].Sphash=entrust.Sphash
 			snapshotSPledge.StoragePledge[pledgeAddr].Spheight=new(big.Int).Set(entrust.Spheight)
 			snapshotSPledge.StoragePledge[pledgeAddr].EntrustRate=new(big.Int).Set(entrust.EntrustRate)
+			snapshotSPledge.StoragePledge[pledgeAddr].PendingRate=entrust.PendingRate.copy()
 			snapshotSPledge.StoragePledge[pledgeAddr].ManagerAmount=new(big.Int).Set(entrust.ManagerAmount)
 			snapshotSPledge.StoragePledge[pledgeAddr].Managerheight=new(big.Int).Set(entrust.Managerheight)
 			snapshotSPledge.StoragePledge[pledgeAddr].HavAmount=new(big.Int).Set(entrust.PledgeAmount)
@@ -568,6 +573,7 @@ type SPledge2 struct {
 	Sphash  common.Hash `json:"sphash"`
 	Spheight  *big.Int `json:"spheight"`
 	EntrustRate *big.Int `json:"entrustRate"`
+	PendingRate *CommissionChange `json:"pendingRate,omitempty"`
 	ManagerAmount  *big.Int `json:"managerAmount"`
 	Managerheight  *big.Int `json:"managerheight"`
 	HavAmount  *big.Int `json:"havAmount"`
@@ -1318,6 +1324,8 @@ func (api *API) GetSPoolAtNumber(number uint64) (*SpDataApi, error) {
 			EntrustRate:    spool.EntrustRate,
 			Status:         spool.Status,
 			EtDetail:       make(map[common.Hash]*EntrustDetailApi, 0),
+			PendingFee:     spool.PendingFee.copy(),
+			PendingEtRate:  spool.PendingEtRate.copy(),
 		}
 		for pledgeHash, detail := range spool.EtDetail {
 			snapshotSPdata.PoolPledgeItem[hash].EtDetail[pledgeHash] = &EntrustDetailApi{
@@ -1350,6 +1358,8 @@ type PoolPledgeApi struct {
 	EntrustRate    uint64                         `json:"entrustRate"`
 	Status         uint64                         `json:"status"`
 	EtDetail       map[common.Hash]*EntrustDetailApi `json:"entrustDetail"`
+	PendingFee     *CommissionChange              `json:"pendingFee,omitempty"`
+	PendingEtRate  *CommissionChange              `json:"pendingEntrustRate,omitempty"`
 }
 type EntrustDetailApi struct {
 	Address common.Address `json:"address"`
@@ -1473,4 +1483,76 @@ func (sr *SnapshotRewardBalanceV1) appendRBd(FlowRevenue map[common.Address]*Loc
 		}
 	}
 	return nil
-}
\ No newline at end of file
+}
+
+// headerByNumber retrieves the header at the given block, or the current header
+// if none is requested.
+func (api *API) headerByNumber(number *rpc.BlockNumber) (*types.Header, error) {
+	var header *types.Header
+	if number == nil || *number == rpc.LatestBlockNumber {
+		header = api.chain.CurrentHeader()
+	} else {
+		header = api.chain.GetHeaderByNumber(uint64(number.Int64()))
+	}
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	return header, nil
+}
+
+// GetSupply retrieves the token supply at a given block: the genesis allocation,
+// the minted funds by reward type, the funds in custody and released from it,
+// the burned funds by reason and the circulating supply. On a node that did not
+// execute every block the totals count from the block the tracking was seeded
+// from, reported as since.
+func (api *API) GetSupply(number *rpc.BlockNumber) (*Supply, error) {
+	log.Info("api GetSupply", "number", number)
+	header, err := api.headerByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := api.supplyStats(header)
+	if err != nil {
+		return nil, err
+	}
+	return stats.supply(header), nil
+}
+
+// supplyStats loads the supply statistics of a block written by the supply
+// indexer. The genesis block is summed up instead.
+func (api *API) supplyStats(header *types.Header) (*supplyStats, error) {
+	if header.Number.Sign() == 0 {
+		return seedSupplyStats(state.NewDatabase(api.alien.db), 0, header.Root)
+	}
+	stats, err := loadSupplyStats(api.alien.db, header.Number.Uint64(), header.Hash())
+	if err != nil {
+		return nil, errSupplyNotTracked
+	}
+	return stats, nil
+}
+
+// VerifySupply compares the tracked balances at a given block with the sum of
+// all balances in its state. The state of the block must be available.
+func (api *API) VerifySupply(number *rpc.BlockNumber) (*SupplyCheck, error) {
+	log.Info("api VerifySupply", "number", number)
+	header, err := api.headerByNumber(number)
+	if err != nil {
+		return nil, err
+	}
+	stats, err := api.supplyStats(header)
+	if err != nil {
+		return nil, err
+	}
+	stateBalances, err := sumBalances(state.NewDatabase(api.alien.db), header.Root)
+	if err != nil {
+		return nil, err
+	}
+	supply := stats.supply(header)
+	return &SupplyCheck{
+		Number:        supply.Number,
+		Hash:          supply.Hash,
+		Balances:      supply.Balances,
+		StateBalances: stateBalances,
+		Consistent:    supply.Balances.Cmp(stateBalances) == 0,
+	}, nil
+}
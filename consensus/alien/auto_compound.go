@@ -0,0 +1,355 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/ethdb"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+	"github.com/shopspring/decimal"
+)
+
+// compoundableLocks are the locks whose releases are rewards. Released
+// pledges and exits are never compounded.
+var compoundableLocks = map[uint32]bool{
+	sscEnumRwdLock:             true,
+	sscEnumSignerReward:        true,
+	sscEnumFlwReward:           true,
+	sscEnumBandwidthReward:     true,
+	sscSpLockReward:            true,
+	sscSpEntrustLockReward:     true,
+	sscEnumSTEntrustLockReward: true,
+}
+
+// AutoCompound is the standing instruction of a delegator to delegate its
+// released rewards to a PoS candidate or an inactive storage node as soon as
+// a payout reaches the threshold.
+type AutoCompound struct {
+	Owner      common.Address `json:"owner"`
+	TargetType string         `json:"targettype"`
+	Target     common.Address `json:"target"`
+	Threshold  *big.Int       `json:"threshold"`
+	Compounded *big.Int       `json:"compounded"`
+	LastNumber uint64         `json:"lastnumber"`
+}
+
+// AutoCompoundRecord registers or, with a zero threshold, cancels the
+// instruction of Address.
+type AutoCompoundRecord struct {
+	Address    common.Address
+	TargetType string
+	Target     common.Address
+	Threshold  *big.Int
+}
+
+// CompoundRecord is a payout delegated by the engine, Hash being the hash of
+// the new delegation.
+type CompoundRecord struct {
+	Address    common.Address
+	TargetType string
+	Target     common.Address
+	Hash       common.Hash
+	Amount     *big.Int
+}
+
+func (c *AutoCompound) copy() *AutoCompound {
+	return &AutoCompound{
+		Owner:      c.Owner,
+		TargetType: c.TargetType,
+		Target:     c.Target,
+		Threshold:  new(big.Int).Set(c.Threshold),
+		Compounded: new(big.Int).Set(c.Compounded),
+		LastNumber: c.LastNumber,
+	}
+}
+
+// processAutoCompound handles
+// utg:1:AutoCompound:<PoS|SN>:<target>:<threshold>, a zero threshold
+// cancelling the instruction of the sender.
+func (a *Alien) processAutoCompound(currentAutoCompound []AutoCompoundRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, snap *Snapshot, number uint64) []AutoCompoundRecord {
+	if len(txDataInfo) < 6 {
+		log.Warn("AutoCompound", "parameter number", len(txDataInfo))
+		return currentAutoCompound
+	}
+	record := AutoCompoundRecord{
+		Address:   txSender,
+		Threshold: big.NewInt(0),
+	}
+	postion := 3
+	record.TargetType = txDataInfo[postion]
+	if record.TargetType != TargetTypePos && record.TargetType != TargetTypeSn {
+		log.Warn("AutoCompound", "target type", txDataInfo[postion])
+		return currentAutoCompound
+	}
+	postion++
+	if err := record.Target.UnmarshalText1([]byte(txDataInfo[postion])); err != nil {
+		log.Warn("AutoCompound", "target", txDataInfo[postion])
+		return currentAutoCompound
+	}
+	postion++
+	if threshold, err := decimal.NewFromString(txDataInfo[postion]); err != nil || threshold.Sign() < 0 {
+		log.Warn("AutoCompound", "threshold", txDataInfo[postion])
+		return currentAutoCompound
+	} else {
+		record.Threshold = threshold.BigInt()
+	}
+	for _, item := range currentAutoCompound {
+		if item.Address == txSender {
+			log.Warn("AutoCompound", "address only one in one block", txSender)
+			return currentAutoCompound
+		}
+	}
+	if record.Threshold.Sign() == 0 {
+		if _, ok := snap.AutoCompound[txSender]; !ok {
+			log.Warn("AutoCompound", "no instruction to cancel", txSender)
+			return currentAutoCompound
+		}
+	} else if !snap.checkAutoCompound(record) {
+		return currentAutoCompound
+	}
+	topics := make([]common.Hash, 2)
+	topics[0].UnmarshalText([]byte("0x754dd04022d1b5133aae713117aaf5f85f01293a5bc9f8160f91cb6f37baf5ef")) //web3.sha3("AutoCompound(address,uint256)")
+	topics[1].SetBytes(record.Target.Bytes())
+	a.addCustomerTxLog(tx, receipts, topics, common.BigToHash(record.Threshold).Bytes())
+	return append(currentAutoCompound, record)
+}
+
+// checkAutoCompound applies the rules of CandEntrust and stwtpg to the
+// instruction, the threshold being the smallest amount delegated.
+func (snap *Snapshot) checkAutoCompound(record AutoCompoundRecord) bool {
+	nilAddr := common.Address{}
+	if record.TargetType == TargetTypePos {
+		if _, ok := snap.PosPledge[record.Target]; !ok {
+			log.Warn("AutoCompound", "candidate is not exist", record.Target)
+			return false
+		}
+		if _, ok := snap.PosPledge[record.Address]; ok {
+			log.Warn("AutoCompound", "txSender is miner address", record.Address)
+			return false
+		}
+		if targetMiner := snap.findPosTargetMiner(record.Address); targetMiner != nilAddr && targetMiner != record.Target {
+			log.Warn("AutoCompound", "one address can only pledge one miner ", targetMiner)
+			return false
+		}
+		if record.Threshold.Cmp(minCndEntrustPledgeBalance) < 0 {
+			log.Warn("AutoCompound", "threshold less than 1", record.Threshold)
+			return false
+		}
+		return true
+	}
+	if snap.StorageData == nil {
+		return false
+	}
+	storagePledge, ok := snap.StorageData.StoragePledge[record.Target]
+	if !ok {
+		log.Warn("AutoCompound", "StoragePledge is not exist", record.Target)
+		return false
+	}
+	if _, ok := snap.StorageData.StorageEntrust[record.Target]; !ok {
+		log.Warn("AutoCompound", "StorageEntrust is not exist", record.Target)
+		return false
+	}
+	if storagePledge.PledgeStatus.Cmp(big.NewInt(SPledgeInactive)) != 0 {
+		log.Warn("AutoCompound", "pledgeStatus is not inactive", record.Target)
+		return false
+	}
+	if _, ok := snap.StorageData.StoragePledge[record.Address]; ok {
+		log.Warn("AutoCompound", "txSender is Storage address", record.Address)
+		return false
+	}
+	if targetMiner := snap.findStorageTargetMiner(record.Address); targetMiner != nilAddr && targetMiner != record.Target {
+		log.Warn("AutoCompound", "one address can only pledge one miner ", targetMiner)
+		return false
+	}
+	if record.Threshold.Cmp(utgOneValue) < 0 {
+		log.Warn("AutoCompound", "threshold small than 1 utg", record.Threshold)
+		return false
+	}
+	return true
+}
+
+func (snap *Snapshot) updateAutoCompound(records []AutoCompoundRecord, number uint64) {
+	if len(records) == 0 {
+		return
+	}
+	if snap.AutoCompound == nil {
+		snap.AutoCompound = make(map[common.Address]*AutoCompound)
+	}
+	for _, item := range records {
+		if item.Threshold.Sign() == 0 {
+			delete(snap.AutoCompound, item.Address)
+			continue
+		}
+		compounded := big.NewInt(0)
+		if old, ok := snap.AutoCompound[item.Address]; ok {
+			compounded = old.Compounded
+		}
+		snap.AutoCompound[item.Address] = &AutoCompound{
+			Owner:      item.Address,
+			TargetType: item.TargetType,
+			Target:     item.Target,
+			Threshold:  new(big.Int).Set(item.Threshold),
+			Compounded: compounded,
+			LastNumber: number,
+		}
+	}
+}
+
+// compoundRewards delegates the rewards paid out by grantProfit to the
+// owners of an instruction, after they have been credited. An owner is
+// compounded when the rewards paid to it, revenue shares excluded, reach the
+// threshold; penalty burns are covered by capping the amount to the balance.
+// It runs in Finalize against the snapshot of the parent block, so the
+// delegations of headerExtra made by the transactions of the block are taken
+// into account as the custom transactions would.
+func (snap *Snapshot) compoundRewards(grantProfit []consensus.GrantProfitRecord, headerExtra HeaderExtra, state *state.StateDB, number uint64) []CompoundRecord {
+	if len(snap.AutoCompound) == 0 || len(grantProfit) == 0 {
+		return nil
+	}
+	splits := snap.revenueSplits(number)
+	released := make(map[common.Address]*big.Int)
+	zeroHash := common.BigToAddress(big.NewInt(0))
+	for _, item := range grantProfit {
+		if !compoundableLocks[item.Which] || item.Amount == nil || item.Amount.Sign() <= 0 {
+			continue
+		}
+		payAddress := item.RevenueAddress
+		if item.MultiSignature != (common.Address{}) && item.MultiSignature != zeroHash {
+			payAddress = item.MultiSignature
+		}
+		if _, ok := snap.AutoCompound[payAddress]; !ok {
+			continue
+		}
+		amount := new(big.Int).Set(item.Amount)
+		pledge := &PledgeItem{PledgeType: item.Which, TargetAddress: item.MinerAddress, RevenueAddress: item.RevenueAddress}
+		for _, share := range splits.shares(pledge, payAddress) {
+			part := new(big.Int).Mul(item.Amount, new(big.Int).SetUint64(share.Share))
+			amount.Sub(amount, part.Div(part, big.NewInt(revenueShareBase)))
+		}
+		if total, ok := released[payAddress]; ok {
+			amount.Add(amount, total)
+		}
+		released[payAddress] = amount
+	}
+	owners := make([]common.Address, 0, len(released))
+	for owner := range released {
+		owners = append(owners, owner)
+	}
+	sort.Slice(owners, func(i, j int) bool { return bytes.Compare(owners[i].Bytes(), owners[j].Bytes()) < 0 })
+	var records []CompoundRecord
+	for _, owner := range owners {
+		compound := snap.AutoCompound[owner]
+		if released[owner].Cmp(compound.Threshold) < 0 {
+			continue
+		}
+		amount := new(big.Int).Set(released[owner])
+		if balance := state.GetBalance(owner); balance.Cmp(amount) < 0 {
+			amount = new(big.Int).Set(balance)
+		}
+		amount = snap.compoundAmount(compound, amount, headerExtra, records)
+		if amount == nil {
+			continue
+		}
+		record := CompoundRecord{
+			Address:    owner,
+			TargetType: compound.TargetType,
+			Target:     compound.Target,
+			Hash:       getHash(owner.String() + new(big.Int).SetUint64(number).String()),
+			Amount:     amount,
+		}
+		setBalanceReason(state, balanceReasonAutoCompound, compound.Target.Hex())
+		state.SubBalance(owner, amount)
+		records = append(records, record)
+	}
+	return records
+}
+
+// compoundAmount checks that the target still accepts the delegation of the
+// owner and returns the part of amount it accepts, nil when none.
+func (snap *Snapshot) compoundAmount(compound *AutoCompound, amount *big.Int, headerExtra HeaderExtra, records []CompoundRecord) *big.Int {
+	if !snap.checkAutoCompound(AutoCompoundRecord{Address: compound.Owner, TargetType: compound.TargetType, Target: compound.Target, Threshold: compound.Threshold}) {
+		return nil
+	}
+	if compound.TargetType == TargetTypePos {
+		if isInCurrentPOSTransfer(headerExtra.POSTransfer, compound.Owner) {
+			return nil
+		}
+		for _, item := range headerExtra.CandidatePledgeEntrust {
+			if item.Address == compound.Owner && item.Target != compound.Target {
+				return nil
+			}
+		}
+		if amount.Cmp(minCndEntrustPledgeBalance) < 0 {
+			return nil
+		}
+		return amount
+	}
+	storageEntrust := snap.StorageData.StorageEntrust[compound.Target]
+	pledgeAmount := new(big.Int).Set(storageEntrust.PledgeAmount)
+	for _, item := range headerExtra.SPEntrust {
+		if item.Address == compound.Owner && item.Target != compound.Target {
+			return nil
+		}
+		if item.Target == compound.Target {
+			pledgeAmount.Add(pledgeAmount, item.Amount)
+		}
+	}
+	for _, item := range records {
+		if item.TargetType == TargetTypeSn && item.Target == compound.Target {
+			pledgeAmount.Add(pledgeAmount, item.Amount)
+		}
+	}
+	room := new(big.Int).Sub(snap.StorageData.StoragePledge[compound.Target].SpaceDeposit, pledgeAmount)
+	if room.Cmp(amount) < 0 {
+		amount = room
+	}
+	amount = new(big.Int).Sub(amount, new(big.Int).Mod(amount, utgOneValue))
+	if amount.Cmp(utgOneValue) < 0 {
+		return nil
+	}
+	return amount
+}
+
+// updateCompounded adds the compounded payouts as new delegations, in the
+// same way as the entrust transactions do.
+func (snap *Snapshot) updateCompounded(records []CompoundRecord, number *big.Int, db ethdb.Database) {
+	if len(records) == 0 {
+		return
+	}
+	var posEntrust []CandidatePledgeEntrustRecord
+	var snEntrust []SPEntrustRecord
+	for _, item := range records {
+		if item.TargetType == TargetTypePos {
+			posEntrust = append(posEntrust, CandidatePledgeEntrustRecord{Target: item.Target, Amount: new(big.Int).Set(item.Amount), Address: item.Address, Hash: item.Hash})
+		} else {
+			snEntrust = append(snEntrust, SPEntrustRecord{Target: item.Target, Amount: new(big.Int).Set(item.Amount), Address: item.Address, Hash: item.Hash})
+		}
+		if compound, ok := snap.AutoCompound[item.Address]; ok {
+			compound.Compounded = new(big.Int).Add(compound.Compounded, item.Amount)
+			compound.LastNumber = number.Uint64()
+		}
+	}
+	snap.updateCandidatePledgeEntrust(posEntrust, number.Uint64())
+	snap.updateSPEntrust(snEntrust, number, db)
+}
@@ -0,0 +1,152 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+)
+
+func utgAmount(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), utgOneValue)
+}
+
+func sendAutoCompound(snap *Snapshot, sender common.Address, data string, number uint64) []AutoCompoundRecord {
+	tx := types.NewTransaction(0, sender, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+	receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(number)}}
+	return (&Alien{}).processAutoCompound(nil, strings.Split(data, ":"), sender, tx, receipts, snap, number)
+}
+
+// releaseRewards credits amount to owner as a signer reward of the candidate
+// and returns the matching grant record.
+func releaseRewards(statedb *state.StateDB, owner common.Address, amount *big.Int) []consensus.GrantProfitRecord {
+	statedb.AddBalance(owner, amount)
+	return []consensus.GrantProfitRecord{
+		{Which: sscEnumSignerReward, MinerAddress: redelegateCandidate, Amount: new(big.Int).Set(amount), RevenueAddress: owner},
+		{Which: sscEnumPosExitLock, MinerAddress: redelegateCandidate, Amount: utgAmount(10), RevenueAddress: owner},
+	}
+}
+
+func TestAutoCompoundToCandidate(t *testing.T) {
+	snap := newRedelegateSnapshot()
+	number := upgradeTestNumber + uint64(10)
+	prefix := "utg:1:AutoCompound:PoS:" + redelegateCandidate.Hex() + ":"
+
+	for _, data := range []string{
+		prefix + "100",
+		prefix + "0",
+		"utg:1:AutoCompound:SN:" + redelegateNode.Hex() + ":100",
+		"utg:1:AutoCompound:SP:" + redelegateCandidate.Hex() + ":" + utgAmount(1).String(),
+	} {
+		if records := sendAutoCompound(snap, redelegateDelegator, data, number); len(records) != 0 {
+			t.Errorf("invalid instruction accepted: %s", data)
+		}
+	}
+	records := sendAutoCompound(snap, redelegateDelegator, prefix+utgAmount(2).String(), number)
+	if len(records) != 1 {
+		t.Fatalf("instruction rejected")
+	}
+	if err := verifyAutoCompound(records, records); err != nil {
+		t.Fatalf("instruction records differ: %v", err)
+	}
+	snap.updateAutoCompound(records, number)
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	// Payouts below the threshold stay liquid.
+	grantProfit := releaseRewards(statedb, redelegateDelegator, new(big.Int).Sub(utgAmount(2), common.Big1))
+	if compounded := snap.compoundRewards(grantProfit, HeaderExtra{}, statedb, number); len(compounded) != 0 {
+		t.Fatalf("payout below the threshold compounded")
+	}
+	grantProfit = releaseRewards(statedb, redelegateDelegator, utgAmount(3))
+	compounded := snap.compoundRewards(grantProfit, HeaderExtra{}, statedb, number)
+	if len(compounded) != 1 || compounded[0].Amount.Cmp(utgAmount(3)) != 0 {
+		t.Fatalf("payout not compounded: %v", compounded)
+	}
+	if err := verifyCompounded(compounded, compounded); err != nil {
+		t.Fatalf("compounded records differ: %v", err)
+	}
+	if balance := statedb.GetBalance(redelegateDelegator); balance.Cmp(new(big.Int).Sub(utgAmount(2), common.Big1)) != 0 {
+		t.Errorf("balance mismatch: %v", balance)
+	}
+	snap.updateCompounded(compounded, new(big.Int).SetUint64(number), nil)
+	candidate := snap.PosPledge[redelegateCandidate]
+	if detail := candidate.Detail[compounded[0].Hash]; detail == nil || detail.Address != redelegateDelegator || detail.Amount.Cmp(utgAmount(3)) != 0 {
+		t.Fatalf("delegation missing: %+v", detail)
+	}
+	if candidate.TotalAmount.Cmp(utgAmount(8)) != 0 {
+		t.Errorf("candidate total mismatch: %v", candidate.TotalAmount)
+	}
+	if compound := snap.AutoCompound[redelegateDelegator]; compound.Compounded.Cmp(utgAmount(3)) != 0 || compound.LastNumber != number {
+		t.Errorf("instruction not updated: %+v", compound)
+	}
+
+	// A delegation made to another candidate in the same block wins.
+	other := common.HexToAddress("0x0d")
+	grantProfit = releaseRewards(statedb, redelegateDelegator, utgAmount(3))
+	headerExtra := HeaderExtra{CandidatePledgeEntrust: []CandidatePledgeEntrustRecord{{Target: other, Amount: utgAmount(1), Address: redelegateDelegator}}}
+	if compounded := snap.compoundRewards(grantProfit, headerExtra, statedb, number+1); len(compounded) != 0 {
+		t.Errorf("payout compounded to a second candidate")
+	}
+
+	if records := sendAutoCompound(snap, redelegateDelegator, prefix+"0", number); len(records) != 1 {
+		t.Fatalf("cancellation rejected")
+	}
+	snap.updateAutoCompound([]AutoCompoundRecord{{Address: redelegateDelegator, Threshold: big.NewInt(0)}}, number)
+	if len(snap.AutoCompound) != 0 {
+		t.Errorf("instruction not cancelled")
+	}
+}
+
+func TestAutoCompoundToStorageNode(t *testing.T) {
+	snap := newRedelegateSnapshot()
+	number := upgradeTestNumber + uint64(10)
+	owner := common.HexToAddress("0x0e")
+	records := sendAutoCompound(snap, owner, "utg:1:AutoCompound:SN:"+redelegateNode.Hex()+":"+utgAmount(1).String(), number)
+	if len(records) != 1 {
+		t.Fatalf("instruction rejected")
+	}
+	snap.updateAutoCompound(records, number)
+
+	// The node has room for two units of its space deposit, the rest of the
+	// payout and the fraction of a unit stay liquid.
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	released := new(big.Int).Add(utgAmount(4), big.NewInt(5))
+	grantProfit := releaseRewards(statedb, owner, released)
+	compounded := snap.compoundRewards(grantProfit, HeaderExtra{}, statedb, number)
+	if len(compounded) != 1 || compounded[0].Amount.Cmp(utgAmount(2)) != 0 {
+		t.Fatalf("payout not capped to the space deposit: %v", compounded)
+	}
+	if balance := statedb.GetBalance(owner); balance.Cmp(new(big.Int).Add(utgAmount(2), big.NewInt(5))) != 0 {
+		t.Errorf("balance mismatch: %v", balance)
+	}
+	snap.updateCompounded(compounded, new(big.Int).SetUint64(number), nil)
+	if snap.StorageData.StoragePledge[redelegateNode].PledgeStatus.Cmp(big.NewInt(SPledgeNormal)) != 0 {
+		t.Errorf("node not activated by a full space deposit")
+	}
+	// A full node takes no more delegations.
+	grantProfit = releaseRewards(statedb, owner, utgAmount(4))
+	if compounded := snap.compoundRewards(grantProfit, HeaderExtra{}, statedb, number+1); len(compounded) != 0 {
+		t.Errorf("payout compounded to an active node")
+	}
+}
@@ -0,0 +1,240 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+)
+
+// Reasons of the balance changes made by the engine while finalising a block.
+const (
+	balanceReasonGrantProfit         = "grantProfit"
+	balanceReasonCustomTx            = "customTx"
+	balanceReasonGasRefund           = "gasRefund"
+	balanceReasonGasFee              = "gasFee"
+	balanceReasonProposalRefund      = "proposalRefund"
+	balanceReasonStorageVerification = "storageVerification"
+	balanceReasonStorageLeftover     = "storageLeftover"
+	balanceReasonLeaseDepositRefund  = "leaseDepositRefund"
+	balanceReasonStorageLeaseReward  = "storageLeaseReward"
+	balanceReasonPledgeRedemption    = "storagePledgeRedemption"
+	balanceReasonSRTExchange         = "srtExchange"
+	balanceReasonSpExit              = "spExit"
+	balanceReasonCandidateExit       = "candidateAutoExit"
+	balanceReasonRepair              = "repairBalance"
+	balanceReasonSideChainGas        = "sideChainGas"
+	balanceReasonSideChainCharging   = "sideChainCharging"
+	balanceReasonPenaltyBurn         = "penaltyBurn"
+	balanceReasonRevenueShare        = "revenueShare"
+	balanceReasonAutoCompound        = "autoCompound"
+	balanceReasonBridgeUnlock        = "bridgeUnlock"
+	balanceReasonBridgeMint          = "bridgeMint"
+	balanceReasonAutoRenewRefund     = "autoRenewRefund"
+	balanceReasonEngine              = "engine"
+)
+
+// lockReasonNames names the payments released from each kind of lock.
+var lockReasonNames = map[uint32]string{
+	sscEnumCndLock:                 "candidatePledge",
+	sscEnumFlwLock:                 "flowPledge",
+	sscEnumRwdLock:                 "rewardLock",
+	sscEnumSignerReward:            "signerReward",
+	sscEnumFlwReward:               "flowReward",
+	sscEnumBandwidthReward:         "bandwidthReward",
+	sscEnumStoragePledgeRedeemLock: balanceReasonPledgeRedemption,
+	sscEnumPosExitLock:             "posExit",
+	sscSpLockReward:                "spReward",
+	sscSpEntrustLockReward:         "spEntrustReward",
+	sscSpEntrustExitLockReward:     "spEntrustExit",
+	sscSpExitLockReward:            "spExit",
+	sscEnumSTEntrustExitLock:       "storageEntrustExit",
+	sscEnumSTEntrustLockReward:     balanceReasonStorageLeaseReward,
+}
+
+// lockReasonName returns the reason of a payment released from a lock.
+func lockReasonName(pledgeType uint32) string {
+	if name, ok := lockReasonNames[pledgeType]; ok {
+		return name
+	}
+	return balanceReasonGrantProfit
+}
+
+// balanceAttribution is a credit announced ahead of the balance change that
+// pays it, for payments aggregated per account before being credited.
+type balanceAttribution struct {
+	reason string
+	source string
+	amount *big.Int
+}
+
+// balanceCall is a balance change observed by the journal, remembering its
+// index in the state journal, where its records start and the attributions
+// pending before it so that a revert of the state can undo it.
+type balanceCall struct {
+	index   int
+	first   int
+	pending []balanceAttribution
+}
+
+// balanceJournal records the balance changes of a block, tagging each with
+// the reason set by the engine at the time or the attribution announced for
+// the credited account.
+type balanceJournal struct {
+	reason   string
+	source   string
+	pending  map[common.Address][]*balanceAttribution
+	deferred []*balanceAttribution
+	changes  []consensus.BalanceChange
+	calls    []balanceCall
+}
+
+// BalanceChanged implements state.BalanceHook.
+func (j *balanceJournal) BalanceChanged(index int, addr common.Address, prev, balance *big.Int) {
+	call := balanceCall{index: index, first: len(j.changes)}
+	for _, item := range j.pending[addr] {
+		call.pending = append(call.pending, *item)
+	}
+	j.calls = append(j.calls, call)
+
+	delta := new(big.Int).Sub(balance, prev)
+	for delta.Sign() > 0 && len(j.pending[addr]) > 0 {
+		item := j.pending[addr][0]
+		amount := item.amount
+		if amount.Cmp(delta) > 0 {
+			amount = delta
+			item.amount = new(big.Int).Sub(item.amount, delta)
+		} else {
+			j.pending[addr] = j.pending[addr][1:]
+		}
+		j.record(addr, amount, item.reason, item.source)
+		delta = new(big.Int).Sub(delta, amount)
+	}
+	if len(j.pending[addr]) == 0 {
+		delete(j.pending, addr)
+	}
+	if delta.Sign() != 0 {
+		j.record(addr, delta, j.reason, j.source)
+	}
+}
+
+// BalanceReverted implements state.BalanceHook, dropping the records of the
+// reverted change and restoring the attributions it consumed. Reverts come in
+// the reverse order of the changes, so the change is the last one observed if
+// it has the same index in the state journal. Changes made before the journal
+// was installed are countered by a record instead.
+func (j *balanceJournal) BalanceReverted(index int, addr common.Address, balance, prev *big.Int) {
+	n := len(j.calls)
+	if n == 0 || j.calls[n-1].index != index {
+		if delta := new(big.Int).Sub(prev, balance); delta.Sign() != 0 {
+			j.record(addr, delta, j.reason, j.source)
+		}
+		return
+	}
+	call := j.calls[n-1]
+	j.calls = j.calls[:n-1]
+	j.changes = j.changes[:call.first]
+
+	delete(j.pending, addr)
+	for i := range call.pending {
+		item := call.pending[i]
+		j.pending[addr] = append(j.pending[addr], &item)
+	}
+}
+
+func (j *balanceJournal) record(addr common.Address, delta *big.Int, reason string, source string) {
+	j.changes = append(j.changes, consensus.BalanceChange{
+		Address: addr,
+		Delta:   new(big.Int).Set(delta),
+		Reason:  reason,
+		Source:  source,
+	})
+}
+
+// beginBalanceJournal installs a new balance journal on the state.
+func beginBalanceJournal(state *state.StateDB) *balanceJournal {
+	journal := &balanceJournal{
+		reason:  balanceReasonEngine,
+		pending: make(map[common.Address][]*balanceAttribution),
+	}
+	state.SetBalanceHook(journal)
+	return journal
+}
+
+// journalOf returns the balance journal installed on the state, if any.
+func journalOf(state *state.StateDB) *balanceJournal {
+	if state == nil {
+		return nil
+	}
+	if journal, ok := state.BalanceHook().(*balanceJournal); ok {
+		return journal
+	}
+	return nil
+}
+
+// setBalanceReason tags the following balance changes of the state.
+func setBalanceReason(state *state.StateDB, reason string, source string) {
+	if journal := journalOf(state); journal != nil {
+		journal.reason, journal.source = reason, source
+	}
+}
+
+// attributeBalance announces a credit of the account that is paid later
+// together with other credits.
+func attributeBalance(state *state.StateDB, addr common.Address, amount *big.Int, reason string, source string) {
+	if journal := journalOf(state); journal != nil && amount.Sign() > 0 {
+		journal.pending[addr] = append(journal.pending[addr], &balanceAttribution{
+			reason: reason,
+			source: source,
+			amount: new(big.Int).Set(amount),
+		})
+	}
+}
+
+// deferBurn holds the tag of the growth of a running burn total that is
+// burned at once later on. A total that shrank was replaced by the last
+// amount, which then carries the whole total.
+func deferBurn(state *state.StateDB, prev *big.Int, total *big.Int, source string) {
+	journal := journalOf(state)
+	if journal == nil || total == nil {
+		return
+	}
+	amount := new(big.Int).Sub(total, prev)
+	if amount.Sign() < 0 {
+		journal.deferred = nil
+		amount = new(big.Int).Set(total)
+	}
+	if amount.Sign() > 0 {
+		journal.deferred = append(journal.deferred, &balanceAttribution{
+			reason: balanceReasonPenaltyBurn,
+			source: source,
+			amount: amount,
+		})
+	}
+}
+
+// releaseBurns announces the held burn tags ahead of the burn paying them, so
+// that the burns made in between do not take them.
+func releaseBurns(state *state.StateDB) {
+	if journal := journalOf(state); journal != nil && len(journal.deferred) > 0 {
+		journal.pending[burnAddress] = append(journal.pending[burnAddress], journal.deferred...)
+		journal.deferred = nil
+	}
+}
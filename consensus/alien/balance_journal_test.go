@@ -0,0 +1,139 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+)
+
+// TestBalanceJournalBurns checks that burns are recorded with the record they
+// originate from, whether deferred to a single burn or burned on their own.
+func TestBalanceJournalBurns(t *testing.T) {
+	var (
+		depositor = common.HexToAddress("0x1100000000000000000000000000000000000011")
+		lease     = common.HexToHash("0x22")
+		pledge    = common.HexToAddress("0x3300000000000000000000000000000000000033")
+		sp        = common.HexToAddress("0x4400000000000000000000000000000000000044")
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	journal := beginBalanceJournal(statedb)
+
+	// Forfeits grow a running total that is burned once at the end
+	setBalanceReason(statedb, balanceReasonStorageVerification, "")
+	deferBurn(statedb, big.NewInt(0), big.NewInt(5), "lease:"+lease.Hex())
+	deferBurn(statedb, big.NewInt(5), big.NewInt(5), "storagePledge:"+pledge.Hex())
+	deferBurn(statedb, big.NewInt(5), big.NewInt(12), "storagePledge:"+pledge.Hex())
+	statedb.AddBalance(depositor, big.NewInt(3))
+
+	// Burns made in between are split by their own attributions only
+	setBalanceReason(statedb, balanceReasonSpExit, "")
+	attributeBalance(statedb, burnAddress, big.NewInt(4), balanceReasonPenaltyBurn, "spExit:"+sp.Hex())
+	statedb.AddBalance(burnAddress, big.NewInt(6))
+
+	// The single burn carries the deferred tags, the rest keeps the reason
+	setBalanceReason(statedb, balanceReasonStorageLeftover, "")
+	releaseBurns(statedb)
+	statedb.AddBalance(burnAddress, big.NewInt(13))
+
+	want := []consensus.BalanceChange{
+		{Address: depositor, Delta: big.NewInt(3), Reason: balanceReasonStorageVerification},
+		{Address: burnAddress, Delta: big.NewInt(4), Reason: balanceReasonPenaltyBurn, Source: "spExit:" + sp.Hex()},
+		{Address: burnAddress, Delta: big.NewInt(2), Reason: balanceReasonSpExit},
+		{Address: burnAddress, Delta: big.NewInt(5), Reason: balanceReasonPenaltyBurn, Source: "lease:" + lease.Hex()},
+		{Address: burnAddress, Delta: big.NewInt(7), Reason: balanceReasonPenaltyBurn, Source: "storagePledge:" + pledge.Hex()},
+		{Address: burnAddress, Delta: big.NewInt(1), Reason: balanceReasonStorageLeftover},
+	}
+	if !reflect.DeepEqual(journal.changes, want) {
+		t.Errorf("balance changes mismatch:\nhave %v\nwant %v", journal.changes, want)
+	}
+	if balance := statedb.GetBalance(burnAddress); balance.Int64() != 19 {
+		t.Errorf("burned balance mismatch: have %v, want 19", balance)
+	}
+	// Engines finalising without a state burn nothing
+	deferBurn(nil, big.NewInt(0), big.NewInt(1), "")
+	releaseBurns(nil)
+	attributeBalance(nil, burnAddress, big.NewInt(1), balanceReasonPenaltyBurn, "")
+}
+
+// TestBalanceJournalReplacedBurn checks that a running burn total replaced by
+// a forfeit is tagged with that forfeit alone.
+func TestBalanceJournalReplacedBurn(t *testing.T) {
+	var (
+		first  = common.HexToAddress("0x1100000000000000000000000000000000000011")
+		second = common.HexToAddress("0x2200000000000000000000000000000000000022")
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	journal := beginBalanceJournal(statedb)
+
+	deferBurn(statedb, big.NewInt(0), big.NewInt(9), "storagePledge:"+first.Hex())
+	deferBurn(statedb, big.NewInt(9), big.NewInt(4), "storagePledge:"+second.Hex())
+	releaseBurns(statedb)
+	statedb.AddBalance(burnAddress, big.NewInt(4))
+
+	want := []consensus.BalanceChange{
+		{Address: burnAddress, Delta: big.NewInt(4), Reason: balanceReasonPenaltyBurn, Source: "storagePledge:" + second.Hex()},
+	}
+	if !reflect.DeepEqual(journal.changes, want) {
+		t.Errorf("balance changes mismatch:\nhave %v\nwant %v", journal.changes, want)
+	}
+}
+
+// TestBalanceJournalRevert checks that reverted balance changes drop their
+// records and restore the attributions they consumed, while changes made
+// before the journal was installed are countered.
+func TestBalanceJournalRevert(t *testing.T) {
+	addr := common.HexToAddress("0x1100000000000000000000000000000000000011")
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+
+	outer := statedb.Snapshot()
+	statedb.AddBalance(addr, big.NewInt(1))
+	journal := beginBalanceJournal(statedb)
+
+	setBalanceReason(statedb, balanceReasonCustomTx, "")
+	statedb.AddBalance(addr, big.NewInt(2))
+	inner := statedb.Snapshot()
+	attributeBalance(statedb, addr, big.NewInt(3), balanceReasonGasRefund, "")
+	statedb.AddBalance(addr, big.NewInt(3))
+	statedb.RevertToSnapshot(inner)
+
+	// The attribution is taken again by the change replacing the reverted one
+	statedb.AddBalance(addr, big.NewInt(3))
+	want := []consensus.BalanceChange{
+		{Address: addr, Delta: big.NewInt(2), Reason: balanceReasonCustomTx},
+		{Address: addr, Delta: big.NewInt(3), Reason: balanceReasonGasRefund},
+	}
+	if !reflect.DeepEqual(journal.changes, want) {
+		t.Errorf("balance changes mismatch:\nhave %v\nwant %v", journal.changes, want)
+	}
+	statedb.RevertToSnapshot(outer)
+	want = []consensus.BalanceChange{
+		{Address: addr, Delta: big.NewInt(-1), Reason: balanceReasonCustomTx},
+	}
+	if !reflect.DeepEqual(journal.changes, want) {
+		t.Errorf("balance changes mismatch after revert:\nhave %v\nwant %v", journal.changes, want)
+	}
+	if len(journal.calls) != 0 || len(journal.pending) != 0 {
+		t.Errorf("journal not unwound: %d calls, %d pending", len(journal.calls), len(journal.pending))
+	}
+}
@@ -0,0 +1,499 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+	"github.com/shopspring/decimal"
+)
+
+const (
+	bridgeReportExpiredLoopCount = 64 // loops a burn is reported to the main chain and its reports are kept there
+	bridgeMaxReportCount         = 64 // burns reported by one side chain confirm transaction
+	bridgeBurnInfoLen            = 5  // hash, sender, recipient, amount and number of a reported burn
+)
+
+// BridgeTransfer is value moved by the lock-and-mint bridge, either locked on
+// the main chain and minted on a side chain, or burned on a side chain and
+// unlocked on the main chain. The hash of the lock or burn transaction is the
+// id of the transfer on both chains.
+type BridgeTransfer struct {
+	Hash      common.Hash    `json:"hash"`
+	SCHash    common.Hash    `json:"schash"`
+	Sender    common.Address `json:"sender"`
+	Recipient common.Address `json:"recipient"`
+	Amount    *big.Int       `json:"amount"`
+	Number    uint64         `json:"number"` // block of the lock or burn transaction
+}
+
+func (t *BridgeTransfer) copy() *BridgeTransfer {
+	return &BridgeTransfer{
+		Hash:      t.Hash,
+		SCHash:    t.SCHash,
+		Sender:    t.Sender,
+		Recipient: t.Recipient,
+		Amount:    new(big.Int).Set(t.Amount),
+		Number:    t.Number,
+	}
+}
+
+func (t *BridgeTransfer) equal(v *BridgeTransfer) bool {
+	return t.Hash == v.Hash && t.SCHash == v.SCHash && t.Sender == v.Sender && t.Recipient == v.Recipient &&
+		t.Amount.Cmp(v.Amount) == 0 && t.Number == v.Number
+}
+
+// BridgeReport collects the reports of one side chain burn sent by the side
+// chain coinbases with their confirm transactions.
+type BridgeReport struct {
+	Number  uint64                             `json:"number"` // block of the first report
+	Reports map[common.Address]*BridgeTransfer `json:"reports"`
+}
+
+// BridgeState is the bridge state of a snapshot. The main chain keeps the
+// value locked for each side chain, the burn reports and the burns it
+// unlocked, the side chain keeps its burns until they are reported long enough
+// and the locks it minted. A paid transfer is kept as long as it can be sent
+// again, so that it is never paid twice.
+type BridgeState struct {
+	Locked    map[common.Hash]*big.Int        `json:"locked"`
+	Reports   map[common.Hash]*BridgeReport   `json:"reports"`
+	Burns     map[common.Hash]*BridgeTransfer `json:"burns"`
+	Unlocked  map[common.Hash]*BridgeTransfer `json:"unlocked"`  // burns unlocked on the main chain
+	Processed map[common.Hash]uint64          `json:"processed"` // locks minted on the side chain, by the last block carrying them
+	Payout    []*BridgeTransfer               `json:"payout"`    // transfers paid by the next block
+}
+
+func newBridgeState() *BridgeState {
+	return &BridgeState{
+		Locked:    make(map[common.Hash]*big.Int),
+		Reports:   make(map[common.Hash]*BridgeReport),
+		Burns:     make(map[common.Hash]*BridgeTransfer),
+		Unlocked:  make(map[common.Hash]*BridgeTransfer),
+		Processed: make(map[common.Hash]uint64),
+	}
+}
+
+func (b *BridgeState) copy() *BridgeState {
+	if b == nil {
+		return nil
+	}
+	cpy := newBridgeState()
+	for hash, amount := range b.Locked {
+		cpy.Locked[hash] = new(big.Int).Set(amount)
+	}
+	for hash, report := range b.Reports {
+		item := &BridgeReport{Number: report.Number, Reports: make(map[common.Address]*BridgeTransfer, len(report.Reports))}
+		for coinbase, transfer := range report.Reports {
+			item.Reports[coinbase] = transfer.copy()
+		}
+		cpy.Reports[hash] = item
+	}
+	for hash, burn := range b.Burns {
+		cpy.Burns[hash] = burn.copy()
+	}
+	for hash, burn := range b.Unlocked {
+		cpy.Unlocked[hash] = burn.copy()
+	}
+	for hash, number := range b.Processed {
+		cpy.Processed[hash] = number
+	}
+	for _, transfer := range b.Payout {
+		cpy.Payout = append(cpy.Payout, transfer.copy())
+	}
+	return cpy
+}
+
+func (s *Snapshot) bridgeState() *BridgeState {
+	if s.Bridge == nil {
+		s.Bridge = newBridgeState()
+	}
+	return s.Bridge
+}
+
+// scMinConfirmedSignerCount is the number of side chain coinbases that must
+// agree before the main chain takes a side chain block as confirmed.
+func (s *Snapshot) scMinConfirmedSignerCount() int {
+	return int(2 * s.config.MaxSignerCount / 3)
+}
+
+// processBridgeLock handles UTG:1:BridgeLock:<side chain hash>:<recipient>:<amount>
+// on the main chain. The amount is taken from the sender and minted to the
+// recipient on the side chain once its signers confirmed the lock.
+func (a *Alien) processBridgeLock(currentLock []BridgeTransfer, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB, snap *Snapshot, number uint64) []BridgeTransfer {
+	if len(txDataInfo) < 6 {
+		log.Warn("BridgeLock", "parameter number", len(txDataInfo))
+		return currentLock
+	}
+	lock := BridgeTransfer{
+		Hash:   tx.Hash(),
+		Sender: txSender,
+		Number: number,
+	}
+	postion := 3
+	if err := lock.SCHash.UnmarshalText([]byte(txDataInfo[postion])); err != nil {
+		log.Warn("BridgeLock", "side chain", txDataInfo[postion])
+		return currentLock
+	}
+	if _, ok := snap.SCRecordMap[lock.SCHash]; !ok {
+		log.Warn("BridgeLock", "side chain not exist", lock.SCHash)
+		return currentLock
+	}
+	postion++
+	if err := lock.Recipient.UnmarshalText1([]byte(txDataInfo[postion])); err != nil || lock.Recipient == (common.Address{}) {
+		log.Warn("BridgeLock", "recipient", txDataInfo[postion])
+		return currentLock
+	}
+	postion++
+	amount, err := decimal.NewFromString(txDataInfo[postion])
+	if err != nil || amount.Sign() <= 0 {
+		log.Warn("BridgeLock", "amount", txDataInfo[postion])
+		return currentLock
+	}
+	lock.Amount = amount.BigInt()
+	if state.GetBalance(txSender).Cmp(lock.Amount) < 0 {
+		log.Warn("BridgeLock", "balance", state.GetBalance(txSender))
+		return currentLock
+	}
+	state.SubBalance(txSender, lock.Amount)
+	topics := make([]common.Hash, 3)
+	topics[0].UnmarshalText([]byte("0xd47cd720e76a889434c2b1a2b2f8539fb4a242fcb1a19fa14c5a8747fcfe19b3")) //web3.sha3("BridgeLock(bytes32,address,uint256)")
+	topics[1] = lock.SCHash
+	topics[2].SetBytes(lock.Recipient.Bytes())
+	data := common.Hash{}
+	data.SetBytes(lock.Amount.Bytes())
+	a.addCustomerTxLog(tx, receipts, topics, data.Bytes())
+	return append(currentLock, lock)
+}
+
+// processBridgeBurn handles UTG:1:BridgeBurn:<recipient>:<amount> on a side
+// chain. The amount is burned and unlocked to the recipient on the main chain
+// once the side chain block is confirmed there.
+func (a *Alien) processBridgeBurn(currentBurn []BridgeTransfer, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB, scHash common.Hash, number uint64) []BridgeTransfer {
+	if len(txDataInfo) < 5 {
+		log.Warn("BridgeBurn", "parameter number", len(txDataInfo))
+		return currentBurn
+	}
+	burn := BridgeTransfer{
+		Hash:   tx.Hash(),
+		SCHash: scHash,
+		Sender: txSender,
+		Number: number,
+	}
+	postion := 3
+	if err := burn.Recipient.UnmarshalText1([]byte(txDataInfo[postion])); err != nil || burn.Recipient == (common.Address{}) {
+		log.Warn("BridgeBurn", "recipient", txDataInfo[postion])
+		return currentBurn
+	}
+	postion++
+	amount, err := decimal.NewFromString(txDataInfo[postion])
+	if err != nil || amount.Sign() <= 0 {
+		log.Warn("BridgeBurn", "amount", txDataInfo[postion])
+		return currentBurn
+	}
+	burn.Amount = amount.BigInt()
+	if state.GetBalance(txSender).Cmp(burn.Amount) < 0 {
+		log.Warn("BridgeBurn", "balance", state.GetBalance(txSender))
+		return currentBurn
+	}
+	state.SubBalance(txSender, burn.Amount)
+	topics := make([]common.Hash, 2)
+	topics[0].UnmarshalText([]byte("0x9b5b9a05e4726d8bb959f1440e05c6b8109443f2083bc4e386237d7654526553")) //web3.sha3("BridgeBurn(address,uint256)")
+	topics[1].SetBytes(burn.Recipient.Bytes())
+	data := common.Hash{}
+	data.SetBytes(burn.Amount.Bytes())
+	a.addCustomerTxLog(tx, receipts, topics, data.Bytes())
+	return append(currentBurn, burn)
+}
+
+// buildBridgeBurnInfo lists the burns of the side chain for its confirm
+// transaction, the oldest first. The main chain ignores the burns it already
+// unlocked, so a burn is reported until it expires.
+func (a *Alien) buildBridgeBurnInfo(snap *Snapshot) string {
+	if snap == nil || snap.Bridge == nil || len(snap.Bridge.Burns) == 0 {
+		return ""
+	}
+	burns := make([]*BridgeTransfer, 0, len(snap.Bridge.Burns))
+	for _, burn := range snap.Bridge.Burns {
+		burns = append(burns, burn)
+	}
+	sortBridgeTransfer(burns)
+	if len(burns) > bridgeMaxReportCount {
+		burns = burns[:bridgeMaxReportCount]
+	}
+	var info []string
+	for _, burn := range burns {
+		info = append(info, burn.Hash.Hex(), burn.Sender.Hex(), burn.Recipient.Hex(), burn.Amount.String(), fmt.Sprintf("%d", burn.Number))
+	}
+	return strings.Join(info, "#")
+}
+
+// parseBridgeBurnInfo reads the burns reported by a side chain confirm
+// transaction, see buildBridgeBurnInfo.
+func (a *Alien) parseBridgeBurnInfo(burnInfo string, scHash common.Hash) []BridgeTransfer {
+	infos := strings.Split(burnInfo, "#")
+	if len(infos)%bridgeBurnInfoLen != 0 || len(infos)/bridgeBurnInfoLen > bridgeMaxReportCount {
+		log.Trace("Side chain confirm info fail", "burns", burnInfo)
+		return nil
+	}
+	var burns []BridgeTransfer
+	for i := 0; i < len(infos); i += bridgeBurnInfoLen {
+		burn := BridgeTransfer{SCHash: scHash, Amount: new(big.Int)}
+		number := new(big.Int)
+		if burn.Hash.UnmarshalText([]byte(infos[i])) != nil || burn.Sender.UnmarshalText([]byte(infos[i+1])) != nil ||
+			burn.Recipient.UnmarshalText([]byte(infos[i+2])) != nil || burn.Amount.UnmarshalText([]byte(infos[i+3])) != nil ||
+			number.UnmarshalText([]byte(infos[i+4])) != nil || burn.Amount.Sign() <= 0 {
+			log.Trace("Side chain confirm info fail", "burn", strings.Join(infos[i:i+bridgeBurnInfoLen], "#"))
+			return nil
+		}
+		burn.Number = number.Uint64()
+		burns = append(burns, burn)
+	}
+	return burns
+}
+
+// updateBridge records the locks and burns of the block. Locks are added to
+// the notice of their side chain, so the side chain signers pick them up. A
+// minted lock is pruned once no block carried it for the report window.
+func (s *Snapshot) updateBridge(locks []BridgeTransfer, burns []BridgeTransfer, number uint64) {
+	bridge := s.bridgeState()
+	bridge.Payout = nil
+	for _, item := range locks {
+		lock := item.copy()
+		if _, ok := s.SCNoticeMap[lock.SCHash]; !ok {
+			s.SCNoticeMap[lock.SCHash] = &CCNotice{CurrentCharging: make(map[common.Hash]GasCharging), ConfirmReceived: make(map[common.Hash]NoticeCR)}
+		}
+		notice := s.SCNoticeMap[lock.SCHash]
+		if notice.CurrentBridge == nil {
+			notice.CurrentBridge = make(map[common.Hash]*BridgeTransfer)
+		}
+		notice.CurrentBridge[lock.Hash] = lock
+		if _, ok := bridge.Locked[lock.SCHash]; !ok {
+			bridge.Locked[lock.SCHash] = new(big.Int)
+		}
+		bridge.Locked[lock.SCHash].Add(bridge.Locked[lock.SCHash], lock.Amount)
+	}
+	for _, item := range burns {
+		bridge.Burns[item.Hash] = item.copy()
+	}
+	if (number+1)%s.config.MaxSignerCount == 0 {
+		expired := bridgeReportExpiredLoopCount * s.config.MaxSignerCount
+		for hash, burn := range bridge.Burns {
+			if burn.Number+expired < number {
+				delete(bridge.Burns, hash)
+			}
+		}
+		for hash, processed := range bridge.Processed {
+			if processed+expired < number {
+				delete(bridge.Processed, hash)
+			}
+		}
+	}
+}
+
+// updateBridgeReport records the burns reported by a coinbase of the side
+// chain on the main chain. Burns older than the report window of the side
+// chain are ignored, the same window the unlocked burns are kept for, so a
+// burn is not reported again once its unlocked entry was pruned.
+func (s *Snapshot) updateBridgeReport(scc *SCConfirmation, number uint64) {
+	bridge := s.bridgeState()
+	var confirmed uint64
+	if record, ok := s.SCRecordMap[scc.Hash]; ok {
+		confirmed = record.LastConfirmedNumber
+	}
+	for _, item := range scc.BridgeBurn {
+		if item.SCHash != scc.Hash || item.Amount == nil || item.Amount.Sign() <= 0 {
+			continue
+		}
+		if item.Number+bridgeReportExpiredLoopCount*s.config.MaxSignerCount < confirmed {
+			continue
+		}
+		if _, ok := bridge.Unlocked[item.Hash]; ok {
+			continue
+		}
+		report, ok := bridge.Reports[item.Hash]
+		if !ok {
+			report = &BridgeReport{Number: number, Reports: make(map[common.Address]*BridgeTransfer)}
+			bridge.Reports[item.Hash] = report
+		}
+		report.Reports[scc.Coinbase] = item.copy()
+	}
+}
+
+// updateBridgeUnlock unlocks on the main chain the burns reported alike by
+// enough coinbases of their side chain, once the side chain block of the burn
+// is confirmed. A burn above the value locked for its side chain stays
+// reported, it is unlocked once enough value was locked or expires with its
+// reports. It runs after the confirmed number of the side chains was updated
+// on the last block of a loop. An unlocked burn is kept until the confirmed
+// number of its side chain passed the report window of the burn, which does
+// not move while the side chain is stalled.
+func (s *Snapshot) updateBridgeUnlock(number uint64) {
+	bridge := s.bridgeState()
+	window := bridgeReportExpiredLoopCount * s.config.MaxSignerCount
+	hashes := make([]common.Hash, 0, len(bridge.Reports))
+	for hash := range bridge.Reports {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool {
+		return bytes.Compare(hashes[i][:], hashes[j][:]) < 0
+	})
+	for _, hash := range hashes {
+		report := bridge.Reports[hash]
+		expired := report.Number+window < number
+		burn := report.agreed(s.scMinConfirmedSignerCount())
+		if burn == nil {
+			if expired {
+				delete(bridge.Reports, hash)
+			}
+			continue
+		}
+		record, ok := s.SCRecordMap[burn.SCHash]
+		if !ok {
+			delete(bridge.Reports, hash)
+			continue
+		}
+		if burn.Number > record.LastConfirmedNumber {
+			continue
+		}
+		locked, ok := bridge.Locked[burn.SCHash]
+		if !ok || locked.Cmp(burn.Amount) < 0 {
+			log.Warn("updateBridgeUnlock", "burn above the locked value", hash, "side chain", burn.SCHash)
+			if expired {
+				delete(bridge.Reports, hash)
+			}
+			continue
+		}
+		delete(bridge.Reports, hash)
+		bridge.Unlocked[hash] = burn.copy()
+		locked.Sub(locked, burn.Amount)
+		bridge.Payout = append(bridge.Payout, burn.copy())
+	}
+	for hash, burn := range bridge.Unlocked {
+		if record, ok := s.SCRecordMap[burn.SCHash]; !ok || burn.Number+window < record.LastConfirmedNumber {
+			delete(bridge.Unlocked, hash)
+		}
+	}
+}
+
+// agreed returns the burn reported alike by at least count coinbases.
+func (r *BridgeReport) agreed(count int) *BridgeTransfer {
+	coinbases := make([]common.Address, 0, len(r.Reports))
+	for coinbase := range r.Reports {
+		coinbases = append(coinbases, coinbase)
+	}
+	sort.Slice(coinbases, func(i, j int) bool {
+		return bytes.Compare(coinbases[i][:], coinbases[j][:]) < 0
+	})
+	for _, coinbase := range coinbases {
+		burn, same := r.Reports[coinbase], 0
+		for _, other := range r.Reports {
+			if burn.equal(other) {
+				same++
+			}
+		}
+		if same >= count {
+			return burn
+		}
+	}
+	return nil
+}
+
+// updateSnapshotByBridgeMint records on the side chain the locks of the main
+// chain notice included by the coinbase, and mints a lock once enough
+// coinbases included it, the same count the main chain needs to confirm a
+// side chain block. A minted lock is carried by every block while the main
+// chain sends it, each of them keeps its processed entry.
+func (s *Snapshot) updateSnapshotByBridgeMint(mints []BridgeTransfer, headerNumber *big.Int, coinbase common.Address) {
+	bridge := s.bridgeState()
+	for _, item := range mints {
+		if _, ok := bridge.Processed[item.Hash]; ok {
+			bridge.Processed[item.Hash] = headerNumber.Uint64()
+			continue
+		}
+		if s.LocalNotice.CurrentBridge == nil {
+			s.LocalNotice.CurrentBridge = make(map[common.Hash]*BridgeTransfer)
+		}
+		if _, ok := s.LocalNotice.CurrentBridge[item.Hash]; !ok {
+			s.LocalNotice.CurrentBridge[item.Hash] = item.copy()
+			s.LocalNotice.ConfirmReceived[item.Hash] = NoticeCR{make(map[common.Address]bool), 0, noticeTypeBridgeLock, false}
+		}
+		s.LocalNotice.ConfirmReceived[item.Hash].NRecord[coinbase] = true
+	}
+
+	if (headerNumber.Uint64()+1)%s.config.MaxSignerCount == 0 {
+		mints := make([]*BridgeTransfer, 0, len(s.LocalNotice.CurrentBridge))
+		for _, mint := range s.LocalNotice.CurrentBridge {
+			mints = append(mints, mint)
+		}
+		sortBridgeTransfer(mints)
+		for _, mint := range mints {
+			noticeRecord := s.LocalNotice.ConfirmReceived[mint.Hash]
+			if len(noticeRecord.NRecord) >= s.scMinConfirmedSignerCount() && !noticeRecord.Success {
+				s.LocalNotice.ConfirmReceived[mint.Hash] = NoticeCR{noticeRecord.NRecord, headerNumber.Uint64(), noticeTypeBridgeLock, true}
+				bridge.Processed[mint.Hash] = headerNumber.Uint64()
+				bridge.Payout = append(bridge.Payout, mint.copy())
+			}
+			if noticeRecord.Success && noticeRecord.Number < headerNumber.Uint64()-s.config.MaxSignerCount*scNoticeClearDelayLoopCount {
+				delete(s.LocalNotice.CurrentBridge, mint.Hash)
+				delete(s.LocalNotice.ConfirmReceived, mint.Hash)
+			}
+		}
+	}
+}
+
+// payBridgeTransfers pays the transfers completed by the parent block, the
+// unlocks on the main chain and the mints on a side chain.
+func payBridgeTransfers(state *state.StateDB, snap *Snapshot, reason string) {
+	if snap.Bridge == nil {
+		return
+	}
+	for _, transfer := range snap.Bridge.Payout {
+		setBalanceReason(state, reason, transfer.Hash.Hex())
+		state.AddBalance(transfer.Recipient, transfer.Amount)
+	}
+}
+
+func sortBridgeTransfer(transfers []*BridgeTransfer) {
+	sort.Slice(transfers, func(i, j int) bool {
+		if transfers[i].Number != transfers[j].Number {
+			return transfers[i].Number < transfers[j].Number
+		}
+		return bytes.Compare(transfers[i].Hash[:], transfers[j].Hash[:]) < 0
+	})
+}
+
+func bridgeTransferList(transfers map[common.Hash]*BridgeTransfer) []BridgeTransfer {
+	sorted := make([]*BridgeTransfer, 0, len(transfers))
+	for _, transfer := range transfers {
+		sorted = append(sorted, transfer)
+	}
+	sortBridgeTransfer(sorted)
+	list := make([]BridgeTransfer, 0, len(sorted))
+	for _, transfer := range sorted {
+		list = append(list, *transfer.copy())
+	}
+	return list
+}
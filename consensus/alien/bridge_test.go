@@ -0,0 +1,476 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"crypto/ecdsa"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+	"github.com/UltronGlow/UltronGlow-Origin/ethdb"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+// TestBridgeLockAndBurn moves value from the main chain to a side chain and
+// back. The test drives the snapshots and states of a main and a side chain
+// directly, the way their block processing does, and passes the notice
+// between them through its JSON encoding as the side chain reads it over RPC.
+// TestBridgeChains runs the same transfers through sealed blocks.
+func TestBridgeLockAndBurn(t *testing.T) {
+	var (
+		scHash        = common.HexToHash("0x5c")
+		mainSender    = common.HexToAddress("0x1100000000000000000000000000000000000011")
+		mainRecipient = common.HexToAddress("0x2200000000000000000000000000000000000022")
+		sideRecipient = common.HexToAddress("0x3300000000000000000000000000000000000033")
+		coinbases     = []common.Address{
+			common.HexToAddress("0xc100000000000000000000000000000000000001"),
+			common.HexToAddress("0xc200000000000000000000000000000000000002"),
+			common.HexToAddress("0xc300000000000000000000000000000000000003"),
+		}
+		engine = &Alien{}
+	)
+	newState := func() *state.StateDB {
+		statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+		return statedb
+	}
+	newSnapshot := func() *Snapshot {
+		return &Snapshot{
+			config:      upgradedConfig(&params.AlienConfig{Period: 10, MaxSignerCount: 3}),
+			SCCoinbase:  make(map[common.Hash]map[common.Address]common.Address),
+			SCRecordMap: make(map[common.Hash]*SCRecord),
+			SCRewardMap: make(map[common.Hash]*SCReward),
+			SCNoticeMap: make(map[common.Hash]*CCNotice),
+			LocalNotice: &CCNotice{CurrentCharging: make(map[common.Hash]GasCharging), ConfirmReceived: make(map[common.Hash]NoticeCR)},
+		}
+	}
+	customTx := func(sender common.Address, data string, number uint64) (*types.Transaction, []*types.Receipt) {
+		tx := types.NewTransaction(number, sender, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+		return tx, []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(number)}}
+	}
+
+	mainState, sideState := newState(), newState()
+	mainSnap, sideSnap := newSnapshot(), newSnapshot()
+	mainSnap.SCRecordMap[scHash] = &SCRecord{Record: make(map[uint64][]*SCConfirmation), CountPerPeriod: 1, RentReward: make(map[common.Hash]*SCRentInfo)}
+	mainSnap.SCCoinbase[scHash] = make(map[common.Address]common.Address)
+	for _, coinbase := range coinbases {
+		mainSnap.SCCoinbase[scHash][coinbase] = coinbase
+	}
+	mainState.AddBalance(mainSender, big.NewInt(100))
+
+	// Lock 40 on the main chain for a recipient on the side chain.
+	mainNumber := upgradeTestNumber + uint64(2)
+	lockData := "UTG:1:BridgeLock:" + common.HexToHash("0x5d").Hex() + ":" + sideRecipient.Hex() + ":40"
+	tx, receipts := customTx(mainSender, lockData, mainNumber)
+	if locks := engine.processBridgeLock(nil, strings.Split(lockData, ":"), mainSender, tx, receipts, mainState, mainSnap, mainNumber); len(locks) != 0 {
+		t.Fatalf("lock for an unknown side chain accepted")
+	}
+	lockData = "UTG:1:BridgeLock:" + scHash.Hex() + ":" + sideRecipient.Hex() + ":40"
+	tx, receipts = customTx(mainSender, lockData, mainNumber)
+	locks := engine.processBridgeLock(nil, strings.Split(lockData, ":"), mainSender, tx, receipts, mainState, mainSnap, mainNumber)
+	if len(locks) != 1 || mainState.GetBalance(mainSender).Int64() != 60 {
+		t.Fatalf("lock rejected or value not taken: %v %v", locks, mainState.GetBalance(mainSender))
+	}
+	mainSnap.updateBridge(locks, nil, mainNumber)
+
+	// The side chain reads the notice from the main chain snapshot over RPC.
+	blob, _ := json.Marshal(mainSnap.SCNoticeMap[scHash])
+	notice := &CCNotice{}
+	if err := json.Unmarshal(blob, notice); err != nil {
+		t.Fatalf("notice not decoded: %v", err)
+	}
+	mints := bridgeTransferList(notice.CurrentBridge)
+	if len(mints) != 1 || !mints[0].equal(&locks[0]) {
+		t.Fatalf("lock not in the notice: %v", mints)
+	}
+
+	// Every side chain block carries the notice; the lock is minted once two
+	// coinbases included it.
+	sideNumber := upgradeTestNumber + uint64(3)
+	sideBlock := func(coinbase common.Address, mints []BridgeTransfer, burns []BridgeTransfer) {
+		sideSnap.updateBridge(nil, burns, sideNumber)
+		sideSnap.updateSnapshotByBridgeMint(mints, new(big.Int).SetUint64(sideNumber), coinbase)
+		sideNumber++
+	}
+	sideBlock(coinbases[0], mints, nil)
+	sideBlock(coinbases[0], mints, nil)
+	sideBlock(coinbases[0], mints, nil)
+	if len(sideSnap.Bridge.Payout) != 0 {
+		t.Fatalf("lock minted with the confirmation of one coinbase")
+	}
+	sideBlock(coinbases[1], mints, nil)
+	sideBlock(coinbases[1], mints, nil)
+	sideBlock(coinbases[1], mints, nil)
+	if len(sideSnap.Bridge.Payout) != 1 {
+		t.Fatalf("lock not minted at the loop end")
+	}
+	payBridgeTransfers(sideState, sideSnap, balanceReasonBridgeMint)
+	if sideState.GetBalance(sideRecipient).Int64() != 40 {
+		t.Fatalf("minted %v, want 40", sideState.GetBalance(sideRecipient))
+	}
+	for i := 0; i < 3; i++ {
+		sideBlock(coinbases[2], mints, nil)
+		if len(sideSnap.Bridge.Payout) != 0 {
+			t.Fatalf("lock minted twice")
+		}
+	}
+
+	// The main chain sends the lock until the echoes of the side chain reach
+	// it. The side chain keeps the minted lock as long as it is sent, past
+	// the report window and the clearing of its local notice.
+	for i := uint64(0); i < (bridgeReportExpiredLoopCount+2)*3; i++ {
+		sideBlock(coinbases[i%3], mints, nil)
+		if len(sideSnap.Bridge.Payout) != 0 {
+			t.Fatalf("lock minted again at %d", sideNumber-1)
+		}
+	}
+	if _, ok := sideSnap.Bridge.Processed[locks[0].Hash]; !ok {
+		t.Fatalf("minted lock pruned while it is sent")
+	}
+
+	// The side chain coinbases echo the notice, so the main chain stops
+	// sending it.
+	var echoes []SCConfirmation
+	for _, coinbase := range coinbases {
+		echoes = engine.processSCEventNoticeConfirm(echoes, scHash, sideNumber, engine.parseNoticeInfo(notice), coinbase)
+	}
+	mainSnap.updateSnapshotByNoticeConfirm(echoes, new(big.Int).SetUint64(mainNumber))
+	if record := mainSnap.SCNoticeMap[scHash].ConfirmReceived[locks[0].Hash]; !record.Success || record.Type != noticeTypeBridgeLock {
+		t.Errorf("notice echo not recorded: %+v", record)
+	}
+
+	// Burn 15 on the side chain for a recipient on the main chain.
+	burnData := "UTG:1:BridgeBurn:" + mainRecipient.Hex() + ":15"
+	tx, receipts = customTx(sideRecipient, burnData, sideNumber)
+	burns := engine.processBridgeBurn(nil, strings.Split(burnData, ":"), sideRecipient, tx, receipts, sideState, scHash, sideNumber)
+	if len(burns) != 1 || sideState.GetBalance(sideRecipient).Int64() != 25 {
+		t.Fatalf("burn rejected or value not taken: %v %v", burns, sideState.GetBalance(sideRecipient))
+	}
+	burnNumber := sideNumber
+	sideBlock(coinbases[0], nil, burns)
+
+	// The coinbases report the burn with their confirm transactions.
+	confirm := func(coinbase common.Address, number uint64) []SCConfirmation {
+		loopInfo := fmt.Sprintf("%d#%s#%d#%s", number-1, coinbases[0].Hex(), number, coinbases[1].Hex())
+		data := string(engine.buildSCEventConfirmData(scHash, new(big.Int).SetUint64(number), big.NewInt(0), loopInfo, "", engine.buildBridgeBurnInfo(sideSnap)))
+		txDataInfo := strings.Split(data, ":")
+		reported := engine.parseBridgeBurnInfo(txDataInfo[ufoMinSplitLen+6], scHash)
+		tx, _ := customTx(coinbase, data, number)
+		confirmations, _ := engine.processSCEventConfirm(nil, scHash, number, txDataInfo[ufoMinSplitLen+4], reported, tx, coinbase, make(RefundHash))
+		return confirmations
+	}
+	mainBlock := func(confirmations []SCConfirmation) {
+		mainSnap.updateBridge(nil, nil, mainNumber)
+		mainSnap.updateSnapshotBySCConfirm(confirmations, new(big.Int).SetUint64(mainNumber))
+		mainNumber++
+	}
+	mainNumber = (mainNumber/3 + 1) * 3
+	mainBlock(confirm(coinbases[0], burnNumber+1))
+	mainBlock(nil)
+	mainBlock(nil)
+	if len(mainSnap.Bridge.Payout) != 0 {
+		t.Fatalf("burn unlocked with the report of one coinbase")
+	}
+	mainBlock(confirm(coinbases[1], burnNumber+1))
+	mainBlock(nil)
+	mainBlock(nil)
+	if mainSnap.SCRecordMap[scHash].LastConfirmedNumber < burnNumber || len(mainSnap.Bridge.Payout) != 1 {
+		t.Fatalf("burn not unlocked: confirmed %d, payout %v", mainSnap.SCRecordMap[scHash].LastConfirmedNumber, mainSnap.Bridge.Payout)
+	}
+	payBridgeTransfers(mainState, mainSnap, balanceReasonBridgeUnlock)
+	if mainState.GetBalance(mainRecipient).Int64() != 15 || mainSnap.Bridge.Locked[scHash].Int64() != 25 {
+		t.Fatalf("unlocked %v with %v still locked", mainState.GetBalance(mainRecipient), mainSnap.Bridge.Locked[scHash])
+	}
+
+	// A replayed report of the burn is ignored.
+	mainBlock(confirm(coinbases[0], burnNumber+4))
+	mainBlock(confirm(coinbases[1], burnNumber+4))
+	mainBlock(nil)
+	if len(mainSnap.Bridge.Payout) != 0 || len(mainSnap.Bridge.Reports) != 0 {
+		t.Errorf("burn unlocked twice")
+	}
+
+	// A burn above the value locked for the side chain is not unlocked.
+	sideState.AddBalance(sideRecipient, big.NewInt(100))
+	burnData = "UTG:1:BridgeBurn:" + mainRecipient.Hex() + ":30"
+	tx, receipts = customTx(sideRecipient, burnData, sideNumber)
+	burns = engine.processBridgeBurn(nil, strings.Split(burnData, ":"), sideRecipient, tx, receipts, sideState, scHash, sideNumber)
+	burnNumber = sideNumber
+	sideBlock(coinbases[0], nil, burns)
+	mainBlock(confirm(coinbases[0], burnNumber+1))
+	mainBlock(confirm(coinbases[1], burnNumber+1))
+	mainBlock(nil)
+	if len(mainSnap.Bridge.Payout) != 0 || mainSnap.Bridge.Locked[scHash].Int64() != 25 {
+		t.Fatalf("burn above the locked value unlocked")
+	}
+	if len(mainSnap.Bridge.Reports) != 1 {
+		t.Fatalf("burn above the locked value no longer reported")
+	}
+
+	// It is unlocked once enough value was locked for the side chain.
+	lockData = "UTG:1:BridgeLock:" + scHash.Hex() + ":" + sideRecipient.Hex() + ":10"
+	tx, receipts = customTx(mainSender, lockData, mainNumber)
+	locks = engine.processBridgeLock(nil, strings.Split(lockData, ":"), mainSender, tx, receipts, mainState, mainSnap, mainNumber)
+	mainSnap.updateBridge(locks, nil, mainNumber)
+	mainBlock(confirm(coinbases[0], burnNumber+1))
+	mainBlock(nil)
+	mainBlock(nil)
+	if len(mainSnap.Bridge.Payout) != 1 || mainSnap.Bridge.Locked[scHash].Int64() != 5 {
+		t.Fatalf("burn not unlocked after the lock: payout %v, locked %v", mainSnap.Bridge.Payout, mainSnap.Bridge.Locked[scHash])
+	}
+	unlocked := burns[0].Hash
+
+	// The side chain stalls, none of its blocks is confirmed while the main
+	// chain moves past the report window. The unlocked burn is kept, so the
+	// reports of the side chain coming back are ignored.
+	window := bridgeReportExpiredLoopCount * uint64(3)
+	mainNumber += 2 * window
+	mainBlock(nil)
+	mainBlock(nil)
+	mainBlock(nil)
+	if _, ok := mainSnap.Bridge.Unlocked[unlocked]; !ok {
+		t.Fatalf("unlocked burn pruned while the side chain is stalled")
+	}
+	mainBlock(confirm(coinbases[0], burnNumber+1))
+	mainBlock(confirm(coinbases[1], burnNumber+1))
+	mainBlock(nil)
+	if len(mainSnap.Bridge.Payout) != 0 || len(mainSnap.Bridge.Reports) != 0 {
+		t.Fatalf("burn unlocked again after the stall")
+	}
+
+	// Once the side chain confirmed blocks past the report window of the
+	// burn, the burn is pruned and a report of it is ignored by the window.
+	mainSnap.SCRecordMap[scHash].LastConfirmedNumber = burnNumber + window + 1
+	mainBlock(nil)
+	mainBlock(nil)
+	mainBlock(nil)
+	if _, ok := mainSnap.Bridge.Unlocked[unlocked]; ok {
+		t.Fatalf("unlocked burn not pruned")
+	}
+	mainBlock(confirm(coinbases[0], burnNumber+1))
+	if _, ok := mainSnap.Bridge.Reports[unlocked]; ok {
+		t.Errorf("expired burn reported again")
+	}
+}
+
+// bridgeTestChain is a main or side chain whose blocks are sealed and applied
+// to its snapshot, paying the bridge transfers of the parent block first as
+// the engine does when it finalizes a block.
+type bridgeTestChain struct {
+	db     ethdb.Database
+	snap   *Snapshot
+	state  *state.StateDB
+	reason string
+}
+
+// newBridgeTestChain creates a chain whose next block is the given number.
+func newBridgeTestChain(engine *Alien, config *params.AlienConfig, number uint64, reason string) *bridgeTestChain {
+	db := rawdb.NewMemoryDatabase()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(db), nil)
+	snap := newSnapshot(config, engine.signatures, common.Hash{}, nil, 1)
+	snap.Number = number - 1
+	// The state the upgrades below the first block set up
+	snap.SRT, _ = NewSRT(common.Hash{}, db)
+	snap.FlowRevenue.PosPgExitLock = NewLockData(LOCKPOSEXITDATA)
+	snap.FlowRevenue.PosExitLock = NewLockData(LOCKPEXITDATA)
+	snap.PosPledge = make(map[common.Address]*PosPledgeItem)
+	snap.TotalLeaseSpace = new(big.Int).Set(initTotalLeaseSpace)
+	snap.initStorageManager()
+	snap.FlowRevenue.STPEntrustExitLock = NewLockData(LOCKSTPEEXITDATA)
+	snap.FlowRevenue.STPEntrustLock = NewLockData(LOCKSTPEDATA)
+	snap.FlowRevenue.SpLock = NewLockData(LOCKSPLOCKDATA)
+	snap.FlowRevenue.SpEntrustLock = NewLockData(LOCKSPETTTDATA)
+	snap.FlowRevenue.SpExitLock = NewLockData(LOCKSPEXITDATA)
+	snap.FlowRevenue.SpEntrustExitLock = NewLockData(LOCKSPETTEXITDATA)
+	return &bridgeTestChain{db: db, snap: snap, state: statedb, reason: reason}
+}
+
+// seal seals the next block of the chain by the key with the given extra.
+func (c *bridgeTestChain) seal(t *testing.T, key *ecdsa.PrivateKey, extra HeaderExtra) {
+	payBridgeTransfers(c.state, c.snap, c.reason)
+	header := &types.Header{
+		ParentHash: c.snap.Hash,
+		Number:     new(big.Int).SetUint64(c.snap.Number + 1),
+		Coinbase:   crypto.PubkeyToAddress(key.PublicKey),
+		Time:       c.snap.HeaderTime + c.snap.config.Period,
+		Difficulty: big.NewInt(1),
+	}
+	extra.GrantProfitHash = c.snap.calGrantProfitHash(nil)
+	extra.StorageDataRoot, extra.SpDataRoot, extra.SRTDataRoot = c.snap.StorageData.Hash, c.snap.SpData.Hash, c.snap.SRT.Root()
+	encoded, err := encodeHeaderExtra(c.snap.config, header.Number, extra)
+	if err != nil {
+		t.Fatalf("block %d: extra not encoded: %v", header.Number, err)
+	}
+	header.Extra = append(append(make([]byte, extraVanity), encoded...), make([]byte, extraSeal)...)
+	hash, _ := sigHash(header)
+	signature, _ := crypto.Sign(hash[:], key)
+	copy(header.Extra[len(header.Extra)-extraSeal:], signature)
+	if c.snap, err = c.snap.apply([]*types.Header{header}, c.db, nil); err != nil {
+		t.Fatalf("block %d not applied: %v", header.Number, err)
+	}
+}
+
+// TestBridgeChains runs a lock, its mint, a burn and its unlock through the
+// blocks of a main and a side chain, with the bridge activated by the
+// BridgeBlock of their engine configs. The chains start above the blocks of
+// the earlier upgrades, whose effect numbers are fixed.
+func TestBridgeChains(t *testing.T) {
+	var (
+		start         = uint64(initStorageManagerNumber + 1)
+		scHash        = common.HexToHash("0x5c")
+		mainRecipient = common.HexToAddress("0x2200000000000000000000000000000000000022")
+		sideRecipient = common.HexToAddress("0x3300000000000000000000000000000000000033")
+		config        = &params.AlienConfig{Period: 10, MaxSignerCount: 3, MinVoterBalance: new(big.Int), BridgeBlock: new(big.Int).SetUint64(start + 2)}
+		engine        = New(config, rawdb.NewMemoryDatabase())
+		mainChain     = newBridgeTestChain(engine, config, start, balanceReasonBridgeUnlock)
+		sideChain     = newBridgeTestChain(engine, &params.AlienConfig{Period: 10, MaxSignerCount: 3, SideChain: true, BridgeBlock: new(big.Int).SetUint64(start + 2)}, start, balanceReasonBridgeMint)
+		keys          []*ecdsa.PrivateKey
+		coinbases     []common.Address
+	)
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		coinbases = append(coinbases, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	mainSigner, mainSender := keys[3], coinbases[3]
+	mainChain.state.AddBalance(mainSender, big.NewInt(100))
+
+	// The side chain is rented and its coinbases are set on the main chain.
+	mainChain.snap.SCRecordMap[scHash] = &SCRecord{Record: make(map[uint64][]*SCConfirmation), CountPerPeriod: 1, RentReward: make(map[common.Hash]*SCRentInfo)}
+	extra := HeaderExtra{}
+	for _, coinbase := range coinbases[:3] {
+		extra.SideChainSetCoinbases = append(extra.SideChainSetCoinbases, SCSetCoinbase{Hash: scHash, Signer: mainSender, Coinbase: coinbase, Type: true})
+	}
+	mainChain.seal(t, mainSigner, extra)
+
+	// A lock in a block before the fork is not recorded, the one of the fork
+	// block is sent to the side chain.
+	early := BridgeTransfer{Hash: common.HexToHash("0x10"), SCHash: scHash, Sender: mainSender, Recipient: sideRecipient, Amount: big.NewInt(40), Number: start + 1}
+	mainChain.seal(t, mainSigner, HeaderExtra{BridgeLock: []BridgeTransfer{early}})
+	if notice := mainChain.snap.SCNoticeMap[scHash]; notice != nil && len(notice.CurrentBridge) != 0 {
+		t.Fatalf("lock recorded before the fork")
+	}
+	number := mainChain.snap.Number + 1
+	data := "UTG:1:BridgeLock:" + scHash.Hex() + ":" + sideRecipient.Hex() + ":40"
+	tx := types.NewTransaction(0, mainSender, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+	receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(number)}}
+	locks := engine.processBridgeLock(nil, strings.Split(data, ":"), mainSender, tx, receipts, mainChain.state, mainChain.snap, number)
+	mainChain.seal(t, mainSigner, HeaderExtra{BridgeLock: locks})
+	if len(mainChain.snap.SCNoticeMap[scHash].CurrentBridge) != 1 || mainChain.snap.Bridge.Locked[scHash].Int64() != 40 || mainChain.state.GetBalance(mainSender).Int64() != 60 {
+		t.Fatalf("lock not recorded after the fork")
+	}
+
+	// Every side chain block carries the locks of the main chain notice until
+	// the main chain receives the echoes of the side chain coinbases, which
+	// seal the side chain in turn.
+	sideBlock := func(burns []BridgeTransfer) {
+		number := sideChain.snap.Number + 1
+		extra := HeaderExtra{BridgeBurn: burns}
+		if isGEBridgeEffect(sideChain.snap.config, number) {
+			extra.SideChainBridgeMint = bridgeTransferList(mainChain.snap.SCNoticeMap[scHash].CurrentBridge)
+		}
+		sideChain.seal(t, keys[number%3], extra)
+	}
+	for i := 0; i < 9; i++ {
+		sideBlock(nil)
+	}
+	if have := sideChain.state.GetBalance(sideRecipient); have.Int64() != 40 {
+		t.Fatalf("minted %v, want 40", have)
+	}
+	var echoes []SCConfirmation
+	for _, coinbase := range coinbases[:3] {
+		echoes = engine.processSCEventNoticeConfirm(echoes, scHash, sideChain.snap.Number, engine.parseNoticeInfo(mainChain.snap.SCNoticeMap[scHash]), coinbase)
+	}
+	for i := 0; len(mainChain.snap.SCNoticeMap[scHash].CurrentBridge) != 0; i++ {
+		if i == 30 {
+			t.Fatalf("lock still in the notice after the echoes")
+		}
+		mainChain.seal(t, mainSigner, HeaderExtra{SideChainNoticeConfirmed: echoes})
+		echoes = nil
+	}
+	for i := 0; i < 3; i++ {
+		sideBlock(nil)
+	}
+	if have := sideChain.state.GetBalance(sideRecipient); have.Int64() != 40 {
+		t.Fatalf("minted %v after the notice was cleared, want 40", have)
+	}
+
+	// Burn 15 on the side chain. Its coinbases confirm the last loop of the
+	// side chain on the main chain and report the burn with it.
+	data = "UTG:1:BridgeBurn:" + mainRecipient.Hex() + ":15"
+	tx = types.NewTransaction(0, sideRecipient, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+	receipts = []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(sideChain.snap.Number + 1)}}
+	burns := engine.processBridgeBurn(nil, strings.Split(data, ":"), sideRecipient, tx, receipts, sideChain.state, scHash, sideChain.snap.Number+1)
+	sideBlock(burns)
+	for (sideChain.snap.Number+1)%3 != 0 {
+		sideBlock(nil)
+	}
+	confirmLoop := func() []SCConfirmation {
+		var (
+			number        = sideChain.snap.Number
+			loop          []string
+			confirmations []SCConfirmation
+		)
+		for n := number - 2; n <= number; n++ {
+			loop = append(loop, fmt.Sprintf("%d#%s", n, coinbases[n%3].Hex()))
+		}
+		data := string(engine.buildSCEventConfirmData(scHash, new(big.Int).SetUint64(number), big.NewInt(0), strings.Join(loop, "#"), "", engine.buildBridgeBurnInfo(sideChain.snap)))
+		txDataInfo := strings.Split(data, ":")
+		for _, coinbase := range coinbases[:3] {
+			tx := types.NewTransaction(number, coinbase, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+			reported := engine.parseBridgeBurnInfo(txDataInfo[ufoMinSplitLen+6], scHash)
+			confirmations, _ = engine.processSCEventConfirm(confirmations, scHash, number, txDataInfo[ufoMinSplitLen+4], reported, tx, coinbase, make(RefundHash))
+		}
+		return confirmations
+	}
+	mainChain.seal(t, mainSigner, HeaderExtra{SideChainConfirmations: confirmLoop()})
+	for i := 0; i < 3; i++ {
+		mainChain.seal(t, mainSigner, HeaderExtra{})
+	}
+	if confirmed := mainChain.snap.SCRecordMap[scHash].LastConfirmedNumber; confirmed != sideChain.snap.Number {
+		t.Fatalf("side chain confirmed to %d, want %d", confirmed, sideChain.snap.Number)
+	}
+	if have := mainChain.state.GetBalance(mainRecipient); have.Int64() != 15 {
+		t.Fatalf("unlocked %v, want 15", have)
+	}
+	if locked := mainChain.snap.Bridge.Locked[scHash]; locked.Int64() != 25 {
+		t.Errorf("locked %v, want 25", locked)
+	}
+	if have := sideChain.state.GetBalance(sideRecipient); have.Int64() != 25 {
+		t.Errorf("side chain balance %v, want 25", have)
+	}
+
+	// The side chain keeps reporting the burn with the next loops, it is not
+	// unlocked again.
+	for i := 0; i < 3; i++ {
+		sideBlock(nil)
+	}
+	mainChain.seal(t, mainSigner, HeaderExtra{SideChainConfirmations: confirmLoop()})
+	for i := 0; i < 3; i++ {
+		mainChain.seal(t, mainSigner, HeaderExtra{})
+	}
+	if have := mainChain.state.GetBalance(mainRecipient); have.Int64() != 15 {
+		t.Errorf("unlocked %v after the burn was paid, want 15", have)
+	}
+}
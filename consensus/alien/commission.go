@@ -0,0 +1,114 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+const (
+	commissionNoticeDay        = params.CommissionNoticeDay        // days a commission change is announced before it takes effect
+	commissionMaxChangePercent = params.CommissionMaxChangePercent // largest change of a commission in one notice period, in percentage points
+)
+
+var spCommissionBase = big.NewInt(100) // storage pool fee and entrust rate are percentages
+
+// CommissionChange is an announced commission of a PoS candidate, storage
+// pool or storage pledge that replaces the current one at ActiveNumber.
+type CommissionChange struct {
+	Rate         *big.Int `json:"rate"`
+	ActiveNumber uint64   `json:"activenumber"`
+}
+
+func (c *CommissionChange) copy() *CommissionChange {
+	if c == nil {
+		return nil
+	}
+	return &CommissionChange{
+		Rate:         new(big.Int).Set(c.Rate),
+		ActiveNumber: c.ActiveNumber,
+	}
+}
+
+func (c *CommissionChange) String() string {
+	return c.Rate.String() + ":" + strconv.FormatUint(c.ActiveNumber, 10)
+}
+
+// checkCommissionChange reports whether a commission on a scale of base may
+// move from current to rate. Only one change can be announced at a time and
+// it can not move the commission by more than commissionMaxChangePercent of
+// the scale.
+func checkCommissionChange(current *big.Int, rate *big.Int, base *big.Int, pending *CommissionChange) bool {
+	if pending != nil {
+		return false
+	}
+	maxChange := new(big.Int).Div(new(big.Int).Mul(base, big.NewInt(commissionMaxChangePercent)), big.NewInt(100))
+	change := new(big.Int).Sub(rate, current)
+	return change.CmpAbs(maxChange) <= 0
+}
+
+func (s *Snapshot) newCommissionChange(rate *big.Int, number uint64) *CommissionChange {
+	return &CommissionChange{
+		Rate:         new(big.Int).Set(rate),
+		ActiveNumber: number + commissionNoticeDay*s.getBlockPreDay(),
+	}
+}
+
+// updatePendingCommission applies the commission changes whose notice period
+// has passed.
+func (s *Snapshot) updatePendingCommission(number uint64) {
+	for _, item := range s.PosPledge {
+		if item.PendingRate != nil && item.PendingRate.ActiveNumber <= number {
+			item.DisRate = item.PendingRate.Rate
+			item.PendingRate = nil
+		}
+	}
+	if s.SpData != nil {
+		changed := false
+		for hash, sp := range s.SpData.PoolPledge {
+			due := false
+			if sp.PendingFee != nil && sp.PendingFee.ActiveNumber <= number {
+				sp.Fee = sp.PendingFee.Rate.Uint64()
+				sp.PendingFee = nil
+				due = true
+			}
+			if sp.PendingEtRate != nil && sp.PendingEtRate.ActiveNumber <= number {
+				sp.EntrustRate = sp.PendingEtRate.Rate.Uint64()
+				sp.PendingEtRate = nil
+				due = true
+			}
+			if due {
+				s.SpData.accumulateSpPledgelHash(hash, false)
+				changed = true
+			}
+		}
+		if changed {
+			s.SpData.accumulateSpDataHash()
+		}
+	}
+	if s.StorageData != nil {
+		for _, entrust := range s.StorageData.StorageEntrust {
+			if entrust.PendingRate != nil && entrust.PendingRate.ActiveNumber <= number {
+				entrust.EntrustRate = entrust.PendingRate.Rate
+				entrust.PendingRate = nil
+			}
+		}
+	}
+}
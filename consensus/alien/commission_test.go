@@ -0,0 +1,105 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+func TestCommissionChange(t *testing.T) {
+	manager := common.HexToAddress("0x1100000000000000000000000000000000000011")
+	miner := common.HexToAddress("0x2200000000000000000000000000000000000022")
+	spHash := common.HexToHash("0x33")
+	snap := &Snapshot{
+		config: upgradedConfig(&params.AlienConfig{Period: 10, MaxSignerCount: 3}),
+		PosPledge: map[common.Address]*PosPledgeItem{
+			miner: {Manager: manager, DisRate: big.NewInt(5000), TotalAmount: big.NewInt(0)},
+		},
+		SpData: NewSPSnap(),
+	}
+	snap.SpData.PoolPledge[spHash] = &PoolPledge{
+		Manager:       manager,
+		Number:        big.NewInt(0),
+		TotalAmount:   big.NewInt(0),
+		TotalCapacity: big.NewInt(0),
+		UsedCapacity:  big.NewInt(0),
+		SnRatio:       big.NewInt(0),
+		ManagerAmount: big.NewInt(0),
+		Fee:           20,
+		EntrustRate:   50,
+	}
+	number := upgradeTestNumber + uint64(1)
+	notice := commissionNoticeDay * snap.getBlockPreDay()
+
+	changeRate := func(data string, current []CandidateChangeRateRecord) []CandidateChangeRateRecord {
+		tx := types.NewTransaction(0, manager, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+		return (&Alien{}).processCandidateChangeRate(current, strings.Split(data, ":"), manager, tx, nil, nil, snap, number)
+	}
+	if records := changeRate("UTG:1:CandChaRate:"+miner.Hex()+":0x1771", nil); len(records) != 0 { // 6001
+		t.Errorf("rate change above the limit accepted")
+	}
+	records := changeRate("UTG:1:CandChaRate:"+miner.Hex()+":0x1770", nil) // 6000
+	if len(records) != 1 {
+		t.Fatalf("rate change within the limit rejected")
+	}
+	if again := changeRate("UTG:1:CandChaRate:"+miner.Hex()+":0x1388", records); len(again) != 1 {
+		t.Errorf("second rate change in the block accepted")
+	}
+	snap.updateCandidateChangeRate(records, &types.Header{Number: new(big.Int).SetUint64(number)}, nil)
+	pledge := snap.PosPledge[miner]
+	if pledge.DisRate.Int64() != 5000 || pledge.PendingRate == nil || pledge.PendingRate.ActiveNumber != number+notice {
+		t.Fatalf("rate change not announced: %v %+v", pledge.DisRate, pledge.PendingRate)
+	}
+	if records := changeRate("UTG:1:CandChaRate:"+miner.Hex()+":0x1388", nil); len(records) != 0 {
+		t.Errorf("rate change accepted while another one is pending")
+	}
+
+	feeData := "UTG:1:spfee:" + spHash.Hex() + ":31"
+	feeTx := types.NewTransaction(0, manager, big.NewInt(0), 0, big.NewInt(0), []byte(feeData))
+	if fees := (&Alien{}).spSetFee(nil, strings.Split(feeData, ":"), manager, feeTx, nil, nil, snap, new(big.Int).SetUint64(number), nil); len(fees) != 0 {
+		t.Errorf("pool fee change above the limit accepted")
+	}
+	feeData = "UTG:1:spfee:" + spHash.Hex() + ":10"
+	fees := (&Alien{}).spSetFee(nil, strings.Split(feeData, ":"), manager, feeTx, nil, nil, snap, new(big.Int).SetUint64(number), nil)
+	if len(fees) != 1 {
+		t.Fatalf("pool fee change within the limit rejected")
+	}
+	hash := snap.SpData.PoolPledge[spHash].Hash
+	snap.updateSpFeeData(fees, nil, new(big.Int).SetUint64(number))
+	sp := snap.SpData.PoolPledge[spHash]
+	if sp.Fee != 20 || sp.PendingFee == nil || sp.PendingFee.Rate.Uint64() != 10 || sp.Hash == hash {
+		t.Fatalf("pool fee change not announced: %d %+v", sp.Fee, sp.PendingFee)
+	}
+
+	snap.updatePendingCommission(number + notice - 1)
+	if pledge.PendingRate == nil || sp.PendingFee == nil {
+		t.Fatalf("commission changed before the notice period ended")
+	}
+	snap.updatePendingCommission(number + notice)
+	if pledge.DisRate.Int64() != 6000 || pledge.PendingRate != nil {
+		t.Errorf("rate change not applied: %v %+v", pledge.DisRate, pledge.PendingRate)
+	}
+	if sp.Fee != 10 || sp.PendingFee != nil || sp.EntrustRate != 50 {
+		t.Errorf("pool fee change not applied: %d %+v", sp.Fee, sp.PendingFee)
+	}
+}
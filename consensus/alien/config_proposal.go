@@ -0,0 +1,156 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math"
+	"math/big"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+)
+
+// configProposalLock maps the lock parameter proposal types to the lock they configure
+var configProposalLock = map[uint64]uint32{
+	proposalTypeConfigCndLock: sscEnumCndLock,
+	proposalTypeConfigFlwLock: sscEnumFlwLock,
+	proposalTypeConfigRwdLock: sscEnumRwdLock,
+}
+
+// configProposalManagers are the manager slots a proposal may assign, every
+// slot of SystemConfig.ManagerAddress. A slot may be given a multi-signature
+// address. The managers of storage nodes and pools are not SystemConfig slots:
+// they are kept with each pledge and changed by the UTG transactions of their
+// current manager, so there is nothing to propose for them.
+var configProposalManagers = map[uint32]bool{
+	sscEnumExchRate:   true,
+	sscEnumSystem:     true,
+	sscEnumWdthPnsh:   true,
+	sscEnumFlowReport: true,
+}
+
+// configProposalDeposits are the deposit ids a proposal may change. The
+// storage pledge index is a counter kept by the chain, not a parameter.
+var configProposalDeposits = map[uint32]bool{
+	sscEnumSignerReward:          true,
+	sscEnumFlwReward:             true,
+	sscEnumBandwidthReward:       true,
+	sscEnumLeaseExpires:          true,
+	sscEnumMinimumRent:           true,
+	sscEnumStoragePrice:          true,
+	sscEnumMaximumRent:           true,
+	sscEnumPosCommitPeriod:       true,
+	sscEnumPosBeyondCommitPeriod: true,
+	sscEnumPosWithinCommitPeriod: true,
+}
+
+// isConfigProposal reports whether the proposal type changes a SystemConfig parameter
+func isConfigProposal(proposalType uint64) bool {
+	return proposalType >= proposalTypeConfigExchRate && proposalType <= proposalTypeConfigDelay
+}
+
+// checkConfigProposal validates the value of a SystemConfig proposal the same
+// way the SSC manager transactions did, and its id against the known manager
+// slots, deposit ids and ISPs.
+func (s *Snapshot) checkConfigProposal(proposal *Proposal) bool {
+	if proposal.ProposalType == proposalTypeConfigManager {
+		return configProposalManagers[proposal.ConfigID] && proposal.TargetAddress != common.Address{}
+	}
+	if proposal.ConfigValue == nil || !proposal.ConfigValue.IsUint64() {
+		return false
+	}
+	switch proposal.ProposalType {
+	case proposalTypeConfigDeposit:
+		return configProposalDeposits[proposal.ConfigID] && proposal.ConfigValue.Sign() > 0
+	case proposalTypeConfigQOS:
+		return s.isKnownISP(proposal.ConfigID) && proposal.ConfigValue.Uint64() <= math.MaxUint32
+	case proposalTypeConfigDelay:
+		return proposal.ConfigValue.Sign() > 0
+	case proposalTypeConfigExchRate, proposalTypeConfigOffLine:
+		return proposal.ConfigValue.Sign() > 0 && proposal.ConfigValue.Uint64() <= math.MaxUint32
+	}
+	return proposal.ConfigValue.Uint64() <= math.MaxUint32
+}
+
+// isKnownISP reports whether the ISP has a configured QOS or is claimed by
+// the bandwidth of a miner.
+func (s *Snapshot) isKnownISP(id uint32) bool {
+	if _, ok := s.SystemConfig.QosConfig[id]; ok {
+		return true
+	}
+	for _, bandwidth := range s.Bandwidth {
+		if bandwidth.ISPQosID == id {
+			return true
+		}
+	}
+	return false
+}
+
+// isConfigProposalPassed reports whether more than two thirds of the active
+// signers declared yes on the proposal.
+func (s *Snapshot) isConfigProposalPassed(proposal *Proposal) bool {
+	var voters []common.Address
+	for _, declare := range proposal.Declares {
+		if declare.Decision {
+			voters = append(voters, declare.Declarer)
+		}
+	}
+	return s.isSignerSupermajority(voters)
+}
+
+// isSignerSupermajority reports whether the voters include more than two
+// thirds of the active signers. Voters outside the signers queue and repeated
+// votes are not counted.
+func (s *Snapshot) isSignerSupermajority(voters []common.Address) bool {
+	signers := make(map[common.Address]bool)
+	for _, signer := range s.Signers {
+		signers[*signer] = false
+	}
+	yes := 0
+	for _, voter := range voters {
+		if voted, ok := signers[voter]; ok && !voted {
+			signers[voter] = true
+			yes++
+		}
+	}
+	return len(signers) > 0 && yes*3 > len(signers)*2
+}
+
+// applyConfigChange writes a SystemConfig change through the same updates
+// the SSC manager transactions used.
+func (s *Snapshot) applyConfigChange(change *PendingConfigChange) {
+	switch change.ProposalType {
+	case proposalTypeConfigExchRate:
+		s.updateConfigExchRate(uint32(change.ConfigValue.Uint64()))
+	case proposalTypeConfigOffLine:
+		s.updateConfigOffLine(uint32(change.ConfigValue.Uint64()))
+	case proposalTypeConfigDeposit:
+		s.updateConfigDeposit([]ConfigDepositRecord{{Who: change.ConfigID, Amount: new(big.Int).Set(change.ConfigValue)}})
+	case proposalTypeConfigCndLock, proposalTypeConfigFlwLock, proposalTypeConfigRwdLock:
+		s.updateLockParameters([]LockParameterRecord{{
+			Who:        configProposalLock[change.ProposalType],
+			LockPeriod: uint32(change.ConfigValue.Uint64()),
+			RlsPeriod:  change.RlsPeriod,
+			Interval:   change.Interval,
+		}})
+	case proposalTypeConfigQOS:
+		s.updateConfigISPQOS([]ISPQOSRecord{{ISPID: change.ConfigID, QOS: uint32(change.ConfigValue.Uint64())}})
+	case proposalTypeConfigManager:
+		s.updateManagerAddress([]ManagerAddressRecord{{Who: change.ConfigID, Target: change.Target}})
+	case proposalTypeConfigDelay:
+		s.SystemConfig.ConfigDelay = change.ConfigValue.Uint64()
+	}
+}
@@ -0,0 +1,168 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+var configSigners = []common.Address{
+	common.HexToAddress("0x1100000000000000000000000000000000000011"),
+	common.HexToAddress("0x2200000000000000000000000000000000000022"),
+	common.HexToAddress("0x3300000000000000000000000000000000000033"),
+}
+
+func newConfigProposalSnapshot() *Snapshot {
+	snap := &Snapshot{
+		config:         upgradedConfig(&params.AlienConfig{Period: 10, MaxSignerCount: 3}),
+		Candidates:     make(map[common.Address]uint64),
+		Tally:          make(map[common.Address]*big.Int),
+		Proposals:      make(map[common.Hash]*Proposal),
+		Bandwidth:      map[common.Address]*ClaimedBandwidth{configSigners[2]: {ISPQosID: 2, BandwidthClaimed: 100}},
+		ProposalRefund: make(map[uint64]map[common.Address]*big.Int),
+		SystemConfig: SystemParameter{
+			ExchRate:       10000,
+			OffLine:        10000,
+			Deposit:        make(map[uint32]*big.Int),
+			QosConfig:      make(map[uint32]uint32),
+			ManagerAddress: make(map[uint32]common.Address),
+			LockParameters: make(map[uint32]*LockParameter),
+		},
+	}
+	for i := range configSigners {
+		snap.Signers = append(snap.Signers, &configSigners[i])
+	}
+	// the signers queue repeats signers when there are few of them
+	snap.Signers = append(snap.Signers, &configSigners[0])
+	return snap
+}
+
+func sendConfigProposal(snap *Snapshot, statedb *state.StateDB, data string, number uint64) []Proposal {
+	tx := types.NewTransaction(0, configSigners[0], big.NewInt(0), 0, big.NewInt(0), []byte(data))
+	return (&Alien{}).processEventProposal(nil, strings.Split(data, ":"), statedb, tx, configSigners[0], snap, number)
+}
+
+func TestConfigProposal(t *testing.T) {
+	snap := newConfigProposalSnapshot()
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	statedb.AddBalance(configSigners[0], new(big.Int).Mul(proposalDeposit, big.NewInt(10)))
+	number := upgradeTestNumber + uint64(1)
+
+	for _, data := range []string{
+		"ufo:1:event:proposal:proposal_type:9:cfgvalue:0:vlcnt:4",
+		"ufo:1:event:proposal:proposal_type:9:cfgvalue:4294967296:vlcnt:4",
+		"ufo:1:event:proposal:proposal_type:10:vlcnt:4",
+		"ufo:1:event:proposal:proposal_type:16:cfgid:1:vlcnt:4",
+		"ufo:1:event:proposal:proposal_type:16:cfgid:1:manager:0x12:vlcnt:4",
+		"ufo:1:event:proposal:proposal_type:16:cfgid:9:manager:" + configSigners[2].Hex() + ":vlcnt:4",
+		"ufo:1:event:proposal:proposal_type:10:cfgid:6:cfgvalue:100:vlcnt:4",
+		"ufo:1:event:proposal:proposal_type:10:cfgid:99:cfgvalue:100:vlcnt:4",
+		"ufo:1:event:proposal:proposal_type:15:cfgid:7:cfgvalue:80:vlcnt:4",
+	} {
+		if proposals := sendConfigProposal(snap, statedb, data, number); len(proposals) != 0 {
+			t.Errorf("invalid proposal accepted: %s", data)
+		}
+	}
+	if proposals := sendConfigProposal(snap, statedb, "ufo:1:event:proposal:proposal_type:15:cfgid:2:cfgvalue:80:vlcnt:4", upgradeTestNumber-1); len(proposals) != 0 {
+		t.Fatalf("proposal accepted before the fork")
+	}
+
+	for _, data := range []string{
+		"ufo:1:event:proposal:proposal_type:16:cfgid:1:manager:" + configSigners[2].Hex() + ":vlcnt:4",
+		"ufo:1:event:proposal:proposal_type:10:cfgid:8:cfgvalue:2:vlcnt:4",
+	} {
+		if proposals := sendConfigProposal(snap, statedb, data, number); len(proposals) != 1 {
+			t.Errorf("valid proposal rejected: %s", data)
+		}
+	}
+	qos := sendConfigProposal(snap, statedb, "ufo:1:event:proposal:proposal_type:15:cfgid:2:cfgvalue:80:vlcnt:4", number)
+	lock := sendConfigProposal(snap, statedb, "ufo:1:event:proposal:proposal_type:12:cfgvalue:100:cfgrls:20:cfginterval:5:vlcnt:4", number)
+	if len(qos) != 1 || len(lock) != 1 {
+		t.Fatalf("proposals rejected")
+	}
+	lock[0].Hash = common.HexToHash("0x01")
+	snap.updateSnapshotByProposals(qos, new(big.Int).SetUint64(number))
+	snap.updateSnapshotByProposals(lock, new(big.Int).SetUint64(number))
+
+	// All signers support the qos change, one of them the lock change. Declares
+	// from outside the signers queue are not counted.
+	outsider := common.HexToAddress("0x44")
+	snap.Candidates[outsider] = candidateStateNormal
+	snap.Tally[outsider] = big.NewInt(1)
+	var declares []Declare
+	for _, signer := range append(configSigners, outsider) {
+		declares = append(declares, Declare{ProposalHash: qos[0].Hash, Declarer: signer, Decision: true})
+	}
+	declares = append(declares,
+		Declare{ProposalHash: lock[0].Hash, Declarer: configSigners[0], Decision: true},
+		Declare{ProposalHash: lock[0].Hash, Declarer: configSigners[1], Decision: false},
+		Declare{ProposalHash: lock[0].Hash, Declarer: outsider, Decision: true},
+	)
+	snap.updateSnapshotByDeclares(declares, new(big.Int).SetUint64(number+1))
+	if len(snap.Proposals[qos[0].Hash].Declares) != 3 || len(snap.Proposals[lock[0].Hash].Declares) != 2 {
+		t.Fatalf("declares from outside the signers queue recorded")
+	}
+
+	// Nothing changes before the validation period ends.
+	end := number + 4*snap.config.MaxSignerCount + 1
+	snap.calculateProposalResult(new(big.Int).SetUint64(end - 1))
+	if _, ok := snap.SystemConfig.QosConfig[2]; ok || len(snap.Proposals) != 2 {
+		t.Fatalf("proposal applied before the validation period ended")
+	}
+	snap.calculateProposalResult(new(big.Int).SetUint64(end))
+	if change := snap.PendingConfig[qos[0].Hash]; change == nil || change.ConfigValue.Uint64() != 80 || change.ActiveNumber != end+snap.configDelay() {
+		t.Errorf("passed proposal not queued: %+v", change)
+	}
+	if _, ok := snap.PendingConfig[lock[0].Hash]; ok || len(snap.PendingConfig) != 1 {
+		t.Errorf("failed proposal queued")
+	}
+	if len(snap.Proposals) != 0 {
+		t.Errorf("proposals not removed")
+	}
+	if refund := snap.ProposalRefund[end][configSigners[0]]; refund == nil || refund.Cmp(new(big.Int).Mul(proposalDeposit, big.NewInt(2))) != 0 {
+		t.Errorf("deposit not refunded: %v", refund)
+	}
+}
+
+// TestConfigProposalManagers checks that every manager slot of the system
+// configuration can be assigned by a proposal, and that the new manager takes
+// over the slot once the change is applied.
+func TestConfigProposalManagers(t *testing.T) {
+	snap := newSnapshot(&params.AlienConfig{Period: 10, MaxSignerCount: 3}, nil, common.Hash{}, nil, 0)
+	if len(snap.SystemConfig.ManagerAddress) != len(configProposalManagers) {
+		t.Errorf("manager slots: have %d, proposals cover %d", len(snap.SystemConfig.ManagerAddress), len(configProposalManagers))
+	}
+	manager := common.HexToAddress("0x5500000000000000000000000000000000000055")
+	for slot := range snap.SystemConfig.ManagerAddress {
+		proposal := &Proposal{ProposalType: proposalTypeConfigManager, ConfigID: slot, TargetAddress: manager}
+		if !snap.checkConfigProposal(proposal) {
+			t.Errorf("manager slot %d can not be proposed", slot)
+		}
+		snap.applyConfigChange(&PendingConfigChange{ProposalType: proposalTypeConfigManager, ConfigID: slot, Target: manager})
+		if have := snap.SystemConfig.ManagerAddress[slot]; have != manager {
+			t.Errorf("manager slot %d: have %v, want %v", slot, have, manager)
+		}
+	}
+}
@@ -0,0 +1,208 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+)
+
+const defaultConfigDelayDays = 2 // days a passed SystemConfig change waits unless ConfigDelay is set
+
+// PendingConfigChange is a passed SystemConfig change waiting in the queue
+// for its activation block.
+type PendingConfigChange struct {
+	Hash         common.Hash      `json:"hash"` // hash of the proposal
+	Issuer       common.Address   `json:"issuer"`
+	ProposalType uint64           `json:"proposaltype"`
+	ConfigID     uint32           `json:"configid"`
+	ConfigValue  *big.Int         `json:"configvalue"`
+	RlsPeriod    uint32           `json:"rlsperiod"`
+	Interval     uint32           `json:"interval"`
+	Target       common.Address   `json:"target"`
+	QueuedNumber uint64           `json:"queuednumber"`
+	ActiveNumber uint64           `json:"activenumber"`
+	CancelVotes  []common.Address `json:"cancelvotes"` // signers voting to withdraw the change
+}
+
+// ConfigCancelRecord is the withdrawal of a pending SystemConfig change by its
+// issuer, or the vote of a signer to withdraw it, before it activates
+type ConfigCancelRecord struct {
+	Hash   common.Hash
+	Signer common.Address
+}
+
+func newConfigChange(proposal *Proposal) *PendingConfigChange {
+	change := &PendingConfigChange{
+		Hash:         proposal.Hash,
+		Issuer:       proposal.Proposer,
+		ProposalType: proposal.ProposalType,
+		ConfigID:     proposal.ConfigID,
+		RlsPeriod:    proposal.ConfigRlsPeriod,
+		Interval:     proposal.ConfigInterval,
+		Target:       proposal.TargetAddress,
+	}
+	if proposal.ConfigValue != nil {
+		change.ConfigValue = new(big.Int).Set(proposal.ConfigValue)
+	}
+	return change
+}
+
+func (c *PendingConfigChange) copy() *PendingConfigChange {
+	cpy := *c
+	if c.ConfigValue != nil {
+		cpy.ConfigValue = new(big.Int).Set(c.ConfigValue)
+	}
+	cpy.CancelVotes = append([]common.Address(nil), c.CancelVotes...)
+	return &cpy
+}
+
+// configDelay returns the number of blocks a passed change waits before it activates
+func (s *Snapshot) configDelay() uint64 {
+	if s.SystemConfig.ConfigDelay > 0 {
+		return s.SystemConfig.ConfigDelay
+	}
+	return defaultConfigDelayDays * s.getBlockPreDay()
+}
+
+func (s *Snapshot) queueConfigChange(proposal *Proposal, number uint64) {
+	if s.PendingConfig == nil {
+		s.PendingConfig = make(map[common.Hash]*PendingConfigChange)
+	}
+	change := newConfigChange(proposal)
+	change.QueuedNumber = number
+	change.ActiveNumber = number + s.configDelay()
+	s.PendingConfig[change.Hash] = change
+}
+
+// processConfigCancel handles SSC:1:CancelConfig:<hash>. The issuer of a
+// pending change withdraws it, and an active signer votes to withdraw it: the
+// change is also withdrawn once more than two thirds of the signers voted, the
+// majority it passed with.
+func (a *Alien) processConfigCancel(currentCancel []ConfigCancelRecord, txDataInfo []string, txSender common.Address, snap *Snapshot) []ConfigCancelRecord {
+	if len(txDataInfo) <= sscPosCancelHash {
+		log.Warn("Config cancel", "parameter number", len(txDataInfo))
+		return currentCancel
+	}
+	cancel := ConfigCancelRecord{
+		Hash:   common.Hash{},
+		Signer: txSender,
+	}
+	if err := cancel.Hash.UnmarshalText([]byte(txDataInfo[sscPosCancelHash])); err != nil {
+		log.Warn("Config cancel", "hash", txDataInfo[sscPosCancelHash])
+		return currentCancel
+	}
+	change, ok := snap.PendingConfig[cancel.Hash]
+	if !ok {
+		log.Warn("Config cancel", "pending change not exist", cancel.Hash)
+		return currentCancel
+	}
+	if change.Issuer != txSender && !snap.isSigner(txSender) {
+		log.Warn("Config cancel", "not the issuer or a signer", txSender)
+		return currentCancel
+	}
+	for _, voter := range change.CancelVotes {
+		if voter == txSender {
+			log.Warn("Config cancel", "already voted", txSender)
+			return currentCancel
+		}
+	}
+	for _, item := range currentCancel {
+		if item.Hash == cancel.Hash && item.Signer == cancel.Signer {
+			log.Warn("Config cancel", "already voted", cancel.Hash)
+			return currentCancel
+		}
+	}
+	return append(currentCancel, cancel)
+}
+
+// updatePendingConfig drops the changes withdrawn by their issuer or more than
+// two thirds of the signers, counting the cancel votes, and activates the
+// changes whose delay has passed, in the order they were queued.
+func (s *Snapshot) updatePendingConfig(configCancel []ConfigCancelRecord, number uint64) {
+	for _, cancel := range configCancel {
+		change, ok := s.PendingConfig[cancel.Hash]
+		if !ok {
+			continue
+		}
+		if change.Issuer == cancel.Signer {
+			delete(s.PendingConfig, cancel.Hash)
+			continue
+		}
+		voted := false
+		for _, voter := range change.CancelVotes {
+			if voter == cancel.Signer {
+				voted = true
+				break
+			}
+		}
+		if !voted {
+			change.CancelVotes = append(change.CancelVotes, cancel.Signer)
+		}
+		if s.isSignerSupermajority(change.CancelVotes) {
+			delete(s.PendingConfig, cancel.Hash)
+		}
+	}
+	var due []*PendingConfigChange
+	for _, change := range s.PendingConfig {
+		if change.ActiveNumber <= number {
+			due = append(due, change)
+		}
+	}
+	sortPendingConfig(due)
+	for _, change := range due {
+		s.applyConfigChange(change)
+		delete(s.PendingConfig, change.Hash)
+	}
+}
+
+func sortPendingConfig(changes []*PendingConfigChange) {
+	sort.Slice(changes, func(i, j int) bool {
+		if changes[i].ActiveNumber != changes[j].ActiveNumber {
+			return changes[i].ActiveNumber < changes[j].ActiveNumber
+		}
+		if changes[i].QueuedNumber != changes[j].QueuedNumber {
+			return changes[i].QueuedNumber < changes[j].QueuedNumber
+		}
+		return bytes.Compare(changes[i].Hash[:], changes[j].Hash[:]) < 0
+	})
+}
+
+// GetPendingConfigChanges returns the SystemConfig changes waiting for their
+// activation block, the earliest first.
+func (api *API) GetPendingConfigChanges() ([]*PendingConfigChange, error) {
+	log.Info("api GetPendingConfigChanges")
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snapshot, err := api.getSnapshotCache(header)
+	if err != nil {
+		log.Warn("Fail to GetPendingConfigChanges", "err", err)
+		return nil, errUnknownBlock
+	}
+	changes := make([]*PendingConfigChange, 0, len(snapshot.PendingConfig))
+	for _, change := range snapshot.PendingConfig {
+		changes = append(changes, change)
+	}
+	sortPendingConfig(changes)
+	return changes, nil
+}
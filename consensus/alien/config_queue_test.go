@@ -0,0 +1,117 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+)
+
+func TestConfigQueue(t *testing.T) {
+	snap := newConfigProposalSnapshot()
+	number := upgradeTestNumber + uint64(1)
+	delay := &Proposal{Hash: common.HexToHash("0x01"), Proposer: configSigners[0], ProposalType: proposalTypeConfigDelay, ConfigValue: big.NewInt(100)}
+	exchRate := &Proposal{Hash: common.HexToHash("0x02"), Proposer: configSigners[1], ProposalType: proposalTypeConfigExchRate, ConfigValue: big.NewInt(12000)}
+	manager := &Proposal{Hash: common.HexToHash("0x03"), Proposer: configSigners[1], ProposalType: proposalTypeConfigManager, ConfigID: sscEnumSystem, TargetAddress: configSigners[2]}
+	snap.queueConfigChange(delay, number)
+	snap.queueConfigChange(exchRate, number)
+	if change := snap.PendingConfig[exchRate.Hash]; change.ActiveNumber != number+defaultConfigDelayDays*snap.getBlockPreDay() {
+		t.Fatalf("default delay not used: %d", change.ActiveNumber)
+	}
+
+	cancel := func(sender common.Address, hash common.Hash) []ConfigCancelRecord {
+		data := "SSC:1:CancelConfig:" + hash.Hex()
+		return (&Alien{}).processConfigCancel(nil, strings.Split(data, ":"), sender, snap)
+	}
+	outsider := common.HexToAddress("0x44")
+	if records := cancel(outsider, exchRate.Hash); len(records) != 0 {
+		t.Errorf("cancel from outside the issuer and the signers accepted")
+	}
+	if records := cancel(configSigners[1], common.HexToHash("0x04")); len(records) != 0 {
+		t.Errorf("cancel of an unknown change accepted")
+	}
+	// The issuer withdraws its change without the votes of the signers.
+	votes := cancel(configSigners[0], exchRate.Hash)
+	snap.updatePendingConfig(votes, number+1)
+	if len(votes) != 1 || len(snap.PendingConfig[exchRate.Hash].CancelVotes) != 1 {
+		t.Fatalf("cancel vote of a signer not counted")
+	}
+	records := cancel(configSigners[1], exchRate.Hash)
+	if len(records) != 1 {
+		t.Fatalf("cancel by the issuer rejected")
+	}
+	if err := verifyConfigCancel(records, records); err != nil {
+		t.Fatalf("cancel records differ: %v", err)
+	}
+	snap.updatePendingConfig(records, number+1)
+	if _, ok := snap.PendingConfig[exchRate.Hash]; ok {
+		t.Fatalf("change withdrawn by the issuer still pending")
+	}
+	// The issuer does not have to be a signer any more.
+	offLine := &Proposal{Hash: common.HexToHash("0x05"), Proposer: outsider, ProposalType: proposalTypeConfigOffLine, ConfigValue: big.NewInt(5000)}
+	snap.queueConfigChange(offLine, number)
+	if records := cancel(common.HexToAddress("0x55"), offLine.Hash); len(records) != 0 {
+		t.Errorf("cancel from outside the issuer and the signers accepted")
+	}
+	snap.updatePendingConfig(cancel(outsider, offLine.Hash), number+1)
+	if _, ok := snap.PendingConfig[offLine.Hash]; ok {
+		t.Fatalf("change withdrawn by a former signer still pending")
+	}
+
+	// Without the issuer, more than two thirds of the signers have to vote to
+	// withdraw a passed change.
+	exchRate.Proposer = outsider
+	snap.queueConfigChange(exchRate, number)
+	for i, signer := range configSigners {
+		records := cancel(signer, exchRate.Hash)
+		if len(records) != 1 {
+			t.Fatalf("cancel vote of signer %d rejected", i)
+		}
+		snap.updatePendingConfig(records, number+1)
+		if _, ok := snap.PendingConfig[exchRate.Hash]; ok != (i < len(configSigners)-1) {
+			t.Fatalf("pending after %d cancel votes: %v", i+1, ok)
+		}
+		if i == 0 {
+			if records := cancel(signer, exchRate.Hash); len(records) != 0 {
+				t.Errorf("repeated cancel vote accepted")
+			}
+		}
+	}
+
+	// The new delay applies to the changes queued after it activated.
+	active := snap.PendingConfig[delay.Hash].ActiveNumber
+	snap.updatePendingConfig(nil, active-1)
+	if snap.SystemConfig.ConfigDelay != 0 {
+		t.Fatalf("change activated before its delay")
+	}
+	snap.updatePendingConfig(nil, active)
+	if snap.SystemConfig.ConfigDelay != 100 || len(snap.PendingConfig) != 0 {
+		t.Fatalf("change not activated: delay %d", snap.SystemConfig.ConfigDelay)
+	}
+	snap.queueConfigChange(manager, active)
+	snap.updatePendingConfig(nil, active+99)
+	if snap.SystemConfig.ManagerAddress[sscEnumSystem] == configSigners[2] {
+		t.Fatalf("manager changed before its delay")
+	}
+	snap.updatePendingConfig(nil, active+100)
+	if snap.SystemConfig.ManagerAddress[sscEnumSystem] != configSigners[2] {
+		t.Errorf("manager not changed")
+	}
+}
@@ -46,6 +46,9 @@ var (
 
 	// errMCGasChargingInvalid is returned if gas charging info on main chain and side chain header are different
 	errMCGasChargingInvalid = errors.New("gas charging info is invalid")
+
+	// errMCBridgeMintInvalid is returned if bridge locks on main chain and bridge mints in side chain header are different
+	errMCBridgeMintInvalid = errors.New("bridge mint info is invalid")
 )
 
 // getMainChainSnapshotByTime return snapshot by header time of side chain
@@ -58,9 +58,13 @@ const (
 
 	nfcCategoryExch         = "Exch"
 	nfcCategoryMultiSign    = "Multi"
+	nfcCategoryMultiAdd     = "MultiAdd"
+	nfcCategoryMultiDel     = "MultiDel"
+	nfcCategoryMultiThr     = "MultiThr"
 	nfcCategoryBind         = "Bind"
 	nfcCategoryUnbind       = "Unbind"
 	nfcCategoryRebind       = "Rebind"
+	nfcCategoryBindSplit    = "BindSplit"
 	nfcCategoryCandReq      = "CandReq"
 	nfcCategoryCandExit     = "CandExit"
 	nfcCategoryCandPnsh     = "CandPnsh"
@@ -70,6 +74,11 @@ const (
 	categoryCandEntrustExit = "CandETExit"
 	categoryCandChangeRate  = "CandChaRate"
 	categoryCandPoSwtfd     = "PoSwtfd"
+	categoryRedelegate      = "Redelegate"
+	categoryAutoCompound    = "AutoCompound"
+	categorySignerRotate    = "SignerRotate"
+	categoryBridgeLock      = "BridgeLock"
+	categoryBridgeBurn      = "BridgeBurn"
 
 	sscCategoryExchRate = "ExchRate"
 	sscCategoryDeposit  = "Deposit"
@@ -80,6 +89,7 @@ const (
 	sscCategoryQOS      = "QOS"
 	sscCategoryWdthPnsh = "WdthPnsh"
 	sscCategoryManager  = "Manager"
+	sscCategoryCancel   = "CancelConfig"
 
 	ufoMinSplitLen = 3
 
@@ -106,6 +116,10 @@ const (
 	nfcPosRevenueAddress  = 7
 	nfcPosISPQosID        = 4
 	nfcPosBandwidth       = 5
+	nfcPosMultiAddress    = 3
+	nfcPosMultiOwner      = 4
+	nfcPosMultiThreshold  = 5
+	nfcPosMultiNewThr     = 4
 
 	sscPosExchRate       = 3
 	sscPosDeposit        = 3
@@ -119,6 +133,7 @@ const (
 	sscPosWdthPnsh       = 4
 	sscPosManagerID      = 3
 	sscPosManagerAddress = 4
+	sscPosCancelHash     = 3
 
 	sscEnumCndLock = 0
 	sscEnumFlwLock = 1
@@ -163,6 +178,15 @@ const (
 	proposalTypeMinVoterBalanceModify         = 6
 	proposalTypeProposalDepositModify         = 7
 	proposalTypeRentSideChain                 = 8 // use TTC to buy coin on side chain
+	proposalTypeConfigExchRate                = 9 // SystemConfig proposals, voted by the active signers
+	proposalTypeConfigDeposit                 = 10
+	proposalTypeConfigCndLock                 = 11
+	proposalTypeConfigFlwLock                 = 12
+	proposalTypeConfigRwdLock                 = 13
+	proposalTypeConfigOffLine                 = 14
+	proposalTypeConfigQOS                     = 15
+	proposalTypeConfigManager                 = 16
+	proposalTypeConfigDelay                   = 17 // blocks a passed SystemConfig change waits before it activates
 
 	/*
 	 * proposal related
@@ -180,6 +204,7 @@ const (
 	 * notice related
 	 */
 	noticeTypeGasCharging = 1
+	noticeTypeBridgeLock  = 2
 )
 
 // RefundGas :
@@ -219,23 +244,27 @@ type Confirmation struct {
 // proposal only come from the current candidates
 // not only candidate add/remove , current signer can proposal for params modify like percentage of reward distribution ...
 type Proposal struct {
-	Hash                   common.Hash    `json:"hash"`                   // tx hash
-	ReceivedNumber         *big.Int       `json:"receivenumber"`          // block number of proposal received
-	CurrentDeposit         *big.Int       `json:"currentdeposit"`         // received deposit for this proposal
-	ValidationLoopCnt      uint64         `json:"validationloopcount"`    // validation block number length of this proposal from the received block number
-	ProposalType           uint64         `json:"proposaltype"`           // type of proposal 1 - add candidate 2 - remove candidate ...
-	Proposer               common.Address `json:"proposer"`               // proposer
-	TargetAddress          common.Address `json:"candidateaddress"`       // candidate need to add/remove if candidateNeedPD == true
-	MinerRewardPerThousand uint64         `json:"minerrewardperthousand"` // reward of miner + side chain miner
-	SCHash                 common.Hash    `json:"schash"`                 // side chain genesis parent hash need to add/remove
-	SCBlockCountPerPeriod  uint64         `json:"scblockcountperpersiod"` // the number block sealed by this side chain per period, default 1
-	SCBlockRewardPerPeriod uint64         `json:"scblockrewardperperiod"` // the reward of this side chain per period if SCBlockCountPerPeriod reach, default 0. SCBlockRewardPerPeriod/1000 * MinerRewardPerThousand/1000 * BlockReward is the reward for this side chain
-	Declares               []*Declare     `json:"declares"`               // Declare this proposal received (always empty in block header)
-	MinVoterBalance        uint64         `json:"minvoterbalance"`        // value of minVoterBalance , need to mul big.Int(1e+18)
-	ProposalDeposit        uint64         `json:"proposaldeposit"`        // The deposit need to be frozen during before the proposal get final conclusion. (TTC)
-	SCRentFee              uint64         `json:"screntfee"`              // number of TTC coin, not wei
-	SCRentRate             uint64         `json:"screntrate"`             // how many coin you want for 1 TTC on main chain
-	SCRentLength           uint64         `json:"screntlength"`           // minimize block number of main chain , the rent fee will be used as reward of side chain miner.
+	Hash                   common.Hash    `json:"hash"`                           // tx hash
+	ReceivedNumber         *big.Int       `json:"receivenumber"`                  // block number of proposal received
+	CurrentDeposit         *big.Int       `json:"currentdeposit"`                 // received deposit for this proposal
+	ValidationLoopCnt      uint64         `json:"validationloopcount"`            // validation block number length of this proposal from the received block number
+	ProposalType           uint64         `json:"proposaltype"`                   // type of proposal 1 - add candidate 2 - remove candidate ...
+	Proposer               common.Address `json:"proposer"`                       // proposer
+	TargetAddress          common.Address `json:"candidateaddress"`               // candidate need to add/remove if candidateNeedPD == true
+	MinerRewardPerThousand uint64         `json:"minerrewardperthousand"`         // reward of miner + side chain miner
+	SCHash                 common.Hash    `json:"schash"`                         // side chain genesis parent hash need to add/remove
+	SCBlockCountPerPeriod  uint64         `json:"scblockcountperpersiod"`         // the number block sealed by this side chain per period, default 1
+	SCBlockRewardPerPeriod uint64         `json:"scblockrewardperperiod"`         // the reward of this side chain per period if SCBlockCountPerPeriod reach, default 0. SCBlockRewardPerPeriod/1000 * MinerRewardPerThousand/1000 * BlockReward is the reward for this side chain
+	Declares               []*Declare     `json:"declares"`                       // Declare this proposal received (always empty in block header)
+	MinVoterBalance        uint64         `json:"minvoterbalance"`                // value of minVoterBalance , need to mul big.Int(1e+18)
+	ProposalDeposit        uint64         `json:"proposaldeposit"`                // The deposit need to be frozen during before the proposal get final conclusion. (TTC)
+	SCRentFee              uint64         `json:"screntfee"`                      // number of TTC coin, not wei
+	SCRentRate             uint64         `json:"screntrate"`                     // how many coin you want for 1 TTC on main chain
+	SCRentLength           uint64         `json:"screntlength"`                   // minimize block number of main chain , the rent fee will be used as reward of side chain miner.
+	ConfigID               uint32         `json:"configid" rlp:"optional"`        // deposit id, isp id or manager id of a SystemConfig proposal
+	ConfigValue            *big.Int       `json:"configvalue" rlp:"optional"`     // new value of a SystemConfig proposal, lock period for lock parameters
+	ConfigRlsPeriod        uint32         `json:"configrlsperiod" rlp:"optional"` // release period of a lock parameters proposal
+	ConfigInterval         uint32         `json:"configinterval" rlp:"optional"`  // release interval of a lock parameters proposal
 }
 
 // Declare :
@@ -250,10 +279,11 @@ type Declare struct {
 
 // SCConfirmation is the confirmed tx send by side chain super node
 type SCConfirmation struct {
-	Hash     common.Hash
-	Coinbase common.Address // the side chain signer , may be diff from signer in main chain
-	Number   uint64
-	LoopInfo []string
+	Hash       common.Hash
+	Coinbase   common.Address // the side chain signer , may be diff from signer in main chain
+	Number     uint64
+	LoopInfo   []string
+	BridgeBurn []BridgeTransfer `rlp:"optional"` // burns on the side chain to unlock on main chain
 }
 
 // SCSetCoinbase is the tx send by main chain super node which can set coinbase for side chain
@@ -478,6 +508,19 @@ type HeaderExtra struct {
 	SpBind                 [] SpBindRecord
 	SpDataRoot        common.Hash
 	SPEPool                []common.Address
+	LeaseAutoRenew         []LeaseAutoRenewRecord `rlp:"optional"`
+	LeaseTransfer          []LeaseTransferRecord  `rlp:"optional"`
+	LeaseChallenge         []LeaseChallengeRecord `rlp:"optional"`
+	LeaseChallengeProof    []LeaseChallengeProofRecord `rlp:"optional"`
+	Redelegate             []RedelegateRecord `rlp:"optional"`
+	RevenueSplit           []RevenueSplitRecord `rlp:"optional"`
+	AutoCompound           []AutoCompoundRecord `rlp:"optional"`
+	Compounded             []CompoundRecord `rlp:"optional"`
+	ConfigCancel           []ConfigCancelRecord `rlp:"optional"`
+	SignerRotate           []SignerRotateRecord `rlp:"optional"`
+	BridgeLock             []BridgeTransfer `rlp:"optional"`
+	BridgeBurn             []BridgeTransfer `rlp:"optional"`
+	SideChainBridgeMint    []BridgeTransfer `rlp:"optional"` //This only exist in side chain's header.Extra
 }
 type HeaderExtraV7 struct {
 	CurrentBlockConfirmations []Confirmation
@@ -925,6 +968,12 @@ func (p *Proposal) copy() *Proposal {
 		SCRentFee:              p.SCRentFee,
 		SCRentRate:             p.SCRentRate,
 		SCRentLength:           p.SCRentLength,
+		ConfigID:               p.ConfigID,
+		ConfigRlsPeriod:        p.ConfigRlsPeriod,
+		ConfigInterval:         p.ConfigInterval,
+	}
+	if p.ConfigValue != nil {
+		cpy.ConfigValue = new(big.Int).Set(p.ConfigValue)
 	}
 
 	copy(cpy.Declares, p.Declares)
@@ -939,6 +988,9 @@ func (s *SCConfirmation) copy() *SCConfirmation {
 		LoopInfo: make([]string, len(s.LoopInfo)),
 	}
 	copy(cpy.LoopInfo, s.LoopInfo)
+	for _, burn := range s.BridgeBurn {
+		cpy.BridgeBurn = append(cpy.BridgeBurn, *burn.copy())
+	}
 	return cpy
 }
 
@@ -1385,7 +1437,12 @@ func decodeHeaderExtra(config *params.AlienConfig, number *big.Int, b []byte, va
 }
 
 // Build side chain confirm data
-func (a *Alien) buildSCEventConfirmData(scHash common.Hash, headerNumber *big.Int, headerTime *big.Int, lastLoopInfo string, chargingInfo string) []byte {
+func (a *Alien) buildSCEventConfirmData(scHash common.Hash, headerNumber *big.Int, headerTime *big.Int, lastLoopInfo string, chargingInfo string, burnInfo string) []byte {
+	if burnInfo != "" {
+		return []byte(fmt.Sprintf("%s:%s:%s:%s:%s:%d:%d:%s:%s:%s",
+			ufoPrefix, ufoVersion, ufoCategorySC, ufoEventConfirm,
+			scHash.Hex(), headerNumber.Uint64(), headerTime.Uint64(), lastLoopInfo, chargingInfo, burnInfo))
+	}
 	return []byte(fmt.Sprintf("%s:%s:%s:%s:%s:%d:%d:%s:%s",
 		ufoPrefix, ufoVersion, ufoCategorySC, ufoEventConfirm,
 		scHash.Hex(), headerNumber.Uint64(), headerTime.Uint64(), lastLoopInfo, chargingInfo))
@@ -1416,7 +1473,7 @@ func (a *Alien) processCustomTx(headerExtra HeaderExtra, chain consensus.ChainHe
 	}
 
 	for _, tx := range txs {
-		txSender, err := types.Sender(types.NewEIP155Signer(tx.ChainId()), tx)
+		txSender, err := types.Sender(customTxSigner(chain.Config(), tx, number), tx)
 		if err != nil {
 			continue
 		}
@@ -1425,6 +1482,11 @@ func (a *Alien) processCustomTx(headerExtra HeaderExtra, chain consensus.ChainHe
 			txData := string(tx.Data())
 			txDataInfo := strings.Split(txData, ":")
 			if len(txDataInfo) >= ufoMinSplitLen {
+				reason := balanceReasonCustomTx + ":" + txDataInfo[posCategory]
+				if txDataInfo[posCategory] == nfcCategoryExch {
+					reason = balanceReasonSRTExchange
+				}
+				setBalanceReason(state, reason, tx.Hash().Hex())
 				if txDataInfo[posPrefix] == ufoPrefix {
 					if txDataInfo[posVersion] == ufoVersion {
 						// process vote event
@@ -1436,8 +1498,8 @@ func (a *Alien) processCustomTx(headerExtra HeaderExtra, chain consensus.ChainHe
 								} else if txDataInfo[posEventConfirm] == ufoEventConfirm && snap.isCandidate(txSender) {
 									headerExtra.CurrentBlockConfirmations, refundHash = a.processEventConfirm(headerExtra.CurrentBlockConfirmations, chain, txDataInfo, number, tx, txSender, refundHash)
 								} else if txDataInfo[posEventProposal] == ufoEventPorposal {
-									headerExtra.CurrentBlockProposals = a.processEventProposal(headerExtra.CurrentBlockProposals, txDataInfo, state, tx, txSender, snap)
-								} else if txDataInfo[posEventDeclare] == ufoEventDeclare && snap.isCandidate(txSender) {
+									headerExtra.CurrentBlockProposals = a.processEventProposal(headerExtra.CurrentBlockProposals, txDataInfo, state, tx, txSender, snap, number)
+								} else if txDataInfo[posEventDeclare] == ufoEventDeclare && (snap.isCandidate(txSender) || (isGEConfigProposalEffect(snap.config, number) && snap.isSigner(txSender))) {
 									headerExtra.CurrentBlockDeclares = a.processEventDeclare(headerExtra.CurrentBlockDeclares, txDataInfo, tx, txSender)
 								}
 							} else {
@@ -1460,8 +1522,12 @@ func (a *Alien) processCustomTx(headerExtra HeaderExtra, chain consensus.ChainHe
 										}
 										loopInfo := txDataInfo[ufoMinSplitLen+4]
 										scHash := common.HexToHash(txDataInfo[ufoMinSplitLen+1])
+										var burns []BridgeTransfer
+										if len(txDataInfo) > ufoMinSplitLen+6 && isGEBridgeEffect(snap.config, header.Number.Uint64()) {
+											burns = a.parseBridgeBurnInfo(txDataInfo[ufoMinSplitLen+6], scHash)
+										}
 										headerExtra.SideChainConfirmations, refundHash = a.processSCEventConfirm(headerExtra.SideChainConfirmations,
-											scHash, number.Uint64(), loopInfo, tx, txSender, refundHash)
+											scHash, number.Uint64(), loopInfo, burns, tx, txSender, refundHash)
 
 										chargingInfo := txDataInfo[ufoMinSplitLen+5]
 										headerExtra.SideChainNoticeConfirmed = a.processSCEventNoticeConfirm(headerExtra.SideChainNoticeConfirmed,
@@ -1488,7 +1554,7 @@ func (a *Alien) processCustomTx(headerExtra HeaderExtra, chain consensus.ChainHe
 										refundHash[tx.Hash()] = RefundPair{txSender, tx.GasPrice()}
 									}
 								} else if ufoEventFlowReport2 == txDataInfo[posEventFlowReport] {
-									if txSender.String() == snap.SystemConfig.ManagerAddress[sscEnumFlowReport].String() {
+									if a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumFlowReport], txSender, tx, number) {
 										headerExtra.FlowReport = a.processFlowReport2(headerExtra.FlowReport, txDataInfo)
 										refundHash[tx.Hash()] = RefundPair{txSender, tx.GasPrice()}
 									}
@@ -1504,12 +1570,20 @@ func (a *Alien) processCustomTx(headerExtra HeaderExtra, chain consensus.ChainHe
 							}
 						} else if txDataInfo[posCategory] == nfcCategoryMultiSign {
 							a.processCreateMultiSignature(txDataInfo, txSender, tx, receipts, state)
+						} else if txDataInfo[posCategory] == nfcCategoryMultiAdd || txDataInfo[posCategory] == nfcCategoryMultiDel || txDataInfo[posCategory] == nfcCategoryMultiThr {
+							if isGEMultiSignatureManageEffect(chain.Config(), number) {
+								a.processUpdateMultiSignature(txDataInfo, txSender, tx, receipts, state)
+							}
 						} else if txDataInfo[posCategory] == nfcCategoryBind {
-							headerExtra.DeviceBind = a.processDeviceBind(headerExtra.DeviceBind, txDataInfo, txSender, tx, receipts, snapCache, number)
+							headerExtra.DeviceBind = a.processDeviceBind(headerExtra.DeviceBind, txDataInfo, txSender, tx, receipts, state, snapCache, number)
 						} else if txDataInfo[posCategory] == nfcCategoryUnbind {
 							headerExtra.DeviceBind = a.processDeviceUnbind(headerExtra.DeviceBind, txDataInfo, txSender, tx, receipts, state, snapCache, number)
 						} else if txDataInfo[posCategory] == nfcCategoryRebind {
 							headerExtra.DeviceBind = a.processDeviceRebind(headerExtra.DeviceBind, txDataInfo, txSender, tx, receipts, state, snapCache, number)
+						} else if txDataInfo[posCategory] == nfcCategoryBindSplit {
+							if isGERevenueSplitEffect(snapCache.config, number) {
+								headerExtra.RevenueSplit = a.processRevenueSplit(headerExtra.RevenueSplit, txDataInfo, txSender, tx, receipts, state, snapCache, number)
+							}
 						} else if txDataInfo[posCategory] == nfcCategoryCandReq {
 							if isGEPOSNewEffect(number) {
 								headerExtra.CandidatePledgeNew = a.processCandidatePledgeNew(headerExtra.CandidatePledgeNew, txDataInfo, txSender, tx, receipts, state, snap, number)
@@ -1549,38 +1623,61 @@ func (a *Alien) processCustomTx(headerExtra HeaderExtra, chain consensus.ChainHe
 							if txDataInfo[posCategory] == categoryCandChangeRate {
 								headerExtra.CandidateChangeRate = a.processCandidateChangeRate(headerExtra.CandidateChangeRate, txDataInfo, txSender, tx, receipts, state, snap, number)
 							}
+							if txDataInfo[posCategory] == categoryRedelegate && isGERedelegateEffect(snap.config, number) {
+								headerExtra.Redelegate = a.processRedelegate(headerExtra.Redelegate, txDataInfo, txSender, tx, receipts, snap, number)
+							}
+							if txDataInfo[posCategory] == categoryAutoCompound && isGEAutoCompoundEffect(snap.config, number) {
+								headerExtra.AutoCompound = a.processAutoCompound(headerExtra.AutoCompound, txDataInfo, txSender, tx, receipts, snap, number)
+							}
+							if txDataInfo[posCategory] == categorySignerRotate && isGESignerRotateEffect(snap.config, number) {
+								headerExtra.SignerRotate = a.processSignerRotate(headerExtra.SignerRotate, txDataInfo, txSender, tx, receipts, snap, number)
+							}
 
 						}
+						if isGEBridgeEffect(snap.config, number) {
+							if txDataInfo[posCategory] == categoryBridgeLock && !chain.Config().Alien.SideChain {
+								headerExtra.BridgeLock = a.processBridgeLock(headerExtra.BridgeLock, txDataInfo, txSender, tx, receipts, state, snap, number)
+							} else if txDataInfo[posCategory] == categoryBridgeBurn && chain.Config().Alien.SideChain {
+								headerExtra.BridgeBurn = a.processBridgeBurn(headerExtra.BridgeBurn, txDataInfo, txSender, tx, receipts, state, chain.GetHeaderByNumber(0).ParentHash, number)
+							}
+						}
 						if header.Number.Uint64() > initStorageManagerNumber {
 							headerExtra = a.processSPCustomTx(txDataInfo, headerExtra, txSender, tx, receipts, snapCache, header.Number, state, chain)
 						}
 					}
 				} else if txDataInfo[posPrefix] == sscPrefix {
 					if txDataInfo[posVersion] == ufoVersion {
-						if txDataInfo[posCategory] == sscCategoryExchRate {
-							headerExtra.ConfigExchRate = a.processExchRate(txDataInfo, txSender, snapCache)
+						if txDataInfo[posCategory] == sscCategoryCancel && isGEConfigQueueEffect(snapCache.config, number) {
+							headerExtra.ConfigCancel = a.processConfigCancel(headerExtra.ConfigCancel, txDataInfo, txSender, snapCache)
+						} else if txDataInfo[posCategory] != sscCategoryWdthPnsh && isGEConfigProposalEffect(snapCache.config, number) {
+							// Configuration changes, including the manager slots, go through
+							// proposals. A bandwidth punishment is not one and stays with its manager.
+							log.Warn("Config by manager is replaced by proposal", "category", txDataInfo[posCategory], "sender", txSender)
+						} else if txDataInfo[posCategory] == sscCategoryExchRate {
+							headerExtra.ConfigExchRate = a.processExchRate(txDataInfo, txSender, tx, state, snapCache, number)
 						} else if txDataInfo[posCategory] == sscCategoryDeposit {
-							headerExtra.ConfigDeposit = a.processCandidateDeposit(headerExtra.ConfigDeposit, txDataInfo, txSender, snapCache)
+							headerExtra.ConfigDeposit = a.processCandidateDeposit(headerExtra.ConfigDeposit, txDataInfo, txSender, tx, state, snapCache, number)
 						} else if txDataInfo[posCategory] == sscCategoryCndLock {
-							headerExtra.LockParameters = a.processCndLockConfig(headerExtra.LockParameters, txDataInfo, txSender, snapCache)
+							headerExtra.LockParameters = a.processCndLockConfig(headerExtra.LockParameters, txDataInfo, txSender, tx, state, snapCache, number)
 						} else if txDataInfo[posCategory] == sscCategoryFlwLock {
-							headerExtra.LockParameters = a.processFlwLockConfig(headerExtra.LockParameters, txDataInfo, txSender, snapCache)
+							headerExtra.LockParameters = a.processFlwLockConfig(headerExtra.LockParameters, txDataInfo, txSender, tx, state, snapCache, number)
 						} else if txDataInfo[posCategory] == sscCategoryRwdLock {
-							headerExtra.LockParameters = a.processRwdLockConfig(headerExtra.LockParameters, txDataInfo, txSender, snapCache)
+							headerExtra.LockParameters = a.processRwdLockConfig(headerExtra.LockParameters, txDataInfo, txSender, tx, state, snapCache, number)
 						} else if txDataInfo[posCategory] == sscCategoryOffLine {
-							headerExtra.ConfigOffLine = a.processOffLine(txDataInfo, txSender, snapCache)
+							headerExtra.ConfigOffLine = a.processOffLine(txDataInfo, txSender, tx, state, snapCache, number)
 						} else if txDataInfo[posCategory] == sscCategoryQOS {
-							headerExtra.ConfigISPQOS = a.processISPQos(headerExtra.ConfigISPQOS, txDataInfo, txSender, snapCache)
+							headerExtra.ConfigISPQOS = a.processISPQos(headerExtra.ConfigISPQOS, txDataInfo, txSender, tx, state, snapCache, number)
 						} else if txDataInfo[posCategory] == sscCategoryWdthPnsh {
-							headerExtra.BandwidthPunish = a.processBandwidthPunish(headerExtra.BandwidthPunish, txDataInfo, txSender, tx, receipts, snapCache)
+							headerExtra.BandwidthPunish = a.processBandwidthPunish(headerExtra.BandwidthPunish, txDataInfo, txSender, tx, receipts, state, snapCache, number)
 						} else if txDataInfo[posCategory] == sscCategoryManager {
-							headerExtra.ManagerAddress = a.processManagerAddress(headerExtra.ManagerAddress, txDataInfo, txSender, snapCache)
+							headerExtra.ManagerAddress = a.processManagerAddress(headerExtra.ManagerAddress, txDataInfo, txSender, tx, state, snapCache, number)
 						}
 					}
 				}
 			}
 		}
 		// check each address
+		setBalanceReason(state, balanceReasonCustomTx, tx.Hash().Hex())
 		if number > 1 {
 			headerExtra.ModifyPredecessorVotes = a.processPredecessorVoter(headerExtra.ModifyPredecessorVotes, state, tx, txSender, snap, number)
 		}
@@ -1617,12 +1714,13 @@ func (a *Alien) processSCEventNoticeConfirm(scEventNoticeConfirm []SCConfirmatio
 	return scEventNoticeConfirm
 }
 
-func (a *Alien) processSCEventConfirm(scEventConfirmaions []SCConfirmation, hash common.Hash, number uint64, loopInfo string, tx *types.Transaction, txSender common.Address, refundHash RefundHash) ([]SCConfirmation, RefundHash) {
+func (a *Alien) processSCEventConfirm(scEventConfirmaions []SCConfirmation, hash common.Hash, number uint64, loopInfo string, burns []BridgeTransfer, tx *types.Transaction, txSender common.Address, refundHash RefundHash) ([]SCConfirmation, RefundHash) {
 	scEventConfirmaions = append(scEventConfirmaions, SCConfirmation{
-		Hash:     hash,
-		Coinbase: txSender,
-		Number:   number,
-		LoopInfo: strings.Split(loopInfo, "#"),
+		Hash:       hash,
+		Coinbase:   txSender,
+		Number:     number,
+		LoopInfo:   strings.Split(loopInfo, "#"),
+		BridgeBurn: burns,
 	})
 	refundHash[tx.Hash()] = RefundPair{txSender, tx.GasPrice()}
 	return scEventConfirmaions, refundHash
@@ -1638,9 +1736,11 @@ func (a *Alien) processSCEventSetCoinbase(scEventSetCoinbases []SCSetCoinbase, h
 	return scEventSetCoinbases
 }
 
-func (a *Alien) processEventProposal(currentBlockProposals []Proposal, txDataInfo []string, state *state.StateDB, tx *types.Transaction, proposer common.Address, snap *Snapshot) []Proposal {
+func (a *Alien) processEventProposal(currentBlockProposals []Proposal, txDataInfo []string, state *state.StateDB, tx *types.Transaction, proposer common.Address, snap *Snapshot, number uint64) []Proposal {
 	// sample for add side chain proposal
 	// eth.sendTransaction({from:eth.accounts[0],to:eth.accounts[0],value:0,data:web3.toHex("ufo:1:event:proposal:proposal_type:4:sccount:2:screward:50:schash:0x3210000000000000000000000000000000000000000000000000000000000000:vlcnt:4")})
+	// sample for SystemConfig proposal, declared by the active signers
+	// eth.sendTransaction({from:eth.accounts[0],to:eth.accounts[0],value:0,data:web3.toHex("ufo:1:event:proposal:proposal_type:15:cfgid:2:cfgvalue:80:vlcnt:4")})
 	// sample for declare
 	// eth.sendTransaction({from:eth.accounts[0],to:eth.accounts[0],value:0,data:web3.toHex("ufo:1:event:declare:hash:0x853e10706e6b9d39c5f4719018aa2417e8b852dec8ad18f9c592d526db64c725:decision:yes")})
 	if len(txDataInfo) <= posEventProposal+2 {
@@ -1745,9 +1845,42 @@ func (a *Alien) processEventProposal(currentBlockProposals []Proposal, txDataInf
 			} else {
 				proposal.SCRentLength = uint64(scrl)
 			}
+		case "cfgid":
+			// deposit id, isp id or manager id
+			if cfgid, err := strconv.ParseUint(v, 10, 32); err != nil {
+				return currentBlockProposals
+			} else {
+				proposal.ConfigID = uint32(cfgid)
+			}
+		case "cfgvalue":
+			if cfgvalue, ok := new(big.Int).SetString(v, 10); !ok || cfgvalue.Sign() < 0 {
+				return currentBlockProposals
+			} else {
+				proposal.ConfigValue = cfgvalue
+			}
+		case "cfgrls":
+			if cfgrls, err := strconv.ParseUint(v, 10, 32); err != nil {
+				return currentBlockProposals
+			} else {
+				proposal.ConfigRlsPeriod = uint32(cfgrls)
+			}
+		case "cfginterval":
+			if cfginterval, err := strconv.ParseUint(v, 10, 32); err != nil {
+				return currentBlockProposals
+			} else {
+				proposal.ConfigInterval = uint32(cfginterval)
+			}
+		case "manager":
+			if err := proposal.TargetAddress.UnmarshalText([]byte(v)); err != nil {
+				return currentBlockProposals
+			}
 		}
 	}
 	// now the proposal is built
+	if isConfigProposal(proposal.ProposalType) && (!isGEConfigProposalEffect(snap.config, number) || !snap.checkConfigProposal(&proposal)) {
+		log.Warn("Config proposal", "type", proposal.ProposalType, "hash", proposal.Hash)
+		return currentBlockProposals
+	}
 	currentProposalPay := new(big.Int).Set(proposalDeposit)
 	if proposal.ProposalType == proposalTypeRentSideChain {
 		// check if the proposal target side chain exist
@@ -1906,32 +2039,21 @@ func (a *Alien) addCustomerTxLog(tx *types.Transaction, receipts []*types.Receip
 }
 
 func (a *Alien) verifyMultiSignatureAddress(state *state.StateDB, address common.Address, signers []common.Address) bool {
-	if state.Empty(address) {
+	parameter, err := consensus.ReadMultiSignatureData(state, address)
+	if err != nil {
 		return false
 	}
-	contractHash := state.GetCodeHash(address)
-	if state.GetNonce(address) != 1 || contractHash == (common.Hash{}) || contractHash == crypto.Keccak256Hash(nil) {
-		return false
-	}
-	var parameter consensus.MultiSignatureData
-	if err := rlp.DecodeBytes(state.GetCode(address), &parameter); nil != err {
-		return false
-	}
-	assistAddress := make(map[common.Address]bool)
-	for _, assist := range parameter.MultiSigners {
-		assistAddress[assist] = true
-	}
-	okNumber := 0
-	okAddress := make(map[common.Address]bool)
-	for _, signer := range signers {
-		if _, ok := okAddress[signer]; !ok {
-			if _, ok = assistAddress[signer]; ok {
-				okNumber++
-				okAddress[signer] = true
-			}
-		}
+	return parameter.Satisfied(signers)
+}
+
+// isManagerSigner reports whether the manager signed tx. A manager may be a
+// multi-signature address from the ManagerMultiSignBlock on, then its
+// threshold of owners must have signed the transaction.
+func (a *Alien) isManagerSigner(state *state.StateDB, manager common.Address, txSender common.Address, tx *types.Transaction, number uint64) bool {
+	if manager == txSender {
+		return true
 	}
-	return okNumber >= int(parameter.Threshold)
+	return isGEManagerMultiSignEffect(a.config, number) && state != nil && a.verifyMultiSignatureAddress(state, manager, tx.AllSigners())
 }
 
 func (a *Alien) processCreateMultiSignature(txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB) {
@@ -2004,6 +2126,104 @@ func (a *Alien) processCreateMultiSignature(txDataInfo []string, txSender common
 	a.addCustomerTxLog(tx, receipts, topics, contractAddr.Hash().Bytes())
 }
 
+// customTxSigner returns the signer used to recover the sender of custom
+// transactions. Multi-signer transactions are accepted from the
+// multi-signature spend fork, so that handlers can authorise a multi-signature
+// address with tx.AllSigners(). Every other transaction type keeps the EIP155
+// signer.
+func customTxSigner(config *params.ChainConfig, tx *types.Transaction, number uint64) types.Signer {
+	if tx.Type() == types.MultiSignerTxType && isGEMultiSignatureManageEffect(config, number) {
+		return types.NewEIP2930Signer(tx.ChainId())
+	}
+	return types.NewEIP155Signer(tx.ChainId())
+}
+
+func (a *Alien) processUpdateMultiSignature(txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB) {
+	category := txDataInfo[posCategory]
+	if (category == nfcCategoryMultiThr && len(txDataInfo) <= nfcPosMultiNewThr) || (category != nfcCategoryMultiThr && len(txDataInfo) <= nfcPosMultiOwner) {
+		log.Warn("Update Multi-Signature fail", "parameter number", len(txDataInfo))
+		return
+	}
+	var multiSignature common.Address
+	if err := multiSignature.UnmarshalText1([]byte(txDataInfo[nfcPosMultiAddress])); err != nil {
+		log.Warn("Update Multi-Signature", "address", txDataInfo[nfcPosMultiAddress])
+		return
+	}
+	parameter, err := consensus.ReadMultiSignatureData(state, multiSignature)
+	if err != nil {
+		log.Warn("Update Multi-Signature fail", "address", multiSignature, "err", err)
+		return
+	}
+	if !parameter.Satisfied(tx.AllSigners()) {
+		log.Warn("Update Multi-Signature fail", "address", multiSignature, "signers", len(parameter.Signed(tx.AllSigners())), "threshold", parameter.Threshold)
+		return
+	}
+	thresholdPos := nfcPosMultiThreshold
+	switch category {
+	case nfcCategoryMultiAdd, nfcCategoryMultiDel:
+		var owner common.Address
+		if err := owner.UnmarshalText1([]byte(txDataInfo[nfcPosMultiOwner])); err != nil {
+			log.Warn("Update Multi-Signature", "owner", txDataInfo[nfcPosMultiOwner])
+			return
+		}
+		index := -1
+		for i, signer := range parameter.MultiSigners {
+			if signer == owner {
+				index = i
+				break
+			}
+		}
+		if category == nfcCategoryMultiAdd {
+			if index >= 0 {
+				log.Warn("Update Multi-Signature fail", "owner already exists", owner)
+				return
+			}
+			parameter.MultiSigners = append(parameter.MultiSigners, owner)
+		} else {
+			if index < 0 {
+				log.Warn("Update Multi-Signature fail", "owner not exists", owner)
+				return
+			}
+			parameter.MultiSigners = append(parameter.MultiSigners[:index], parameter.MultiSigners[index+1:]...)
+		}
+	case nfcCategoryMultiThr:
+		thresholdPos = nfcPosMultiNewThr
+	}
+	if len(txDataInfo) > thresholdPos && txDataInfo[thresholdPos] != "" {
+		threshold, err := strconv.ParseUint(txDataInfo[thresholdPos], 10, 32)
+		if err != nil || 2 > threshold || 10 < threshold {
+			log.Warn("Update Multi-Signature", "threshold", txDataInfo[thresholdPos])
+			return
+		}
+		parameter.Threshold = uint32(threshold)
+	}
+	if len(parameter.MultiSigners) <= int(parameter.Threshold) || len(parameter.MultiSigners) > 1000 {
+		log.Warn("Update Multi-Signature fail", "Owner number", len(parameter.MultiSigners), "threshold", parameter.Threshold)
+		return
+	}
+	data, err := rlp.EncodeToBytes(parameter)
+	if nil != err {
+		log.Warn("Update Multi-Signature fail", "err", err)
+		return
+	}
+	if len(data) > params.MaxCodeSize {
+		log.Warn("Update Multi-Signature fail for max code size exceeded")
+		return
+	}
+	state.SetCode(multiSignature, data)
+	topics := make([]common.Hash, 3)
+	topics[0].UnmarshalText([]byte("0x2465dd599c9bbeea7011debc2e80173b3d4f5cdb73f6e883d8816d86adc38f41")) //web3.sha3("UpdateMultiSignature(uint256,address[])")
+	topics[1].SetBytes(multiSignature.Bytes())
+	topics[2].SetBytes(txSender.Bytes())
+	logData := common.LeftPadBytes(big.NewInt(int64(parameter.Threshold)).Bytes(), 32)
+	logData = append(logData, common.LeftPadBytes(big.NewInt(64).Bytes(), 32)...)
+	logData = append(logData, common.LeftPadBytes(big.NewInt(int64(len(parameter.MultiSigners))).Bytes(), 32)...)
+	for _, owner := range parameter.MultiSigners {
+		logData = append(logData, owner.Hash().Bytes()...)
+	}
+	a.addCustomerTxLog(tx, receipts, topics, logData)
+}
+
 func (a *Alien) processExchangeNFC(currentExchangeNFC []ExchangeNFCRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB, snap *Snapshot) []ExchangeNFCRecord {
 	if len(txDataInfo) <= nfcPosExchValue {
 		log.Warn("Exchange NFC to FUL fail", "parameter number", len(txDataInfo))
@@ -2044,7 +2264,7 @@ func (a *Alien) processExchangeNFC(currentExchangeNFC []ExchangeNFCRecord, txDat
 	return currentExchangeNFC
 }
 
-func (a *Alien) processDeviceBind(currentDeviceBind []DeviceBindRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, snap *Snapshot, number uint64) []DeviceBindRecord {
+func (a *Alien) processDeviceBind(currentDeviceBind []DeviceBindRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB, snap *Snapshot, number uint64) []DeviceBindRecord {
 	if len(txDataInfo) <= nfcPosMiltiSign {
 		log.Warn("Device bind revenue", "parameter number", len(txDataInfo))
 		return currentDeviceBind
@@ -2079,7 +2299,7 @@ func (a *Alien) processDeviceBind(currentDeviceBind []DeviceBindRecord, txDataIn
 					return currentDeviceBind
 				}
 				if isGEInitStorageManagerNumber(number) {
-					if !a.isStorageManager(snap, deviceBind, txSender, txDataInfo) {
+					if !a.isStorageManager(snap, deviceBind, txSender, txDataInfo, tx, state, number) {
 						return currentDeviceBind
 					}
 				}
@@ -2217,7 +2437,7 @@ func (a *Alien) processDeviceUnbind(currentDeviceBind []DeviceBindRecord, txData
 				} else {
 					if oldBind.MultiSignature == nilHash || oldBind.MultiSignature == zeroHash {
 						if isGEInitStorageManagerNumber(number) {
-							if !a.isStorageManager(snap, deviceBind, txSender, txDataInfo) {
+							if !a.isStorageManager(snap, deviceBind, txSender, txDataInfo, tx, state, number) {
 								return currentDeviceBind
 							}
 						} else {
@@ -2332,7 +2552,7 @@ func (a *Alien) processDeviceRebind(currentDeviceBind []DeviceBindRecord, txData
 		} else {
 			if isGEInitStorageManagerNumber(number) {
 				if _, ok := snap.RevenueStorage[deviceBind.Device]; ok {
-					if !a.isStorageManager(snap, deviceBind, txSender, txDataInfo) {
+					if !a.isStorageManager(snap, deviceBind, txSender, txDataInfo, tx, state, number) {
 						return currentDeviceBind
 					}
 				} else {
@@ -2825,12 +3045,12 @@ func (a *Alien) processMinerExit(currentFlowMinerExit []common.Address, txDataIn
 	return currentFlowMinerExit
 }
 
-func (a *Alien) processBandwidthPunish(currentBandwidthPunish []BandwidthPunishRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, snap *Snapshot) []BandwidthPunishRecord {
+func (a *Alien) processBandwidthPunish(currentBandwidthPunish []BandwidthPunishRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB, snap *Snapshot, number uint64) []BandwidthPunishRecord {
 	if len(txDataInfo) <= sscPosWdthPnsh {
 		log.Warn("Bandwidth punish", "parameter number", len(txDataInfo))
 		return currentBandwidthPunish
 	}
-	if snap.SystemConfig.ManagerAddress[sscEnumWdthPnsh].String() != txSender.String() {
+	if !a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumWdthPnsh], txSender, tx, number) {
 		log.Warn("Bandwidth punish", "manager address", txSender)
 		return currentBandwidthPunish
 	}
@@ -2868,7 +3088,7 @@ func (a *Alien) processBandwidthPunish(currentBandwidthPunish []BandwidthPunishR
 	return currentBandwidthPunish
 }
 
-func (a *Alien) processExchRate(txDataInfo []string, txSender common.Address, snap *Snapshot) uint32 {
+func (a *Alien) processExchRate(txDataInfo []string, txSender common.Address, tx *types.Transaction, state *state.StateDB, snap *Snapshot, number uint64) uint32 {
 	if len(txDataInfo) <= sscPosExchRate {
 		log.Warn("Config exchrate", "parameter number", len(txDataInfo))
 		return 0
@@ -2877,7 +3097,7 @@ func (a *Alien) processExchRate(txDataInfo []string, txSender common.Address, sn
 		log.Warn("Config exchrate", "exchrate", txDataInfo[sscPosExchRate])
 		return 0
 	} else {
-		if snap.SystemConfig.ManagerAddress[sscEnumExchRate].String() != txSender.String() {
+		if !a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumExchRate], txSender, tx, number) {
 			log.Warn("Config exchrate", "manager address", txSender)
 			return 0
 		}
@@ -2885,7 +3105,7 @@ func (a *Alien) processExchRate(txDataInfo []string, txSender common.Address, sn
 	}
 }
 
-func (a *Alien) processCandidateDeposit(currentDeposit []ConfigDepositRecord, txDataInfo []string, txSender common.Address, snap *Snapshot) []ConfigDepositRecord {
+func (a *Alien) processCandidateDeposit(currentDeposit []ConfigDepositRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, state *state.StateDB, snap *Snapshot, number uint64) []ConfigDepositRecord {
 	if len(txDataInfo) <= sscPosDepositWho {
 		log.Warn("Config candidate deposit", "parameter number", len(txDataInfo))
 		return currentDeposit
@@ -2905,7 +3125,7 @@ func (a *Alien) processCandidateDeposit(currentDeposit []ConfigDepositRecord, tx
 	} else {
 		deposit.Who = uint32(id)
 	}
-	if snap.SystemConfig.ManagerAddress[sscEnumSystem].String() != txSender.String() {
+	if !a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumSystem], txSender, tx, number) {
 		log.Warn("Config candidate deposit", "manager address", txSender)
 		return currentDeposit
 	}
@@ -2913,7 +3133,7 @@ func (a *Alien) processCandidateDeposit(currentDeposit []ConfigDepositRecord, tx
 	return currentDeposit
 }
 
-func (a *Alien) processCndLockConfig(currentLockParameters []LockParameterRecord, txDataInfo []string, txSender common.Address, snap *Snapshot) []LockParameterRecord {
+func (a *Alien) processCndLockConfig(currentLockParameters []LockParameterRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, state *state.StateDB, snap *Snapshot, number uint64) []LockParameterRecord {
 	if len(txDataInfo) <= sscPosInterval {
 		log.Warn("Config candidate lock", "parameter number", len(txDataInfo))
 		return currentLockParameters
@@ -2942,7 +3162,7 @@ func (a *Alien) processCndLockConfig(currentLockParameters []LockParameterRecord
 	} else {
 		lockParameter.Interval = uint32(interval)
 	}
-	if snap.SystemConfig.ManagerAddress[sscEnumSystem].String() != txSender.String() {
+	if !a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumSystem], txSender, tx, number) {
 		log.Warn("Config candidate lock", "manager address", txSender)
 		return currentLockParameters
 	}
@@ -2950,7 +3170,7 @@ func (a *Alien) processCndLockConfig(currentLockParameters []LockParameterRecord
 	return currentLockParameters
 }
 
-func (a *Alien) processFlwLockConfig(currentLockParameters []LockParameterRecord, txDataInfo []string, txSender common.Address, snap *Snapshot) []LockParameterRecord {
+func (a *Alien) processFlwLockConfig(currentLockParameters []LockParameterRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, state *state.StateDB, snap *Snapshot, number uint64) []LockParameterRecord {
 	if len(txDataInfo) <= sscPosInterval {
 		log.Warn("Config miner lock", "parameter number", len(txDataInfo))
 		return currentLockParameters
@@ -2979,7 +3199,7 @@ func (a *Alien) processFlwLockConfig(currentLockParameters []LockParameterRecord
 	} else {
 		lockParameter.Interval = uint32(interval)
 	}
-	if snap.SystemConfig.ManagerAddress[sscEnumSystem].String() != txSender.String() {
+	if !a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumSystem], txSender, tx, number) {
 		log.Warn("Config miner lock", "manager address", txSender)
 		return currentLockParameters
 	}
@@ -2987,7 +3207,7 @@ func (a *Alien) processFlwLockConfig(currentLockParameters []LockParameterRecord
 	return currentLockParameters
 }
 
-func (a *Alien) processRwdLockConfig(currentLockParameters []LockParameterRecord, txDataInfo []string, txSender common.Address, snap *Snapshot) []LockParameterRecord {
+func (a *Alien) processRwdLockConfig(currentLockParameters []LockParameterRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, state *state.StateDB, snap *Snapshot, number uint64) []LockParameterRecord {
 	if len(txDataInfo) <= sscPosInterval {
 		log.Warn("Config reward lock", "parameter number", len(txDataInfo))
 		return currentLockParameters
@@ -3016,7 +3236,7 @@ func (a *Alien) processRwdLockConfig(currentLockParameters []LockParameterRecord
 	} else {
 		lockParameter.Interval = uint32(interval)
 	}
-	if snap.SystemConfig.ManagerAddress[sscEnumSystem].String() != txSender.String() {
+	if !a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumSystem], txSender, tx, number) {
 		log.Warn("Config reward lock", "manager address", txSender)
 		return currentLockParameters
 	}
@@ -3024,7 +3244,7 @@ func (a *Alien) processRwdLockConfig(currentLockParameters []LockParameterRecord
 	return currentLockParameters
 }
 
-func (a *Alien) processOffLine(txDataInfo []string, txSender common.Address, snap *Snapshot) uint32 {
+func (a *Alien) processOffLine(txDataInfo []string, txSender common.Address, tx *types.Transaction, state *state.StateDB, snap *Snapshot, number uint64) uint32 {
 	if len(txDataInfo) <= sscPosOffLine {
 		log.Warn("Config offLine", "parameter number", len(txDataInfo))
 		return 0
@@ -3033,7 +3253,7 @@ func (a *Alien) processOffLine(txDataInfo []string, txSender common.Address, sna
 		log.Warn("Config offline", "offline", txDataInfo[sscPosOffLine])
 		return 0
 	} else {
-		if snap.SystemConfig.ManagerAddress[sscEnumSystem].String() != txSender.String() {
+		if !a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumSystem], txSender, tx, number) {
 			log.Warn("Config offLine", "manager address", txSender)
 			return 0
 		}
@@ -3041,7 +3261,7 @@ func (a *Alien) processOffLine(txDataInfo []string, txSender common.Address, sna
 	}
 }
 
-func (a *Alien) processISPQos(currentISPQOS []ISPQOSRecord, txDataInfo []string, txSender common.Address, snap *Snapshot) []ISPQOSRecord {
+func (a *Alien) processISPQos(currentISPQOS []ISPQOSRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, state *state.StateDB, snap *Snapshot, number uint64) []ISPQOSRecord {
 	if len(txDataInfo) <= sscPosQosValue {
 		log.Warn("Config isp qos", "parameter number", len(txDataInfo))
 		return currentISPQOS
@@ -3062,7 +3282,7 @@ func (a *Alien) processISPQos(currentISPQOS []ISPQOSRecord, txDataInfo []string,
 	} else {
 		ISPQOS.QOS = uint32(qos)
 	}
-	if snap.SystemConfig.ManagerAddress[sscEnumSystem].String() != txSender.String() {
+	if !a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumSystem], txSender, tx, number) {
 		log.Warn("Config isp qos", "manager address", txSender)
 		return currentISPQOS
 	}
@@ -3070,12 +3290,12 @@ func (a *Alien) processISPQos(currentISPQOS []ISPQOSRecord, txDataInfo []string,
 	return currentISPQOS
 }
 
-func (a *Alien) processManagerAddress(currentManagerAddress []ManagerAddressRecord, txDataInfo []string, txSender common.Address, snap *Snapshot) []ManagerAddressRecord {
+func (a *Alien) processManagerAddress(currentManagerAddress []ManagerAddressRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, state *state.StateDB, snap *Snapshot, number uint64) []ManagerAddressRecord {
 	if len(txDataInfo) <= sscPosManagerAddress {
 		log.Warn("Config manager", "parameter number", len(txDataInfo))
 		return currentManagerAddress
 	}
-	if txSender.String() != managerAddressManager.String() {
+	if !a.isManagerSigner(state, managerAddressManager, txSender, tx, number) {
 		log.Warn("Config manager", "manager", txSender)
 		return currentManagerAddress
 	}
@@ -3368,8 +3588,9 @@ func (a *Alien) processCandidateExitNew(currentCandidatePEntrustExit []Candidate
 		log.Warn("Candidate exit New", "miner address", txDataInfo[nfcPosMinerAddress])
 		return currentCandidatePEntrustExit, currentCandidateExit
 	}
+	systemManager := a.isManagerSigner(state, snap.SystemConfig.ManagerAddress[sscEnumSystem], txSender, tx, number)
 	if oldBind, ok := snap.PosPledge[minerAddress]; ok {
-		if oldBind.Manager != txSender && !(snap.isSystemManagerAndInTally(txSender, minerAddress)) {
+		if oldBind.Manager != txSender && !(systemManager && snap.isInTally(minerAddress)) {
 			log.Warn("Candidate exit New", "Manager address is not txSender", txSender)
 			return currentCandidatePEntrustExit, currentCandidateExit
 		}
@@ -3383,7 +3604,7 @@ func (a *Alien) processCandidateExitNew(currentCandidatePEntrustExit []Candidate
 		return currentCandidatePEntrustExit, currentCandidateExit
 	}
 
-	if snap.isInTally(minerAddress) && !snap.isSystemManager(txSender) {
+	if snap.isInTally(minerAddress) && !systemManager {
 		log.Warn("Candidate exit New", "minerAddress is in tally", minerAddress)
 		return currentCandidatePEntrustExit, currentCandidateExit
 	}
@@ -3401,7 +3622,7 @@ func (a *Alien) processCandidateExitNew(currentCandidatePEntrustExit []Candidate
 		}
 		currentCandidatePEntrustExit = append(currentCandidatePEntrustExit, candidateExitPledge)
 	}
-	if snap.isSystemManagerAndInTally(txSender, minerAddress) {
+	if systemManager && snap.isInTally(minerAddress) {
 		currentCandidateExit = append(currentCandidateExit, minerAddress)
 	}
 	return currentCandidatePEntrustExit, currentCandidateExit
@@ -3459,6 +3680,19 @@ func (a *Alien) processCandidateChangeRate(currentCandidateRate []CandidateChang
 		log.Warn("Candidate ChangeRate", "Rate Less than or equal to 0 ", txDataInfo[postion])
 		return currentCandidateRate
 	}
+	if isGECommissionNoticeEffect(snap.config, number) {
+		pledge := snap.PosPledge[minerAddress]
+		if !checkCommissionChange(pledge.DisRate, candidateChangeRate.Rate, posDistributionDefaultRate, pledge.PendingRate) {
+			log.Warn("Candidate ChangeRate", "rate change not allowed", candidateChangeRate.Rate, "current", pledge.DisRate)
+			return currentCandidateRate
+		}
+		for _, item := range currentCandidateRate {
+			if item.Target == minerAddress {
+				log.Warn("Candidate ChangeRate", "miner only one in one block", minerAddress)
+				return currentCandidateRate
+			}
+		}
+	}
 	topics := make([]common.Hash, 3)
 	topics[0].UnmarshalText([]byte("0x4c3b40c94758c0e27ceddbf9149c59c96f3694815f7b7dcb267fd8db56762bcf")) //web3.sha3("PledgeChaRate(address,uint256)")
 	topics[1].SetBytes(minerAddress.Bytes())
@@ -3478,9 +3712,9 @@ func isInCurrentCandidatePEntrustExit(currentCandidatePEntrustExit []CandidatePE
 	}
 	return has
 }
-func (a *Alien) isStorageManager(snap *Snapshot, deviceBind DeviceBindRecord, txSender common.Address, txDataInfo []string) bool {
+func (a *Alien) isStorageManager(snap *Snapshot, deviceBind DeviceBindRecord, txSender common.Address, txDataInfo []string, tx *types.Transaction, state *state.StateDB, number uint64) bool {
 	if _, ok := snap.StorageData.StorageEntrust[deviceBind.Device]; ok {
-		if snap.StorageData.StorageEntrust[deviceBind.Device].Manager != txSender {
+		if !a.isManagerSigner(state, snap.StorageData.StorageEntrust[deviceBind.Device].Manager, txSender, tx, number) {
 			log.Warn("isStorageManager", "txSender is not manager", txSender)
 			return false
 		} else {
@@ -353,9 +353,9 @@ func (s *LockData) updateLockDataNew(snap *Snapshot, item LockRewardRecord, head
 	lockBalance[item.IsReward].Amount = new(big.Int).Add(lockBalance[item.IsReward].Amount, flowRevenusTarget.RewardBalance[item.IsReward])
 	flowRevenusTarget.RewardBalance[item.IsReward] = big.NewInt(0)
 }
-func (s *LockData) payProfit(hash common.Hash, db ethdb.Database, period uint64, headerNumber uint64, currentGrantProfit []consensus.GrantProfitRecord, playGrantProfit []consensus.GrantProfitRecord, header *types.Header, state *state.StateDB, payAddressAll map[common.Address]*big.Int) ([]consensus.GrantProfitRecord, []consensus.GrantProfitRecord, error) {
+func (s *LockData) payProfit(hash common.Hash, db ethdb.Database, period uint64, headerNumber uint64, currentGrantProfit []consensus.GrantProfitRecord, playGrantProfit []consensus.GrantProfitRecord, header *types.Header, state *state.StateDB, payAddressAll map[common.Address]*big.Int, splits *revenueSplits) ([]consensus.GrantProfitRecord, []consensus.GrantProfitRecord, error) {
 	if isGEInitStorageManagerNumber(headerNumber){
-		return s.payProfitV1(hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.payProfitV1(hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	timeNow := time.Now()
 	rlsLockBalance := make(map[common.Address]*RlsLockData)
@@ -379,7 +379,7 @@ func (s *LockData) payProfit(hash common.Hash, db ethdb.Database, period uint64,
 	for address, items := range rlsLockBalance {
 		for blockNumber, item1 := range items.LockBalance {
 			for which, item := range item1 {
-				result, amount := paymentPledge(true, item, state, header, payAddressAll)
+				result, amount := paymentPledge(true, item, state, header, payAddressAll, splits)
 				if 0 == result {
 					playGrantProfit = append(playGrantProfit, consensus.GrantProfitRecord{
 						Which:           which,
@@ -968,54 +968,54 @@ func (s *LockProfitSnap) updateLockData(snap *Snapshot, LockReward []LockRewardR
 	}
 }
 
-func (s *LockProfitSnap) payProfit(db ethdb.Database, period uint64, headerNumber uint64, currentGrantProfit []consensus.GrantProfitRecord, playGrantProfit []consensus.GrantProfitRecord, header *types.Header, state *state.StateDB, payAddressAll map[common.Address]*big.Int) ([]consensus.GrantProfitRecord, []consensus.GrantProfitRecord, error) {
+func (s *LockProfitSnap) payProfit(db ethdb.Database, period uint64, headerNumber uint64, currentGrantProfit []consensus.GrantProfitRecord, playGrantProfit []consensus.GrantProfitRecord, header *types.Header, state *state.StateDB, payAddressAll map[common.Address]*big.Int, splits *revenueSplits) ([]consensus.GrantProfitRecord, []consensus.GrantProfitRecord, error) {
 	number := header.Number.Uint64()
 	if number == 0 {
 		return currentGrantProfit, playGrantProfit, nil
 	}
 	if isPaySignerRewards(number, period) {
 		log.Info("LockProfitSnap pay reward profit")
-		return s.RewardLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.RewardLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPayFlowRewards(number, period) {
 		log.Info("LockProfitSnap pay flow profit")
-		return s.FlowLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.FlowLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPayBandWidthRewards(number, period) {
 		log.Info("LockProfitSnap pay bandwidth profit")
-		return s.BandwidthLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.BandwidthLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPayPosPledgeExit(number, period) {
 		log.Info("LockProfitSnap pay POS pledge exit amount")
-		return s.PosPgExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.PosPgExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPayPosExit(number, period) {
 		log.Info("LockProfitSnap pay POS exit amount")
-		return s.PosExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.PosExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPaySTPEntrustExit(number, period) {
 		log.Info("LockProfitSnap pay STP entrust exit amount")
-		return s.STPEntrustExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.STPEntrustExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPaySTPEntrust(number, period) {
 		log.Info("LockProfitSnap pay STP entrust amount")
-		return s.STPEntrustLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.STPEntrustLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPaySpReWard(number, period) {
 		log.Info("LockProfitSnap pay SP Reward amount")
-		return s.SpLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.SpLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPaySpEntrustReWard(number, period) {
 		log.Info("LockProfitSnap pay SP Entrust Reward amount")
-		return s.SpEntrustLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.SpEntrustLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPaySpExit(number, period) {
 		log.Info("LockProfitSnap pay SP  Exit amount")
-		return s.SpExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.SpExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	if isPaySpEntrustExit(number, period) {
 		log.Info("LockProfitSnap pay SP Entrust Exit amount")
-		return s.SpEntrustExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll)
+		return s.SpEntrustExitLock.payProfit(s.Hash, db, period, headerNumber, currentGrantProfit, playGrantProfit, header, state, payAddressAll, splits)
 	}
 	return currentGrantProfit, playGrantProfit, nil
 }
@@ -2436,7 +2436,7 @@ func (s *LockData) loadRlsLockBalanceV1(db ethdb.Database) (map[common.Address]*
 	return rlsLockBalance,nil
 }
 
-func (s *LockData) payProfitV1(hash common.Hash, db ethdb.Database, period uint64, headerNumber uint64, currentGrantProfit []consensus.GrantProfitRecord, playGrantProfit []consensus.GrantProfitRecord, header *types.Header, state *state.StateDB, payAddressAll map[common.Address]*big.Int) ([]consensus.GrantProfitRecord, []consensus.GrantProfitRecord, error) {
+func (s *LockData) payProfitV1(hash common.Hash, db ethdb.Database, period uint64, headerNumber uint64, currentGrantProfit []consensus.GrantProfitRecord, playGrantProfit []consensus.GrantProfitRecord, header *types.Header, state *state.StateDB, payAddressAll map[common.Address]*big.Int, splits *revenueSplits) ([]consensus.GrantProfitRecord, []consensus.GrantProfitRecord, error) {
 	timeNow := time.Now()
 	rlsLockBalance := make(map[common.Address]*RlsLockDataV1)
 	err := s.saveCacheL1(db, hash)
@@ -2460,7 +2460,7 @@ func (s *LockData) payProfitV1(hash common.Hash, db ethdb.Database, period uint6
 		for blockNumber, item1 := range items.LockBalanceV1 {
 			for which, itemSource := range item1 {
 				for _, item := range itemSource {
-					result, amount := paymentPledge(true, item, state, header, payAddressAll)
+					result, amount := paymentPledge(true, item, state, header, payAddressAll, splits)
 					if 0 == result {
 						playGrantProfit = append(playGrantProfit, consensus.GrantProfitRecord{
 							Which:           which,
@@ -0,0 +1,130 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+	"github.com/UltronGlow/UltronGlow-Origin/rlp"
+)
+
+func TestMultiSignatureManager(t *testing.T) {
+	var (
+		keys   []*ecdsa.PrivateKey
+		owners []common.Address
+	)
+	for i := 0; i < 3; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		owners = append(owners, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	manager := common.HexToAddress("0x5500000000000000000000000000000000000055")
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	code, _ := rlp.EncodeToBytes(consensus.MultiSignatureData{Threshold: 2, MultiSigners: owners})
+	statedb.SetNonce(manager, 1)
+	statedb.SetCode(manager, code)
+
+	miner := common.HexToAddress("0x6600000000000000000000000000000000000066")
+	engine := &Alien{config: upgradedConfig(&params.AlienConfig{Period: 3})}
+	snap := &Snapshot{
+		config:    engine.config,
+		Bandwidth: map[common.Address]*ClaimedBandwidth{miner: {ISPQosID: 1, BandwidthClaimed: 100}},
+		SystemConfig: SystemParameter{
+			ManagerAddress: map[uint32]common.Address{sscEnumWdthPnsh: manager},
+		},
+	}
+	data := "SSC:1:WdthPnsh:" + miner.Hex() + ":10"
+	chainID := big.NewInt(1337)
+	signer := types.LatestSignerForChainID(chainID)
+	sign := func(keys ...*ecdsa.PrivateKey) *types.Transaction {
+		tx := types.NewMultiSignerTransaction(chainID, 0, miner, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+		for _, key := range keys {
+			tx, _ = types.SignTx(tx, signer, key)
+		}
+		return tx
+	}
+	punish := func(tx *types.Transaction, number uint64) []BandwidthPunishRecord {
+		receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(number)}}
+		return engine.processBandwidthPunish(nil, strings.Split(data, ":"), owners[0], tx, receipts, statedb, snap, number)
+	}
+
+	number := upgradeTestNumber + uint64(1)
+	if records := punish(sign(keys[0]), number); len(records) != 0 {
+		t.Errorf("punish below the manager threshold accepted")
+	}
+	if records := punish(sign(keys[0], keys[2]), upgradeTestNumber-1); len(records) != 0 {
+		t.Errorf("multi-signature manager accepted before the fork")
+	}
+	if records := punish(sign(keys[0], keys[2]), number); len(records) != 1 || records[0].WdthPnsh != 0x10 {
+		t.Fatalf("punish signed by the manager owners rejected: %v", records)
+	}
+
+	// A plain manager key keeps working.
+	snap.SystemConfig.ManagerAddress[sscEnumWdthPnsh] = owners[0]
+	if records := punish(sign(keys[0]), number); len(records) != 1 {
+		t.Errorf("punish by the manager key rejected")
+	}
+
+	// The manager of a storage pledge may be the multi-signature address too.
+	snap.SystemConfig.ManagerAddress[sscEnumWdthPnsh] = manager
+	node := common.HexToAddress("0x7700000000000000000000000000000000000077")
+	snap.StorageData = &StorageData{
+		StoragePledge: map[common.Address]*SPledge{node: {Address: node, PledgeStatus: big.NewInt(SPledgeNormal)}},
+		StorageEntrust: map[common.Address]*SEntrust{
+			node: {Manager: manager, EntrustRate: big.NewInt(8000)},
+		},
+	}
+	data = "utg:1:stwtreward:" + node.Hex() + ":8500"
+	setRatio := func(tx *types.Transaction, number uint64) []SPRewardRatioRecord {
+		receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(number)}}
+		return engine.storageSetRewardRatio(nil, strings.Split(data, ":"), owners[0], tx, receipts, statedb, snap, new(big.Int).SetUint64(number))
+	}
+	if records := setRatio(sign(keys[1]), number); len(records) != 0 {
+		t.Errorf("reward ratio below the manager threshold accepted")
+	}
+	if records := setRatio(sign(keys[1], keys[2]), number); len(records) != 1 || records[0].Rate.Int64() != 8500 {
+		t.Errorf("reward ratio signed by the manager owners rejected: %v", records)
+	}
+
+	// So may the system managers configuring the chain without proposals.
+	snap.SystemConfig.ManagerAddress[sscEnumExchRate] = manager
+	snap.SystemConfig.ManagerAddress[sscEnumSystem] = manager
+	data = "SSC:1:ExchRate:12000"
+	if rate := engine.processExchRate(strings.Split(data, ":"), owners[0], sign(keys[0]), statedb, snap, number); rate != 0 {
+		t.Errorf("exchange rate below the manager threshold accepted: %d", rate)
+	}
+	if rate := engine.processExchRate(strings.Split(data, ":"), owners[0], sign(keys[0], keys[1]), statedb, snap, number); rate != 12000 {
+		t.Errorf("exchange rate signed by the manager owners rejected: %d", rate)
+	}
+	data = "SSC:1:OffLine:30"
+	if offline := engine.processOffLine(strings.Split(data, ":"), owners[0], sign(keys[0], keys[1]), statedb, snap, upgradeTestNumber-1); offline != 0 {
+		t.Errorf("multi-signature system manager accepted before the fork: %d", offline)
+	}
+	if offline := engine.processOffLine(strings.Split(data, ":"), owners[0], sign(keys[0], keys[1]), statedb, snap, number); offline != 30 {
+		t.Errorf("offline signed by the manager owners rejected: %d", offline)
+	}
+}
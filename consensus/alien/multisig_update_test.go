@@ -0,0 +1,114 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+	"github.com/UltronGlow/UltronGlow-Origin/rlp"
+)
+
+func TestUpdateMultiSignature(t *testing.T) {
+	var (
+		keys   []*ecdsa.PrivateKey
+		owners []common.Address
+	)
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		owners = append(owners, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	outsider, _ := crypto.GenerateKey()
+	multiSig := common.HexToAddress("0x5500000000000000000000000000000000000055")
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	code, _ := rlp.EncodeToBytes(consensus.MultiSignatureData{Threshold: 2, MultiSigners: owners[:3]})
+	statedb.SetNonce(multiSig, 1)
+	statedb.SetCode(multiSig, code)
+
+	chainID := big.NewInt(1337)
+	signer := types.LatestSignerForChainID(chainID)
+	update := func(data string, keys ...*ecdsa.PrivateKey) consensus.MultiSignatureData {
+		tx := types.NewMultiSignerTransaction(chainID, 0, multiSig, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+		for _, key := range keys {
+			tx, _ = types.SignTx(tx, signer, key)
+		}
+		receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: big.NewInt(1)}}
+		(&Alien{}).processUpdateMultiSignature(strings.Split(data, ":"), crypto.PubkeyToAddress(keys[0].PublicKey), tx, receipts, statedb)
+		parameter, err := consensus.ReadMultiSignatureData(statedb, multiSig)
+		if err != nil {
+			t.Fatal(err)
+		}
+		return *parameter
+	}
+	prefix := "UTG:1:"
+	target := multiSig.Hex()
+
+	// Owners are only changed by enough owners of the account
+	if p := update(prefix+"MultiAdd:"+target+":"+owners[3].Hex(), keys[0]); len(p.MultiSigners) != 3 {
+		t.Errorf("owner added below the threshold: %v", p.MultiSigners)
+	}
+	if p := update(prefix+"MultiAdd:"+target+":"+owners[3].Hex(), outsider, keys[3]); len(p.MultiSigners) != 3 {
+		t.Errorf("owner added by non-owners: %v", p.MultiSigners)
+	}
+	p := update(prefix+"MultiAdd:"+target+":"+owners[3].Hex()+":3", keys[0], keys[1])
+	if len(p.MultiSigners) != 4 || p.MultiSigners[3] != owners[3] || p.Threshold != 3 {
+		t.Fatalf("owner not added: %v threshold %d", p.MultiSigners, p.Threshold)
+	}
+	if p := update(prefix+"MultiAdd:"+target+":"+owners[3].Hex(), keys[0], keys[1], keys[2]); len(p.MultiSigners) != 4 {
+		t.Errorf("existing owner added twice: %v", p.MultiSigners)
+	}
+
+	// The threshold stays within its bounds and below the number of owners
+	for _, threshold := range []string{"1", "11", "4", "x"} {
+		if p := update(prefix+"MultiThr:"+target+":"+threshold, keys[0], keys[1], keys[2]); p.Threshold != 3 {
+			t.Errorf("threshold %s accepted: %d", threshold, p.Threshold)
+		}
+	}
+	if p := update(prefix+"MultiThr:"+target+":2", keys[0], keys[1]); p.Threshold != 3 {
+		t.Errorf("threshold changed below the new threshold of 3: %d", p.Threshold)
+	}
+	if p := update(prefix+"MultiThr:"+target+":2", keys[1], keys[2], keys[3]); p.Threshold != 2 {
+		t.Fatalf("threshold not changed: %d", p.Threshold)
+	}
+
+	// Owners are removed while more owners than the threshold remain
+	if p := update(prefix+"MultiDel:"+target+":"+outsider.PublicKey.X.String(), keys[0], keys[1]); len(p.MultiSigners) != 4 {
+		t.Errorf("malformed owner removed: %v", p.MultiSigners)
+	}
+	p = update(prefix+"MultiDel:"+target+":"+owners[0].Hex(), keys[1], keys[2])
+	if len(p.MultiSigners) != 3 || p.MultiSigners[0] != owners[1] {
+		t.Fatalf("owner not removed: %v", p.MultiSigners)
+	}
+	if p := update(prefix+"MultiDel:"+target+":"+owners[0].Hex(), keys[1], keys[2]); len(p.MultiSigners) != 3 {
+		t.Errorf("unknown owner removed: %v", p.MultiSigners)
+	}
+	if p := update(prefix+"MultiDel:"+target+":"+owners[1].Hex(), keys[0], keys[1]); len(p.MultiSigners) != 3 {
+		t.Errorf("owner removed with the signature of a former owner: %v", p.MultiSigners)
+	}
+	if p := update(prefix+"MultiDel:"+target+":"+owners[1].Hex(), keys[2], keys[3]); len(p.MultiSigners) != 3 {
+		t.Errorf("owner removed down to the threshold: %v", p.MultiSigners)
+	}
+}
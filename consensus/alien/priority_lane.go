@@ -0,0 +1,93 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"strings"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+)
+
+// IsPriorityTx reports whether the transaction belongs in the priority lane of
+// the txpool. The lane takes the custom transactions with a deadline, sent by
+// the accounts registered for them: the storage proofs of storage pledges, the
+// block confirmations of candidates and the flow reports of side chain
+// coinbases and of the flow report manager.
+func (a *Alien) IsPriorityTx(chain consensus.ChainHeaderReader, from common.Address, tx *types.Transaction) bool {
+	txDataInfo := strings.SplitN(string(tx.Data()), ":", posEventConfirm+2)
+	if !isPriorityCategory(txDataInfo) {
+		return false
+	}
+	header := chain.CurrentHeader()
+	if header == nil {
+		return false
+	}
+	snap, err := a.snapshot(chain, header.Number.Uint64(), header.Hash(), nil, nil, defaultLoopCntRecalculateSigners)
+	if err != nil {
+		return false
+	}
+	return snap.isPrioritySender(txDataInfo, from)
+}
+
+// isPriorityCategory reports whether the split data of a transaction is one of
+// the custom transactions of the priority lane.
+func isPriorityCategory(txDataInfo []string) bool {
+	if len(txDataInfo) < ufoMinSplitLen || txDataInfo[posVersion] != ufoVersion {
+		return false
+	}
+	switch txDataInfo[posPrefix] {
+	case ufoPrefix:
+		if len(txDataInfo) <= ufoMinSplitLen {
+			return false
+		}
+		switch txDataInfo[posCategory] {
+		case ufoCategoryEvent:
+			return txDataInfo[posEventConfirm] == ufoEventConfirm
+		case ufoCategorySC:
+			return txDataInfo[posEventFlowReport] == ufoEventFlowReport1 || txDataInfo[posEventFlowReport] == ufoEventFlowReport2
+		}
+	case utgPrefix:
+		return txDataInfo[posCategory] == utgStorageProof || txDataInfo[posCategory] == utgRentChallengeProof
+	}
+	return false
+}
+
+// isPrioritySender reports whether the sender is registered for the custom
+// transaction of the priority lane.
+func (s *Snapshot) isPrioritySender(txDataInfo []string, from common.Address) bool {
+	switch txDataInfo[posCategory] {
+	case ufoCategoryEvent:
+		return s.isCandidate(from)
+	case ufoCategorySC:
+		if txDataInfo[posEventFlowReport] == ufoEventFlowReport2 {
+			return from == s.SystemConfig.ManagerAddress[sscEnumFlowReport]
+		}
+		for _, coinbases := range s.SCCoinbase {
+			if _, ok := coinbases[from]; ok {
+				return true
+			}
+		}
+	case utgStorageProof, utgRentChallengeProof:
+		if s.StorageData != nil {
+			_, ok := s.StorageData.StoragePledge[from]
+			return ok
+		}
+	}
+	return false
+}
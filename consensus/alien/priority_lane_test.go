@@ -0,0 +1,68 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+)
+
+func TestPriorityLane(t *testing.T) {
+	var (
+		candidate = common.HexToAddress("0xa100000000000000000000000000000000000001")
+		pledge    = common.HexToAddress("0xb200000000000000000000000000000000000002")
+		coinbase  = common.HexToAddress("0xc300000000000000000000000000000000000003")
+		manager   = common.HexToAddress("0xd400000000000000000000000000000000000004")
+		stranger  = common.HexToAddress("0xe500000000000000000000000000000000000005")
+	)
+	snap := &Snapshot{
+		Candidates:  map[common.Address]uint64{candidate: candidateStateNormal},
+		SCCoinbase:  map[common.Hash]map[common.Address]common.Address{common.HexToHash("0x5c"): {coinbase: candidate}},
+		StorageData: &StorageData{StoragePledge: map[common.Address]*SPledge{pledge: {Address: pledge}}},
+	}
+	snap.SystemConfig.ManagerAddress = map[uint32]common.Address{sscEnumFlowReport: manager}
+
+	tests := []struct {
+		data     string
+		from     common.Address
+		priority bool
+	}{
+		{"ufo:1:event:confirm:123", candidate, true},
+		{"ufo:1:event:confirm:123", stranger, false},
+		{"ufo:1:event:vote", candidate, false},
+		{"ufo:1:sc:flwrpt:00", coinbase, true},
+		{"ufo:1:sc:flwrpt:00", candidate, false},
+		{"ufo:1:sc:flwrptm:00", manager, true},
+		{"ufo:1:sc:flwrptm:00", coinbase, false},
+		{"ufo:1:sc:confirm:00", coinbase, false},
+		{"UTG:1:stProof:" + pledge.Hex() + ":0:0:v1", pledge, true},
+		{"UTG:1:stChallengeProof:00", pledge, true},
+		{"UTG:1:stProof:" + stranger.Hex() + ":0:0:v1", stranger, false},
+		{"UTG:1:Bind:00", pledge, false},
+		{"UTG:2:stProof", pledge, false},
+		{"ufo:1:event", candidate, false},
+		{"", candidate, false},
+	}
+	for _, test := range tests {
+		txDataInfo := strings.SplitN(test.data, ":", posEventConfirm+2)
+		if priority := isPriorityCategory(txDataInfo) && snap.isPrioritySender(txDataInfo, test.from); priority != test.priority {
+			t.Errorf("%q from %x: priority %v, want %v", test.data, test.from, priority, test.priority)
+		}
+	}
+}
@@ -0,0 +1,439 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+const (
+	redelegateCooldownDay = params.RedelegateCooldownDay // days before a moved position can be moved again
+)
+
+// RedelegateRecord moves one delegated position between PoS candidates,
+// storage nodes and pools. Candidates and storage nodes are addressed by
+// Source/Target, pools by SourceHash/TargetHash.
+type RedelegateRecord struct {
+	Address    common.Address `json:"address"`
+	Hash       common.Hash    `json:"hash"`
+	SourceType string         `json:"sourcetype"`
+	Source     common.Address `json:"source"`
+	SourceHash common.Hash    `json:"sourcehash"`
+	TargetType string         `json:"targettype"`
+	Target     common.Address `json:"target"`
+	TargetHash common.Hash    `json:"targethash"`
+	Amount     *big.Int       `json:"amount"`
+	Height     *big.Int       `json:"height"`
+}
+
+// processRedelegate handles
+// utg:1:Redelegate:<source type>:<source>:<position>:<target type>:<target>.
+// The types are PoS, SN or SP; candidates and storage nodes are given by
+// address and pools by hash. The whole position moves without unbonding once
+// it has been held for the minimum period of its source, and keeps its
+// original height, so the lock schedule of the target counts from the
+// original delegation.
+func (a *Alien) processRedelegate(currentRedelegate []RedelegateRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, snap *Snapshot, number uint64) []RedelegateRecord {
+	if len(txDataInfo) < 8 {
+		log.Warn("Redelegate", "parameter number", len(txDataInfo))
+		return currentRedelegate
+	}
+	record := RedelegateRecord{
+		Address: txSender,
+	}
+	postion := 3
+	record.SourceType = txDataInfo[postion]
+	postion++
+	if !parseRedelegateParty(record.SourceType, txDataInfo[postion], &record.Source, &record.SourceHash) {
+		log.Warn("Redelegate", "source", txDataInfo[postion])
+		return currentRedelegate
+	}
+	postion++
+	if err := record.Hash.UnmarshalText1([]byte(txDataInfo[postion])); err != nil {
+		log.Warn("Redelegate", "position hash", txDataInfo[postion])
+		return currentRedelegate
+	}
+	postion++
+	record.TargetType = txDataInfo[postion]
+	postion++
+	if !parseRedelegateParty(record.TargetType, txDataInfo[postion], &record.Target, &record.TargetHash) {
+		log.Warn("Redelegate", "target", txDataInfo[postion])
+		return currentRedelegate
+	}
+	if record.SourceType == record.TargetType && record.Source == record.Target && record.SourceHash == record.TargetHash {
+		log.Warn("Redelegate", "source and target are the same", record.Hash)
+		return currentRedelegate
+	}
+	for _, item := range currentRedelegate {
+		if item.Address == txSender || item.Hash == record.Hash {
+			log.Warn("Redelegate", "address only one in one block", txSender)
+			return currentRedelegate
+		}
+	}
+	if last, ok := snap.Redelegation[record.Hash]; ok && number < last+redelegateCooldownDay*snap.getBlockPreDay() {
+		log.Warn("Redelegate", "position is cooling down", record.Hash)
+		return currentRedelegate
+	}
+	amount, height, ok := snap.checkRedelegateSource(record)
+	if !ok {
+		log.Warn("Redelegate", "checkRedelegateSource fail", record.Hash)
+		return currentRedelegate
+	}
+	if new(big.Int).Sub(new(big.Int).SetUint64(number), height).Cmp(a.getRedelegatePledgeMinBLock(snap, record, number)) < 0 {
+		log.Warn("Redelegate", "position held for less than the minimum period", height)
+		return currentRedelegate
+	}
+	record.Amount = amount
+	record.Height = height
+	if !snap.checkRedelegateTarget(record) {
+		log.Warn("Redelegate", "checkRedelegateTarget fail", record.Hash)
+		return currentRedelegate
+	}
+	topics := make([]common.Hash, 3)
+	topics[0].UnmarshalText([]byte("0xb9aed94935609a5a843094034ab2716e880e0173793c40e2756c58d4c939782f")) //web3.sha3("Redelegate(address)")
+	topics[1].SetBytes(record.Hash.Bytes())
+	if record.TargetType == TargetTypeSp {
+		topics[2].SetBytes(record.TargetHash.Bytes())
+	} else {
+		topics[2].SetBytes(record.Target.Bytes())
+	}
+	a.addCustomerTxLog(tx, receipts, topics, record.Amount.Bytes())
+	return append(currentRedelegate, record)
+}
+
+func parseRedelegateParty(partyType string, data string, addr *common.Address, hash *common.Hash) bool {
+	switch partyType {
+	case TargetTypePos, TargetTypeSn:
+		return addr.UnmarshalText1([]byte(data)) == nil
+	case TargetTypeSp:
+		return hash.UnmarshalText1([]byte(data)) == nil
+	}
+	return false
+}
+
+// checkRedelegateSource returns the amount and height of the position being
+// moved. Managers can not move their own stake.
+func (snap *Snapshot) checkRedelegateSource(record RedelegateRecord) (*big.Int, *big.Int, bool) {
+	switch record.SourceType {
+	case TargetTypePos:
+		pos, ok := snap.PosPledge[record.Source]
+		if !ok {
+			log.Info("checkRedelegateSource", "PoS node not exist", record.Source)
+			return nil, nil, false
+		}
+		if pos.Manager == record.Address {
+			log.Info("checkRedelegateSource", "manager address no role", record.Address)
+			return nil, nil, false
+		}
+		if detail, ok := pos.Detail[record.Hash]; ok && detail.Address == record.Address {
+			return new(big.Int).Set(detail.Amount), new(big.Int).SetUint64(detail.Height), true
+		}
+	case TargetTypeSn:
+		se, ok := snap.StorageData.StorageEntrust[record.Source]
+		if !ok {
+			log.Info("checkRedelegateSource", "SN not exist", record.Source)
+			return nil, nil, false
+		}
+		if se.Manager == record.Address {
+			log.Info("checkRedelegateSource", "manager address no role", record.Address)
+			return nil, nil, false
+		}
+		stp, ok := snap.StorageData.StoragePledge[record.Source]
+		if !ok || (stp.PledgeStatus.Cmp(big.NewInt(SPledgeNormal)) != 0 && stp.PledgeStatus.Cmp(big.NewInt(SPledgeInactive)) != 0) {
+			log.Info("checkRedelegateSource", "SN is exiting or exited", record.Source)
+			return nil, nil, false
+		}
+		if detail, ok := se.Detail[record.Hash]; ok && detail.Address == record.Address {
+			return new(big.Int).Set(detail.Amount), new(big.Int).Set(detail.Height), true
+		}
+	case TargetTypeSp:
+		sp, ok := snap.SpData.PoolPledge[record.SourceHash]
+		if !ok {
+			log.Info("checkRedelegateSource", "SP not exist", record.SourceHash)
+			return nil, nil, false
+		}
+		if sp.Manager == record.Address {
+			log.Info("checkRedelegateSource", "manager address no role", record.Address)
+			return nil, nil, false
+		}
+		if sp.Status != spStatusActive {
+			log.Info("checkRedelegateSource", "SP Status is need active", record.SourceHash)
+			return nil, nil, false
+		}
+		if detail, ok := sp.EtDetail[record.Hash]; ok && detail.Address == record.Address {
+			return new(big.Int).Set(detail.Amount), new(big.Int).Set(detail.Height), true
+		}
+	}
+	log.Info("checkRedelegateSource", "position not exist", record.Hash)
+	return nil, nil, false
+}
+
+// getRedelegatePledgeMinBLock returns the blocks a position is held at its
+// source before it can be moved, the same as before it can be transferred or
+// withdrawn there.
+func (a *Alien) getRedelegatePledgeMinBLock(snap *Snapshot, record RedelegateRecord, number uint64) *big.Int {
+	switch record.SourceType {
+	case TargetTypePos:
+		posEntrustMinDay := snap.SystemConfig.Deposit[sscEnumPosBeyondCommitPeriod]
+		if snap.isInPosCommitPeriod(record.Source, number) {
+			posEntrustMinDay = snap.SystemConfig.Deposit[sscEnumPosWithinCommitPeriod]
+		}
+		return a.getEntrustPledgeMinBLock(posEntrustMinDay)
+	case TargetTypeSn:
+		return a.getEntrustPledgeMinBLock(stpEntrustMinDay)
+	}
+	return a.getEntrustPledgeMinBLock(spEntrustMinDay)
+}
+
+// checkRedelegateTarget applies the rules of a new delegation to the target:
+// one pool and one storage node per address, active pools only, and storage
+// nodes that are still collecting whole-unit deposits up to their space
+// deposit.
+func (snap *Snapshot) checkRedelegateTarget(record RedelegateRecord) bool {
+	switch record.TargetType {
+	case TargetTypePos:
+		pos, ok := snap.PosPledge[record.Target]
+		if !ok {
+			log.Info("checkRedelegateTarget", "PoS node not exist", record.Target)
+			return false
+		}
+		if pos.Manager == record.Address {
+			log.Info("checkRedelegateTarget", "manager address no role", record.Address)
+			return false
+		}
+		if _, ok := pos.Detail[record.Hash]; ok {
+			log.Info("checkRedelegateTarget", "position exist", record.Hash)
+			return false
+		}
+		return true
+	case TargetTypeSn:
+		if _, ok := snap.StorageData.StoragePledge[record.Address]; ok {
+			log.Info("checkRedelegateTarget", "txSender is Storage address", record.Address)
+			return false
+		}
+		se, ok := snap.StorageData.StorageEntrust[record.Target]
+		if !ok {
+			log.Info("checkRedelegateTarget", "SN not exist", record.Target)
+			return false
+		}
+		stp, ok := snap.StorageData.StoragePledge[record.Target]
+		if !ok || stp.PledgeStatus.Cmp(big.NewInt(SPledgeInactive)) != 0 {
+			log.Info("checkRedelegateTarget", "SN is not inactive", record.Target)
+			return false
+		}
+		if se.Manager == record.Address {
+			log.Info("checkRedelegateTarget", "manager address no role", record.Address)
+			return false
+		}
+		if _, ok := se.Detail[record.Hash]; ok {
+			log.Info("checkRedelegateTarget", "position exist", record.Hash)
+			return false
+		}
+		for miner, item := range snap.StorageData.StorageEntrust {
+			if miner == record.Target {
+				continue
+			}
+			for hash, detail := range item.Detail {
+				if detail.Address == record.Address && hash != record.Hash {
+					log.Info("checkRedelegateTarget", "one address can only pledge one miner", miner)
+					return false
+				}
+			}
+		}
+		if new(big.Int).Mod(record.Amount, utgOneValue).Cmp(common.Big0) != 0 {
+			log.Info("checkRedelegateTarget", "SN deposit must be an integer", record.Amount)
+			return false
+		}
+		if new(big.Int).Add(se.PledgeAmount, record.Amount).Cmp(stp.SpaceDeposit) > 0 {
+			log.Info("checkRedelegateTarget", "SN entrusted pledge is full", record.Target)
+			return false
+		}
+		return true
+	case TargetTypeSp:
+		sp, ok := snap.SpData.PoolPledge[record.TargetHash]
+		if !ok {
+			log.Info("checkRedelegateTarget", "SP not exist", record.TargetHash)
+			return false
+		}
+		if sp.Status != spStatusActive {
+			log.Info("checkRedelegateTarget", "SP Status is need active", record.TargetHash)
+			return false
+		}
+		if sp.Manager == record.Address {
+			log.Info("checkRedelegateTarget", "manager address no role", record.Address)
+			return false
+		}
+		if _, ok := sp.EtDetail[record.Hash]; ok {
+			log.Info("checkRedelegateTarget", "position exist", record.Hash)
+			return false
+		}
+		for pool, item := range snap.SpData.PoolPledge {
+			if pool == record.TargetHash {
+				continue
+			}
+			for hash, detail := range item.EtDetail {
+				if detail.Address == record.Address && hash != record.Hash {
+					log.Info("checkRedelegateTarget", "one address can only pledge one pool", pool)
+					return false
+				}
+			}
+		}
+		return true
+	}
+	log.Info("checkRedelegateTarget", "TargetType is illegal", record.TargetType)
+	return false
+}
+
+// updateRedelegate moves the recorded positions. A position that changed
+// earlier in the same block, by an exit or a transfer, is left alone.
+func (s *Snapshot) updateRedelegate(records []RedelegateRecord, number *big.Int) {
+	cooldown := redelegateCooldownDay * s.getBlockPreDay()
+	for hash, last := range s.Redelegation {
+		if number.Uint64() >= last+cooldown {
+			delete(s.Redelegation, hash)
+		}
+	}
+	if len(records) == 0 {
+		return
+	}
+	spCount := 0
+	for _, record := range records {
+		if !s.removeRedelegateSource(record) {
+			continue
+		}
+		if record.SourceType == TargetTypeSp {
+			spCount++
+		}
+		switch record.TargetType {
+		case TargetTypePos:
+			pos := s.PosPledge[record.Target]
+			pos.Detail[record.Hash] = &PledgeDetail{
+				Address: record.Address,
+				Height:  record.Height.Uint64(),
+				Amount:  new(big.Int).Set(record.Amount),
+			}
+			pos.TotalAmount = new(big.Int).Add(pos.TotalAmount, record.Amount)
+		case TargetTypeSn:
+			se := s.StorageData.StorageEntrust[record.Target]
+			se.Detail[record.Hash] = &SEntrustDetail{
+				Address: record.Address,
+				Height:  new(big.Int).Set(record.Height),
+				Amount:  new(big.Int).Set(record.Amount),
+			}
+			se.PledgeAmount = new(big.Int).Add(se.PledgeAmount, record.Amount)
+			stp := s.StorageData.StoragePledge[record.Target]
+			if se.PledgeAmount.Cmp(stp.SpaceDeposit) >= 0 && stp.PledgeStatus.Cmp(big.NewInt(SPledgeInactive)) == 0 {
+				stp.PledgeStatus = big.NewInt(SPledgeNormal)
+				s.StorageData.accumulatePledgeHash(record.Target)
+			}
+		case TargetTypeSp:
+			sp := s.SpData.PoolPledge[record.TargetHash]
+			sp.EtDetail[record.Hash] = &EntrustDetail{
+				Address: record.Address,
+				Height:  new(big.Int).Set(record.Height),
+				Amount:  new(big.Int).Set(record.Amount),
+			}
+			sp.TotalAmount = new(big.Int).Add(sp.TotalAmount, record.Amount)
+			if capacity := getCapacity(sp.TotalAmount); capacity.Cmp(sp.TotalCapacity) > 0 {
+				sp.TotalCapacity = capacity
+			}
+			s.SpData.accumulateEntrustDetailHash(record.TargetHash, record.Hash, false)
+			s.SpData.accumulateSpPledgelHash(record.TargetHash, false)
+			spCount++
+		}
+		if s.Redelegation == nil {
+			s.Redelegation = make(map[common.Hash]uint64)
+		}
+		s.Redelegation[record.Hash] = number.Uint64()
+	}
+	if spCount > 0 {
+		s.SpData.accumulateSpDataHash()
+	}
+}
+
+// removeRedelegateSource takes the position off its source if both ends are
+// still as recorded.
+func (s *Snapshot) removeRedelegateSource(record RedelegateRecord) bool {
+	switch record.TargetType {
+	case TargetTypePos:
+		if pos, ok := s.PosPledge[record.Target]; !ok || pos.Detail[record.Hash] != nil {
+			return false
+		}
+	case TargetTypeSn:
+		se, ok := s.StorageData.StorageEntrust[record.Target]
+		stp, ok1 := s.StorageData.StoragePledge[record.Target]
+		if !ok || !ok1 || se.Detail[record.Hash] != nil || new(big.Int).Add(se.PledgeAmount, record.Amount).Cmp(stp.SpaceDeposit) > 0 {
+			return false
+		}
+	case TargetTypeSp:
+		if sp, ok := s.SpData.PoolPledge[record.TargetHash]; !ok || sp.EtDetail[record.Hash] != nil {
+			return false
+		}
+	default:
+		return false
+	}
+	switch record.SourceType {
+	case TargetTypePos:
+		pos, ok := s.PosPledge[record.Source]
+		if !ok {
+			return false
+		}
+		detail, ok := pos.Detail[record.Hash]
+		if !ok || detail.Address != record.Address || detail.Amount.Cmp(record.Amount) != 0 {
+			return false
+		}
+		delete(pos.Detail, record.Hash)
+		pos.TotalAmount = new(big.Int).Sub(pos.TotalAmount, record.Amount)
+	case TargetTypeSn:
+		se, ok := s.StorageData.StorageEntrust[record.Source]
+		if !ok {
+			return false
+		}
+		detail, ok := se.Detail[record.Hash]
+		if !ok || detail.Address != record.Address || detail.Amount.Cmp(record.Amount) != 0 {
+			return false
+		}
+		delete(se.Detail, record.Hash)
+		se.PledgeAmount = new(big.Int).Sub(se.PledgeAmount, record.Amount)
+		if stp, ok := s.StorageData.StoragePledge[record.Source]; ok {
+			if se.PledgeAmount.Cmp(stp.SpaceDeposit) < 0 && stp.PledgeStatus.Cmp(big.NewInt(SPledgeNormal)) == 0 {
+				stp.PledgeStatus = big.NewInt(SPledgeInactive)
+				s.StorageData.accumulatePledgeHash(record.Source)
+			}
+		}
+	case TargetTypeSp:
+		sp, ok := s.SpData.PoolPledge[record.SourceHash]
+		if !ok {
+			return false
+		}
+		detail, ok := sp.EtDetail[record.Hash]
+		if !ok || detail.Address != record.Address || detail.Amount.Cmp(record.Amount) != 0 {
+			return false
+		}
+		delete(sp.EtDetail, record.Hash)
+		sp.TotalAmount = new(big.Int).Sub(sp.TotalAmount, record.Amount)
+		s.SpData.accumulateSpPledgelHash(record.SourceHash, false)
+	default:
+		return false
+	}
+	return true
+}
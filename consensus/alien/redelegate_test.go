@@ -0,0 +1,249 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+var (
+	redelegateManager   = common.HexToAddress("0x5000000000000000000000000000000000000005")
+	redelegateDelegator = common.HexToAddress("0x6000000000000000000000000000000000000006")
+	redelegateCandidate = common.HexToAddress("0x7000000000000000000000000000000000000007")
+	redelegateNode      = common.HexToAddress("0x8000000000000000000000000000000000000008")
+	redelegatePool      = common.HexToHash("0x0b")
+	redelegatePosition  = common.HexToHash("0x0c")
+)
+
+// newRedelegateSnapshot holds one delegation of two units with a PoS
+// candidate, an empty pool and an inactive storage node still short of its
+// space deposit.
+func newRedelegateSnapshot() *Snapshot {
+	node := &SPledge{
+		Address: redelegateNode,
+		StorageSpaces: &SPledgeSpaces{
+			Address:                     redelegateNode,
+			StorageCapacity:             big.NewInt(4096),
+			StorageFile:                 make(map[common.Hash]*StorageFile),
+			LastVerificationTime:        big.NewInt(0),
+			LastVerificationSuccessTime: big.NewInt(0),
+			ValidationFailureTotalTime:  big.NewInt(0),
+		},
+		Number:                      big.NewInt(0),
+		TotalCapacity:               big.NewInt(4096),
+		Bandwidth:                   big.NewInt(100),
+		Price:                       big.NewInt(1),
+		StorageSize:                 big.NewInt(0),
+		SpaceDeposit:                new(big.Int).Mul(big.NewInt(3), utgOneValue),
+		Lease:                       make(map[common.Hash]*Lease),
+		LastVerificationTime:        big.NewInt(0),
+		LastVerificationSuccessTime: big.NewInt(0),
+		ValidationFailureTotalTime:  big.NewInt(0),
+		PledgeStatus:                big.NewInt(SPledgeInactive),
+	}
+	snap := &Snapshot{
+		config: upgradedConfig(&params.AlienConfig{Period: 10}),
+		SystemConfig: SystemParameter{
+			Deposit: map[uint32]*big.Int{
+				sscEnumPosCommitPeriod:       big.NewInt(30),
+				sscEnumPosBeyondCommitPeriod: big.NewInt(7),
+				sscEnumPosWithinCommitPeriod: big.NewInt(30),
+			},
+		},
+		PosPledge: map[common.Address]*PosPledgeItem{
+			redelegateCandidate: {
+				Manager:     redelegateManager,
+				TotalAmount: new(big.Int).Mul(big.NewInt(5), utgOneValue),
+				Detail: map[common.Hash]*PledgeDetail{
+					redelegatePosition: {Address: redelegateDelegator, Height: 100, Amount: new(big.Int).Mul(big.NewInt(2), utgOneValue)},
+				},
+				DisRate: big.NewInt(0),
+			},
+		},
+		StorageData: &StorageData{
+			StoragePledge: map[common.Address]*SPledge{redelegateNode: node},
+			StorageEntrust: map[common.Address]*SEntrust{
+				redelegateNode: {
+					Manager:      redelegateManager,
+					PledgeAmount: new(big.Int).Set(utgOneValue),
+					Detail:       make(map[common.Hash]*SEntrustDetail),
+				},
+			},
+		},
+		SpData: NewSPSnap(),
+	}
+	snap.SpData.PoolPledge[redelegatePool] = &PoolPledge{
+		Manager:       redelegateManager,
+		Number:        big.NewInt(0),
+		TotalAmount:   big.NewInt(0),
+		TotalCapacity: big.NewInt(0),
+		UsedCapacity:  big.NewInt(0),
+		SnRatio:       big.NewInt(1),
+		ManagerAmount: big.NewInt(0),
+		Status:        spStatusActive,
+		EtDetail:      make(map[common.Hash]*EntrustDetail),
+	}
+	return snap
+}
+
+func redelegateTx(sourceType, source, targetType, target string) string {
+	return "utg:1:Redelegate:" + sourceType + ":" + source + ":" + redelegatePosition.Hex() + ":" + targetType + ":" + target
+}
+
+func sendRedelegate(snap *Snapshot, records []RedelegateRecord, sender common.Address, data string, number uint64) []RedelegateRecord {
+	tx := types.NewTransaction(0, sender, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+	receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(number)}}
+	return (&Alien{config: snap.config}).processRedelegate(records, strings.Split(data, ":"), sender, tx, receipts, snap, number)
+}
+
+func TestRedelegateKeepsHeight(t *testing.T) {
+	snap := newRedelegateSnapshot()
+	number := upgradeTestNumber + uint64(10)
+	toPool := redelegateTx(TargetTypePos, redelegateCandidate.Hex(), TargetTypeSp, redelegatePool.Hex())
+
+	if records := sendRedelegate(snap, nil, redelegateManager, toPool, number); len(records) != 0 {
+		t.Fatalf("redelegation by the manager accepted")
+	}
+	records := sendRedelegate(snap, nil, redelegateDelegator, toPool, number)
+	if len(records) != 1 {
+		t.Fatalf("redelegation rejected")
+	}
+	if records = sendRedelegate(snap, records, redelegateDelegator, toPool, number); len(records) != 1 {
+		t.Fatalf("second redelegation in one block accepted")
+	}
+	if err := verifyRedelegate(records, records); err != nil {
+		t.Fatalf("redelegation records differ: %v", err)
+	}
+	snap.updateRedelegate(records, new(big.Int).SetUint64(number))
+
+	candidate := snap.PosPledge[redelegateCandidate]
+	if _, ok := candidate.Detail[redelegatePosition]; ok || candidate.TotalAmount.Cmp(new(big.Int).Mul(big.NewInt(3), utgOneValue)) != 0 {
+		t.Errorf("position left on the candidate: %v", candidate.TotalAmount)
+	}
+	pool := snap.SpData.PoolPledge[redelegatePool]
+	detail, ok := pool.EtDetail[redelegatePosition]
+	if !ok {
+		t.Fatalf("position missing from the pool")
+	}
+	if detail.Height.Uint64() != 100 || detail.Address != redelegateDelegator || detail.Hash == (common.Hash{}) {
+		t.Errorf("position mismatch: %+v", detail)
+	}
+	if pool.TotalAmount.Cmp(detail.Amount) != 0 || pool.Hash == (common.Hash{}) {
+		t.Errorf("pool total mismatch: %v", pool.TotalAmount)
+	}
+
+	// The position can not move again until the cooldown has passed.
+	back := redelegateTx(TargetTypeSp, redelegatePool.Hex(), TargetTypePos, redelegateCandidate.Hex())
+	cooldown := redelegateCooldownDay * snap.getBlockPreDay()
+	if records := sendRedelegate(snap, nil, redelegateDelegator, back, number+cooldown-1); len(records) != 0 {
+		t.Fatalf("redelegation during the cooldown accepted")
+	}
+	snap.updateRedelegate(nil, new(big.Int).SetUint64(number+cooldown))
+	if len(snap.Redelegation) != 0 {
+		t.Errorf("cooldown not cleared: %v", snap.Redelegation)
+	}
+	records = sendRedelegate(snap, nil, redelegateDelegator, back, number+cooldown)
+	if len(records) != 1 {
+		t.Fatalf("redelegation after the cooldown rejected")
+	}
+	snap.updateRedelegate(records, new(big.Int).SetUint64(number+cooldown))
+	if detail := candidate.Detail[redelegatePosition]; detail == nil || detail.Height != 100 {
+		t.Errorf("position not returned with its height: %+v", detail)
+	}
+	if pool.TotalAmount.Sign() != 0 {
+		t.Errorf("pool total not reduced: %v", pool.TotalAmount)
+	}
+}
+
+func TestRedelegateToStorageNode(t *testing.T) {
+	snap := newRedelegateSnapshot()
+	number := upgradeTestNumber + uint64(10)
+	toNode := redelegateTx(TargetTypePos, redelegateCandidate.Hex(), TargetTypeSn, redelegateNode.Hex())
+
+	// A position that does not fit the remaining space deposit is refused.
+	snap.StorageData.StorageEntrust[redelegateNode].PledgeAmount = new(big.Int).Mul(big.NewInt(2), utgOneValue)
+	if records := sendRedelegate(snap, nil, redelegateDelegator, toNode, number); len(records) != 0 {
+		t.Fatalf("redelegation beyond the space deposit accepted")
+	}
+	snap.StorageData.StorageEntrust[redelegateNode].PledgeAmount = new(big.Int).Set(utgOneValue)
+
+	records := sendRedelegate(snap, nil, redelegateDelegator, toNode, number)
+	if len(records) != 1 {
+		t.Fatalf("redelegation rejected")
+	}
+	// Exiting the position in the same block wins over the redelegation.
+	delete(snap.PosPledge[redelegateCandidate].Detail, redelegatePosition)
+	snap.updateRedelegate(records, new(big.Int).SetUint64(number))
+	if len(snap.StorageData.StorageEntrust[redelegateNode].Detail) != 0 || len(snap.Redelegation) != 0 {
+		t.Fatalf("removed position redelegated")
+	}
+
+	snap = newRedelegateSnapshot()
+	records = sendRedelegate(snap, nil, redelegateDelegator, toNode, number)
+	snap.updateRedelegate(records, new(big.Int).SetUint64(number))
+	entrust := snap.StorageData.StorageEntrust[redelegateNode]
+	if detail := entrust.Detail[redelegatePosition]; detail == nil || detail.Height.Uint64() != 100 {
+		t.Fatalf("position not moved with its height: %+v", detail)
+	}
+	if entrust.PledgeAmount.Cmp(new(big.Int).Mul(big.NewInt(3), utgOneValue)) != 0 {
+		t.Errorf("node pledge mismatch: %v", entrust.PledgeAmount)
+	}
+	if snap.StorageData.StoragePledge[redelegateNode].PledgeStatus.Cmp(big.NewInt(SPledgeNormal)) != 0 {
+		t.Errorf("node not activated by a full space deposit")
+	}
+}
+
+func TestRedelegateMinimumPeriod(t *testing.T) {
+	snap := newRedelegateSnapshot()
+	number := upgradeTestNumber + uint64(10)
+	blockPerDay := snap.getBlockPreDay()
+	toNode := redelegateTx(TargetTypePos, redelegateCandidate.Hex(), TargetTypeSn, redelegateNode.Hex())
+	toPool := redelegateTx(TargetTypeSn, redelegateNode.Hex(), TargetTypeSp, redelegatePool.Hex())
+
+	// A PoS position is held as long as before it can be transferred.
+	snap.PosPledge[redelegateCandidate].Detail[redelegatePosition].Height = number - 7*blockPerDay + 1
+	if records := sendRedelegate(snap, nil, redelegateDelegator, toNode, number); len(records) != 0 {
+		t.Fatalf("redelegation of a recent PoS position accepted")
+	}
+	snap.PosPledge[redelegateCandidate].Active = number - blockPerDay
+	if records := sendRedelegate(snap, nil, redelegateDelegator, toNode, number+blockPerDay); len(records) != 0 {
+		t.Fatalf("redelegation within the commit period of the candidate accepted")
+	}
+	snap.PosPledge[redelegateCandidate].Active = 0
+	records := sendRedelegate(snap, nil, redelegateDelegator, toNode, number+1)
+	if len(records) != 1 {
+		t.Fatalf("redelegation after the minimum period rejected")
+	}
+	snap.updateRedelegate(records, new(big.Int).SetUint64(number+1))
+
+	// The storage node counts the minimum period from the original delegation.
+	later := number + 1 + redelegateCooldownDay*blockPerDay
+	snap.updateRedelegate(nil, new(big.Int).SetUint64(later))
+	snap.StorageData.StorageEntrust[redelegateNode].Detail[redelegatePosition].Height = new(big.Int).SetUint64(later - 7*blockPerDay + 1)
+	if records := sendRedelegate(snap, nil, redelegateDelegator, toPool, later); len(records) != 0 {
+		t.Fatalf("redelegation of a recent storage node position accepted")
+	}
+	if records := sendRedelegate(snap, nil, redelegateDelegator, toPool, later+1); len(records) != 1 {
+		t.Fatalf("redelegation of a storage node position after the minimum period rejected")
+	}
+}
@@ -0,0 +1,233 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strconv"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+)
+
+const (
+	maxRevenueBeneficiaries = 8
+	revenueShareBase        = 10000 // shares are in basis points
+
+	// sscEnumRevenueShare marks the grant profit records of the shares paid to
+	// beneficiaries. No lock has this type, so the records never count as a
+	// payment of a lock.
+	sscEnumRevenueShare = 14
+)
+
+// RevenueShare is the part of the revenue of a binding paid to a beneficiary
+// other than the revenue address, in basis points.
+type RevenueShare struct {
+	Address common.Address `json:"address"`
+	Share   uint64         `json:"share"`
+}
+
+// RevenueSplitRecord replaces the beneficiaries of a revenue binding. An
+// empty list pays everything to the revenue address again.
+type RevenueSplitRecord struct {
+	Device        common.Address
+	Type          uint32
+	Beneficiaries []RevenueShare
+}
+
+// processRevenueSplit handles
+// utg:1:BindSplit:<device>:<revenue type>[:<beneficiary>:<basis points>]...
+// sent by the manager of a bound PoS (type 0) or storage (type 1) device.
+// The shares must leave a part to the revenue address, which also receives
+// the rounding remainders.
+func (a *Alien) processRevenueSplit(currentSplit []RevenueSplitRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB, snap *Snapshot, number uint64) []RevenueSplitRecord {
+	if len(txDataInfo) <= nfcPosRevenueType || len(txDataInfo)%2 != 1 {
+		log.Warn("BindSplit", "parameter number", len(txDataInfo))
+		return currentSplit
+	}
+	record := RevenueSplitRecord{}
+	bind := DeviceBindRecord{}
+	if err := bind.Device.UnmarshalText1([]byte(txDataInfo[nfcPosMinerAddress])); err != nil {
+		log.Warn("BindSplit", "miner address", txDataInfo[nfcPosMinerAddress])
+		return currentSplit
+	}
+	record.Device = bind.Device
+	var revenue *RevenueParameter
+	if revenueType, err := strconv.ParseUint(txDataInfo[nfcPosRevenueType], 10, 32); err != nil {
+		log.Warn("BindSplit", "type", txDataInfo[nfcPosRevenueType])
+		return currentSplit
+	} else if revenueType == 0 {
+		if !a.isPosManager(snap, bind, txSender, txDataInfo) {
+			return currentSplit
+		}
+		revenue = snap.RevenueNormal[record.Device]
+	} else if revenueType == 1 {
+		if !a.isStorageManager(snap, bind, txSender, txDataInfo, tx, state, number) {
+			return currentSplit
+		}
+		revenue = snap.RevenueStorage[record.Device]
+		record.Type = 1
+	} else {
+		log.Warn("BindSplit", "type", txDataInfo[nfcPosRevenueType])
+		return currentSplit
+	}
+	if revenue == nil {
+		log.Warn("BindSplit", "device not bond", record.Device)
+		return currentSplit
+	}
+	if revenue.MultiSignature != (common.Address{}) && revenue.MultiSignature != common.BigToAddress(big.NewInt(0)) {
+		log.Warn("BindSplit", "revenue paid to multi-signature address", revenue.MultiSignature)
+		return currentSplit
+	}
+	total := uint64(0)
+	for postion := nfcPosRevenueType + 1; postion < len(txDataInfo); postion += 2 {
+		share := RevenueShare{}
+		if err := share.Address.UnmarshalText1([]byte(txDataInfo[postion])); err != nil {
+			log.Warn("BindSplit", "beneficiary", txDataInfo[postion])
+			return currentSplit
+		}
+		value, err := strconv.ParseUint(txDataInfo[postion+1], 10, 64)
+		if err != nil || value == 0 || value >= revenueShareBase {
+			log.Warn("BindSplit", "share", txDataInfo[postion+1])
+			return currentSplit
+		}
+		share.Share = value
+		if share.Address == revenue.RevenueAddress {
+			log.Warn("BindSplit", "beneficiary is the revenue address", share.Address)
+			return currentSplit
+		}
+		for _, item := range record.Beneficiaries {
+			if item.Address == share.Address {
+				log.Warn("BindSplit", "beneficiary repeated", share.Address)
+				return currentSplit
+			}
+		}
+		total += share.Share
+		record.Beneficiaries = append(record.Beneficiaries, share)
+	}
+	if len(record.Beneficiaries) > maxRevenueBeneficiaries || total >= revenueShareBase {
+		log.Warn("BindSplit", "beneficiaries", len(record.Beneficiaries), "total share", total)
+		return currentSplit
+	}
+	for _, item := range currentSplit {
+		if item.Device == record.Device && item.Type == record.Type {
+			log.Warn("BindSplit", "device only one in one block", record.Device)
+			return currentSplit
+		}
+	}
+	topics := make([]common.Hash, 3)
+	topics[0].UnmarshalText([]byte("0x288108a261366b2ff20bd79fec363496516656db6dd14f5bdd673689df3e0bee")) //web3.sha3("BindSplit(address,uint32)")
+	topics[1].SetBytes(record.Device.Bytes())
+	topics[2].SetBytes(big.NewInt(int64(record.Type)).Bytes())
+	a.addCustomerTxLog(tx, receipts, topics, new(big.Int).SetUint64(total).Bytes())
+	return append(currentSplit, record)
+}
+
+func (snap *Snapshot) updateRevenueSplit(records []RevenueSplitRecord) {
+	for _, item := range records {
+		revenue, ok := snap.RevenueNormal[item.Device]
+		if item.Type != 0 {
+			revenue, ok = snap.RevenueStorage[item.Device]
+		}
+		if !ok {
+			continue
+		}
+		revenue.Beneficiaries = copyRevenueShares(item.Beneficiaries)
+	}
+}
+
+func copyRevenueShares(shares []RevenueShare) []RevenueShare {
+	if len(shares) == 0 {
+		return nil
+	}
+	return append([]RevenueShare{}, shares...)
+}
+
+// revenueSplits looks up the beneficiaries of the bindings a lock is paid
+// through and collects the shares paid. A nil value pays every lock to its
+// revenue address.
+type revenueSplits struct {
+	normal  map[common.Address]*RevenueParameter
+	storage map[common.Address]*RevenueParameter
+	paid    []consensus.GrantProfitRecord
+}
+
+func (snap *Snapshot) revenueSplits(number uint64) *revenueSplits {
+	if !isGERevenueSplitEffect(snap.config, number) {
+		return nil
+	}
+	return &revenueSplits{normal: snap.RevenueNormal, storage: snap.RevenueStorage}
+}
+
+// binding returns the revenue binding the locks of pledgeType are paid
+// through, nil for the locks not paid to a bound revenue address.
+func (r *revenueSplits) binding(pledgeType uint32, target common.Address) *RevenueParameter {
+	switch pledgeType {
+	case sscEnumCndLock, sscEnumSignerReward, sscEnumPosExitLock:
+		return r.normal[target]
+	case sscEnumFlwReward, sscEnumBandwidthReward, sscEnumSTEntrustLockReward:
+		return r.storage[target]
+	}
+	return nil
+}
+
+// shares returns the beneficiaries of the binding that pledge pays to
+// payAddress.
+func (r *revenueSplits) shares(pledge *PledgeItem, payAddress common.Address) []RevenueShare {
+	if r == nil || payAddress != pledge.RevenueAddress {
+		return nil
+	}
+	revenue := r.binding(pledge.PledgeType, pledge.TargetAddress)
+	if revenue == nil || revenue.RevenueAddress != payAddress {
+		return nil
+	}
+	return revenue.Beneficiaries
+}
+
+// pay credits the beneficiaries with their share of amount and returns the
+// part left for payAddress, rounding remainders included.
+func (r *revenueSplits) pay(pledge *PledgeItem, payAddress common.Address, amount *big.Int, state *state.StateDB, payAddressAll map[common.Address]*big.Int, reason string) *big.Int {
+	left := new(big.Int).Set(amount)
+	for _, share := range r.shares(pledge, payAddress) {
+		part := new(big.Int).Mul(amount, new(big.Int).SetUint64(share.Share))
+		part.Div(part, big.NewInt(revenueShareBase))
+		if part.Sign() <= 0 {
+			continue
+		}
+		attributeBalance(state, share.Address, part, balanceReasonRevenueShare, reason+":"+pledge.TargetAddress.Hex())
+		addPayAddressBalance(share.Address, payAddressAll, part)
+		left.Sub(left, part)
+		r.paid = append(r.paid, consensus.GrantProfitRecord{
+			Which:          sscEnumRevenueShare,
+			MinerAddress:   pledge.TargetAddress,
+			Amount:         part,
+			RevenueAddress: share.Address,
+		})
+	}
+	return left
+}
+
+// grantProfit returns the grant profit records of the shares paid.
+func (r *revenueSplits) grantProfit() []consensus.GrantProfitRecord {
+	if r == nil {
+		return nil
+	}
+	return r.paid
+}
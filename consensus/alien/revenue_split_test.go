@@ -0,0 +1,159 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+var (
+	splitDevice   = common.HexToAddress("0x9000000000000000000000000000000000000009")
+	splitPrimary  = common.HexToAddress("0xa00000000000000000000000000000000000000a")
+	splitInvestor = common.HexToAddress("0xb00000000000000000000000000000000000000b")
+	splitHost     = common.HexToAddress("0xc00000000000000000000000000000000000000c")
+)
+
+func sendRevenueSplit(snap *Snapshot, records []RevenueSplitRecord, sender common.Address, data string, number uint64) []RevenueSplitRecord {
+	tx := types.NewTransaction(0, sender, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+	receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(number)}}
+	return (&Alien{}).processRevenueSplit(records, strings.Split(data, ":"), sender, tx, receipts, nil, snap, number)
+}
+
+func TestRevenueSplit(t *testing.T) {
+	snap := &Snapshot{
+		config:         upgradedConfig(&params.AlienConfig{Period: 10}),
+		PosPledge:      map[common.Address]*PosPledgeItem{splitDevice: {Manager: redelegateManager}},
+		RevenueNormal:  map[common.Address]*RevenueParameter{splitDevice: {RevenueAddress: splitPrimary}},
+		RevenueStorage: make(map[common.Address]*RevenueParameter),
+	}
+	number := upgradeTestNumber + uint64(1)
+	prefix := "utg:1:BindSplit:" + splitDevice.Hex() + ":0"
+	split := prefix + ":" + splitInvestor.Hex() + ":2500:" + splitHost.Hex() + ":1234"
+
+	for _, data := range []string{
+		prefix + ":" + splitInvestor.Hex() + ":9000:" + splitHost.Hex() + ":1000",
+		prefix + ":" + splitPrimary.Hex() + ":100",
+		prefix + ":" + splitInvestor.Hex() + ":100:" + splitInvestor.Hex() + ":100",
+		prefix + ":" + splitInvestor.Hex(),
+	} {
+		if records := sendRevenueSplit(snap, nil, redelegateManager, data, number); len(records) != 0 {
+			t.Errorf("invalid split accepted: %s", data)
+		}
+	}
+	if records := sendRevenueSplit(snap, nil, splitPrimary, split, number); len(records) != 0 {
+		t.Fatalf("split by a non manager accepted")
+	}
+	records := sendRevenueSplit(snap, nil, redelegateManager, split, number)
+	if len(records) != 1 || len(records[0].Beneficiaries) != 2 {
+		t.Fatalf("split rejected")
+	}
+	if err := verifyRevenueSplit(records, records); err != nil {
+		t.Fatalf("split records differ: %v", err)
+	}
+	snap.updateRevenueSplit(records)
+	if len(snap.RevenueNormal[splitDevice].Beneficiaries) != 2 {
+		t.Errorf("beneficiaries not stored")
+	}
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	journal := beginBalanceJournal(statedb)
+	header := &types.Header{Number: new(big.Int).SetUint64(number)}
+	pledge := &PledgeItem{
+		Amount:         big.NewInt(10001),
+		PledgeType:     sscEnumSignerReward,
+		Playment:       big.NewInt(0),
+		StartHigh:      1,
+		TargetAddress:  splitDevice,
+		RevenueAddress: splitPrimary,
+	}
+	payAddressAll := make(map[common.Address]*big.Int)
+	splits := snap.revenueSplits(number)
+	if result, amount := paymentPledge(true, pledge, statedb, header, payAddressAll, splits); result != 0 || amount.Int64() != 10001 {
+		t.Fatalf("payment failed: %d %v", result, amount)
+	}
+	if paid := splits.grantProfit(); len(paid) != 2 {
+		t.Errorf("revenue share records: have %d, want 2", len(paid))
+	} else {
+		for _, record := range paid {
+			if record.Which != sscEnumRevenueShare || record.MinerAddress != splitDevice || record.BlockNumber != 0 {
+				t.Errorf("revenue share record mismatch: %+v", record)
+			}
+		}
+		if paid[0].RevenueAddress != splitInvestor || paid[0].Amount.Int64() != 2500 {
+			t.Errorf("investor share record: %+v", paid[0])
+		}
+	}
+	// Shares round down, the remainder stays with the revenue address.
+	want := map[common.Address]int64{splitInvestor: 2500, splitHost: 1234, splitPrimary: 6267}
+	for addr, amount := range want {
+		if payAddressAll[addr] == nil || payAddressAll[addr].Int64() != amount {
+			t.Errorf("payment to %x: have %v, want %d", addr, payAddressAll[addr], amount)
+		}
+	}
+	toPayAddressBalance(header, payAddressAll, statedb)
+	shares := 0
+	for _, change := range journal.changes {
+		if change.Reason == balanceReasonRevenueShare {
+			shares++
+			if change.Source != "signerReward:"+splitDevice.Hex() {
+				t.Errorf("share source mismatch: %s", change.Source)
+			}
+		}
+	}
+	if shares != 2 {
+		t.Errorf("revenue shares in the payout history: have %d, want 2", shares)
+	}
+
+	// The locks paid through the storage binding use its own beneficiaries,
+	// the locks paid to no binding are never split.
+	snap.RevenueStorage[splitDevice] = &RevenueParameter{
+		RevenueAddress: splitPrimary,
+		Beneficiaries:  []RevenueShare{{Address: splitHost, Share: 5000}},
+	}
+	for _, item := range []struct {
+		pledgeType uint32
+		host       int64
+	}{
+		{sscEnumBandwidthReward, 5000},
+		{sscEnumSTEntrustLockReward, 5000},
+		{sscEnumStoragePledgeRedeemLock, 0},
+		{sscEnumSTEntrustExitLock, 0},
+	} {
+		pledge := &PledgeItem{Amount: big.NewInt(10000), PledgeType: item.pledgeType, Playment: big.NewInt(0), StartHigh: 1, TargetAddress: splitDevice, RevenueAddress: splitPrimary}
+		payAddressAll = make(map[common.Address]*big.Int)
+		paymentPledge(true, pledge, statedb, header, payAddressAll, snap.revenueSplits(number))
+		if host := payAddressAll[splitHost]; (host == nil && item.host != 0) || (host != nil && host.Int64() != item.host) || payAddressAll[splitInvestor] != nil {
+			t.Errorf("lock type %d: payments %v", item.pledgeType, payAddressAll)
+		}
+	}
+
+	// Revenue paid through another binding is not split.
+	pledge.RevenueAddress, pledge.Playment = splitHost, big.NewInt(0)
+	payAddressAll = make(map[common.Address]*big.Int)
+	paymentPledge(true, pledge, statedb, header, payAddressAll, snap.revenueSplits(number))
+	if len(payAddressAll) != 1 || payAddressAll[splitHost].Int64() != 10001 {
+		t.Errorf("foreign revenue split: %v", payAddressAll)
+	}
+}
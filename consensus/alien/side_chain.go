@@ -0,0 +1,326 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+)
+
+const (
+	scConfirmedHistoryLength = 64 // confirmed numbers kept for each side chain
+)
+
+var (
+	errUnknownSideChain = errors.New("unknown side chain")
+)
+
+// SCConfirmedNumber records one advance of the confirmed number of a side
+// chain at a loop end of the main chain.
+type SCConfirmedNumber struct {
+	Number          uint64           `json:"number"`          // main chain block of the loop end
+	ConfirmedNumber uint64           `json:"confirmedNumber"` // side chain header number confirmed
+	Confirmers      []common.Address `json:"confirmers"`      // side chain coinbases which sent the confirmations
+}
+
+func (c *SCConfirmedNumber) copy() *SCConfirmedNumber {
+	cpy := *c
+	cpy.Confirmers = make([]common.Address, len(c.Confirmers))
+	copy(cpy.Confirmers, c.Confirmers)
+	return &cpy
+}
+
+// recordConfirmedNumber appends the confirmed number reached at the loop end
+// to the history of the side chain, before the confirmations are dropped.
+// The history is kept in the snapshot only, it is not derived from the
+// headers. It starts at the SideChainHistoryBlock, a node whose snapshot
+// was already past that block when it upgraded only holds the loops it
+// processed since, so the history is partial there.
+func (r *SCRecord) recordConfirmedNumber(confirmedNumber uint64, number uint64) {
+	confirmed := &SCConfirmedNumber{
+		Number:          number,
+		ConfirmedNumber: confirmedNumber,
+		Confirmers:      sideChainConfirmers(r, r.LastConfirmedNumber+1, confirmedNumber),
+	}
+	r.History = append(r.History, confirmed)
+	if len(r.History) > scConfirmedHistoryLength {
+		r.History = r.History[len(r.History)-scConfirmedHistoryLength:]
+	}
+}
+
+// sideChainConfirmers returns the coinbases which sent confirmations for the
+// side chain headers from first to last.
+func sideChainConfirmers(record *SCRecord, first uint64, last uint64) []common.Address {
+	seen := make(map[common.Address]bool)
+	confirmers := make([]common.Address, 0)
+	for number := first; number <= last; number++ {
+		for _, scConfirm := range record.Record[number] {
+			if !seen[scConfirm.Coinbase] {
+				seen[scConfirm.Coinbase] = true
+				confirmers = append(confirmers, scConfirm.Coinbase)
+			}
+		}
+	}
+	sort.Slice(confirmers, func(i, j int) bool {
+		return bytes.Compare(confirmers[i][:], confirmers[j][:]) < 0
+	})
+	return confirmers
+}
+
+// SideChainRent is a rent of a side chain bought by proposal.
+type SideChainRent struct {
+	Hash            common.Hash `json:"hash"` // rent proposal
+	RentPerPeriod   *big.Int    `json:"rentPerPeriod"`
+	MaxRewardNumber uint64      `json:"maxRewardNumber"`
+	Active          bool        `json:"active"`
+	RemainingBlocks uint64      `json:"remainingBlocks"`
+	ExpireTime      uint64      `json:"expireTime"` // estimated from the block period
+}
+
+// SideChainHeight lists the coinbases which confirmed a side chain header not
+// confirmed yet.
+type SideChainHeight struct {
+	Number     uint64           `json:"number"`
+	Confirmers []common.Address `json:"confirmers"`
+}
+
+// SideChainReward is the reward score of the side chain coinbases at a loop
+// end of the main chain.
+type SideChainReward struct {
+	Number uint64                    `json:"number"`
+	Rent   *big.Int                  `json:"rent"` // rent per period still active at number
+	Scores map[common.Address]uint64 `json:"scores"`
+}
+
+// SideChainAccrued is the reward accrued by a main chain signer through its
+// side chain coinbases.
+type SideChainAccrued struct {
+	Score uint64   `json:"score"` // sum of the reward scores, 100 per period
+	Rent  *big.Int `json:"rent"`  // share of the side chain rent
+}
+
+// SideChainCharging is a gas charging waiting for the side chain to confirm
+// its notice.
+type SideChainCharging struct {
+	Hash      common.Hash    `json:"hash"` // rent proposal
+	Target    common.Address `json:"target"`
+	Volume    uint64         `json:"volume"`
+	Confirmed int            `json:"confirmed"` // coinbases which echoed the notice
+	Success   bool           `json:"success"`
+}
+
+// SideChainStatus is a side chain as recorded by the main chain.
+type SideChainStatus struct {
+	Hash                   common.Hash `json:"hash"`
+	CountPerPeriod         uint64      `json:"countPerPeriod"`
+	RewardPerPeriod        uint64      `json:"rewardPerPeriod"` // number per thousand
+	LastConfirmedNumber    uint64      `json:"lastConfirmedNumber"`
+	MaxHeaderNumber        uint64      `json:"maxHeaderNumber"`
+	PendingConfirmedNumber uint64      `json:"pendingConfirmedNumber"` // confirmed number at the next loop end
+	RentActive             bool        `json:"rentActive"`
+	RentExpireNumber       uint64      `json:"rentExpireNumber"`
+	BridgeLocked           *big.Int    `json:"bridgeLocked,omitempty"`
+
+	Rents     []*SideChainRent                     `json:"rents"`
+	History   []*SCConfirmedNumber                 `json:"history"` // partial on nodes upgraded past the SideChainHistoryBlock
+	Pending   []*SideChainHeight                   `json:"pending"`
+	Coinbases map[common.Address][]common.Address  `json:"coinbases"` // side chain coinbases by main chain signer
+	Rewards   []*SideChainReward                   `json:"rewards"`
+	Accrued   map[common.Address]*SideChainAccrued `json:"accrued"` // by main chain signer
+	Charging  []*SideChainCharging                 `json:"charging"`
+}
+
+// SideChains lists the side chains recorded by the main chain.
+type SideChains struct {
+	Number     uint64             `json:"number"`
+	SideChains []*SideChainStatus `json:"sideChains"`
+}
+
+// GetSideChain returns the side chain identified by hash at the current block,
+// with its rents, confirmations, coinbases and accrued rewards.
+func (api *API) GetSideChain(hash common.Hash) (*SideChainStatus, error) {
+	log.Info("api GetSideChain", "hash", hash)
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snapshot, err := api.getSnapshotCache(header)
+	if err != nil {
+		log.Warn("Fail to GetSideChain", "err", err)
+		return nil, errUnknownBlock
+	}
+	if _, ok := snapshot.SCRecordMap[hash]; !ok {
+		return nil, errUnknownSideChain
+	}
+	return snapshot.sideChainStatus(hash, header.Number.Uint64(), header.Time), nil
+}
+
+// ListSideChains returns every side chain at the current block, ordered by hash.
+func (api *API) ListSideChains() (*SideChains, error) {
+	log.Info("api ListSideChains")
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snapshot, err := api.getSnapshotCache(header)
+	if err != nil {
+		log.Warn("Fail to ListSideChains", "err", err)
+		return nil, errUnknownBlock
+	}
+	number := header.Number.Uint64()
+	result := &SideChains{
+		Number:     number,
+		SideChains: make([]*SideChainStatus, 0, len(snapshot.SCRecordMap)),
+	}
+	for hash := range snapshot.SCRecordMap {
+		result.SideChains = append(result.SideChains, snapshot.sideChainStatus(hash, number, header.Time))
+	}
+	sort.Slice(result.SideChains, func(i, j int) bool {
+		return bytes.Compare(result.SideChains[i].Hash[:], result.SideChains[j].Hash[:]) < 0
+	})
+	return result, nil
+}
+
+// sideChainStatus builds the status of a side chain recorded in SCRecordMap,
+// number and time being those of the snapshot header.
+func (s *Snapshot) sideChainStatus(hash common.Hash, number uint64, time uint64) *SideChainStatus {
+	record := s.SCRecordMap[hash]
+	status := &SideChainStatus{
+		Hash:                hash,
+		CountPerPeriod:      record.CountPerPeriod,
+		RewardPerPeriod:     record.RewardPerPeriod,
+		LastConfirmedNumber: record.LastConfirmedNumber,
+		MaxHeaderNumber:     record.MaxHeaderNumber,
+		Rents:               make([]*SideChainRent, 0, len(record.RentReward)),
+		History:             make([]*SCConfirmedNumber, 0, len(record.History)),
+		Pending:             make([]*SideChainHeight, 0, len(record.Record)),
+		Coinbases:           make(map[common.Address][]common.Address),
+		Rewards:             make([]*SideChainReward, 0),
+		Accrued:             make(map[common.Address]*SideChainAccrued),
+		Charging:            make([]*SideChainCharging, 0),
+	}
+	status.PendingConfirmedNumber, _ = s.calculateSCConfirmedNumber(record, s.scMinConfirmedSignerCount())
+
+	for rentHash, rent := range record.RentReward {
+		maxRewardNumber := rent.MaxRewardNumber.Uint64()
+		sideChainRent := &SideChainRent{
+			Hash:            rentHash,
+			RentPerPeriod:   new(big.Int).Set(rent.RentPerPeriod),
+			MaxRewardNumber: maxRewardNumber,
+			Active:          maxRewardNumber >= number,
+		}
+		if sideChainRent.Active {
+			sideChainRent.RemainingBlocks = maxRewardNumber - number
+			sideChainRent.ExpireTime = time + sideChainRent.RemainingBlocks*s.config.Period
+			status.RentActive = true
+			if maxRewardNumber > status.RentExpireNumber {
+				status.RentExpireNumber = maxRewardNumber
+			}
+		}
+		status.Rents = append(status.Rents, sideChainRent)
+	}
+	sort.Slice(status.Rents, func(i, j int) bool {
+		if status.Rents[i].MaxRewardNumber != status.Rents[j].MaxRewardNumber {
+			return status.Rents[i].MaxRewardNumber < status.Rents[j].MaxRewardNumber
+		}
+		return bytes.Compare(status.Rents[i].Hash[:], status.Rents[j].Hash[:]) < 0
+	})
+
+	for _, confirmed := range record.History {
+		status.History = append(status.History, confirmed.copy())
+	}
+	for headerNumber := range record.Record {
+		status.Pending = append(status.Pending, &SideChainHeight{
+			Number:     headerNumber,
+			Confirmers: sideChainConfirmers(record, headerNumber, headerNumber),
+		})
+	}
+	sort.Slice(status.Pending, func(i, j int) bool {
+		return status.Pending[i].Number < status.Pending[j].Number
+	})
+
+	for coinbase, signer := range s.SCCoinbase[hash] {
+		status.Coinbases[signer] = append(status.Coinbases[signer], coinbase)
+	}
+	for _, coinbases := range status.Coinbases {
+		sort.Slice(coinbases, func(i, j int) bool {
+			return bytes.Compare(coinbases[i][:], coinbases[j][:]) < 0
+		})
+	}
+
+	if scReward, ok := s.SCRewardMap[hash]; ok {
+		for rewardNumber, blockReward := range scReward.SCBlockRewardMap {
+			reward := &SideChainReward{
+				Number: rewardNumber,
+				Rent:   new(big.Int),
+				Scores: make(map[common.Address]uint64),
+			}
+			for _, rent := range record.RentReward {
+				if rent.MaxRewardNumber.Uint64() >= rewardNumber {
+					reward.Rent.Add(reward.Rent, rent.RentPerPeriod)
+				}
+			}
+			for coinbase, score := range blockReward.RewardScoreMap {
+				reward.Scores[coinbase] = score
+				signer, ok := s.SCCoinbase[hash][coinbase]
+				if !ok {
+					signer = coinbase
+				}
+				if _, ok := status.Accrued[signer]; !ok {
+					status.Accrued[signer] = &SideChainAccrued{Rent: new(big.Int)}
+				}
+				status.Accrued[signer].Score += score
+				rentShare := new(big.Int).Mul(reward.Rent, new(big.Int).SetUint64(score))
+				status.Accrued[signer].Rent.Add(status.Accrued[signer].Rent, rentShare.Div(rentShare, big.NewInt(100)))
+			}
+			status.Rewards = append(status.Rewards, reward)
+		}
+		sort.Slice(status.Rewards, func(i, j int) bool {
+			return status.Rewards[i].Number < status.Rewards[j].Number
+		})
+	}
+
+	if notice, ok := s.SCNoticeMap[hash]; ok {
+		for chargingHash, charging := range notice.CurrentCharging {
+			sideChainCharging := &SideChainCharging{
+				Hash:   chargingHash,
+				Target: charging.Target,
+				Volume: charging.Volume,
+			}
+			if confirm, ok := notice.ConfirmReceived[chargingHash]; ok {
+				sideChainCharging.Confirmed = len(confirm.NRecord)
+				sideChainCharging.Success = confirm.Success
+			}
+			status.Charging = append(status.Charging, sideChainCharging)
+		}
+		sort.Slice(status.Charging, func(i, j int) bool {
+			return bytes.Compare(status.Charging[i].Hash[:], status.Charging[j].Hash[:]) < 0
+		})
+	}
+
+	if s.Bridge != nil {
+		if locked, ok := s.Bridge.Locked[hash]; ok {
+			status.BridgeLocked = new(big.Int).Set(locked)
+		}
+	}
+	return status
+}
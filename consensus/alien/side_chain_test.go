@@ -0,0 +1,104 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+func TestSideChainStatus(t *testing.T) {
+	var (
+		base      = uint64(upgradeTestNumber)
+		scHash    = common.HexToHash("0x5c")
+		rentHash  = common.HexToHash("0x7e01")
+		oldRent   = common.HexToHash("0x7e02")
+		signerA   = common.HexToAddress("0xa100000000000000000000000000000000000001")
+		signerB   = common.HexToAddress("0xb200000000000000000000000000000000000002")
+		coinbases = []common.Address{
+			common.HexToAddress("0xc100000000000000000000000000000000000001"),
+			common.HexToAddress("0xc200000000000000000000000000000000000002"),
+			common.HexToAddress("0xc300000000000000000000000000000000000003"),
+		}
+	)
+	snap := &Snapshot{
+		config:      upgradedConfig(&params.AlienConfig{Period: 10, MaxSignerCount: 3}),
+		SCCoinbase:  map[common.Hash]map[common.Address]common.Address{scHash: {coinbases[0]: signerA, coinbases[1]: signerA, coinbases[2]: signerB}},
+		SCRecordMap: make(map[common.Hash]*SCRecord),
+		SCRewardMap: make(map[common.Hash]*SCReward),
+		SCNoticeMap: make(map[common.Hash]*CCNotice),
+	}
+	snap.SCRecordMap[scHash] = &SCRecord{
+		Record:         make(map[uint64][]*SCConfirmation),
+		CountPerPeriod: 1,
+		RentReward: map[common.Hash]*SCRentInfo{
+			rentHash: {big.NewInt(10), new(big.Int).SetUint64(base + 1000)},
+			oldRent:  {big.NewInt(5), new(big.Int).SetUint64(base + 95)},
+		},
+	}
+	snap.SCNoticeMap[scHash] = &CCNotice{
+		CurrentCharging: map[common.Hash]GasCharging{rentHash: {coinbases[2], 30, rentHash}},
+		ConfirmReceived: map[common.Hash]NoticeCR{rentHash: {map[common.Address]bool{coinbases[0]: true}, 0, noticeTypeGasCharging, false}},
+	}
+	confirm := func(coinbase common.Address) []SCConfirmation {
+		loopInfo := []string{"10", coinbases[0].Hex(), "11", coinbases[1].Hex()}
+		return []SCConfirmation{{Hash: scHash, Coinbase: coinbase, Number: 11, LoopInfo: loopInfo}}
+	}
+
+	snap.updateSnapshotBySCConfirm(confirm(coinbases[0]), new(big.Int).SetUint64(base+99))
+	status := snap.sideChainStatus(scHash, base+99, 1000)
+	if status.PendingConfirmedNumber != 0 || len(status.Pending) != 1 || len(status.Pending[0].Confirmers) != 1 {
+		t.Fatalf("pending confirmation mismatch: %d %v", status.PendingConfirmedNumber, status.Pending)
+	}
+	snap.updateSnapshotBySCConfirm(confirm(coinbases[1]), new(big.Int).SetUint64(base+100))
+	if status = snap.sideChainStatus(scHash, base+100, 1010); status.PendingConfirmedNumber != 11 {
+		t.Fatalf("pending confirmed number %d, want 11", status.PendingConfirmedNumber)
+	}
+	snap.updateSnapshotBySCConfirm(nil, new(big.Int).SetUint64(base+101))
+
+	status = snap.sideChainStatus(scHash, base+101, 1020)
+	if status.LastConfirmedNumber != 11 || len(status.Pending) != 0 {
+		t.Errorf("confirmation not applied: %d %v", status.LastConfirmedNumber, status.Pending)
+	}
+	if len(status.History) != 1 || status.History[0].Number != base+101 || status.History[0].ConfirmedNumber != 11 || len(status.History[0].Confirmers) != 2 {
+		t.Errorf("history mismatch: %v", status.History)
+	}
+	if !status.RentActive || status.RentExpireNumber != base+1000 || len(status.Rents) != 2 {
+		t.Fatalf("rent status mismatch: %+v", status)
+	}
+	if rent := status.Rents[1]; !rent.Active || rent.RemainingBlocks != 899 || rent.ExpireTime != 1020+899*10 {
+		t.Errorf("active rent mismatch: %+v", rent)
+	}
+	if rent := status.Rents[0]; rent.Active || rent.Hash != oldRent {
+		t.Errorf("expired rent mismatch: %+v", rent)
+	}
+	if len(status.Coinbases[signerA]) != 2 || len(status.Coinbases[signerB]) != 1 {
+		t.Errorf("coinbases mismatch: %v", status.Coinbases)
+	}
+	if len(status.Rewards) != 1 || status.Rewards[0].Rent.Int64() != 10 || status.Rewards[0].Scores[coinbases[1]] != 100 {
+		t.Errorf("rewards mismatch: %v", status.Rewards)
+	}
+	if accrued := status.Accrued[signerA]; accrued == nil || accrued.Score != 200 || accrued.Rent.Int64() != 20 {
+		t.Errorf("accrued reward mismatch: %+v", accrued)
+	}
+	if len(status.Charging) != 1 || status.Charging[0].Confirmed != 1 || status.Charging[0].Volume != 30 {
+		t.Errorf("charging mismatch: %v", status.Charging)
+	}
+}
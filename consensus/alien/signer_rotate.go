@@ -0,0 +1,265 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"sort"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+)
+
+// SignerRotateRecord schedules the move of a PoS candidate to a new signing
+// address at the loop boundary ActiveNumber.
+type SignerRotateRecord struct {
+	Signer       common.Address
+	NewSigner    common.Address
+	ActiveNumber uint64
+}
+
+// SignerRotation is a scheduled key rotation waiting for its loop boundary.
+type SignerRotation struct {
+	Signer       common.Address `json:"signer"`
+	NewSigner    common.Address `json:"newsigner"`
+	Number       uint64         `json:"number"` // block the rotation was requested in
+	ActiveNumber uint64         `json:"activenumber"`
+}
+
+// processSignerRotate handles utg:1:SignerRotate:<signer>:<new signer>. The
+// transaction must be signed by the current signer and by the manager of its
+// pledge, usually as a multi-signer transaction. The rotation takes effect at
+// the start of the loop after the next one, so the operator has a full loop
+// to bring up the new key.
+func (a *Alien) processSignerRotate(currentRotate []SignerRotateRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, snap *Snapshot, number uint64) []SignerRotateRecord {
+	if len(txDataInfo) < 5 {
+		log.Warn("SignerRotate", "parameter number", len(txDataInfo))
+		return currentRotate
+	}
+	record := SignerRotateRecord{}
+	postion := 3
+	if err := record.Signer.UnmarshalText1([]byte(txDataInfo[postion])); err != nil {
+		log.Warn("SignerRotate", "signer", txDataInfo[postion])
+		return currentRotate
+	}
+	postion++
+	if err := record.NewSigner.UnmarshalText1([]byte(txDataInfo[postion])); err != nil || record.NewSigner == (common.Address{}) {
+		log.Warn("SignerRotate", "new signer", txDataInfo[postion])
+		return currentRotate
+	}
+	pledge, ok := snap.PosPledge[record.Signer]
+	if !ok {
+		log.Warn("SignerRotate", "PoS node not exist", record.Signer)
+		return currentRotate
+	}
+	if !hasSigner(tx, txSender, record.Signer) || !hasSigner(tx, txSender, pledge.Manager) {
+		log.Warn("SignerRotate", "not signed by signer and manager", record.Signer)
+		return currentRotate
+	}
+	if _, ok := snap.SignerRotation[record.Signer]; ok {
+		log.Warn("SignerRotate", "rotation already scheduled", record.Signer)
+		return currentRotate
+	}
+	if !snap.isFreeSignerAddress(record.NewSigner) {
+		log.Warn("SignerRotate", "new signer in use", record.NewSigner)
+		return currentRotate
+	}
+	for _, item := range currentRotate {
+		if item.Signer == record.Signer || item.NewSigner == record.NewSigner {
+			log.Warn("SignerRotate", "address only one in one block", record.Signer)
+			return currentRotate
+		}
+	}
+	record.ActiveNumber = (number/snap.config.MaxSignerCount + 2) * snap.config.MaxSignerCount
+	topics := make([]common.Hash, 3)
+	topics[0].UnmarshalText([]byte("0xa6a403692bafd9f8f50ac946fa8f2761aa36d4a7e6e3007f853fc2e43daace11")) //web3.sha3("SignerRotate(address,address)")
+	topics[1].SetBytes(record.Signer.Bytes())
+	topics[2].SetBytes(record.NewSigner.Bytes())
+	a.addCustomerTxLog(tx, receipts, topics, nil)
+	return append(currentRotate, record)
+}
+
+// isFreeSignerAddress reports whether no candidate state is kept for the
+// address, so a signer can be moved to it.
+func (s *Snapshot) isFreeSignerAddress(address common.Address) bool {
+	if _, ok := s.PosPledge[address]; ok {
+		return false
+	}
+	if _, ok := s.TallyMiner[address]; ok {
+		return false
+	}
+	if _, ok := s.Tally[address]; ok {
+		return false
+	}
+	if _, ok := s.Candidates[address]; ok {
+		return false
+	}
+	if _, ok := s.CandidatePledge[address]; ok {
+		return false
+	}
+	if _, ok := s.RevenueNormal[address]; ok {
+		return false
+	}
+	if _, ok := s.Bandwidth[address]; ok {
+		return false
+	}
+	if _, ok := s.SCMinerRevenue[address]; ok {
+		return false
+	}
+	if s.isSigner(address) {
+		return false
+	}
+	for _, rotation := range s.SignerRotation {
+		if rotation.NewSigner == address {
+			return false
+		}
+	}
+	return true
+}
+
+// updateSignerRotate records the rotations of the block and, on the last
+// block of a loop, applies the rotations due at the next loop boundary. The
+// signer queue of the next loop is then built with the new addresses, both
+// by the sealer and by the verifiers.
+func (s *Snapshot) updateSignerRotate(records []SignerRotateRecord, number uint64) {
+	for _, item := range records {
+		if s.SignerRotation == nil {
+			s.SignerRotation = make(map[common.Address]*SignerRotation)
+		}
+		s.SignerRotation[item.Signer] = &SignerRotation{
+			Signer:       item.Signer,
+			NewSigner:    item.NewSigner,
+			Number:       number,
+			ActiveNumber: item.ActiveNumber,
+		}
+	}
+	if (number+1)%s.config.MaxSignerCount != 0 {
+		return
+	}
+	var due []*SignerRotation
+	for _, rotation := range s.SignerRotation {
+		if rotation.ActiveNumber <= number+1 {
+			due = append(due, rotation)
+		}
+	}
+	sortSignerRotation(due)
+	for _, rotation := range due {
+		delete(s.SignerRotation, rotation.Signer)
+		if _, ok := s.PosPledge[rotation.Signer]; !ok || !s.isFreeSignerAddress(rotation.NewSigner) {
+			log.Info("updateSignerRotate", "rotation dropped", rotation.Signer)
+			continue
+		}
+		s.rotateSigner(rotation.Signer, rotation.NewSigner)
+	}
+}
+
+// rotateSigner moves the pledge, delegations, tally, punishment, bandwidth
+// and side chain state of a candidate to a new address, and replaces it in
+// the signer queue.
+func (s *Snapshot) rotateSigner(signer common.Address, newSigner common.Address) {
+	s.PosPledge[newSigner] = s.PosPledge[signer]
+	delete(s.PosPledge, signer)
+	if miner, ok := s.TallyMiner[signer]; ok {
+		s.TallyMiner[newSigner] = miner
+		delete(s.TallyMiner, signer)
+	}
+	if tally, ok := s.Tally[signer]; ok {
+		s.Tally[newSigner] = tally
+		delete(s.Tally, signer)
+	}
+	for _, vote := range s.Votes {
+		if vote.Candidate == signer {
+			vote.Candidate = newSigner
+		}
+	}
+	if state, ok := s.Candidates[signer]; ok {
+		s.Candidates[newSigner] = state
+		delete(s.Candidates, signer)
+	}
+	if pledge, ok := s.CandidatePledge[signer]; ok {
+		s.CandidatePledge[newSigner] = pledge
+		delete(s.CandidatePledge, signer)
+	}
+	if punished, ok := s.Punished[signer]; ok {
+		s.Punished[newSigner] = punished
+		delete(s.Punished, signer)
+	}
+	if signed, ok := s.TallySigner[signer]; ok {
+		s.TallySigner[newSigner] = signed
+		delete(s.TallySigner, signer)
+	}
+	if revenue, ok := s.RevenueNormal[signer]; ok {
+		s.RevenueNormal[newSigner] = revenue
+		delete(s.RevenueNormal, signer)
+	}
+	if bandwidth, ok := s.Bandwidth[signer]; ok {
+		s.Bandwidth[newSigner] = bandwidth
+		delete(s.Bandwidth, signer)
+	}
+	if revenue, ok := s.SCMinerRevenue[signer]; ok {
+		s.SCMinerRevenue[newSigner] = revenue
+		delete(s.SCMinerRevenue, signer)
+	}
+	for _, coinbases := range s.SCCoinbase {
+		for coinbase, address := range coinbases {
+			if address == signer {
+				coinbases[coinbase] = newSigner
+			}
+		}
+	}
+	for _, compound := range s.AutoCompound {
+		if compound.TargetType == TargetTypePos && compound.Target == signer {
+			compound.Target = newSigner
+		}
+	}
+	for i, address := range s.Signers {
+		if *address == signer {
+			s.Signers[i] = &newSigner
+		}
+	}
+}
+
+func sortSignerRotation(rotations []*SignerRotation) {
+	sort.Slice(rotations, func(i, j int) bool {
+		if rotations[i].ActiveNumber != rotations[j].ActiveNumber {
+			return rotations[i].ActiveNumber < rotations[j].ActiveNumber
+		}
+		return bytes.Compare(rotations[i].Signer[:], rotations[j].Signer[:]) < 0
+	})
+}
+
+// GetSignerRotations returns the signer key rotations waiting for their loop
+// boundary, the earliest first.
+func (api *API) GetSignerRotations() ([]*SignerRotation, error) {
+	log.Info("api GetSignerRotations")
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snapshot, err := api.getSnapshotCache(header)
+	if err != nil {
+		log.Warn("Fail to GetSignerRotations", "err", err)
+		return nil, errUnknownBlock
+	}
+	rotations := make([]*SignerRotation, 0, len(snapshot.SignerRotation))
+	for _, rotation := range snapshot.SignerRotation {
+		rotations = append(rotations, rotation)
+	}
+	sortSignerRotation(rotations)
+	return rotations, nil
+}
@@ -0,0 +1,137 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+func TestSignerRotate(t *testing.T) {
+	signerKey, _ := crypto.GenerateKey()
+	managerKey, _ := crypto.GenerateKey()
+	signer := crypto.PubkeyToAddress(signerKey.PublicKey)
+	manager := crypto.PubkeyToAddress(managerKey.PublicKey)
+	newSigner := common.HexToAddress("0x7700000000000000000000000000000000000077")
+	other := common.HexToAddress("0x8800000000000000000000000000000000000088")
+	delegation := common.HexToHash("0x01")
+	sideChain := common.HexToHash("0x02")
+	coinbase := common.HexToAddress("0x9900000000000000000000000000000000000099")
+
+	snap := &Snapshot{
+		config: upgradedConfig(&params.AlienConfig{Period: 10, MaxSignerCount: 3}),
+		PosPledge: map[common.Address]*PosPledgeItem{
+			signer: {
+				Manager:     manager,
+				TotalAmount: big.NewInt(300),
+				Detail:      map[common.Hash]*PledgeDetail{delegation: {Address: other, Amount: big.NewInt(100), Height: 1}},
+			},
+			other: {Manager: other, TotalAmount: big.NewInt(100)},
+		},
+		TallyMiner:    map[common.Address]*CandidateState{signer: {Stake: big.NewInt(300)}},
+		Tally:         map[common.Address]*big.Int{},
+		Candidates:    map[common.Address]uint64{},
+		Punished:      map[common.Address]uint64{signer: 20},
+		TallySigner:   map[common.Address]uint64{signer: 2},
+		RevenueNormal: map[common.Address]*RevenueParameter{signer: {RevenueAddress: manager}},
+		Bandwidth:     map[common.Address]*ClaimedBandwidth{signer: {ISPQosID: 1, BandwidthClaimed: 100}},
+		SCCoinbase: map[common.Hash]map[common.Address]common.Address{
+			sideChain: {coinbase: signer, other: other},
+		},
+		SCMinerRevenue: map[common.Address]common.Address{signer: coinbase},
+		AutoCompound: map[common.Address]*AutoCompound{
+			other: {Owner: other, TargetType: TargetTypePos, Target: signer, Threshold: big.NewInt(1), Compounded: big.NewInt(0)},
+		},
+	}
+	snap.Signers = []*common.Address{&other, &signer, &other}
+
+	chainID := big.NewInt(1337)
+	rotate := func(data string, number uint64, keys ...*ecdsa.PrivateKey) []SignerRotateRecord {
+		tx := types.NewMultiSignerTransaction(chainID, 0, signer, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+		for _, key := range keys {
+			tx, _ = types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+		}
+		sender := crypto.PubkeyToAddress(keys[0].PublicKey)
+		receipts := []*types.Receipt{{Status: types.ReceiptStatusSuccessful, TxHash: tx.Hash(), BlockNumber: new(big.Int).SetUint64(number)}}
+		return (&Alien{}).processSignerRotate(nil, strings.Split(data, ":"), sender, tx, receipts, snap, number)
+	}
+
+	number := upgradeTestNumber + uint64(1)
+	data := "utg:1:SignerRotate:" + signer.Hex() + ":" + newSigner.Hex()
+	if records := rotate(data, number, signerKey); len(records) != 0 {
+		t.Errorf("rotation without the manager accepted")
+	}
+	if records := rotate(data, number, managerKey); len(records) != 0 {
+		t.Errorf("rotation without the signer accepted")
+	}
+	if records := rotate("utg:1:SignerRotate:"+signer.Hex()+":"+other.Hex(), number, signerKey, managerKey); len(records) != 0 {
+		t.Errorf("rotation to an existing candidate accepted")
+	}
+	records := rotate(data, number, signerKey, managerKey)
+	if len(records) != 1 {
+		t.Fatalf("rotation signed by signer and manager rejected")
+	}
+	active := (number/3 + 2) * 3
+	if records[0].ActiveNumber != active {
+		t.Fatalf("rotation active at %d, want %d", records[0].ActiveNumber, active)
+	}
+	snap.updateSignerRotate(records, number)
+	if records := rotate(data, number+1, signerKey, managerKey); len(records) != 0 {
+		t.Errorf("second rotation of the signer accepted")
+	}
+
+	// The loop ending before the activation boundary keeps the old signer.
+	snap.updateSignerRotate(nil, active-4)
+	if _, ok := snap.PosPledge[signer]; !ok || snap.SignerRotation[signer] == nil {
+		t.Fatalf("rotation applied before its loop boundary")
+	}
+	snap.updateSignerRotate(nil, active-1)
+	if len(snap.SignerRotation) != 0 {
+		t.Errorf("rotation still pending after its boundary")
+	}
+	if _, ok := snap.PosPledge[signer]; ok {
+		t.Errorf("old signer still pledged")
+	}
+	if pledge := snap.PosPledge[newSigner]; pledge == nil || pledge.Manager != manager || pledge.Detail[delegation] == nil {
+		t.Errorf("pledge and delegations not moved: %+v", pledge)
+	}
+	if miner := snap.TallyMiner[newSigner]; miner == nil || snap.TallyMiner[signer] != nil {
+		t.Errorf("tally not moved")
+	}
+	if snap.Punished[newSigner] != 20 || snap.TallySigner[newSigner] != 2 || snap.RevenueNormal[newSigner] == nil {
+		t.Errorf("punishment or revenue state not moved")
+	}
+	if snap.Bandwidth[newSigner] == nil || snap.Bandwidth[signer] != nil {
+		t.Errorf("claimed bandwidth not moved")
+	}
+	if snap.SCMinerRevenue[newSigner] != coinbase || snap.SCCoinbase[sideChain][coinbase] != newSigner || snap.SCCoinbase[sideChain][other] != other {
+		t.Errorf("side chain state not moved")
+	}
+	if snap.AutoCompound[other].Target != newSigner {
+		t.Errorf("auto compound target not moved")
+	}
+	if *snap.Signers[0] != other || *snap.Signers[1] != newSigner || *snap.Signers[2] != other {
+		t.Errorf("signer queue not updated")
+	}
+}
@@ -92,6 +92,7 @@ type SCRecord struct {
 	CountPerPeriod      uint64                       `json:"countPerPeriod"`      // block sealed per period on this side chain
 	RewardPerPeriod     uint64                       `json:"rewardPerPeriod"`     // full reward per period, number per thousand
 	RentReward          map[common.Hash]*SCRentInfo  `json:"rentReward"`          // reward info by rent
+	History             []*SCConfirmedNumber         `json:"history,omitempty"`   // last confirmed numbers since the SideChainHistoryBlock, the earliest first
 }
 
 type NoticeCR struct {
@@ -103,14 +104,16 @@ type NoticeCR struct {
 
 // CCNotice (cross chain notice) contain the information main chain need to notify given side chain
 type CCNotice struct {
-	CurrentCharging map[common.Hash]GasCharging `json:"currentCharging"` // common.Hash here is the proposal txHash not the hash of side chain
-	ConfirmReceived map[common.Hash]NoticeCR    `json:"confirmReceived"` // record the confirm address
+	CurrentCharging map[common.Hash]GasCharging     `json:"currentCharging"`         // common.Hash here is the proposal txHash not the hash of side chain
+	ConfirmReceived map[common.Hash]NoticeCR        `json:"confirmReceived"`         // record the confirm address
+	CurrentBridge   map[common.Hash]*BridgeTransfer `json:"currentBridge,omitempty"` // bridge locks to mint on side chain, by lock txHash
 }
 
 type RevenueParameter struct {
 	RevenueAddress  common.Address `json:"revenueaddress"`
 	RevenueContract common.Address `json:"contractaddress"`
 	MultiSignature  common.Address `json:"multisignatureaddress"`
+	Beneficiaries   []RevenueShare `json:"beneficiaries,omitempty"`
 }
 
 type PledgeItem struct {
@@ -155,6 +158,7 @@ type SystemParameter struct {
 	QosConfig      map[uint32]uint32         `json:"BandwidthQOS"`
 	ManagerAddress map[uint32]common.Address `json:"FoundationAddress"`
 	LockParameters map[uint32]*LockParameter `json:"PledgeParameter"`
+	ConfigDelay    uint64                    `json:"ConfigDelay"` // blocks a passed change waits in the pending queue, 0 for the default
 }
 
 type FlowMinerReport struct {
@@ -187,6 +191,7 @@ type PosPledgeItem struct {
 	Detail      map[common.Hash]*PledgeDetail `json:"detail"`
 	LastPunish  uint64                        `json:"lastpunish"`
 	DisRate     *big.Int                      `json:"distributerate"`
+	PendingRate *CommissionChange             `json:"pendingrate,omitempty"` // announced DisRate
 }
 
 type PledgeDetail struct {
@@ -256,6 +261,11 @@ type Snapshot struct {
 	PosPledge          map[common.Address]*PosPledgeItem    `json:"pospledge"`
 	TotalLeaseSpace    *big.Int                             `json:"totalleasespace"`
 	SpData             *SpData                              `json:"SpoolData"`
+	Redelegation       map[common.Hash]uint64               `json:"redelegation"`
+	AutoCompound       map[common.Address]*AutoCompound     `json:"autocompound"`
+	PendingConfig      map[common.Hash]*PendingConfigChange `json:"pendingconfig"`
+	SignerRotation     map[common.Address]*SignerRotation   `json:"signerrotation"`
+	Bridge             *BridgeState                         `json:"bridge"`
 }
 
 var (
@@ -574,6 +584,7 @@ func (s *Snapshot) copy() *Snapshot {
 		SystemConfig: SystemParameter{
 			ExchRate:       s.SystemConfig.ExchRate,
 			OffLine:        s.SystemConfig.OffLine,
+			ConfigDelay:    s.SystemConfig.ConfigDelay,
 			Deposit:        make(map[uint32]*big.Int),
 			QosConfig:      make(map[uint32]uint32),
 			ManagerAddress: make(map[uint32]common.Address),
@@ -623,6 +634,7 @@ func (s *Snapshot) copy() *Snapshot {
 				Detail:      make(map[common.Hash]*PledgeDetail, 0),
 				LastPunish:  item.LastPunish,
 				DisRate:     new(big.Int).Set(item.DisRate),
+				PendingRate: item.PendingRate.copy(),
 			}
 			for hash, detail := range item.Detail {
 				cpy.PosPledge[addr].Detail[hash] = &PledgeDetail{
@@ -636,6 +648,32 @@ func (s *Snapshot) copy() *Snapshot {
 	if s.TotalLeaseSpace != nil {
 		cpy.TotalLeaseSpace = new(big.Int).Set(s.TotalLeaseSpace)
 	}
+	if s.Redelegation != nil {
+		cpy.Redelegation = make(map[common.Hash]uint64, len(s.Redelegation))
+		for hash, number := range s.Redelegation {
+			cpy.Redelegation[hash] = number
+		}
+	}
+	if s.PendingConfig != nil {
+		cpy.PendingConfig = make(map[common.Hash]*PendingConfigChange, len(s.PendingConfig))
+		for hash, change := range s.PendingConfig {
+			cpy.PendingConfig[hash] = change.copy()
+		}
+	}
+	cpy.Bridge = s.Bridge.copy()
+	if s.SignerRotation != nil {
+		cpy.SignerRotation = make(map[common.Address]*SignerRotation, len(s.SignerRotation))
+		for signer, rotation := range s.SignerRotation {
+			item := *rotation
+			cpy.SignerRotation[signer] = &item
+		}
+	}
+	if s.AutoCompound != nil {
+		cpy.AutoCompound = make(map[common.Address]*AutoCompound, len(s.AutoCompound))
+		for owner, compound := range s.AutoCompound {
+			cpy.AutoCompound[owner] = compound.copy()
+		}
+	}
 	copy(cpy.HistoryHash, s.HistoryHash)
 	copy(cpy.Signers, s.Signers)
 	copy(cpy.SignerMissing, s.SignerMissing)
@@ -693,6 +731,9 @@ func (s *Snapshot) copy() *Snapshot {
 		for rentHash, scRentInfo := range scc.RentReward {
 			cpy.SCRecordMap[hash].RentReward[rentHash] = &SCRentInfo{new(big.Int).Set(scRentInfo.RentPerPeriod), new(big.Int).Set(scRentInfo.MaxRewardNumber)}
 		}
+		for _, confirmed := range scc.History {
+			cpy.SCRecordMap[hash].History = append(cpy.SCRecordMap[hash].History, confirmed.copy())
+		}
 	}
 
 	for hash, sca := range s.SCRewardMap {
@@ -723,6 +764,12 @@ func (s *Snapshot) copy() *Snapshot {
 				cpy.SCNoticeMap[hash].ConfirmReceived[txHash].NRecord[addr] = b
 			}
 		}
+		if scn.CurrentBridge != nil {
+			cpy.SCNoticeMap[hash].CurrentBridge = make(map[common.Hash]*BridgeTransfer, len(scn.CurrentBridge))
+			for txHash, lock := range scn.CurrentBridge {
+				cpy.SCNoticeMap[hash].CurrentBridge[txHash] = lock.copy()
+			}
+		}
 	}
 
 	for txHash, charge := range s.LocalNotice.CurrentCharging {
@@ -734,6 +781,12 @@ func (s *Snapshot) copy() *Snapshot {
 			cpy.LocalNotice.ConfirmReceived[txHash].NRecord[addr] = b
 		}
 	}
+	if s.LocalNotice.CurrentBridge != nil {
+		cpy.LocalNotice.CurrentBridge = make(map[common.Hash]*BridgeTransfer, len(s.LocalNotice.CurrentBridge))
+		for txHash, mint := range s.LocalNotice.CurrentBridge {
+			cpy.LocalNotice.CurrentBridge[txHash] = mint.copy()
+		}
+	}
 
 	for number, refund := range s.ProposalRefund {
 		cpy.ProposalRefund[number] = make(map[common.Address]*big.Int)
@@ -766,6 +819,7 @@ func (s *Snapshot) copy() *Snapshot {
 			RevenueAddress:  revenue.RevenueAddress,
 			RevenueContract: revenue.RevenueContract,
 			MultiSignature:  revenue.MultiSignature,
+			Beneficiaries:   copyRevenueShares(revenue.Beneficiaries),
 		}
 	}
 	for who, revenue := range s.RevenueFlow {
@@ -773,6 +827,7 @@ func (s *Snapshot) copy() *Snapshot {
 			RevenueAddress:  revenue.RevenueAddress,
 			RevenueContract: revenue.RevenueContract,
 			MultiSignature:  revenue.MultiSignature,
+			Beneficiaries:   copyRevenueShares(revenue.Beneficiaries),
 		}
 	}
 	for who, pledge := range s.CandidatePledge {
@@ -890,6 +945,7 @@ func (s *Snapshot) copy() *Snapshot {
 			RevenueAddress:  revenue.RevenueAddress,
 			RevenueContract: revenue.RevenueContract,
 			MultiSignature:  revenue.MultiSignature,
+			Beneficiaries:   copyRevenueShares(revenue.Beneficiaries),
 		}
 	}
 	return cpy
@@ -969,6 +1025,11 @@ func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database, chain conse
 		// deal setcoinbase for side chain
 		snap.updateSnapshotBySetSCCoinbase(headerExtra.SideChainSetCoinbases)
 
+		// deal bridge locks and burns
+		if isGEBridgeEffect(snap.config, header.Number.Uint64()) {
+			snap.updateBridge(headerExtra.BridgeLock, headerExtra.BridgeBurn, header.Number.Uint64())
+		}
+
 		// deal confirmation for side chain
 		snap.updateSnapshotBySCConfirm(headerExtra.SideChainConfirmations, header.Number)
 
@@ -989,6 +1050,9 @@ func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database, chain conse
 
 		// deal the notice from main chain
 		snap.updateSnapshotBySCCharging(headerExtra.SideChainCharging, header.Number, header.Coinbase)
+		if isGEBridgeEffect(snap.config, header.Number.Uint64()) {
+			snap.updateSnapshotByBridgeMint(headerExtra.SideChainBridgeMint, header.Number, header.Coinbase)
+		}
 
 		snap.updateSnapshotForExpired(header.Number)
 
@@ -1009,6 +1073,9 @@ func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database, chain conse
 		snap.updateFlowRevenueRls(headerExtra.LockReward, header.Number)
 		snap.updateExchangeNFC(headerExtra.ExchangeNFC)
 		snap.updateDeviceBind(headerExtra.DeviceBind, header.Number.Uint64())
+		if isGERevenueSplitEffect(snap.config, header.Number.Uint64()) {
+			snap.updateRevenueSplit(headerExtra.RevenueSplit)
+		}
 		snap.updateCandidatePledge(headerExtra.CandidatePledge)
 		snap.updateCandidatePunish(headerExtra.CandidatePunish, header.Number.Uint64())
 		snap.updateCandidateExit(headerExtra.CandidateExit, header.Number)
@@ -1023,6 +1090,9 @@ func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database, chain conse
 		snap.updateConfigISPQOS(headerExtra.ConfigISPQOS)
 		snap.updateManagerAddress(headerExtra.ManagerAddress)
 		snap.updateLockParameters(headerExtra.LockParameters)
+		if isGEConfigQueueEffect(snap.config, header.Number.Uint64()) {
+			snap.updatePendingConfig(headerExtra.ConfigCancel, header.Number.Uint64())
+		}
 		if header.Number.Uint64()%(snap.config.MaxSignerCount*snap.LCRS) == 0 && header.Number.Uint64() >= signFixBlockNumber {
 			snap.updateSignerNumber(headerExtra.SignerQueue, header.Number.Uint64())
 		}
@@ -1040,6 +1110,10 @@ func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database, chain conse
 				return reSnap, nil
 			}
 		}
+		if isGEAutoCompoundEffect(snap.config, header.Number.Uint64()) {
+			snap.updateCompounded(headerExtra.Compounded, header.Number, db)
+			snap.updateAutoCompound(headerExtra.AutoCompound, header.Number.Uint64())
+		}
 		if header.Number.Uint64() == (StorageEffectBlockNumber - 1) {
 			snap.StorageData = NewStorageSnap()
 		}
@@ -1106,6 +1180,15 @@ func (s *Snapshot) apply(headers []*types.Header, db ethdb.Database, chain conse
 		if isGEInitStorageManagerNumber(header.Number.Uint64()){
 			snap.updatePOSTransfer(headerExtra.POSTransfer,header.Number)
 		}
+		if isGERedelegateEffect(snap.config, header.Number.Uint64()) {
+			snap.updateRedelegate(headerExtra.Redelegate, header.Number)
+		}
+		if isGECommissionNoticeEffect(snap.config, header.Number.Uint64()) {
+			snap.updatePendingCommission(header.Number.Uint64())
+		}
+		if isGESignerRotateEffect(snap.config, header.Number.Uint64()) {
+			snap.updateSignerRotate(headerExtra.SignerRotate, header.Number.Uint64())
+		}
 	}
 	snap.Number += uint64(len(headers))
 	snap.Hash = headers[len(headers)-1].Hash()
@@ -1550,6 +1633,9 @@ func (s *Snapshot) updateSnapshotBySCConfirm(scConfirmations []SCConfirmation, h
 	for _, scc := range scConfirmations {
 		// new confirmation header number must larger than last confirmed number of this side chain
 		if s.isSideChainCoinbase(scc.Hash, scc.Coinbase, false) {
+			if len(scc.BridgeBurn) > 0 && isGEBridgeEffect(s.config, headerNumber.Uint64()) {
+				s.updateBridgeReport(&scc, headerNumber.Uint64())
+			}
 			if _, ok := s.SCRecordMap[scc.Hash]; ok && scc.Number > s.SCRecordMap[scc.Hash].LastConfirmedNumber {
 				s.SCRecordMap[scc.Hash].Record[scc.Number] = append(s.SCRecordMap[scc.Hash].Record[scc.Number], scc.copy())
 				if scc.Number > s.SCRecordMap[scc.Hash].MaxHeaderNumber {
@@ -1562,6 +1648,9 @@ func (s *Snapshot) updateSnapshotBySCConfirm(scConfirmations []SCConfirmation, h
 	if (headerNumber.Uint64()+1)%s.config.MaxSignerCount == 0 {
 		s.checkSCConfirmation(headerNumber)
 		s.updateSCConfirmation(headerNumber)
+		if isGEBridgeEffect(s.config, headerNumber.Uint64()) {
+			s.updateBridgeUnlock(headerNumber.Uint64())
+		}
 	}
 }
 
@@ -1585,6 +1674,11 @@ func (s *Snapshot) updateSnapshotByNoticeConfirm(scNoticeConfirmed []SCConfirmat
 						s.SCNoticeMap[noticeConfirm.Hash].ConfirmReceived[noticeHash] = NoticeCR{make(map[common.Address]bool), 0, noticeTypeGasCharging, false}
 					}
 					s.SCNoticeMap[noticeConfirm.Hash].ConfirmReceived[noticeHash].NRecord[noticeConfirm.Coinbase] = true
+				} else if _, ok := s.SCNoticeMap[noticeConfirm.Hash].CurrentBridge[noticeHash]; ok {
+					if _, ok := s.SCNoticeMap[noticeConfirm.Hash].ConfirmReceived[noticeHash]; !ok {
+						s.SCNoticeMap[noticeConfirm.Hash].ConfirmReceived[noticeHash] = NoticeCR{make(map[common.Address]bool), 0, noticeTypeBridgeLock, false}
+					}
+					s.SCNoticeMap[noticeConfirm.Hash].ConfirmReceived[noticeHash].NRecord[noticeConfirm.Coinbase] = true
 				}
 			}
 		}
@@ -1605,6 +1699,7 @@ func (s *Snapshot) updateSnapshotByNoticeConfirm(scNoticeConfirmed []SCConfirmat
 
 				if noticeRecord.Success && noticeRecord.Number < headerNumber.Uint64()-s.config.MaxSignerCount*mcNoticeClearDelayLoopCount {
 					delete(s.SCNoticeMap[chainHash].CurrentCharging, noticeHash)
+					delete(s.SCNoticeMap[chainHash].CurrentBridge, noticeHash)
 					delete(s.SCNoticeMap[chainHash].ConfirmReceived, noticeHash)
 				}
 			}
@@ -1625,6 +1720,10 @@ func (s *Snapshot) updateSnapshotBySCCharging(scCharging []GasCharging, headerNu
 
 	if (headerNumber.Uint64()+1)%s.config.MaxSignerCount == 0 {
 		for hash, noticeRecord := range s.LocalNotice.ConfirmReceived {
+			if noticeRecord.Type == noticeTypeBridgeLock {
+				// bridge locks are minted by updateSnapshotByBridgeMint
+				continue
+			}
 			if len(noticeRecord.NRecord) >= int(2*s.config.MaxSignerCount/3+1) && !noticeRecord.Success {
 				s.LocalNotice.ConfirmReceived[hash] = NoticeCR{noticeRecord.NRecord, headerNumber.Uint64(), noticeTypeGasCharging, true}
 				// todo charging the gas fee on set block
@@ -1741,7 +1840,7 @@ func (s *Snapshot) calculateCurrentBlockReward(currentCount uint64, periodCount
 }
 
 func (s *Snapshot) updateSCConfirmation(headerNumber *big.Int) {
-	minConfirmedSignerCount := int(2 * s.config.MaxSignerCount / 3)
+	minConfirmedSignerCount := s.scMinConfirmedSignerCount()
 	for scHash, record := range s.SCRecordMap {
 		if _, ok := s.SCRewardMap[scHash]; !ok {
 			s.SCRewardMap[scHash] = &SCReward{SCBlockRewardMap: make(map[uint64]*SCBlockReward)}
@@ -1772,6 +1871,9 @@ func (s *Snapshot) updateSCConfirmation(headerNumber *big.Int) {
 				}
 			}
 
+			if isGESideChainHistoryEffect(s.config, headerNumber.Uint64()) {
+				record.recordConfirmedNumber(confirmedNumber, headerNumber.Uint64())
+			}
 			for i := record.LastConfirmedNumber + 1; i <= confirmedNumber; i++ {
 				if _, ok := s.SCRecordMap[scHash].Record[i]; ok {
 					delete(s.SCRecordMap[scHash].Record, i)
@@ -1804,7 +1906,15 @@ func (s *Snapshot) updateSnapshotByDeclares(declares []Declare, headerNumber *bi
 	for _, declare := range declares {
 		if proposal, ok := s.Proposals[declare.ProposalHash]; ok {
 			// check the proposal enable status and valid block number
-			if proposal.ReceivedNumber.Uint64()+proposal.ValidationLoopCnt*s.config.MaxSignerCount < headerNumber.Uint64() || !s.isCandidate(declare.Declarer) {
+			if proposal.ReceivedNumber.Uint64()+proposal.ValidationLoopCnt*s.config.MaxSignerCount < headerNumber.Uint64() {
+				continue
+			}
+			// SystemConfig proposals are declared by the active signers
+			if isConfigProposal(proposal.ProposalType) {
+				if !s.isSigner(declare.Declarer) {
+					continue
+				}
+			} else if !s.isCandidate(declare.Declarer) {
 				continue
 			}
 			// check if this signer already declare on this proposal
@@ -1863,7 +1973,11 @@ func (s *Snapshot) calculateProposalResult(headerNumber *big.Int) {
 					}
 				}
 			}
-			if yesDeclareStake.Cmp(judegmentStake) > 0 {
+			passed := yesDeclareStake.Cmp(judegmentStake) > 0
+			if isConfigProposal(proposal.ProposalType) {
+				passed = s.isConfigProposalPassed(proposal)
+			}
+			if passed {
 				// process add candidate
 				switch proposal.ProposalType {
 				case proposalTypeCandidateAdd:
@@ -1879,7 +1993,7 @@ func (s *Snapshot) calculateProposalResult(headerNumber *big.Int) {
 
 				case proposalTypeSideChainAdd:
 					if _, ok := s.SCRecordMap[proposal.SCHash]; !ok {
-						s.SCRecordMap[proposal.SCHash] = &SCRecord{make(map[uint64][]*SCConfirmation), 0, 0, proposal.SCBlockCountPerPeriod, proposal.SCBlockRewardPerPeriod, make(map[common.Hash]*SCRentInfo)}
+						s.SCRecordMap[proposal.SCHash] = &SCRecord{make(map[uint64][]*SCConfirmation), 0, 0, proposal.SCBlockCountPerPeriod, proposal.SCBlockRewardPerPeriod, make(map[common.Hash]*SCRentInfo), nil}
 					} else {
 						s.SCRecordMap[proposal.SCHash].CountPerPeriod = proposal.SCBlockCountPerPeriod
 						s.SCRecordMap[proposal.SCHash].RewardPerPeriod = proposal.SCBlockRewardPerPeriod
@@ -1908,10 +2022,17 @@ func (s *Snapshot) calculateProposalResult(headerNumber *big.Int) {
 							maxRewardNumber,
 						}
 						if _, ok := s.SCNoticeMap[proposal.SCHash]; !ok {
-							s.SCNoticeMap[proposal.SCHash] = &CCNotice{make(map[common.Hash]GasCharging), make(map[common.Hash]NoticeCR)}
+							s.SCNoticeMap[proposal.SCHash] = &CCNotice{CurrentCharging: make(map[common.Hash]GasCharging), ConfirmReceived: make(map[common.Hash]NoticeCR)}
 						}
 						s.SCNoticeMap[proposal.SCHash].CurrentCharging[proposal.Hash] = GasCharging{proposal.TargetAddress, proposal.SCRentFee * proposal.SCRentRate, proposal.Hash}
 					}
+				case proposalTypeConfigExchRate, proposalTypeConfigDeposit, proposalTypeConfigCndLock, proposalTypeConfigFlwLock,
+					proposalTypeConfigRwdLock, proposalTypeConfigOffLine, proposalTypeConfigQOS, proposalTypeConfigManager, proposalTypeConfigDelay:
+					if isGEConfigQueueEffect(s.config, headerNumber.Uint64()) {
+						s.queueConfigChange(proposal, headerNumber.Uint64())
+					} else {
+						s.applyConfigChange(newConfigChange(proposal))
+					}
 				default:
 					// todo
 				}
@@ -2183,6 +2304,16 @@ func (s *Snapshot) isVoter(address common.Address) bool {
 	return false
 }
 
+// check if address belong to the current signers queue
+func (s *Snapshot) isSigner(address common.Address) bool {
+	for _, signer := range s.Signers {
+		if *signer == address {
+			return true
+		}
+	}
+	return false
+}
+
 // check if address belong to candidate
 func (s *Snapshot) isCandidate(address common.Address) bool {
 	if _, ok := s.Candidates[address]; ok {
@@ -2684,6 +2815,7 @@ func (snap *Snapshot) checkCandidateAutoExit(number uint64, candidateAutoExit []
 				for hash, detail := range snap.PosPledge[miner].Detail {
 					if detail.Address == item.Manager {
 						burnAmount = new(big.Int).Add(burnAmount, detail.Amount)
+						attributeBalance(state, common.BigToAddress(big.NewInt(0)), detail.Amount, balanceReasonPenaltyBurn, "candidateAutoExit:"+miner.Hex())
 					} else {
 						candidatePEntrustExit = append(candidatePEntrustExit, CandidatePEntrustExitRecord{
 							Target:  miner,
@@ -2746,6 +2878,10 @@ func (snap *Snapshot) findPosTargetMiner(txSender common.Address) common.Address
 func (snap *Snapshot) updateCandidateChangeRate(candidateChangeRate []CandidateChangeRateRecord, header *types.Header, db ethdb.Database) {
 	for _, item := range candidateChangeRate {
 		if _, ok := snap.PosPledge[item.Target]; ok {
+			if isGECommissionNoticeEffect(snap.config, header.Number.Uint64()) {
+				snap.PosPledge[item.Target].PendingRate = snap.newCommissionChange(item.Rate, header.Number.Uint64())
+				continue
+			}
 			snap.PosPledge[item.Target].DisRate = new(big.Int).Set(item.Rate)
 		}
 	}
@@ -2925,14 +3061,6 @@ func (snap *Snapshot) initPosExitPunish(header *types.Header, db ethdb.Database)
 	}
 }
 
-func (snap *Snapshot) isSystemManager(txSender common.Address) bool {
-	return snap.SystemConfig.ManagerAddress[sscEnumSystem] == txSender
-}
-
-func (snap *Snapshot) isSystemManagerAndInTally(txSender common.Address, minerAddress common.Address) bool {
-	return snap.isSystemManager(txSender) && snap.isInTally(minerAddress)
-}
-
 func (snap *Snapshot) updateCandidateExit2(candidateExit []common.Address, number *big.Int) {
 	if candidateExit == nil || len(candidateExit) == 0 {
 		return
@@ -0,0 +1,410 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"strconv"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/ethdb"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+	"github.com/shopspring/decimal"
+)
+
+// Outcomes of the automatic renewal of a lease.
+const (
+	autoRenewRenewed         = "renewed"
+	autoRenewNotRenewable    = "lease not renewable"
+	autoRenewDurationRange   = "duration out of range"
+	autoRenewPriceTooHigh    = "price above maximum"
+	autoRenewLowDeposit      = "insufficient deposit"
+	autoRenewLowSRTAllowance = "insufficient SRT allowance"
+	autoRenewLowSRTBalance   = "insufficient SRT balance"
+	autoRenewPendingRenewal  = "renewal pending"
+)
+
+// LeaseAutoRenew is the standing instruction of a tenant to renew a lease
+// when its renewal window opens. The deposit is paid by the tenant when the
+// instruction is registered and the rent is burnt from the SRT of the tenant
+// up to the allowance. What is left of the deposit is paid back when the
+// instruction is cancelled, the lease is transferred, or the daily settlement
+// finds the lease rescinded or expired or its storage pledge exited.
+type LeaseAutoRenew struct {
+	Pledge       common.Address `json:"pledge"`
+	Hash         common.Hash    `json:"hash"`
+	Owner        common.Address `json:"owner"`
+	Duration     *big.Int       `json:"duration"`
+	MaxPrice     *big.Int       `json:"maxprice"`
+	Deposit      *big.Int       `json:"deposit"`
+	SRTAllowance *big.Int       `json:"srtallowance"`
+	Renewals     uint64         `json:"renewals"`
+	LastNumber   *big.Int       `json:"lastnumber"`
+	LastResult   string         `json:"lastresult"`
+}
+
+// LeaseAutoRenewRecord registers, tops up or, with a zero duration, cancels
+// the automatic renewal of a lease.
+type LeaseAutoRenewRecord struct {
+	Address      common.Address `json:"address"`
+	Hash         common.Hash    `json:"hash"`
+	Owner        common.Address `json:"owner"`
+	Duration     *big.Int       `json:"duration"`
+	MaxPrice     *big.Int       `json:"maxprice"`
+	Deposit      *big.Int       `json:"deposit"`
+	SRTAllowance *big.Int       `json:"srtallowance"`
+}
+
+func (r *LeaseAutoRenew) copy() *LeaseAutoRenew {
+	return &LeaseAutoRenew{
+		Pledge:       r.Pledge,
+		Hash:         r.Hash,
+		Owner:        r.Owner,
+		Duration:     new(big.Int).Set(r.Duration),
+		MaxPrice:     new(big.Int).Set(r.MaxPrice),
+		Deposit:      new(big.Int).Set(r.Deposit),
+		SRTAllowance: new(big.Int).Set(r.SRTAllowance),
+		Renewals:     r.Renewals,
+		LastNumber:   new(big.Int).Set(r.LastNumber),
+		LastResult:   r.LastResult,
+	}
+}
+
+// processLeaseAutoRenew handles
+// utg:1:stAutoReNew:<storage node>:<lease>:<days>:<max price>:<deposit>:<srt allowance>,
+// and utg:1:stAutoReNew:<storage node>:<lease>:0 to cancel the instruction and
+// get the deposit back.
+func (a *Alien) processLeaseAutoRenew(currentAutoRenew []LeaseAutoRenewRecord, currentTransfer []LeaseTransferRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB, snap *Snapshot, number uint64) []LeaseAutoRenewRecord {
+	if len(txDataInfo) < 6 {
+		log.Warn("stAutoReNew", "parameter number", len(txDataInfo))
+		return currentAutoRenew
+	}
+	record := LeaseAutoRenewRecord{
+		Owner:        txSender,
+		Duration:     big.NewInt(0),
+		MaxPrice:     big.NewInt(0),
+		Deposit:      big.NewInt(0),
+		SRTAllowance: big.NewInt(0),
+	}
+	postion := 3
+	if err := record.Address.UnmarshalText1([]byte(txDataInfo[postion])); err != nil {
+		log.Warn("stAutoReNew", "address", txDataInfo[postion])
+		return currentAutoRenew
+	}
+	postion++
+	record.Hash = common.HexToHash(txDataInfo[postion])
+	postion++
+	if duration, err := strconv.ParseUint(txDataInfo[postion], 10, 32); err != nil {
+		log.Warn("stAutoReNew", "duration", txDataInfo[postion])
+		return currentAutoRenew
+	} else {
+		record.Duration = new(big.Int).SetUint64(duration)
+	}
+	for _, item := range currentAutoRenew {
+		if item.Hash == record.Hash {
+			log.Warn("stAutoReNew", "lease only one in one block", record.Hash)
+			return currentAutoRenew
+		}
+	}
+	for _, item := range currentTransfer {
+		if item.Hash == record.Hash {
+			log.Warn("stAutoReNew", "lease transferred in this block", record.Hash)
+			return currentAutoRenew
+		}
+	}
+	if record.Duration.Sign() == 0 {
+		renew, ok := snap.StorageData.LeaseAutoRenew[record.Hash]
+		if !ok || renew.Owner != txSender {
+			log.Warn("stAutoReNew", "no instruction to cancel", record.Hash)
+			return currentAutoRenew
+		}
+		if isStorageVerificationCheck(number, snap.Period) {
+			log.Warn("stAutoReNew", "can not cancel while leases are settled", number)
+			return currentAutoRenew
+		}
+		state.AddBalance(txSender, renew.Deposit)
+		topics := make([]common.Hash, 2)
+		topics[0].UnmarshalText([]byte("0xdd7a5d545cb4ec2b42e01d90346a172c868302ed338251516d8dc152426afcbe")) //web3.sha3("stAutoReNewCancel(address)")
+		topics[1].SetBytes(record.Hash.Bytes())
+		a.addCustomerTxLog(tx, receipts, topics, nil)
+		return append(currentAutoRenew, record)
+	}
+	if len(txDataInfo) < 9 {
+		log.Warn("stAutoReNew", "parameter number", len(txDataInfo))
+		return currentAutoRenew
+	}
+	amounts := []*big.Int{record.MaxPrice, record.Deposit, record.SRTAllowance}
+	for i := range amounts {
+		postion++
+		value, err := decimal.NewFromString(txDataInfo[postion])
+		if err != nil || value.Sign() < 0 {
+			log.Warn("stAutoReNew", "amount", txDataInfo[postion])
+			return currentAutoRenew
+		}
+		amounts[i].Set(value.BigInt())
+	}
+	if record.Duration.Cmp(snap.SystemConfig.Deposit[sscEnumMinimumRent]) < 0 || record.Duration.Cmp(snap.SystemConfig.Deposit[sscEnumMaximumRent]) > 0 {
+		log.Warn("stAutoReNew", "duration out of range", record.Duration)
+		return currentAutoRenew
+	}
+	if !snap.StorageData.checkLeaseAutoRenew(record, txSender) {
+		log.Warn("stAutoReNew", "checkLeaseAutoRenew fail", record.Hash)
+		return currentAutoRenew
+	}
+	if state.GetBalance(txSender).Cmp(record.Deposit) < 0 {
+		log.Warn("stAutoReNew", "balance", state.GetBalance(txSender))
+		return currentAutoRenew
+	}
+	state.SubBalance(txSender, record.Deposit)
+	topics := make([]common.Hash, 2)
+	topics[0].UnmarshalText([]byte("0xf255881da011596f6372287de8762618b9d8e079378095316962b998595981c8")) //web3.sha3("stAutoReNew(address)")
+	topics[1].SetBytes(record.Hash.Bytes())
+	a.addCustomerTxLog(tx, receipts, topics, common.BigToHash(record.Deposit).Bytes())
+	return append(currentAutoRenew, record)
+}
+
+func (s *StorageData) checkLeaseAutoRenew(record LeaseAutoRenewRecord, txSender common.Address) bool {
+	pledge, ok := s.StoragePledge[record.Address]
+	if !ok {
+		log.Info("checkLeaseAutoRenew", "address not exist", record.Address)
+		return false
+	}
+	if pledge.PledgeStatus.Cmp(big.NewInt(SPledgeNormal)) != 0 {
+		log.Info("checkLeaseAutoRenew", "address PledgeStatus is not normal", record.Address)
+		return false
+	}
+	lease, ok := pledge.Lease[record.Hash]
+	if !ok {
+		log.Info("checkLeaseAutoRenew", "hash not exist", record.Hash)
+		return false
+	}
+	if lease.Address != txSender || lease.DepositAddress != txSender {
+		log.Info("checkLeaseAutoRenew", "txSender is not lease renter", txSender)
+		return false
+	}
+	if lease.Status != LeaseNormal && lease.Status != LeaseBreach {
+		log.Info("checkLeaseAutoRenew", "lease Status can not renew", lease.Status)
+		return false
+	}
+	if renew, ok := s.LeaseAutoRenew[record.Hash]; ok && renew.Owner != txSender {
+		log.Info("checkLeaseAutoRenew", "instruction owner is not txSender", txSender)
+		return false
+	}
+	return true
+}
+
+func (s *Snapshot) updateLeaseAutoRenew(records []LeaseAutoRenewRecord, number *big.Int, db ethdb.Database) {
+	if records == nil || len(records) == 0 {
+		return
+	}
+	if s.StorageData.LeaseAutoRenew == nil {
+		s.StorageData.LeaseAutoRenew = make(map[common.Hash]*LeaseAutoRenew)
+	}
+	for _, item := range records {
+		if item.Duration.Sign() == 0 {
+			delete(s.StorageData.LeaseAutoRenew, item.Hash)
+			continue
+		}
+		if renew, ok := s.StorageData.LeaseAutoRenew[item.Hash]; ok {
+			renew.Duration = new(big.Int).Set(item.Duration)
+			renew.MaxPrice = new(big.Int).Set(item.MaxPrice)
+			renew.Deposit = new(big.Int).Add(renew.Deposit, item.Deposit)
+			renew.SRTAllowance = new(big.Int).Set(item.SRTAllowance)
+			renew.LastResult = ""
+			continue
+		}
+		s.StorageData.LeaseAutoRenew[item.Hash] = &LeaseAutoRenew{
+			Pledge:       item.Address,
+			Hash:         item.Hash,
+			Owner:        item.Owner,
+			Duration:     new(big.Int).Set(item.Duration),
+			MaxPrice:     new(big.Int).Set(item.MaxPrice),
+			Deposit:      new(big.Int).Set(item.Deposit),
+			SRTAllowance: new(big.Int).Set(item.SRTAllowance),
+			LastNumber:   new(big.Int).Set(number),
+		}
+	}
+}
+
+// leaseAutoRenewReleased reports whether the instruction can no longer renew
+// its lease: the lease was rescinded, expired or returned, or the storage
+// pledge exited.
+func (s *StorageData) leaseAutoRenewReleased(renew *LeaseAutoRenew) bool {
+	pledge, ok := s.StoragePledge[renew.Pledge]
+	if !ok {
+		return true
+	}
+	switch pledge.PledgeStatus.Int64() {
+	case SPledgeExit, SPledgeRemoving, SPledgeRetrun:
+		return true
+	}
+	lease, ok := pledge.Lease[renew.Hash]
+	if !ok {
+		return true
+	}
+	return lease.Status == LeaseUserRescind || lease.Status == LeaseExpiration || lease.Status == LeaseReturn
+}
+
+// releaseLeaseAutoRenew removes the instructions that can no longer renew
+// their lease in the daily storage settlement. The block producer passes the
+// state to pay the deposits back to the owners, the snapshot only removes the
+// instructions.
+func (s *StorageData) releaseLeaseAutoRenew(state *state.StateDB) {
+	if len(s.LeaseAutoRenew) == 0 {
+		return
+	}
+	hashes := make([]common.Hash, 0, len(s.LeaseAutoRenew))
+	for hash := range s.LeaseAutoRenew {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i].Bytes(), hashes[j].Bytes()) < 0 })
+	for _, hash := range hashes {
+		renew := s.LeaseAutoRenew[hash]
+		if !s.leaseAutoRenewReleased(renew) {
+			continue
+		}
+		delete(s.LeaseAutoRenew, hash)
+		if state != nil && renew.Deposit.Sign() > 0 {
+			setBalanceReason(state, balanceReasonAutoRenewRefund, "")
+			state.AddBalance(renew.Owner, renew.Deposit)
+		}
+		log.Info("releaseLeaseAutoRenew", "lease", hash, "owner", renew.Owner, "deposit", renew.Deposit)
+	}
+}
+
+// dealLeaseAutoRenew renews the leases with a standing instruction whose
+// renewal window is open, in the same way as a renewal request followed by
+// its pledge, keeping the stored file of the lease. It runs in the daily
+// storage settlement of both the block producer and the snapshot, so it only
+// depends on the storage data and the SRT of the snapshot.
+func (s *StorageData) dealLeaseAutoRenew(number uint64, blockPerday uint64, snap *Snapshot) {
+	if len(s.LeaseAutoRenew) == 0 {
+		return
+	}
+	hashes := make([]common.Hash, 0, len(s.LeaseAutoRenew))
+	for hash := range s.LeaseAutoRenew {
+		hashes = append(hashes, hash)
+	}
+	sort.Slice(hashes, func(i, j int) bool { return bytes.Compare(hashes[i].Bytes(), hashes[j].Bytes()) < 0 })
+	bigNumber := new(big.Int).SetUint64(number)
+	for _, hash := range hashes {
+		renew := s.LeaseAutoRenew[hash]
+		pledge, ok := s.StoragePledge[renew.Pledge]
+		if !ok {
+			continue
+		}
+		lease, ok := pledge.Lease[hash]
+		if !ok {
+			continue
+		}
+		first, ok := lease.LeaseList[hash]
+		if !ok || first.StartTime == nil || first.StartTime.Sign() == 0 {
+			continue
+		}
+		startTime := big.NewInt(0)
+		duration := big.NewInt(0)
+		pending := false
+		for _, detail := range lease.LeaseList {
+			if detail.Deposit.Sign() <= 0 {
+				pending = true
+			} else if detail.StartTime.Cmp(startTime) > 0 {
+				startTime = detail.StartTime
+				duration = new(big.Int).Mul(detail.Duration, new(big.Int).SetUint64(blockPerday))
+			}
+		}
+		if startTime.Sign() == 0 {
+			continue
+		}
+		reNewNumber := new(big.Int).Div(new(big.Int).Mul(duration, big.NewInt(rentRenewalExpires)), big.NewInt(100))
+		reNewNumber.Add(reNewNumber, startTime)
+		lEndNumber := new(big.Int).Add(startTime, duration)
+		if first.StartTime.Cmp(startTime) != 0 {
+			reNewNumber.Sub(reNewNumber, common.Big1)
+			lEndNumber.Sub(lEndNumber, common.Big1)
+		}
+		if reNewNumber.Cmp(bigNumber) > 0 || lEndNumber.Cmp(bigNumber) < 0 {
+			continue
+		}
+		renew.LastNumber = new(big.Int).Set(bigNumber)
+		if pending {
+			renew.LastResult = autoRenewPendingRenewal
+			continue
+		}
+		renew.LastResult = s.autoRenewLease(renew, pledge, lease, first, bigNumber, blockPerday, snap)
+		if renew.LastResult == autoRenewRenewed {
+			renew.Renewals++
+		}
+	}
+}
+
+func (s *StorageData) autoRenewLease(renew *LeaseAutoRenew, pledge *SPledge, lease *Lease, first *LeaseDetail, number *big.Int, blockPerday uint64, snap *Snapshot) string {
+	if pledge.PledgeStatus.Cmp(big.NewInt(SPledgeNormal)) != 0 || (lease.Status != LeaseNormal && lease.Status != LeaseBreach) {
+		return autoRenewNotRenewable
+	}
+	if renew.Duration.Cmp(snap.SystemConfig.Deposit[sscEnumMinimumRent]) < 0 || renew.Duration.Cmp(snap.SystemConfig.Deposit[sscEnumMaximumRent]) > 0 {
+		return autoRenewDurationRange
+	}
+	if lease.UnitPrice.Cmp(renew.MaxPrice) > 0 {
+		return autoRenewPriceTooHigh
+	}
+	srtAmount := new(big.Int).Mul(renew.Duration, lease.UnitPrice)
+	srtAmount = new(big.Int).Mul(srtAmount, lease.Capacity)
+	srtAmount = new(big.Int).Div(srtAmount, gbTob)
+	amount := new(big.Int).Div(new(big.Int).Mul(srtAmount, big.NewInt(10000)), big.NewInt(int64(snap.SystemConfig.ExchRate)))
+	if renew.Deposit.Cmp(amount) < 0 {
+		return autoRenewLowDeposit
+	}
+	if renew.SRTAllowance.Cmp(srtAmount) < 0 {
+		return autoRenewLowSRTAllowance
+	}
+	if snap.SRT == nil || snap.SRT.Get(lease.Address).Cmp(srtAmount) < 0 {
+		return autoRenewLowSRTBalance
+	}
+	if snap.SRT.Get(lease.Address).Cmp(srtAmount) > 0 {
+		snap.SRT.Sub(lease.Address, srtAmount)
+	} else {
+		snap.SRT.Del(lease.Address)
+	}
+	renew.Deposit = new(big.Int).Sub(renew.Deposit, amount)
+	renew.SRTAllowance = new(big.Int).Sub(renew.SRTAllowance, srtAmount)
+
+	startTime := new(big.Int).Mul(lease.Duration, new(big.Int).SetUint64(blockPerday))
+	startTime = new(big.Int).Add(startTime, first.StartTime)
+	startTime = new(big.Int).Add(startTime, big.NewInt(1))
+	requestHash := getHash(changeOxToUx(renew.Hash.String()) + number.String())
+	lease.LeaseList[requestHash] = &LeaseDetail{
+		RequestHash:                requestHash,
+		PledgeHash:                 requestHash,
+		RequestTime:                new(big.Int).Set(number),
+		StartTime:                  startTime,
+		Duration:                   new(big.Int).Set(renew.Duration),
+		Cost:                       srtAmount,
+		Deposit:                    amount,
+		ValidationFailureTotalTime: big.NewInt(0),
+	}
+	lease.Deposit = new(big.Int).Add(lease.Deposit, amount)
+	lease.Cost = new(big.Int).Add(lease.Cost, srtAmount)
+	lease.Duration = new(big.Int).Add(lease.Duration, renew.Duration)
+	lease.Status = LeaseNormal
+	s.accumulateLeaseDetailHash(renew.Pledge, renew.Hash, lease.LeaseList[requestHash])
+	log.Info("dealLeaseAutoRenew", "lease", renew.Hash, "duration", renew.Duration, "srt", srtAmount, "deposit", amount)
+	return autoRenewRenewed
+}
@@ -0,0 +1,290 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+var (
+	renewPledge = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	renewTenant = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	renewBuyer  = common.HexToAddress("0x4000000000000000000000000000000000000004")
+	renewLease  = common.HexToHash("0x3000000000000000000000000000000000000000000000000000000000000003")
+)
+
+const renewBlockPerDay = secondsPerDay / 3
+
+// newRenewSnapshot returns a snapshot holding a one GB lease of the tenant,
+// rented for 30 days from the given block at a unit price of 1, and the SRT
+// to renew it once.
+func newRenewSnapshot(start uint64) *Snapshot {
+	lease := &Lease{
+		Address:                     renewTenant,
+		DepositAddress:              renewTenant,
+		Capacity:                    new(big.Int).Set(gbTob),
+		Deposit:                     big.NewInt(30),
+		UnitPrice:                   big.NewInt(1),
+		Cost:                        big.NewInt(30),
+		Duration:                    big.NewInt(30),
+		StorageFile:                 make(map[common.Hash]*StorageFile),
+		LastVerificationTime:        big.NewInt(0),
+		LastVerificationSuccessTime: big.NewInt(0),
+		ValidationFailureTotalTime:  big.NewInt(0),
+		Status:                      LeaseNormal,
+		LeaseList: map[common.Hash]*LeaseDetail{
+			renewLease: {
+				RequestHash:                renewLease,
+				PledgeHash:                 renewLease,
+				RequestTime:                new(big.Int).SetUint64(start),
+				StartTime:                  new(big.Int).SetUint64(start),
+				Duration:                   big.NewInt(30),
+				Cost:                       big.NewInt(30),
+				Deposit:                    big.NewInt(30),
+				ValidationFailureTotalTime: big.NewInt(0),
+			},
+		},
+	}
+	pledge := &SPledge{
+		Address: renewPledge,
+		StorageSpaces: &SPledgeSpaces{
+			Address:                     renewPledge,
+			StorageCapacity:             new(big.Int).Mul(gbTob, big.NewInt(3)),
+			StorageFile:                 make(map[common.Hash]*StorageFile),
+			LastVerificationTime:        big.NewInt(0),
+			LastVerificationSuccessTime: big.NewInt(0),
+			ValidationFailureTotalTime:  big.NewInt(0),
+		},
+		Number:                      big.NewInt(0),
+		TotalCapacity:               new(big.Int).Mul(gbTob, big.NewInt(4)),
+		Bandwidth:                   big.NewInt(100),
+		Price:                       big.NewInt(1),
+		StorageSize:                 big.NewInt(0),
+		SpaceDeposit:                big.NewInt(0),
+		Lease:                       map[common.Hash]*Lease{renewLease: lease},
+		LastVerificationTime:        big.NewInt(0),
+		LastVerificationSuccessTime: big.NewInt(0),
+		ValidationFailureTotalTime:  big.NewInt(0),
+		PledgeStatus:                big.NewInt(SPledgeNormal),
+	}
+	srt, _ := NewDefaultSRTState()
+	srt.Set(renewTenant, big.NewInt(30))
+	snap := &Snapshot{
+		config: upgradedConfig(&params.AlienConfig{Period: 3}),
+		Period: 3,
+		SRT:    srt,
+		SystemConfig: SystemParameter{
+			ExchRate: 10000,
+			Deposit: map[uint32]*big.Int{
+				sscEnumMinimumRent: big.NewInt(1),
+				sscEnumMaximumRent: big.NewInt(365),
+			},
+		},
+		StorageData: &StorageData{
+			StoragePledge:  map[common.Address]*SPledge{renewPledge: pledge},
+			StorageEntrust: map[common.Address]*SEntrust{renewPledge: {Manager: renewTenant}},
+		},
+	}
+	snap.StorageData.accumulateLeaseHash(renewPledge, lease)
+	snap.StorageData.accumulateHeaderHash()
+	return snap
+}
+
+// renewBlock runs the storage transactions of a block through the engine and
+// applies the resulting records to the snapshot.
+type renewBlock struct {
+	snap     *Snapshot
+	state    *state.StateDB
+	number   uint64
+	extra    HeaderExtra
+	receipts []*types.Receipt
+}
+
+func (b *renewBlock) send(sender common.Address, data string) *types.Receipt {
+	tx := types.NewTransaction(uint64(len(b.receipts)), sender, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      tx.Hash(),
+		BlockNumber: new(big.Int).SetUint64(b.number),
+	}
+	b.receipts = append(b.receipts, receipt)
+	b.extra = (&Alien{}).processStorageCustomTx(strings.Split(data, ":"), b.extra, sender, tx, b.receipts, b.snap, new(big.Int).SetUint64(b.number), b.state, nil)
+	return receipt
+}
+
+func (b *renewBlock) apply() {
+	number := new(big.Int).SetUint64(b.number)
+	b.snap.updateLeaseAutoRenew(b.extra.LeaseAutoRenew, number, nil)
+}
+
+func autoRenewTx(days string, deposit string) string {
+	return "utg:1:" + utgRentAutoReNew + ":" + renewPledge.Hex() + ":" + renewLease.Hex() + ":" + days + ":1:" + deposit + ":30"
+}
+
+func autoRenewCancelTx() string {
+	return "utg:1:" + utgRentAutoReNew + ":" + renewPledge.Hex() + ":" + renewLease.Hex() + ":0"
+}
+
+func newRenewState(t *testing.T) *state.StateDB {
+	statedb, err := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	statedb.AddBalance(renewTenant, big.NewInt(1000))
+	statedb.AddBalance(renewBuyer, big.NewInt(1000))
+	return statedb
+}
+
+// TestLeaseAutoRenewRenewal registers an instruction and renews the lease when
+// its renewal window opens.
+func TestLeaseAutoRenewRenewal(t *testing.T) {
+	number := upgradeTestNumber + uint64(100)
+	start := number - 20*renewBlockPerDay
+	snap := newRenewSnapshot(start)
+	statedb := newRenewState(t)
+
+	block := &renewBlock{snap: snap, state: statedb, number: number}
+	if receipt := block.send(renewBuyer, autoRenewTx("30", "100")); len(receipt.Logs) != 0 {
+		t.Fatalf("instruction of another account accepted")
+	}
+	if receipt := block.send(renewTenant, autoRenewTx("30", "100")); len(receipt.Logs) != 1 {
+		t.Fatalf("instruction rejected")
+	}
+	block.apply()
+	if have := statedb.GetBalance(renewTenant); have.Int64() != 900 {
+		t.Errorf("tenant balance mismatch: have %v, want 900", have)
+	}
+	renew := snap.StorageData.LeaseAutoRenew[renewLease]
+	if renew == nil || renew.Deposit.Int64() != 100 {
+		t.Fatalf("instruction not registered: %+v", renew)
+	}
+	// Outside the renewal window nothing happens
+	snap.StorageData.dealLeaseAutoRenew(number, renewBlockPerDay, snap)
+	if renew.Renewals != 0 || renew.LastResult != "" {
+		t.Fatalf("lease renewed before its window: %d, %q", renew.Renewals, renew.LastResult)
+	}
+	renewal := start + 30*renewBlockPerDay - 1
+	snap.StorageData.dealLeaseAutoRenew(renewal, renewBlockPerDay, snap)
+	if renew.Renewals != 1 || renew.LastResult != autoRenewRenewed {
+		t.Fatalf("lease not renewed: %d, %q", renew.Renewals, renew.LastResult)
+	}
+	lease := snap.StorageData.StoragePledge[renewPledge].Lease[renewLease]
+	if lease.Duration.Int64() != 60 || len(lease.LeaseList) != 2 {
+		t.Errorf("lease not extended: duration %v, details %d", lease.Duration, len(lease.LeaseList))
+	}
+	if renew.Deposit.Int64() != 70 || renew.SRTAllowance.Sign() != 0 || snap.SRT.Get(renewTenant).Sign() != 0 {
+		t.Errorf("renewal not paid: deposit %v, allowance %v, srt %v", renew.Deposit, renew.SRTAllowance, snap.SRT.Get(renewTenant))
+	}
+	// The allowance is used up, the next window fails
+	snap.StorageData.dealLeaseAutoRenew(start+60*renewBlockPerDay-2, renewBlockPerDay, snap)
+	if renew.Renewals != 1 || renew.LastResult != autoRenewLowSRTAllowance {
+		t.Errorf("renewal without allowance: %d, %q", renew.Renewals, renew.LastResult)
+	}
+}
+
+// TestLeaseAutoRenewBlock checks that instructions are taken from the lease
+// auto renewal block of the engine config.
+func TestLeaseAutoRenewBlock(t *testing.T) {
+	number := uint64(1000)
+	snap := newRenewSnapshot(number - renewBlockPerDay)
+	statedb := newRenewState(t)
+
+	block := &renewBlock{snap: snap, state: statedb, number: number}
+	if receipt := block.send(renewTenant, autoRenewTx("30", "100")); len(receipt.Logs) != 0 {
+		t.Fatalf("instruction accepted before the configured block")
+	}
+	snap.config.LeaseAutoRenewBlock = new(big.Int).SetUint64(number)
+	if receipt := block.send(renewTenant, autoRenewTx("30", "100")); len(receipt.Logs) != 1 {
+		t.Fatalf("instruction rejected from the configured block")
+	}
+}
+
+// TestLeaseAutoRenewCancel cancels an instruction and gets the deposit back.
+func TestLeaseAutoRenewCancel(t *testing.T) {
+	number := upgradeTestNumber + uint64(100)
+	snap := newRenewSnapshot(number - renewBlockPerDay)
+	statedb := newRenewState(t)
+
+	block := &renewBlock{snap: snap, state: statedb, number: number}
+	block.send(renewTenant, autoRenewTx("30", "100"))
+	block.apply()
+
+	block = &renewBlock{snap: snap, state: statedb, number: number + 1}
+	if receipt := block.send(renewBuyer, autoRenewCancelTx()); len(receipt.Logs) != 0 {
+		t.Fatalf("cancellation by another account accepted")
+	}
+	if receipt := block.send(renewTenant, autoRenewCancelTx()); len(receipt.Logs) != 1 {
+		t.Fatalf("cancellation rejected")
+	}
+	block.apply()
+	if _, ok := snap.StorageData.LeaseAutoRenew[renewLease]; ok {
+		t.Errorf("instruction not removed")
+	}
+	if have := statedb.GetBalance(renewTenant); have.Int64() != 1000 {
+		t.Errorf("deposit not returned: have %v, want 1000", have)
+	}
+}
+
+// TestLeaseAutoRenewRelease checks that the instructions of expired leases
+// and exited storage pledges are released in the daily settlement.
+func TestLeaseAutoRenewRelease(t *testing.T) {
+	number := upgradeTestNumber + uint64(100)
+	snap := newRenewSnapshot(number - renewBlockPerDay)
+	statedb := newRenewState(t)
+
+	block := &renewBlock{snap: snap, state: statedb, number: number}
+	block.send(renewTenant, autoRenewTx("30", "100"))
+	block.apply()
+
+	// A renewable lease keeps its instruction
+	snap.StorageData.releaseLeaseAutoRenew(statedb)
+	if _, ok := snap.StorageData.LeaseAutoRenew[renewLease]; !ok {
+		t.Fatalf("instruction of a renewable lease released")
+	}
+	// The block producer pays the deposit of an expired lease back
+	lease := snap.StorageData.StoragePledge[renewPledge].Lease[renewLease]
+	lease.Status = LeaseExpiration
+	snap.StorageData.releaseLeaseAutoRenew(statedb)
+	if _, ok := snap.StorageData.LeaseAutoRenew[renewLease]; ok {
+		t.Errorf("instruction of an expired lease kept")
+	}
+	if have := statedb.GetBalance(renewTenant); have.Int64() != 1000 {
+		t.Errorf("deposit not returned: have %v, want 1000", have)
+	}
+	// The snapshot only removes the instruction of an exited pledge
+	lease.Status = LeaseNormal
+	block = &renewBlock{snap: snap, state: statedb, number: number + 1}
+	block.send(renewTenant, autoRenewTx("30", "100"))
+	block.apply()
+
+	snap.StorageData.StoragePledge[renewPledge].PledgeStatus = big.NewInt(SPledgeExit)
+	snap.StorageData.releaseLeaseAutoRenew(nil)
+	if _, ok := snap.StorageData.LeaseAutoRenew[renewLease]; ok {
+		t.Errorf("instruction of an exited pledge kept")
+	}
+	if have := statedb.GetBalance(renewTenant); have.Int64() != 900 {
+		t.Errorf("deposit paid by the snapshot: have %v, want 900", have)
+	}
+}
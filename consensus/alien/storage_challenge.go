@@ -0,0 +1,309 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/ethdb"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+)
+
+// maxChallengeSeedDelay bounds how far ahead of the challenge the seed block
+// of a lease challenge may be.
+const maxChallengeSeedDelay = 600
+
+// LeaseChallenge is a storage proof requested by the tenant of a lease. The
+// storage pledge answers with a proof over the root hash of the lease sampled
+// with the hash of the seed block, at the latest proofTimeOut blocks after it.
+type LeaseChallenge struct {
+	Pledge     common.Address `json:"pledge"`
+	Hash       common.Hash    `json:"hash"`
+	Challenger common.Address `json:"challenger"`
+	SeedNumber *big.Int       `json:"seednumber"`
+	Number     *big.Int       `json:"number"`
+}
+
+// LeaseChallengeRecord opens a challenge on a lease.
+type LeaseChallengeRecord struct {
+	Address    common.Address `json:"address"`
+	Hash       common.Hash    `json:"hash"`
+	Challenger common.Address `json:"challenger"`
+	SeedNumber *big.Int       `json:"seednumber"`
+}
+
+// LeaseChallengeProofRecord answers the open challenge on a lease.
+type LeaseChallengeProofRecord struct {
+	Address    common.Address `json:"address"`
+	Hash       common.Hash    `json:"hash"`
+	SeedNumber *big.Int       `json:"seednumber"`
+}
+
+func (c *LeaseChallenge) copy() *LeaseChallenge {
+	return &LeaseChallenge{
+		Pledge:     c.Pledge,
+		Hash:       c.Hash,
+		Challenger: c.Challenger,
+		SeedNumber: new(big.Int).Set(c.SeedNumber),
+		Number:     new(big.Int).Set(c.Number),
+	}
+}
+
+// deadline is the last block able to carry the answer to the challenge.
+func (c *LeaseChallenge) deadline() *big.Int {
+	return new(big.Int).Add(c.SeedNumber, proofTimeOut)
+}
+
+// processLeaseChallenge handles utg:1:stChallenge:<storage node>:<lease>:<seed block>.
+// The tenant picks a block after the current one, so the storage node can not
+// prepare the proof before the hash of that block is known. A lease has at
+// most one open challenge and is challenged at most once a day, as answering
+// costs the storage node a proof while challenging costs the tenant nothing.
+func (a *Alien) processLeaseChallenge(currentChallenge []LeaseChallengeRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, snap *Snapshot, number uint64) []LeaseChallengeRecord {
+	if len(txDataInfo) < 6 {
+		log.Warn("stChallenge", "parameter number", len(txDataInfo))
+		return currentChallenge
+	}
+	record := LeaseChallengeRecord{
+		Challenger: txSender,
+	}
+	postion := 3
+	if err := record.Address.UnmarshalText1([]byte(txDataInfo[postion])); err != nil {
+		log.Warn("stChallenge", "address", txDataInfo[postion])
+		return currentChallenge
+	}
+	postion++
+	record.Hash = common.HexToHash(txDataInfo[postion])
+	postion++
+	seedNumber, err := strconv.ParseUint(txDataInfo[postion], 10, 64)
+	if err != nil || seedNumber <= number || seedNumber > number+maxChallengeSeedDelay {
+		log.Warn("stChallenge", "seed block", txDataInfo[postion])
+		return currentChallenge
+	}
+	record.SeedNumber = new(big.Int).SetUint64(seedNumber)
+	for _, item := range currentChallenge {
+		if item.Hash == record.Hash {
+			log.Warn("stChallenge", "lease only one in one block", record.Hash)
+			return currentChallenge
+		}
+	}
+	if !snap.StorageData.checkLeaseChallenge(record, number, snap.getBlockPreDay()) {
+		log.Warn("stChallenge", "checkLeaseChallenge fail", record.Hash)
+		return currentChallenge
+	}
+	topics := make([]common.Hash, 3)
+	topics[0].UnmarshalText([]byte("0x3d26c3e2f40cf7bf897b4138f65a99a1a34d0d45f64fe39c45ca211bfc353456")) //web3.sha3("stChallenge(address)")
+	topics[1].SetBytes(record.Hash.Bytes())
+	topics[2].SetBytes(record.Address.Bytes())
+	a.addCustomerTxLog(tx, receipts, topics, []byte(record.SeedNumber.String()))
+	return append(currentChallenge, record)
+}
+
+func (s *StorageData) checkLeaseChallenge(record LeaseChallengeRecord, number uint64, blockPerDay uint64) bool {
+	pledge, ok := s.StoragePledge[record.Address]
+	if !ok {
+		log.Info("checkLeaseChallenge", "address not exist", record.Address)
+		return false
+	}
+	if pledge.PledgeStatus.Cmp(big.NewInt(SPledgeNormal)) != 0 {
+		log.Info("checkLeaseChallenge", "address PledgeStatus is not normal", record.Address)
+		return false
+	}
+	lease, ok := pledge.Lease[record.Hash]
+	if !ok {
+		log.Info("checkLeaseChallenge", "hash not exist", record.Hash)
+		return false
+	}
+	if lease.Status != LeaseNormal && lease.Status != LeaseBreach {
+		log.Info("checkLeaseChallenge", "lease Status can not be challenged", lease.Status)
+		return false
+	}
+	if lease.Address != record.Challenger {
+		log.Info("checkLeaseChallenge", "challenger is not the lease renter", record.Challenger)
+		return false
+	}
+	if _, ok := s.LeaseChallenge[record.Hash]; ok {
+		log.Info("checkLeaseChallenge", "lease has an open challenge", record.Hash)
+		return false
+	}
+	if last, ok := s.LeaseChallengeLast[record.Hash]; ok && number < last.Uint64()+blockPerDay {
+		log.Info("checkLeaseChallenge", "lease challenged within a day", last)
+		return false
+	}
+	return true
+}
+
+// processLeaseChallengeProof handles utg:1:stChallengeProof:<storage node>:<lease>:<proof>.
+// The proof has the format of the daily storage proof, sampled with the seed
+// block of the challenge and computed over the root hash of the lease. The
+// snapshot is the one of the parent block, the first ancestor searched for the
+// seed block.
+func (a *Alien) processLeaseChallengeProof(currentProof []LeaseChallengeProofRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, snap *Snapshot, number uint64, chain consensus.ChainHeaderReader) []LeaseChallengeProofRecord {
+	if len(txDataInfo) < 6 {
+		log.Warn("stChallengeProof", "parameter number", len(txDataInfo))
+		return currentProof
+	}
+	record := LeaseChallengeProofRecord{}
+	postion := 3
+	if err := record.Address.UnmarshalText1([]byte(txDataInfo[postion])); err != nil {
+		log.Warn("stChallengeProof", "address", txDataInfo[postion])
+		return currentProof
+	}
+	if record.Address != txSender {
+		log.Warn("stChallengeProof", "txSender no role", txSender)
+		return currentProof
+	}
+	postion++
+	record.Hash = common.HexToHash(txDataInfo[postion])
+	postion++
+	for _, item := range currentProof {
+		if item.Hash == record.Hash {
+			log.Warn("stChallengeProof", "lease only one in one block", record.Hash)
+			return currentProof
+		}
+	}
+	challenge, ok := snap.StorageData.LeaseChallenge[record.Hash]
+	if !ok || challenge.Pledge != record.Address {
+		log.Warn("stChallengeProof", "no open challenge", record.Hash)
+		return currentProof
+	}
+	if new(big.Int).SetUint64(number).Cmp(challenge.deadline()) > 0 {
+		log.Warn("stChallengeProof", "challenge timeout", challenge.deadline())
+		return currentProof
+	}
+	pledge, ok := snap.StorageData.StoragePledge[record.Address]
+	if !ok {
+		log.Warn("stChallengeProof", "address not exist", record.Address)
+		return currentProof
+	}
+	lease, ok := pledge.Lease[record.Hash]
+	if !ok {
+		log.Warn("stChallengeProof", "hash not exist", record.Hash)
+		return currentProof
+	}
+	if !verifyChallengeProof(txDataInfo[postion], challenge, lease.RootHash, chain, snap.Hash, number) {
+		log.Warn("stChallengeProof", "verify faild", lease.RootHash)
+		return currentProof
+	}
+	record.SeedNumber = new(big.Int).Set(challenge.SeedNumber)
+	topics := make([]common.Hash, 3)
+	topics[0].UnmarshalText([]byte("0x3c67ad789653ae141335372f29650fc888827763caba0106604ea8ceb5f87e0d")) //web3.sha3("stChallengeProof(address)")
+	topics[1].SetBytes(record.Hash.Bytes())
+	topics[2].SetBytes(record.Address.Bytes())
+	a.addCustomerTxLog(tx, receipts, topics, []byte(record.SeedNumber.String()))
+	return append(currentProof, record)
+}
+
+// verifyChallengeProof checks that the proof is sampled with the seed
+// block of the challenge and leads to the root hash of the lease. The seed
+// block is looked up among the ancestors of the block, walking back from its
+// parent, so the proof is verified on the branch the block belongs to.
+func verifyChallengeProof(proof string, challenge *LeaseChallenge, rootHash common.Hash, chain consensus.ChainHeaderReader, parent common.Hash, number uint64) bool {
+	verifyType := ""
+	verifyData := proof
+	if strings.HasPrefix(proof, "v1") {
+		verifyType = "v1"
+		verifyData = proof[3:]
+	}
+	pocs := strings.Split(verifyData, ",")
+	if len(pocs) < 10 {
+		log.Warn("verifyChallengeProof", "invalide poc string format")
+		return false
+	}
+	if pocs[0] != challenge.SeedNumber.String() {
+		log.Warn("verifyChallengeProof", "not sampled with the seed block", pocs[0])
+		return false
+	}
+	seedHeader := chain.GetHeader(parent, number-1)
+	for seedHeader != nil && seedHeader.Number.Cmp(challenge.SeedNumber) > 0 {
+		seedHeader = chain.GetHeader(seedHeader.ParentHash, seedHeader.Number.Uint64()-1)
+	}
+	if seedHeader == nil || seedHeader.Hash() != common.HexToHash(pocs[2]) || strconv.FormatUint(seedHeader.Nonce.Uint64(), 10) != pocs[1] {
+		log.Warn("verifyChallengeProof", "seed block not an ancestor of the block", pocs[2])
+		return false
+	}
+	if verifyType == "v1" {
+		return verifyStoragePocV1(proof, rootHash.String(), seedHeader.Nonce.Uint64())
+	}
+	return verifyStoragePoc(verifyData, rootHash.String(), seedHeader.Nonce.Uint64())
+}
+
+// updateLeaseChallenge opens the challenges of the block, closes the answered
+// ones and counts a validation failure on the lease of every challenge left
+// unanswered past its deadline. The block of the last challenge on a lease is
+// kept for a day to rate limit the challenges.
+func (s *Snapshot) updateLeaseChallenge(challenges []LeaseChallengeRecord, proofs []LeaseChallengeProofRecord, number *big.Int, db ethdb.Database) {
+	if s.StorageData.LeaseChallenge == nil && len(challenges) > 0 {
+		s.StorageData.LeaseChallenge = make(map[common.Hash]*LeaseChallenge)
+	}
+	if s.StorageData.LeaseChallengeLast == nil && len(challenges) > 0 {
+		s.StorageData.LeaseChallengeLast = make(map[common.Hash]*big.Int)
+	}
+	for hash, last := range s.StorageData.LeaseChallengeLast {
+		if number.Uint64() >= last.Uint64()+s.getBlockPreDay() {
+			delete(s.StorageData.LeaseChallengeLast, hash)
+		}
+	}
+	for _, item := range challenges {
+		s.StorageData.LeaseChallengeLast[item.Hash] = new(big.Int).Set(number)
+		s.StorageData.LeaseChallenge[item.Hash] = &LeaseChallenge{
+			Pledge:     item.Address,
+			Hash:       item.Hash,
+			Challenger: item.Challenger,
+			SeedNumber: new(big.Int).Set(item.SeedNumber),
+			Number:     new(big.Int).Set(number),
+		}
+	}
+	for _, item := range proofs {
+		if challenge, ok := s.StorageData.LeaseChallenge[item.Hash]; ok && challenge.SeedNumber.Cmp(item.SeedNumber) == 0 {
+			delete(s.StorageData.LeaseChallenge, item.Hash)
+		}
+	}
+	var expired []*LeaseChallenge
+	for _, challenge := range s.StorageData.LeaseChallenge {
+		if number.Cmp(challenge.deadline()) > 0 {
+			expired = append(expired, challenge)
+		}
+	}
+	if len(expired) == 0 {
+		return
+	}
+	sort.Slice(expired, func(i, j int) bool {
+		return bytes.Compare(expired[i].Hash.Bytes(), expired[j].Hash.Bytes()) < 0
+	})
+	for _, challenge := range expired {
+		delete(s.StorageData.LeaseChallenge, challenge.Hash)
+		pledge, ok := s.StorageData.StoragePledge[challenge.Pledge]
+		if !ok {
+			continue
+		}
+		lease, ok := pledge.Lease[challenge.Hash]
+		if !ok || (lease.Status != LeaseNormal && lease.Status != LeaseBreach) {
+			continue
+		}
+		lease.ValidationFailureTotalTime = new(big.Int).Add(lease.ValidationFailureTotalTime, big.NewInt(1))
+		s.StorageData.accumulateLeaseHash(challenge.Pledge, lease)
+	}
+	s.StorageData.accumulateHeaderHash()
+}
@@ -0,0 +1,362 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+var (
+	challengePledge = common.HexToAddress("0x1000000000000000000000000000000000000001")
+	challengeTenant = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	challengeLease  = common.HexToHash("0x3000000000000000000000000000000000000000000000000000000000000003")
+)
+
+// testHeaderReader serves the headers of a test chain by hash. Headers are
+// linked to their parents, so a block sees the seed blocks of its own branch.
+type testHeaderReader struct {
+	headers map[common.Hash]*types.Header
+}
+
+func (r *testHeaderReader) Config() *params.ChainConfig  { return nil }
+func (r *testHeaderReader) CurrentHeader() *types.Header { return nil }
+func (r *testHeaderReader) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if header, ok := r.headers[hash]; ok && header.Number.Uint64() == number {
+		return header
+	}
+	return nil
+}
+func (r *testHeaderReader) GetHeaderByNumber(number uint64) *types.Header { return nil }
+func (r *testHeaderReader) GetHeaderByHash(hash common.Hash) *types.Header {
+	return r.headers[hash]
+}
+
+// addHeader adds a header with the given nonce on top of the parent, or at the
+// given number without a known parent.
+func (r *testHeaderReader) addHeader(parent *types.Header, number uint64, nonce uint64) *types.Header {
+	header := &types.Header{
+		Number:     new(big.Int).SetUint64(number),
+		Nonce:      types.EncodeNonce(nonce),
+		Difficulty: big.NewInt(1),
+	}
+	if parent != nil {
+		header.ParentHash = parent.Hash()
+		header.Number.Add(parent.Number, common.Big1)
+	}
+	r.headers[header.Hash()] = header
+	return header
+}
+
+// challengeLeaves are the two file blocks stored under the lease.
+func challengeLeaves(data string) (string, string) {
+	return Sha1([]byte(data + "0")), Sha1([]byte(data + "1"))
+}
+
+// challengeRootHash is the root hash of a file of two blocks.
+func challengeRootHash(data string) common.Hash {
+	leaf0, leaf1 := challengeLeaves(data)
+	return common.HexToHash(Hash(leaf0, leaf1, ""))
+}
+
+// challengeProof builds the storage proof of a file of two blocks sampled
+// with the seed header. A single sample position keeps the proof short.
+func challengeProof(seed *types.Header, data string) string {
+	b0 := Sha1([]byte("b0"))
+	b1 := Sha1([]byte("b1"))
+	leaf0, leaf1 := challengeLeaves(data)
+	return strings.Join([]string{
+		seed.Number.String(),
+		strconv.FormatUint(seed.Nonce.Uint64(), 10),
+		seed.Hash().Hex(),
+		"0",
+		"1024",
+		"1",
+		b0,
+		b1,
+		Hash(b0, b1, "0"),
+		"",
+		leaf0,
+		leaf1,
+		Hash(leaf0, leaf1, ""),
+	}, ",")
+}
+
+func newChallengeSnapshot() *Snapshot {
+	lease := &Lease{
+		Address:                     challengeTenant,
+		DepositAddress:              challengeTenant,
+		Capacity:                    big.NewInt(1024),
+		RootHash:                    challengeRootHash("lease"),
+		Deposit:                     big.NewInt(0),
+		UnitPrice:                   big.NewInt(1),
+		Cost:                        big.NewInt(0),
+		Duration:                    big.NewInt(30),
+		StorageFile:                 make(map[common.Hash]*StorageFile),
+		LeaseList:                   make(map[common.Hash]*LeaseDetail),
+		LastVerificationTime:        big.NewInt(0),
+		LastVerificationSuccessTime: big.NewInt(0),
+		ValidationFailureTotalTime:  big.NewInt(0),
+		Status:                      LeaseNormal,
+	}
+	pledge := &SPledge{
+		Address: challengePledge,
+		StorageSpaces: &SPledgeSpaces{
+			Address:                     challengePledge,
+			StorageCapacity:             big.NewInt(3072),
+			StorageFile:                 make(map[common.Hash]*StorageFile),
+			LastVerificationTime:        big.NewInt(0),
+			LastVerificationSuccessTime: big.NewInt(0),
+			ValidationFailureTotalTime:  big.NewInt(0),
+		},
+		Number:                      big.NewInt(0),
+		TotalCapacity:               big.NewInt(4096),
+		Bandwidth:                   big.NewInt(100),
+		Price:                       big.NewInt(1),
+		StorageSize:                 big.NewInt(0),
+		SpaceDeposit:                big.NewInt(0),
+		Lease:                       map[common.Hash]*Lease{challengeLease: lease},
+		LastVerificationTime:        big.NewInt(0),
+		LastVerificationSuccessTime: big.NewInt(0),
+		ValidationFailureTotalTime:  big.NewInt(0),
+		PledgeStatus:                big.NewInt(SPledgeNormal),
+	}
+	snap := &Snapshot{
+		config: upgradedConfig(&params.AlienConfig{Period: 3}),
+		StorageData: &StorageData{
+			StoragePledge: map[common.Address]*SPledge{challengePledge: pledge},
+		},
+	}
+	snap.StorageData.accumulateLeaseHash(challengePledge, lease)
+	snap.StorageData.accumulateHeaderHash()
+	return snap
+}
+
+// challengeBlock runs the custom transactions of a block through the storage
+// transaction dispatch and applies the resulting records to the snapshot.
+type challengeBlock struct {
+	alien    *Alien
+	snap     *Snapshot
+	chain    *testHeaderReader
+	parent   *types.Header
+	number   uint64
+	extra    HeaderExtra
+	receipts []*types.Receipt
+}
+
+func (b *challengeBlock) send(sender common.Address, data string) *types.Receipt {
+	if b.parent != nil {
+		// The block is processed on the snapshot of its parent
+		b.snap.Hash, b.snap.Number = b.parent.Hash(), b.parent.Number.Uint64()
+	}
+	tx := types.NewTransaction(uint64(len(b.receipts)), sender, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      tx.Hash(),
+		BlockNumber: new(big.Int).SetUint64(b.number),
+	}
+	b.receipts = append(b.receipts, receipt)
+	b.extra = b.alien.processStorageCustomTx(strings.Split(data, ":"), b.extra, sender, tx, b.receipts, b.snap, new(big.Int).SetUint64(b.number), nil, b.chain)
+	return receipt
+}
+
+func (b *challengeBlock) apply() {
+	b.snap.updateLeaseChallenge(b.extra.LeaseChallenge, b.extra.LeaseChallengeProof, new(big.Int).SetUint64(b.number), nil)
+}
+
+func challengeTx(seed uint64) string {
+	return "utg:1:" + utgRentChallenge + ":" + challengePledge.Hex() + ":" + challengeLease.Hex() + ":" + strconv.FormatUint(seed, 10)
+}
+
+func challengeProofTx(proof string) string {
+	return "utg:1:" + utgRentChallengeProof + ":" + challengePledge.Hex() + ":" + challengeLease.Hex() + ":" + proof
+}
+
+func TestLeaseChallengeAnswered(t *testing.T) {
+	snap := newChallengeSnapshot()
+	chain := &testHeaderReader{headers: make(map[common.Hash]*types.Header)}
+	alien := &Alien{}
+	number := upgradeTestNumber + uint64(100)
+	seed := number + 10
+	lease := snap.StorageData.StoragePledge[challengePledge].Lease[challengeLease]
+
+	block := &challengeBlock{alien: alien, snap: snap, chain: chain, number: number}
+	if receipt := block.send(challengePledge, challengeTx(seed)); len(receipt.Logs) != 0 {
+		t.Fatalf("challenge from the storage node accepted")
+	}
+	if receipt := block.send(challengeTenant, challengeTx(number)); len(receipt.Logs) != 0 {
+		t.Fatalf("challenge with a past seed block accepted")
+	}
+	if receipt := block.send(challengeTenant, challengeTx(number+maxChallengeSeedDelay+1)); len(receipt.Logs) != 0 {
+		t.Fatalf("challenge with a distant seed block accepted")
+	}
+	if receipt := block.send(challengeTenant, challengeTx(seed)); len(receipt.Logs) != 1 {
+		t.Fatalf("challenge rejected")
+	}
+	if len(block.extra.LeaseChallenge) != 1 {
+		t.Fatalf("challenge records: have %d, want 1", len(block.extra.LeaseChallenge))
+	}
+	if err := verifyLeaseChallenge(block.extra.LeaseChallenge, block.extra.LeaseChallenge); err != nil {
+		t.Fatalf("challenge records differ: %v", err)
+	}
+	block.apply()
+	challenge, ok := snap.StorageData.LeaseChallenge[challengeLease]
+	if !ok {
+		t.Fatalf("challenge not opened")
+	}
+	if challenge.Challenger != challengeTenant || challenge.SeedNumber.Uint64() != seed || challenge.Number.Uint64() != number {
+		t.Fatalf("challenge mismatch: %+v", challenge)
+	}
+
+	block = &challengeBlock{alien: alien, snap: snap, chain: chain, number: number + 1}
+	if receipt := block.send(challengeTenant, challengeTx(seed+1)); len(receipt.Logs) != 0 {
+		t.Fatalf("second challenge on the lease accepted")
+	}
+	block.apply()
+
+	// The proof can not be made before the seed block is known.
+	early := &types.Header{Number: new(big.Int).SetUint64(seed), Nonce: types.EncodeNonce(7), Difficulty: big.NewInt(1)}
+	head := chain.addHeader(nil, number+4, 0)
+	block = &challengeBlock{alien: alien, snap: snap, chain: chain, parent: head, number: number + 5}
+	if receipt := block.send(challengePledge, challengeProofTx(challengeProof(early, "lease"))); len(receipt.Logs) != 0 {
+		t.Fatalf("proof with an unknown seed block accepted")
+	}
+	block.apply()
+
+	for head.Number.Uint64() < seed-1 {
+		head = chain.addHeader(head, 0, 0)
+	}
+	other := head
+	seedHeader := chain.addHeader(other, 0, 7)
+	side := chain.addHeader(other, 0, 9)
+	block = &challengeBlock{alien: alien, snap: snap, chain: chain, parent: seedHeader, number: seed + 1}
+	if receipt := block.send(challengePledge, challengeProofTx(challengeProof(side, "lease"))); len(receipt.Logs) != 0 {
+		t.Fatalf("proof sampled with a side chain block accepted")
+	}
+	if receipt := block.send(challengeTenant, challengeProofTx(challengeProof(seedHeader, "lease"))); len(receipt.Logs) != 0 {
+		t.Fatalf("proof from the tenant accepted")
+	}
+	if receipt := block.send(challengePledge, challengeProofTx(challengeProof(other, "lease"))); len(receipt.Logs) != 0 {
+		t.Fatalf("proof sampled with another block accepted")
+	}
+	if receipt := block.send(challengePledge, challengeProofTx(challengeProof(seedHeader, "other"))); len(receipt.Logs) != 0 {
+		t.Fatalf("proof over another root hash accepted")
+	}
+	// On the side branch only the proof sampled with its own seed block holds
+	fork := &challengeBlock{alien: alien, snap: snap, chain: chain, parent: chain.addHeader(side, 0, 0), number: seed + 2}
+	if receipt := fork.send(challengePledge, challengeProofTx(challengeProof(seedHeader, "lease"))); len(receipt.Logs) != 0 {
+		t.Fatalf("proof sampled with a block of another branch accepted")
+	}
+	if receipt := fork.send(challengePledge, challengeProofTx(challengeProof(side, "lease"))); len(receipt.Logs) != 1 {
+		t.Fatalf("proof on the side branch rejected")
+	}
+	if receipt := block.send(challengePledge, challengeProofTx(challengeProof(seedHeader, "lease"))); len(receipt.Logs) != 1 {
+		t.Fatalf("proof rejected")
+	}
+	if len(block.extra.LeaseChallengeProof) != 1 || block.extra.LeaseChallengeProof[0].SeedNumber.Uint64() != seed {
+		t.Fatalf("proof records mismatch: %+v", block.extra.LeaseChallengeProof)
+	}
+	block.apply()
+	if _, ok := snap.StorageData.LeaseChallenge[challengeLease]; ok {
+		t.Fatalf("answered challenge still open")
+	}
+
+	leaseHash := lease.Hash
+	block = &challengeBlock{alien: alien, snap: snap, chain: chain, number: seed + proofTimeOut.Uint64() + 1}
+	block.apply()
+	if lease.ValidationFailureTotalTime.Sign() != 0 {
+		t.Fatalf("answered challenge counted as failure: %v", lease.ValidationFailureTotalTime)
+	}
+	if lease.Hash != leaseHash {
+		t.Fatalf("lease hash changed")
+	}
+}
+
+func TestLeaseChallengeUnanswered(t *testing.T) {
+	snap := newChallengeSnapshot()
+	chain := &testHeaderReader{headers: make(map[common.Hash]*types.Header)}
+	alien := &Alien{}
+	number := upgradeTestNumber + uint64(100)
+	seed := number + 10
+	lease := snap.StorageData.StoragePledge[challengePledge].Lease[challengeLease]
+	leaseHash := lease.Hash
+	rootHash := snap.StorageData.Hash
+
+	block := &challengeBlock{alien: alien, snap: snap, chain: chain, number: number}
+	block.send(challengeTenant, challengeTx(seed))
+	block.apply()
+
+	// The challenge stays open until its deadline.
+	deadline := seed + proofTimeOut.Uint64()
+	seedHeader := chain.addHeader(nil, seed, 11)
+	block = &challengeBlock{alien: alien, snap: snap, chain: chain, number: deadline}
+	block.apply()
+	if _, ok := snap.StorageData.LeaseChallenge[challengeLease]; !ok {
+		t.Fatalf("challenge closed before its deadline")
+	}
+	if lease.ValidationFailureTotalTime.Sign() != 0 {
+		t.Fatalf("failure counted before the deadline")
+	}
+
+	// A late proof is rejected and the challenge counts against the lease.
+	block = &challengeBlock{alien: alien, snap: snap, chain: chain, number: deadline + 1}
+	if receipt := block.send(challengePledge, challengeProofTx(challengeProof(seedHeader, "lease"))); len(receipt.Logs) != 0 {
+		t.Fatalf("late proof accepted")
+	}
+	block.apply()
+	if _, ok := snap.StorageData.LeaseChallenge[challengeLease]; ok {
+		t.Fatalf("expired challenge still open")
+	}
+	if lease.ValidationFailureTotalTime.Cmp(big.NewInt(1)) != 0 {
+		t.Fatalf("validation failures: have %v, want 1", lease.ValidationFailureTotalTime)
+	}
+	if lease.Hash == leaseHash || snap.StorageData.Hash == rootHash {
+		t.Fatalf("storage hashes not updated")
+	}
+
+	// The lease can be challenged again a day after the last challenge.
+	blockPerDay := snap.getBlockPreDay()
+	block = &challengeBlock{alien: alien, snap: snap, chain: chain, number: number + blockPerDay - 1}
+	if receipt := block.send(challengeTenant, challengeTx(number+blockPerDay)); len(receipt.Logs) != 0 {
+		t.Fatalf("challenge within a day of the last one accepted")
+	}
+	block.apply()
+	again := number + blockPerDay
+	block = &challengeBlock{alien: alien, snap: snap, chain: chain, number: again}
+	if receipt := block.send(challengeTenant, challengeTx(again+1)); len(receipt.Logs) != 1 {
+		t.Fatalf("challenge a day after the last one rejected")
+	}
+	block.apply()
+	if last := snap.StorageData.LeaseChallengeLast[challengeLease]; last == nil || last.Uint64() != again {
+		t.Fatalf("last challenge block: have %v, want %d", last, again)
+	}
+
+	// The copy of the storage data keeps the open challenges.
+	clone := snap.StorageData.copy()
+	if challenge, ok := clone.LeaseChallenge[challengeLease]; !ok || challenge.SeedNumber.Uint64() != again+1 {
+		t.Fatalf("challenge not copied")
+	}
+	if last := clone.LeaseChallengeLast[challengeLease]; last == nil || last.Uint64() != again {
+		t.Fatalf("last challenge block not copied")
+	}
+}
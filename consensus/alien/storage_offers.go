@@ -0,0 +1,226 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+)
+
+const (
+	storageOfferSortPrice        = "price"
+	storageOfferSortCapacity     = "capacity"
+	storageOfferSortBandwidth    = "bandwidth"
+	storageOfferSortVerification = "verification"
+
+	defaultStorageOfferLimit = 20
+	maxStorageOfferLimit     = 100
+)
+
+var (
+	errRentCapacityTooSmall = errors.New("rent capacity less than minimum rent space")
+	errRentDurationRange    = errors.New("rent duration out of range")
+	errUnknownOfferSort     = errors.New("unknown storage offer sort")
+)
+
+// StorageOfferCriteria filters and orders the storage pledges returned by
+// alien_findStorageOffers. Unset fields do not filter.
+type StorageOfferCriteria struct {
+	Capacity         *big.Int `json:"capacity"`         // space to rent in bytes, at least minRentSpace
+	Duration         uint64   `json:"duration"`         // rent days, within the configured rent range
+	MaxPrice         *big.Int `json:"maxprice"`         // highest acceptable price per GB and day
+	MinBandwidth     *big.Int `json:"minbandwidth"`     // lowest acceptable bandwidth
+	MinVerifySuccess uint64   `json:"minverifysuccess"` // lowest capacity percentage verified today
+	SortBy           string   `json:"sortby"`           // price, capacity, bandwidth or verification
+	Offset           uint64   `json:"offset"`
+	Limit            uint64   `json:"limit"`
+}
+
+// StorageOffer is a storage pledge able to take a rent request.
+type StorageOffer struct {
+	Address                     common.Address `json:"address"`
+	Price                       *big.Int       `json:"price"`
+	TotalCapacity               *big.Int       `json:"totalcapacity"`
+	FreeCapacity                *big.Int       `json:"freecapacity"`
+	Bandwidth                   *big.Int       `json:"bandwidth"`
+	VerifySuccess               *big.Int       `json:"verifysuccess"`
+	LastVerificationSuccessTime *big.Int       `json:"lastverificationsuccesstime"`
+	Cost                        *big.Int       `json:"cost,omitempty"`
+}
+
+// StorageOffers is a page of storage offers.
+type StorageOffers struct {
+	Number uint64          `json:"number"`
+	Total  int             `json:"total"`
+	Offers []*StorageOffer `json:"offers"`
+}
+
+// FindStorageOffers lists the storage pledges with enough free capacity for a
+// rent request matching the criteria at the current block, best first.
+func (api *API) FindStorageOffers(criteria *StorageOfferCriteria) (*StorageOffers, error) {
+	log.Info("api FindStorageOffers", "criteria", criteria)
+	if criteria == nil {
+		criteria = &StorageOfferCriteria{}
+	}
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snapshot, err := api.getSnapshotCache(header)
+	if err != nil {
+		log.Warn("Fail to FindStorageOffers", "err", err)
+		return nil, errUnknownBlock
+	}
+	capacity := minRentSpace
+	if criteria.Capacity != nil {
+		if criteria.Capacity.Cmp(minRentSpace) < 0 {
+			return nil, errRentCapacityTooSmall
+		}
+		capacity = criteria.Capacity
+	}
+	if criteria.Duration != 0 {
+		duration := new(big.Int).SetUint64(criteria.Duration)
+		if duration.Cmp(snapshot.SystemConfig.Deposit[sscEnumMinimumRent]) < 0 || duration.Cmp(snapshot.SystemConfig.Deposit[sscEnumMaximumRent]) > 0 {
+			return nil, errRentDurationRange
+		}
+	}
+	less, err := storageOfferLess(criteria.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	number := header.Number.Uint64()
+	verifySuccess := api.calStorageVerifyPercentage(number, snapshot.getBlockPreDay(), snapshot.StorageData)
+	var offers []*StorageOffer
+	for address, pledge := range snapshot.StorageData.StoragePledge {
+		if pledge.PledgeStatus.Cmp(big.NewInt(SPledgeNormal)) != 0 {
+			continue
+		}
+		free := storageOfferFreeCapacity(pledge, number+1)
+		if free.Cmp(capacity) < 0 {
+			continue
+		}
+		if criteria.MaxPrice != nil && pledge.Price.Cmp(criteria.MaxPrice) > 0 {
+			continue
+		}
+		if criteria.MinBandwidth != nil && pledge.Bandwidth.Cmp(criteria.MinBandwidth) < 0 {
+			continue
+		}
+		success := verifySuccess[address]
+		if success == nil {
+			success = new(big.Int)
+		}
+		if success.Cmp(new(big.Int).SetUint64(criteria.MinVerifySuccess)) < 0 {
+			continue
+		}
+		offer := &StorageOffer{
+			Address:                     address,
+			Price:                       new(big.Int).Set(pledge.Price),
+			TotalCapacity:               new(big.Int).Set(pledge.TotalCapacity),
+			FreeCapacity:                free,
+			Bandwidth:                   new(big.Int).Set(pledge.Bandwidth),
+			VerifySuccess:               new(big.Int).Set(success),
+			LastVerificationSuccessTime: new(big.Int).Set(pledge.LastVerificationSuccessTime),
+		}
+		if criteria.Duration != 0 {
+			cost := new(big.Int).Mul(new(big.Int).SetUint64(criteria.Duration), pledge.Price)
+			cost.Mul(cost, capacity)
+			offer.Cost = cost.Div(cost, gbTob)
+		}
+		offers = append(offers, offer)
+	}
+	sort.Slice(offers, func(i, j int) bool { return less(offers[i], offers[j]) })
+
+	limit := criteria.Limit
+	if limit == 0 {
+		limit = defaultStorageOfferLimit
+	}
+	if limit > maxStorageOfferLimit {
+		limit = maxStorageOfferLimit
+	}
+	result := &StorageOffers{
+		Number: number,
+		Total:  len(offers),
+		Offers: make([]*StorageOffer, 0),
+	}
+	if criteria.Offset < uint64(len(offers)) {
+		end := criteria.Offset + limit
+		if end > uint64(len(offers)) {
+			end = uint64(len(offers))
+		}
+		result.Offers = offers[criteria.Offset:end]
+	}
+	return result, nil
+}
+
+// storageOfferFreeCapacity returns the space of a storage pledge that a rent
+// request can still take: the total capacity minus the capacity of its active
+// leases, bounded by the unrented storage space and keeping the reserved space
+// once required.
+func storageOfferFreeCapacity(pledge *SPledge, number uint64) *big.Int {
+	free := new(big.Int).Set(pledge.TotalCapacity)
+	for _, lease := range pledge.Lease {
+		if lease.Status == LeaseNormal || lease.Status == LeaseBreach {
+			free.Sub(free, lease.Capacity)
+		}
+	}
+	if pledge.StorageSpaces != nil && pledge.StorageSpaces.StorageCapacity.Cmp(free) < 0 {
+		free.Set(pledge.StorageSpaces.StorageCapacity)
+	}
+	if isGEPosAutoExitPunishChange(number) {
+		free.Sub(free, rentLeftSpace)
+	}
+	if free.Sign() < 0 {
+		free.SetUint64(0)
+	}
+	return free
+}
+
+// storageOfferLess returns the ordering of the storage offers: the requested
+// key first, then lower price, more free capacity, more bandwidth and better
+// verification, and the address to keep the order stable.
+func storageOfferLess(sortBy string) (func(a, b *StorageOffer) bool, error) {
+	keys := []func(a, b *StorageOffer) int{
+		func(a, b *StorageOffer) int { return a.Price.Cmp(b.Price) },
+		func(a, b *StorageOffer) int { return b.FreeCapacity.Cmp(a.FreeCapacity) },
+		func(a, b *StorageOffer) int { return b.Bandwidth.Cmp(a.Bandwidth) },
+		func(a, b *StorageOffer) int { return b.VerifySuccess.Cmp(a.VerifySuccess) },
+	}
+	switch sortBy {
+	case "", storageOfferSortPrice:
+	case storageOfferSortCapacity:
+		keys[0], keys[1] = keys[1], keys[0]
+	case storageOfferSortBandwidth:
+		keys[0], keys[2] = keys[2], keys[0]
+	case storageOfferSortVerification:
+		keys[0], keys[3] = keys[3], keys[0]
+	default:
+		return nil, errUnknownOfferSort
+	}
+	return func(a, b *StorageOffer) bool {
+		for _, key := range keys {
+			if c := key(a, b); c != 0 {
+				return c < 0
+			}
+		}
+		return bytes.Compare(a.Address.Bytes(), b.Address.Bytes()) < 0
+	}, nil
+}
@@ -0,0 +1,192 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"container/list"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+// offerTestChain is a chain whose head is the block the offers are found at.
+type offerTestChain struct {
+	consensus.ChainHeaderReader
+	head *types.Header
+}
+
+func (c *offerTestChain) CurrentHeader() *types.Header { return c.head }
+
+// offerGB returns the given number of GB in bytes.
+func offerGB(n int64) *big.Int {
+	return new(big.Int).Mul(big.NewInt(n), gbTob)
+}
+
+// newOfferPledge creates a storage pledge with an unrented space of the given
+// capacity, of which verified GB passed the verification at the given block.
+func newOfferPledge(price int64, total int64, unrented int64, bandwidth int64, verified int64, verifiedAt uint64) *SPledge {
+	return &SPledge{
+		StorageSpaces: &SPledgeSpaces{
+			StorageCapacity: offerGB(unrented),
+			StorageFile: map[common.Hash]*StorageFile{
+				common.HexToHash("0x01"): {Capacity: offerGB(verified), LastVerificationSuccessTime: new(big.Int).SetUint64(verifiedAt)},
+			},
+		},
+		TotalCapacity:               offerGB(total),
+		Bandwidth:                   big.NewInt(bandwidth),
+		Price:                       big.NewInt(price),
+		Lease:                       make(map[common.Hash]*Lease),
+		LastVerificationSuccessTime: new(big.Int).SetUint64(verifiedAt),
+		PledgeStatus:                big.NewInt(SPledgeNormal),
+	}
+}
+
+func TestFindStorageOffers(t *testing.T) {
+	var (
+		number   = uint64(PosAutoExitPunishChangeNumber + 100000)
+		head     = &types.Header{Number: new(big.Int).SetUint64(number)}
+		snap     = &Snapshot{config: &params.AlienConfig{Period: 10}}
+		today    = number / snap.getBlockPreDay() * snap.getBlockPreDay()
+		cheap    = common.HexToAddress("0x1100000000000000000000000000000000000011")
+		fast     = common.HexToAddress("0x2200000000000000000000000000000000000022")
+		verified = common.HexToAddress("0x3300000000000000000000000000000000000033")
+		small    = common.HexToAddress("0x4400000000000000000000000000000000000044")
+		exiting  = common.HexToAddress("0x5500000000000000000000000000000000000055")
+	)
+	snap.SystemConfig.Deposit = map[uint32]*big.Int{
+		sscEnumMinimumRent: big.NewInt(30),
+		sscEnumMaximumRent: big.NewInt(365),
+	}
+	snap.StorageData = &StorageData{StoragePledge: map[common.Address]*SPledge{
+		// Free capacity bounded by the unrented space: 2500 GB
+		cheap: newOfferPledge(5, 3000, 2500, 50, 1500, today),
+		// Free capacity 1100 GB, verified yesterday
+		fast: newOfferPledge(5, 1100, 1100, 200, 1100, today-1),
+		// Free capacity 2000 GB less the active lease of 500 GB
+		verified: newOfferPledge(10, 2000, 2000, 100, 2000, today),
+		// Free capacity below the minimum rent space once the reserve is kept
+		small: newOfferPledge(1, 1028, 1028, 500, 1028, today),
+		// Not taking rent requests
+		exiting: newOfferPledge(1, 5000, 5000, 500, 5000, today),
+	}}
+	snap.StorageData.StoragePledge[verified].Lease[common.HexToHash("0x0a")] = &Lease{Capacity: offerGB(500), Status: LeaseNormal}
+	snap.StorageData.StoragePledge[verified].Lease[common.HexToHash("0x0b")] = &Lease{Capacity: offerGB(800), Status: LeaseReturn}
+	snap.StorageData.StoragePledge[exiting].PledgeStatus = big.NewInt(SPledgeExit)
+
+	api := &API{chain: &offerTestChain{head: head}, alien: &Alien{}, sCache: list.New()}
+	api.sCache.PushBack(SnapCache{number: number, s: snap})
+
+	find := func(criteria *StorageOfferCriteria) ([]common.Address, *StorageOffers) {
+		offers, err := api.FindStorageOffers(criteria)
+		if err != nil {
+			t.Fatalf("criteria %+v: %v", criteria, err)
+		}
+		var addresses []common.Address
+		for _, offer := range offers.Offers {
+			addresses = append(addresses, offer.Address)
+		}
+		return addresses, offers
+	}
+
+	// Free capacity keeps the reserved space and skips ended leases
+	addresses, offers := find(nil)
+	if want := []common.Address{cheap, fast, verified}; !reflect.DeepEqual(addresses, want) {
+		t.Fatalf("offers mismatch: have %x, want %x", addresses, want)
+	}
+	if offers.Number != number || offers.Total != 3 {
+		t.Errorf("page mismatch: number %d, total %d", offers.Number, offers.Total)
+	}
+	for i, free := range []int64{2495, 1095, 1495} {
+		if offer := offers.Offers[i]; offer.FreeCapacity.Cmp(offerGB(free)) != 0 {
+			t.Errorf("offer %x: free capacity %v, want %d GB", offer.Address, offer.FreeCapacity, free)
+		}
+	}
+	for i, success := range []int64{50, 0, 100} {
+		if offer := offers.Offers[i]; offer.VerifySuccess.Int64() != success || offer.Cost != nil {
+			t.Errorf("offer %x: verified %v, cost %v, want %d and no cost", offer.Address, offer.VerifySuccess, offer.Cost, success)
+		}
+	}
+
+	// Rankings break ties by the remaining keys
+	rankings := map[string][]common.Address{
+		storageOfferSortPrice:        {cheap, fast, verified},
+		storageOfferSortCapacity:     {cheap, verified, fast},
+		storageOfferSortBandwidth:    {fast, verified, cheap},
+		storageOfferSortVerification: {verified, cheap, fast},
+	}
+	for sortBy, want := range rankings {
+		if addresses, _ := find(&StorageOfferCriteria{SortBy: sortBy}); !reflect.DeepEqual(addresses, want) {
+			t.Errorf("sort by %s: have %x, want %x", sortBy, addresses, want)
+		}
+	}
+
+	// Filters
+	filters := []struct {
+		criteria *StorageOfferCriteria
+		want     []common.Address
+	}{
+		{&StorageOfferCriteria{MaxPrice: big.NewInt(5)}, []common.Address{cheap, fast}},
+		{&StorageOfferCriteria{MinBandwidth: big.NewInt(100)}, []common.Address{fast, verified}},
+		{&StorageOfferCriteria{MinVerifySuccess: 50}, []common.Address{cheap, verified}},
+		{&StorageOfferCriteria{Capacity: offerGB(1200)}, []common.Address{cheap, verified}},
+		{&StorageOfferCriteria{Capacity: offerGB(2000), MaxPrice: big.NewInt(5), MinVerifySuccess: 50}, []common.Address{cheap}},
+		{&StorageOfferCriteria{MaxPrice: big.NewInt(1)}, nil},
+	}
+	for _, filter := range filters {
+		if addresses, _ := find(filter.criteria); !reflect.DeepEqual(addresses, filter.want) {
+			t.Errorf("criteria %+v: have %x, want %x", filter.criteria, addresses, filter.want)
+		}
+	}
+
+	// The cost covers the rented capacity for the duration
+	_, offers = find(&StorageOfferCriteria{Capacity: offerGB(1200), Duration: 30})
+	if cost := offers.Offers[0].Cost; cost == nil || cost.Int64() != 30*5*1200 {
+		t.Errorf("cost mismatch: have %v, want %d", cost, 30*5*1200)
+	}
+
+	// Pagination
+	if addresses, offers := find(&StorageOfferCriteria{Offset: 1, Limit: 1}); !reflect.DeepEqual(addresses, []common.Address{fast}) || offers.Total != 3 {
+		t.Errorf("second page: have %x of %d", addresses, offers.Total)
+	}
+	if addresses, offers := find(&StorageOfferCriteria{Offset: 2, Limit: 5}); !reflect.DeepEqual(addresses, []common.Address{verified}) || offers.Total != 3 {
+		t.Errorf("last page: have %x of %d", addresses, offers.Total)
+	}
+	if _, offers := find(&StorageOfferCriteria{Offset: 3}); len(offers.Offers) != 0 || offers.Offers == nil || offers.Total != 3 {
+		t.Errorf("page past the end: have %d offers of %d", len(offers.Offers), offers.Total)
+	}
+
+	// Invalid requests
+	invalid := []struct {
+		criteria *StorageOfferCriteria
+		err      error
+	}{
+		{&StorageOfferCriteria{Capacity: offerGB(1000)}, errRentCapacityTooSmall},
+		{&StorageOfferCriteria{Duration: 29}, errRentDurationRange},
+		{&StorageOfferCriteria{Duration: 366}, errRentDurationRange},
+		{&StorageOfferCriteria{SortBy: "age"}, errUnknownOfferSort},
+	}
+	for _, test := range invalid {
+		if _, err := api.FindStorageOffers(test.criteria); err != test.err {
+			t.Errorf("criteria %+v: have error %v, want %v", test.criteria, err, test.err)
+		}
+	}
+}
@@ -109,6 +109,8 @@ type PoolPledge struct {
 	Status         uint64                         `json:"status"`
 	EtDetail       map[common.Hash]*EntrustDetail `json:"entrustDetail"`
 	Hash           common.Hash                    `json:"validHash"`
+	PendingFee     *CommissionChange              `json:"pendingFee,omitempty"`
+	PendingEtRate  *CommissionChange              `json:"pendingEntrustRate,omitempty"`
 }
 type EntrustDetail struct {
 	Address common.Address `json:"address"`
@@ -196,6 +198,8 @@ func (s *SpData) copy() *SpData {
 			Status:         spool.Status,
 			EtDetail:       make(map[common.Hash]*EntrustDetail, 0),
 			Hash:           spool.Hash,
+			PendingFee:     spool.PendingFee.copy(),
+			PendingEtRate:  spool.PendingEtRate.copy(),
 		}
 
 		if len(spool.EtDetail) > 0 {
@@ -345,6 +349,12 @@ func (s *SpData) accumulateSpPledgelHash(sphash common.Hash, accumulateAll bool)
 		strconv.FormatUint(spPledge.Fee, 10)+
 		strconv.FormatUint(spPledge.EntrustRate, 10)+
 		strconv.FormatUint(spPledge.Status, 10))
+	if spPledge.PendingFee != nil {
+		dataArr = append(dataArr, "fee:"+spPledge.PendingFee.String())
+	}
+	if spPledge.PendingEtRate != nil {
+		dataArr = append(dataArr, "etrate:"+spPledge.PendingEtRate.String())
+	}
 	sort.Strings(dataArr)
 	spPledge.Hash = getHash(dataArr)
 	if accumulateAll {
@@ -1134,6 +1144,19 @@ func (a *Alien) spSetFee(feeRecord []SpFeeRecord, txDataInfo []string, txSender
 	}else {
 		apFee.Fee = uint64(fee)
 	}
+	if isGECommissionNoticeEffect(snap.config, blocknumber.Uint64()) {
+		sp := snap.SpData.PoolPledge[apFee.Hash]
+		if !checkCommissionChange(new(big.Int).SetUint64(sp.Fee), new(big.Int).SetUint64(apFee.Fee), spCommissionBase, sp.PendingFee) {
+			log.Warn("spSetFee", "fee change not allowed", apFee.Fee, "current", sp.Fee)
+			return feeRecord
+		}
+		for _, item := range feeRecord {
+			if item.Hash == apFee.Hash {
+				log.Warn("spSetFee", "sp only one in one block", apFee.Hash)
+				return feeRecord
+			}
+		}
+	}
 	feeRecord = append(feeRecord, apFee)
 	topics := make([]common.Hash, 3)
 	//web3.sha3("sp set fee")
@@ -1150,7 +1173,11 @@ func (s *Snapshot) updateSpFeeData(spFee []SpFeeRecord, db ethdb.Database, numbe
 	}
 	for _, record := range spFee {
 		if sp, ok := s.SpData.PoolPledge[record.Hash]; ok {
-			sp.Fee = record.Fee
+			if isGECommissionNoticeEffect(s.config, number.Uint64()) {
+				sp.PendingFee = s.newCommissionChange(new(big.Int).SetUint64(record.Fee), number.Uint64())
+			} else {
+				sp.Fee = record.Fee
+			}
 			s.SpData.accumulateSpPledgelHash(record.Hash, false)
 		}
 	}
@@ -1192,6 +1219,19 @@ func (a *Alien) spSetEntrustRate(entrustRateRecord []SpEntrustRateRecord, txData
 	} else {
 		apEtRate.EntrustRate = uint64(entrustRate)
 	}
+	if isGECommissionNoticeEffect(snap.config, blocknumber.Uint64()) {
+		sp := snap.SpData.PoolPledge[apEtRate.Hash]
+		if !checkCommissionChange(new(big.Int).SetUint64(sp.EntrustRate), new(big.Int).SetUint64(apEtRate.EntrustRate), spCommissionBase, sp.PendingEtRate) {
+			log.Warn("spSetEntrustRate", "entrust rate change not allowed", apEtRate.EntrustRate, "current", sp.EntrustRate)
+			return entrustRateRecord
+		}
+		for _, item := range entrustRateRecord {
+			if item.Hash == apEtRate.Hash {
+				log.Warn("spSetEntrustRate", "sp only one in one block", apEtRate.Hash)
+				return entrustRateRecord
+			}
+		}
+	}
 	entrustRateRecord = append(entrustRateRecord, apEtRate)
 	topics := make([]common.Hash, 3)
 	//web3.sha3("sp set entrustrate")
@@ -1207,7 +1247,11 @@ func (s *Snapshot) updateSpEntrustRateData(spEtRate []SpEntrustRateRecord, db et
 	}
 	for _, record := range spEtRate {
 		if sp, ok := s.SpData.PoolPledge[record.Hash]; ok {
-			sp.EntrustRate = record.EntrustRate
+			if isGECommissionNoticeEffect(s.config, number.Uint64()) {
+				sp.PendingEtRate = s.newCommissionChange(new(big.Int).SetUint64(record.EntrustRate), number.Uint64())
+			} else {
+				sp.EntrustRate = record.EntrustRate
+			}
 			s.SpData.accumulateSpPledgelHash(record.Hash, false)
 		}
 	}
@@ -1432,6 +1476,7 @@ func (s *Snapshot) accumulateSpExitBurnAmount( number *big.Int, state *state.Sta
 	for spHash, sp := range s.SpData.PoolPledge {
 		if sp.Status == spStatusIllegalExit {
 			spAddr := common.BigToAddress(spHash.Big())
+			spBurnStart := burnAmount
 			etAddrMap := calculateEtPledge(sp.EtDetail)
 			revenueAddr := sp.Manager
 			if sp.RevenueAddress!=common.BigToAddress(big.NewInt(0)) {
@@ -1457,6 +1502,7 @@ func (s *Snapshot) accumulateSpExitBurnAmount( number *big.Int, state *state.Sta
 			if burnEtRvReward.Cmp(common.Big0) >0 {
 				burnAmount=new(big.Int).Add(burnAmount,burnEtRvReward)
 			}
+			attributeBalance(state, common.BigToAddress(common.Big0), new(big.Int).Sub(burnAmount, spBurnStart), balanceReasonPenaltyBurn, "spExit:"+spAddr.Hex())
 		}
 	}
 	if state!= nil {
@@ -0,0 +1,390 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"sort"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+)
+
+const (
+	storagePoolSortAPR          = "apr"
+	storagePoolSortAmount       = "amount"
+	storagePoolSortCapacity     = "capacity"
+	storagePoolSortUtilisation  = "utilisation"
+	storagePoolSortVerification = "verification"
+	storagePoolSortFee          = "fee"
+
+	storagePoolPageSize = 20
+	storagePoolAPRBase  = 10000 // projected APR in basis points
+)
+
+var (
+	errUnknownStoragePool = errors.New("unknown storage pool")
+	errUnknownPoolSort    = errors.New("unknown storage pool sort")
+)
+
+// StoragePoolMember is a storage node entrusted to a storage pool.
+type StoragePoolMember struct {
+	Address       common.Address `json:"address"`
+	Capacity      *big.Int       `json:"capacity"`
+	VerifySuccess *big.Int       `json:"verifysuccess"`
+}
+
+// StoragePoolExit is a delegation withdrawn from a storage pool whose pledge
+// is still locked.
+type StoragePoolExit struct {
+	Address common.Address `json:"address"`
+	Number  uint64         `json:"number"`
+	Amount  *big.Int       `json:"amount"`
+}
+
+// StoragePool is a storage pool with the metrics delegators compare before
+// entrusting it.
+type StoragePool struct {
+	Hash              common.Hash    `json:"hash"`
+	SpAddr            common.Address `json:"spAddr"`
+	Manager           common.Address `json:"manager"`
+	RevenueAddress    common.Address `json:"revenueAddress"`
+	Number            *big.Int       `json:"number"`
+	Status            uint64         `json:"status"`
+	Fee               uint64         `json:"fee"`
+	EntrustRate       uint64         `json:"entrustRate"`
+	TotalAmount       *big.Int       `json:"totalAmount"`
+	ManagerAmount     *big.Int       `json:"managerAmount"`
+	DelegatedAmount   *big.Int       `json:"delegatedAmount"`
+	Delegators        int            `json:"delegators"`
+	TotalCapacity     *big.Int       `json:"totalcapacity"`
+	UsedCapacity      *big.Int       `json:"usedcapacity"`
+	Utilisation       *big.Int       `json:"utilisation"` // used capacity percentage
+	SnRatio           *big.Int       `json:"snRatio"`
+	MemberCount       int            `json:"memberCount"`
+	VerifySuccess     *big.Int       `json:"verifysuccess"` // member capacity percentage verified today
+	PunishNumber      *big.Int       `json:"punishNumber"`
+	PunishDeadline    *big.Int       `json:"punishDeadline,omitempty"` // illegal exit unless the pledge covers the capacity
+	RecentReward      *big.Int       `json:"recentReward"`             // delegator reward of the last lock period and the current one
+	RewardBlocks      uint64         `json:"rewardBlocks"`
+	ProjectedAPR      *big.Int       `json:"projectedAPR"`
+	PendingExits      int            `json:"pendingExits"`
+	PendingExitAmount *big.Int       `json:"pendingExitAmount"`
+
+	PendingFee         *CommissionChange `json:"pendingFee,omitempty"`
+	PendingEntrustRate *CommissionChange `json:"pendingEntrustRate,omitempty"`
+
+	Members []*StoragePoolMember `json:"members,omitempty"`
+	Exits   []*StoragePoolExit   `json:"exits,omitempty"`
+}
+
+// StoragePools is a page of storage pools.
+type StoragePools struct {
+	Number uint64         `json:"number"`
+	Total  int            `json:"total"`
+	Page   uint64         `json:"page"`
+	Pools  []*StoragePool `json:"pools"`
+}
+
+// GetStoragePool returns the storage pool identified by hash at the current
+// block, with its members and pending exits.
+func (api *API) GetStoragePool(hash common.Hash) (*StoragePool, error) {
+	log.Info("api GetStoragePool", "hash", hash)
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snapshot, err := api.getSnapshotCache(header)
+	if err != nil {
+		log.Warn("Fail to GetStoragePool", "err", err)
+		return nil, errUnknownBlock
+	}
+	sp, ok := snapshot.SpData.PoolPledge[hash]
+	if !ok {
+		return nil, errUnknownStoragePool
+	}
+	number := header.Number.Uint64()
+	view := api.newStoragePoolView(snapshot, number)
+	return view.pool(hash, sp, true), nil
+}
+
+// ListStoragePools lists the storage pools at the current block, a page of
+// storagePoolPageSize pools at a time, ordered by sortBy: apr (the default),
+// amount, capacity, utilisation, verification or fee.
+func (api *API) ListStoragePools(sortBy string, page uint64) (*StoragePools, error) {
+	log.Info("api ListStoragePools", "sort", sortBy, "page", page)
+	less, err := storagePoolLess(sortBy)
+	if err != nil {
+		return nil, err
+	}
+	header := api.chain.CurrentHeader()
+	if header == nil {
+		return nil, errUnknownBlock
+	}
+	snapshot, err := api.getSnapshotCache(header)
+	if err != nil {
+		log.Warn("Fail to ListStoragePools", "err", err)
+		return nil, errUnknownBlock
+	}
+	number := header.Number.Uint64()
+	view := api.newStoragePoolView(snapshot, number)
+	pools := make([]*StoragePool, 0, len(snapshot.SpData.PoolPledge))
+	for hash, sp := range snapshot.SpData.PoolPledge {
+		pools = append(pools, view.pool(hash, sp, false))
+	}
+	sort.Slice(pools, func(i, j int) bool { return less(pools[i], pools[j]) })
+
+	result := &StoragePools{
+		Number: number,
+		Total:  len(pools),
+		Page:   page,
+		Pools:  make([]*StoragePool, 0),
+	}
+	if start := page * storagePoolPageSize; start < uint64(len(pools)) {
+		end := start + storagePoolPageSize
+		if end > uint64(len(pools)) {
+			end = uint64(len(pools))
+		}
+		result.Pools = pools[start:end]
+	}
+	return result, nil
+}
+
+// storagePoolView holds the data shared by the storage pools of a snapshot.
+type storagePoolView struct {
+	snapshot      *Snapshot
+	number        uint64
+	verifySuccess map[common.Address]*big.Int
+	members       map[common.Hash][]common.Address
+	rewards       map[common.Address]*big.Int
+	rewardStart   uint64
+	exits         map[common.Address][]*StoragePoolExit
+}
+
+func (api *API) newStoragePoolView(snapshot *Snapshot, number uint64) *storagePoolView {
+	view := &storagePoolView{
+		snapshot:      snapshot,
+		number:        number,
+		verifySuccess: api.calStorageVerifyPercentage(number, snapshot.getBlockPreDay(), snapshot.StorageData),
+		members:       make(map[common.Hash][]common.Address),
+		rewards:       make(map[common.Address]*big.Int),
+		exits:         make(map[common.Address][]*StoragePoolExit),
+	}
+	for address, entrust := range snapshot.StorageData.StorageEntrust {
+		if _, ok := snapshot.StorageData.StoragePledge[address]; ok && entrust.Sphash != (common.Hash{}) {
+			view.members[entrust.Sphash] = append(view.members[entrust.Sphash], address)
+		}
+	}
+
+	// The delegator rewards are accumulated until the lock number, then locked
+	// all together. The rewards since the start of the previous lock period
+	// give the recent payouts of every pool.
+	lockInterval := utgLockRewardInterval * snapshot.getBlockPreDay()
+	lockOffset := accumulateRewardLockInterval / snapshot.Period
+	var lastLock uint64
+	if number >= lockOffset {
+		lastLock = number - (number-lockOffset)%lockInterval
+		if lastLock >= lockInterval {
+			view.rewardStart = lastLock - lockInterval
+		}
+	}
+	entrustLock := &SnapshotRelease{FlowRevenue: make(map[common.Address]*LockBalanceData)}
+	if snapshot.FlowRevenue.SpEntrustLock != nil {
+		entrustLock.appendFRlockData(snapshot.FlowRevenue.SpEntrustLock, api.alien.db)
+	}
+	for _, revenue := range entrustLock.FlowRevenue {
+		for spAddr, item := range revenue.RewardBalanceV1[sscSpEntrustLockReward] {
+			view.addReward(spAddr, item.Amount)
+		}
+		if lastLock > 0 {
+			for spAddr, item := range revenue.LockBalanceV1[lastLock][sscSpEntrustLockReward] {
+				view.addReward(spAddr, item.Amount)
+			}
+		}
+	}
+
+	exitLock := &SnapshotRelease{FlowRevenue: make(map[common.Address]*LockBalanceData)}
+	if snapshot.FlowRevenue.SpEntrustExitLock != nil {
+		exitLock.appendFRlockData(snapshot.FlowRevenue.SpEntrustExitLock, api.alien.db)
+	}
+	for target, revenue := range exitLock.FlowRevenue {
+		for lockNumber, lockBalance := range revenue.LockBalanceV1 {
+			for spAddr, item := range lockBalance[sscSpEntrustExitLockReward] {
+				left := new(big.Int).Sub(item.Amount, item.Playment)
+				if left.Sign() <= 0 {
+					continue
+				}
+				view.exits[spAddr] = append(view.exits[spAddr], &StoragePoolExit{
+					Address: target,
+					Number:  lockNumber,
+					Amount:  left,
+				})
+			}
+		}
+	}
+	return view
+}
+
+func (v *storagePoolView) addReward(spAddr common.Address, amount *big.Int) {
+	if reward, ok := v.rewards[spAddr]; ok {
+		v.rewards[spAddr] = new(big.Int).Add(reward, amount)
+	} else {
+		v.rewards[spAddr] = new(big.Int).Set(amount)
+	}
+}
+
+func (v *storagePoolView) pool(hash common.Hash, sp *PoolPledge, detail bool) *StoragePool {
+	spAddr := common.BigToAddress(hash.Big())
+	delegated := new(big.Int)
+	delegators := calculateEtPledge(sp.EtDetail)
+	for _, amount := range delegators {
+		delegated.Add(delegated, amount)
+	}
+	pool := &StoragePool{
+		Hash:              hash,
+		SpAddr:            spAddr,
+		Manager:           sp.Manager,
+		RevenueAddress:    sp.RevenueAddress,
+		Number:            new(big.Int).Set(sp.Number),
+		Status:            sp.Status,
+		Fee:               sp.Fee,
+		EntrustRate:       sp.EntrustRate,
+		TotalAmount:       new(big.Int).Set(sp.TotalAmount),
+		ManagerAmount:     new(big.Int).Set(sp.ManagerAmount),
+		DelegatedAmount:   delegated,
+		Delegators:        len(delegators),
+		TotalCapacity:     new(big.Int).Set(sp.TotalCapacity),
+		UsedCapacity:      new(big.Int).Set(sp.UsedCapacity),
+		Utilisation:       new(big.Int),
+		SnRatio:           new(big.Int).Set(sp.SnRatio),
+		VerifySuccess:     new(big.Int),
+		PunishNumber:      new(big.Int).Set(sp.PunishNumber),
+		RecentReward:      new(big.Int),
+		ProjectedAPR:      new(big.Int),
+		PendingExitAmount: new(big.Int),
+	}
+	pool.PendingFee = sp.PendingFee.copy()
+	pool.PendingEntrustRate = sp.PendingEtRate.copy()
+	if sp.TotalCapacity.Sign() > 0 {
+		pool.Utilisation.Mul(sp.UsedCapacity, big.NewInt(100))
+		pool.Utilisation.Div(pool.Utilisation, sp.TotalCapacity)
+	}
+	if sp.PunishNumber.Sign() > 0 {
+		pool.PunishDeadline = new(big.Int).Add(sp.PunishNumber, new(big.Int).SetUint64(v.snapshot.getBlockByDay(7)*2))
+	}
+
+	members := v.members[hash]
+	sort.Slice(members, func(i, j int) bool { return bytes.Compare(members[i].Bytes(), members[j].Bytes()) < 0 })
+	pool.MemberCount = len(members)
+	memberCapacity := new(big.Int)
+	verified := new(big.Int)
+	for _, address := range members {
+		pledge := v.snapshot.StorageData.StoragePledge[address]
+		success := v.verifySuccess[address]
+		if success == nil {
+			success = new(big.Int)
+		}
+		memberCapacity.Add(memberCapacity, pledge.TotalCapacity)
+		verified.Add(verified, new(big.Int).Mul(success, pledge.TotalCapacity))
+		if detail {
+			pool.Members = append(pool.Members, &StoragePoolMember{
+				Address:       address,
+				Capacity:      new(big.Int).Set(pledge.TotalCapacity),
+				VerifySuccess: new(big.Int).Set(success),
+			})
+		}
+	}
+	if memberCapacity.Sign() > 0 {
+		pool.VerifySuccess.Div(verified, memberCapacity)
+	}
+
+	rewardStart := v.rewardStart
+	if sp.Number.Uint64() > rewardStart {
+		rewardStart = sp.Number.Uint64()
+	}
+	if v.number > rewardStart {
+		pool.RewardBlocks = v.number - rewardStart
+	}
+	if reward, ok := v.rewards[spAddr]; ok {
+		pool.RecentReward.Set(reward)
+	}
+	if pool.RewardBlocks > 0 && delegated.Sign() > 0 {
+		blocksPerYear := new(big.Int).SetUint64(365 * v.snapshot.getBlockPreDay())
+		apr := new(big.Int).Mul(pool.RecentReward, big.NewInt(storagePoolAPRBase))
+		apr.Mul(apr, blocksPerYear)
+		apr.Div(apr, new(big.Int).SetUint64(pool.RewardBlocks))
+		pool.ProjectedAPR = apr.Div(apr, delegated)
+	}
+
+	exits := v.exits[spAddr]
+	sort.Slice(exits, func(i, j int) bool {
+		if exits[i].Number != exits[j].Number {
+			return exits[i].Number < exits[j].Number
+		}
+		return bytes.Compare(exits[i].Address.Bytes(), exits[j].Address.Bytes()) < 0
+	})
+	pool.PendingExits = len(exits)
+	for _, exit := range exits {
+		pool.PendingExitAmount.Add(pool.PendingExitAmount, exit.Amount)
+	}
+	if detail {
+		pool.Exits = exits
+	}
+	return pool
+}
+
+// storagePoolLess returns the ordering of the storage pools: the requested
+// key first, then the projected APR, and the hash to keep the order stable.
+func storagePoolLess(sortBy string) (func(a, b *StoragePool) bool, error) {
+	var key func(a, b *StoragePool) int
+	switch sortBy {
+	case "", storagePoolSortAPR:
+	case storagePoolSortAmount:
+		key = func(a, b *StoragePool) int { return b.TotalAmount.Cmp(a.TotalAmount) }
+	case storagePoolSortCapacity:
+		key = func(a, b *StoragePool) int { return b.TotalCapacity.Cmp(a.TotalCapacity) }
+	case storagePoolSortUtilisation:
+		key = func(a, b *StoragePool) int { return b.Utilisation.Cmp(a.Utilisation) }
+	case storagePoolSortVerification:
+		key = func(a, b *StoragePool) int { return b.VerifySuccess.Cmp(a.VerifySuccess) }
+	case storagePoolSortFee:
+		key = func(a, b *StoragePool) int {
+			if a.Fee != b.Fee {
+				if a.Fee < b.Fee {
+					return -1
+				}
+				return 1
+			}
+			return 0
+		}
+	default:
+		return nil, errUnknownPoolSort
+	}
+	return func(a, b *StoragePool) bool {
+		if key != nil {
+			if c := key(a, b); c != 0 {
+				return c < 0
+			}
+		}
+		if c := b.ProjectedAPR.Cmp(a.ProjectedAPR); c != 0 {
+			return c < 0
+		}
+		return bytes.Compare(a.Hash.Bytes(), b.Hash.Bytes()) < 0
+	}, nil
+}
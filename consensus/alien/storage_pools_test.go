@@ -0,0 +1,99 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+func TestStoragePoolMetrics(t *testing.T) {
+	snap := newChallengeSnapshot()
+	snap.Period = 10
+	snap.config = &params.AlienConfig{Period: 10}
+	poolHash := common.HexToHash("0x0a")
+	spAddr := common.BigToAddress(poolHash.Big())
+	delegator := common.HexToAddress("0x4000000000000000000000000000000000000004")
+	sp := &PoolPledge{
+		Manager:       challengeTenant,
+		Number:        big.NewInt(0),
+		TotalAmount:   big.NewInt(1000),
+		TotalCapacity: big.NewInt(400),
+		UsedCapacity:  big.NewInt(100),
+		PunishNumber:  big.NewInt(50),
+		SnRatio:       big.NewInt(1),
+		ManagerAmount: big.NewInt(200),
+		Fee:           5,
+		EtDetail: map[common.Hash]*EntrustDetail{
+			common.HexToHash("0x01"): {Address: challengeTenant, Amount: big.NewInt(200)},
+			common.HexToHash("0x02"): {Address: delegator, Amount: big.NewInt(800)},
+		},
+	}
+	blockPerYear := 365 * snap.getBlockPreDay()
+	view := &storagePoolView{
+		snapshot:      snap,
+		number:        blockPerYear,
+		verifySuccess: map[common.Address]*big.Int{challengePledge: big.NewInt(80)},
+		members:       map[common.Hash][]common.Address{poolHash: {challengePledge}},
+		rewards:       map[common.Address]*big.Int{spAddr: big.NewInt(50)},
+		exits: map[common.Address][]*StoragePoolExit{spAddr: {
+			{Address: delegator, Number: 9, Amount: big.NewInt(30)},
+			{Address: challengeTenant, Number: 3, Amount: big.NewInt(20)},
+		}},
+	}
+	pool := view.pool(poolHash, sp, true)
+	if pool.SpAddr != spAddr || pool.Delegators != 2 || pool.DelegatedAmount.Int64() != 1000 {
+		t.Fatalf("pool mismatch: %+v", pool)
+	}
+	if pool.Utilisation.Int64() != 25 {
+		t.Errorf("utilisation: have %v, want 25", pool.Utilisation)
+	}
+	if pool.MemberCount != 1 || pool.VerifySuccess.Int64() != 80 || len(pool.Members) != 1 {
+		t.Errorf("members mismatch: %d %v", pool.MemberCount, pool.VerifySuccess)
+	}
+	// A reward of 50 for a year of blocks on 1000 delegated is 5%.
+	if pool.RewardBlocks != blockPerYear || pool.ProjectedAPR.Int64() != 500 {
+		t.Errorf("projected APR: have %v over %d blocks, want 500", pool.ProjectedAPR, pool.RewardBlocks)
+	}
+	if pool.PunishDeadline.Uint64() != 50+14*snap.getBlockPreDay() {
+		t.Errorf("punish deadline: have %v", pool.PunishDeadline)
+	}
+	if pool.PendingExits != 2 || pool.PendingExitAmount.Int64() != 50 || pool.Exits[0].Number != 3 {
+		t.Errorf("pending exits mismatch: %d %v", pool.PendingExits, pool.PendingExitAmount)
+	}
+	if list := view.pool(poolHash, sp, false); list.Members != nil || list.Exits != nil {
+		t.Errorf("list entry carries details")
+	}
+
+	less, err := storagePoolLess(storagePoolSortFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cheap := &StoragePool{Hash: common.HexToHash("0x02"), Fee: 1, ProjectedAPR: big.NewInt(1)}
+	if !less(cheap, pool) || less(pool, cheap) {
+		t.Errorf("fee order mismatch")
+	}
+	if less, _ = storagePoolLess(""); !less(pool, cheap) {
+		t.Errorf("APR order mismatch")
+	}
+	if _, err := storagePoolLess("size"); err != errUnknownPoolSort {
+		t.Errorf("expected errUnknownPoolSort, have %v", err)
+	}
+}
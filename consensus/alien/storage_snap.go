@@ -29,6 +29,10 @@ const (
 	utgRentReNew             = "stReNew"
 	utgRentReNewPg           = "stReNewPg"
 	utgRentRescind           = "stRescind"
+	utgRentAutoReNew         = "stAutoReNew"
+	utgRentTransfer          = "stTransfer"
+	utgRentChallenge         = "stChallenge"
+	utgRentChallengeProof    = "stChallengeProof"
 	utgStorageRecoverValid   = "stReValid"
 	utgStorageProof          = "stProof"
 	utgStoragePrice          = "chPrice"
@@ -113,6 +117,9 @@ type StorageData struct {
 	StoragePledge map[common.Address]*SPledge `json:"spledge"`
 	Hash          common.Hash                 `json:"validhash"`
 	StorageEntrust map[common.Address]*SEntrust `json:"sentrust"`
+	LeaseAutoRenew map[common.Hash]*LeaseAutoRenew `json:"leaseautorenew"`
+	LeaseChallenge map[common.Hash]*LeaseChallenge `json:"leasechallenge"`
+	LeaseChallengeLast map[common.Hash]*big.Int `json:"leasechallengelast"`
 }
 
 /**
@@ -330,6 +337,7 @@ type SEntrust struct {
 	 ManagerAmount *big.Int `json:"managerAmount"`
 	 Managerheight *big.Int `json:"managerheight"`
 	 Detail map[common.Hash]*SEntrustDetail `json:"detail"`
+	 PendingRate *CommissionChange `json:"pendingRate,omitempty"`
 }
 
 type ModifySManagerRecord struct {
@@ -416,6 +424,22 @@ func (a *Alien) processStorageCustomTx(txDataInfo []string, headerExtra HeaderEx
 		headerExtra.LeaseRenewalPledge = a.processLeaseRenewalPledge(headerExtra.LeaseRenewalPledge, txDataInfo, txSender, tx, receipts, state, snapCache, number.Uint64(),chain)
 	} else if txDataInfo[posCategory] == utgRentRescind {
 		headerExtra.LeaseRescind, headerExtra.ExchangeSRT = a.processLeaseRescind(headerExtra.LeaseRescind, headerExtra.ExchangeSRT, txDataInfo, txSender, tx, receipts, state, snapCache, number.Uint64())
+	} else if txDataInfo[posCategory] == utgRentAutoReNew {
+		if isGELeaseAutoRenewEffect(snapCache.config, number.Uint64()) {
+			headerExtra.LeaseAutoRenew = a.processLeaseAutoRenew(headerExtra.LeaseAutoRenew, headerExtra.LeaseTransfer, txDataInfo, txSender, tx, receipts, state, snapCache, number.Uint64())
+		}
+	} else if txDataInfo[posCategory] == utgRentTransfer {
+		if isGELeaseTransferEffect(snapCache.config, number.Uint64()) {
+			headerExtra.LeaseTransfer = a.processLeaseTransfer(headerExtra.LeaseTransfer, headerExtra.LeaseAutoRenew, headerExtra.LeaseRescind, txDataInfo, txSender, tx, receipts, state, snapCache, number.Uint64())
+		}
+	} else if txDataInfo[posCategory] == utgRentChallenge {
+		if isGELeaseChallengeEffect(snapCache.config, number.Uint64()) {
+			headerExtra.LeaseChallenge = a.processLeaseChallenge(headerExtra.LeaseChallenge, txDataInfo, txSender, tx, receipts, snapCache, number.Uint64())
+		}
+	} else if txDataInfo[posCategory] == utgRentChallengeProof {
+		if isGELeaseChallengeEffect(snapCache.config, number.Uint64()) {
+			headerExtra.LeaseChallengeProof = a.processLeaseChallengeProof(headerExtra.LeaseChallengeProof, txDataInfo, txSender, tx, receipts, snapCache, number.Uint64(), chain)
+		}
 	} else if txDataInfo[posCategory] == utgStorageRecoverValid {
 		headerExtra.StorageRecoveryData = a.storageRecoveryCertificate(headerExtra.StorageRecoveryData, txDataInfo, txSender, tx, receipts, state, snapCache, number, chain)
 	} else if txDataInfo[posCategory] == utgStorageProof {
@@ -515,6 +539,15 @@ func (snap *Snapshot) storageApply(headerExtra HeaderExtra, header *types.Header
 		snap.updateSETransfer(headerExtra.SETransfer,header.Number, db)
 		snap.updateSEExit(headerExtra.SEExit,header.Number, db)
 	}
+	if isGELeaseAutoRenewEffect(snap.config, header.Number.Uint64()) {
+		snap.updateLeaseAutoRenew(headerExtra.LeaseAutoRenew, header.Number, db)
+	}
+	if isGELeaseTransferEffect(snap.config, header.Number.Uint64()) {
+		snap.updateLeaseTransfer(headerExtra.LeaseTransfer, header.Number, db)
+	}
+	if isGELeaseChallengeEffect(snap.config, header.Number.Uint64()) {
+		snap.updateLeaseChallenge(headerExtra.LeaseChallenge, headerExtra.LeaseChallengeProof, header.Number, db)
+	}
 	return snap, nil
 }
 func (s *StorageData) checkSRent(sRent []LeaseRequestRecord, rent LeaseRequestRecord, number uint64) bool {
@@ -930,6 +963,7 @@ func (s *StorageData) copy() *StorageData {
 				ManagerAmount:new(big.Int).Set(sentrust.ManagerAmount),
 				Managerheight:new(big.Int).Set(sentrust.Managerheight),
 				Detail:make(map[common.Hash]*SEntrustDetail),
+				PendingRate:sentrust.PendingRate.copy(),
 			}
 
 			entrustDetail := s.StorageEntrust[address].Detail
@@ -944,6 +978,24 @@ func (s *StorageData) copy() *StorageData {
 			}
 		}
 	}
+	if s.LeaseAutoRenew != nil {
+		clone.LeaseAutoRenew = make(map[common.Hash]*LeaseAutoRenew)
+		for hash, renew := range s.LeaseAutoRenew {
+			clone.LeaseAutoRenew[hash] = renew.copy()
+		}
+	}
+	if s.LeaseChallenge != nil {
+		clone.LeaseChallenge = make(map[common.Hash]*LeaseChallenge)
+		for hash, challenge := range s.LeaseChallenge {
+			clone.LeaseChallenge[hash] = challenge.copy()
+		}
+	}
+	if s.LeaseChallengeLast != nil {
+		clone.LeaseChallengeLast = make(map[common.Hash]*big.Int)
+		for hash, last := range s.LeaseChallengeLast {
+			clone.LeaseChallengeLast[hash] = new(big.Int).Set(last)
+		}
+	}
 
 	return clone
 }
@@ -1231,7 +1283,7 @@ func (a *Alien) storagePledgeNewExit(storagePledgeExit []SPledgeExitRecord, exch
 	pledgeAddr := common.HexToAddress(txDataInfo[3])
 	if isGEInitStorageManagerNumber(blocknumber.Uint64()){
 		if entrustItem, ok := snap.StorageData.StorageEntrust[pledgeAddr]; ok {
-			if snap.StorageData.StorageEntrust[pledgeAddr].Manager != txSender {
+			if !a.isManagerSigner(state, snap.StorageData.StorageEntrust[pledgeAddr].Manager, txSender, tx, blocknumber.Uint64()) {
 				log.Warn("isStorageManager", "txSender is not manager", txSender)
 				return storagePledgeExit, exchangeSRT
 			}
@@ -1760,7 +1812,7 @@ func (s *StorageData) updateLeaseRescind(sRescinds []LeaseRescindRecord, number
 	s.accumulateHeaderHash()
 }
 
-func (s *StorageData) storageVerificationCheck(number uint64, blockPerday uint64, passTime *big.Int, rate uint32, revenueStorage map[common.Address]*RevenueParameter, period uint64, db ethdb.Database, basePrice *big.Int, currentLockReward []LockRewardRecord, snapTotalLeaseSpace *big.Int, spData *SpData,snap *Snapshot) ([]LockRewardRecord, []ExchangeSRTRecord, *big.Int, error, *big.Int, *big.Int) {
+func (s *StorageData) storageVerificationCheck(number uint64, blockPerday uint64, passTime *big.Int, rate uint32, revenueStorage map[common.Address]*RevenueParameter, period uint64, db ethdb.Database, basePrice *big.Int, currentLockReward []LockRewardRecord, snapTotalLeaseSpace *big.Int, spData *SpData,snap *Snapshot, state *state.StateDB) ([]LockRewardRecord, []ExchangeSRTRecord, *big.Int, error, *big.Int, *big.Int) {
 
 	sussSPAddrs, sussRentHashs, storageRatios, capSuccAddrs := s.storageVerify(number, blockPerday, revenueStorage)
 
@@ -1779,7 +1831,7 @@ func (s *StorageData) storageVerificationCheck(number uint64, blockPerday uint64
 		}
 	}
 	var burnAmount *big.Int
-	revertSpaceLockReward, revertExchangeSRT,bAmount := s.dealLeaseStatus(number, rate, blockPerday, revenueStorage,snap)
+	revertSpaceLockReward, revertExchangeSRT,bAmount := s.dealLeaseStatus(number, rate, blockPerday, revenueStorage,snap,state)
 	if bAmount!=nil&&bAmount.Cmp(common.Big0)>0{
 		burnAmount=new(big.Int).Set(bAmount)
 	}
@@ -2545,7 +2597,7 @@ func (a *Alien) exchangeStoragePrice(storageExchangePriceRecord []StorageExchang
 	pledgeAddr := common.HexToAddress(txDataInfo[3])
 	if isGEInitStorageManagerNumber(blocknumber.Uint64()){
 		if _, ok := snap.StorageData.StorageEntrust[pledgeAddr]; ok {
-			if snap.StorageData.StorageEntrust[pledgeAddr].Manager != txSender {
+			if !a.isManagerSigner(state, snap.StorageData.StorageEntrust[pledgeAddr].Manager, txSender, tx, blocknumber.Uint64()) {
 				log.Warn("isStorageManager", "txSender is not manager", txSender)
 				return storageExchangePriceRecord
 			}
@@ -3072,11 +3124,16 @@ func (s *StorageData) storageVerify2(number uint64, blockPerday uint64, revenueS
 	return sussSPAddrs, sussRentHashs, storageRatios, capSuccAddrs
 }
 
-func (s *StorageData) dealLeaseStatus(number uint64, rate uint32, blockPerday uint64,revenueStorage map[common.Address]*RevenueParameter,snap *Snapshot) ([]SpaceRewardRecord, []ExchangeSRTRecord,*big.Int) {
+// dealLeaseStatus returns the pledges and leases that ended and the deposits
+// and rewards they forfeit, deferring the tag of each forfeit to the burn.
+func (s *StorageData) dealLeaseStatus(number uint64, rate uint32, blockPerday uint64,revenueStorage map[common.Address]*RevenueParameter,snap *Snapshot, state *state.StateDB) ([]SpaceRewardRecord, []ExchangeSRTRecord,*big.Int) {
 	revertLockReward := make([]SpaceRewardRecord, 0)
 	revertExchangeSRT := make([]ExchangeSRTRecord, 0)
 	delPledge := make([]common.Address, 0)
 	bAmount:=common.Big0
+	if isGELeaseAutoRenewEffect(snap.config, number) {
+		s.dealLeaseAutoRenew(number, blockPerday, snap)
+	}
 	for pledgeAddress, sPledge := range s.StoragePledge {
 		if sPledge.PledgeStatus.Cmp(big.NewInt(SPledgeRetrun)) == 0 {
 			continue
@@ -3084,7 +3141,9 @@ func (s *StorageData) dealLeaseStatus(number uint64, rate uint32, blockPerday ui
 		if sPledge.PledgeStatus.Cmp(big.NewInt(SPledgeRemoving)) == 0 || sPledge.PledgeStatus.Cmp(big.NewInt(SPledgeExit)) == 0 {
 			sPledgePledgeStatus:=new(big.Int).Set(sPledge.PledgeStatus)
 			sPledge.PledgeStatus = big.NewInt(SPledgeRetrun)
+			prevAmount:=bAmount
 			revertLockReward, revertExchangeSRT,bAmount = s.dealSPledgeRevert(revertLockReward, revertExchangeSRT, sPledge, rate, number, blockPerday,sPledgePledgeStatus,bAmount,revenueStorage,pledgeAddress,snap)
+			deferBurn(state, prevAmount, bAmount, "storagePledge:"+pledgeAddress.Hex())
 			delPledge = append(delPledge, pledgeAddress)
 			s.accumulateSpaceHash(pledgeAddress)
 			continue
@@ -3098,7 +3157,9 @@ func (s *StorageData) dealLeaseStatus(number uint64, rate uint32, blockPerday ui
 			if lease.Status == LeaseUserRescind || lease.Status == LeaseExpiration {
 				lStatus:=lease.Status
 				lease.Status = LeaseReturn
+				prevAmount:=bAmount
 				revertLockReward, revertExchangeSRT,bAmount = s.dealLeaseRevert(lease, revertLockReward, revertExchangeSRT, rate,lStatus,number,lHash,blockPerday,bAmount)
+				deferBurn(state, prevAmount, bAmount, "lease:"+lHash.Hex())
 				s.accumulateLeaseHash(pledgeAddress, lease)
 			}
 		}
@@ -3109,6 +3170,9 @@ func (s *StorageData) dealLeaseStatus(number uint64, rate uint32, blockPerday ui
 		}
 		delete(s.StoragePledge, delAddr)
 	}
+	if isGELeaseAutoRenewEffect(snap.config, number) {
+		s.releaseLeaseAutoRenew(state)
+	}
 	if isGEInitStorageManagerNumber(number) {
 		snap.SpData.accumulateSpDataHash()
 	}
@@ -3748,7 +3812,7 @@ func (s *Snapshot) storageVerificationCheck(number uint64, blockPerday uint64, d
 	if isStorageVerificationCheck(number, s.Period) {
 		passTime := new(big.Int).Mul(s.SystemConfig.Deposit[sscEnumLeaseExpires], new(big.Int).SetUint64(blockPerday))
 		basePrice := s.SystemConfig.Deposit[sscEnumStoragePrice]
-		return s.StorageData.storageVerificationCheck(number, blockPerday, passTime, s.SystemConfig.ExchRate, s.RevenueStorage, s.Period, db, basePrice, currentLockReward,s.TotalLeaseSpace,s.SpData,s)
+		return s.StorageData.storageVerificationCheck(number, blockPerday, passTime, s.SystemConfig.ExchRate, s.RevenueStorage, s.Period, db, basePrice, currentLockReward,s.TotalLeaseSpace,s.SpData,s,state)
 	}
 	return currentLockReward,nil, nil,nil,nil,nil
 }
@@ -3824,6 +3888,9 @@ func (s *StorageData) calDealLeaseStatus(number uint64, snap *Snapshot, db ethdb
 	for _, delAddr := range delPledge {
 		delete(s.StoragePledge, delAddr)
 	}
+	if isGELeaseAutoRenewEffect(snap.config, number) {
+		s.releaseLeaseAutoRenew(nil)
+	}
 	s.accumulateHeaderHash()
 	if number >= StoragePledgeOptEffectNumber && len(removePledge) > 0 {
 		snap.setStorageRemovePunish(removePledge, number, db, header)
@@ -4475,7 +4542,7 @@ func (s *StorageData) fixLeaseCapacity(currentLockReward []LockRewardRecord, sta
 			continue
 		}
 		leases := sPledge.Lease
-		for _, lease := range leases {
+		for lHash, lease := range leases {
 			if lease.Status == LeaseReturn {
 				continue
 			}
@@ -4484,6 +4551,7 @@ func (s *StorageData) fixLeaseCapacity(currentLockReward []LockRewardRecord, sta
 				lCapMod:=new(big.Int).Mod(leaseCapacity,big.NewInt(5))
 				if lCapMod.Cmp(common.Big0)!=0{
 					if state!=nil{
+						setBalanceReason(state, balanceReasonLeaseDepositRefund, lHash.Hex())
 						state.AddBalance(lease.DepositAddress,lease.Deposit)
 					}
 					revertExchangeSRT = append(revertExchangeSRT, ExchangeSRTRecord{
@@ -4904,7 +4972,7 @@ func (a *Alien) completeStoragePledge(currentCSPledge []CompleteSPledgeRecord, t
 		return currentCSPledge
 	}
 	if _, ok := snap.StorageData.StorageEntrust[completeSPledge.Pledge]; ok {
-		if snap.StorageData.StorageEntrust[completeSPledge.Pledge].Manager != txSender {
+		if !a.isManagerSigner(state, snap.StorageData.StorageEntrust[completeSPledge.Pledge].Manager, txSender, tx, number.Uint64()) {
 			log.Warn("completeSPledge", "txSender is not manager", txSender)
 			return currentCSPledge
 		}
@@ -5007,7 +5075,7 @@ func (a *Alien) storageSetRewardRatio(currentRatio []SPRewardRatioRecord, txData
 		return currentRatio
 	}
 	if _, ok := snap.StorageData.StorageEntrust[sPRewardRatio.Pledge]; ok {
-		if snap.StorageData.StorageEntrust[sPRewardRatio.Pledge].Manager != txSender {
+		if !a.isManagerSigner(state, snap.StorageData.StorageEntrust[sPRewardRatio.Pledge].Manager, txSender, tx, number.Uint64()) {
 			log.Warn("storageSetRewardRatio", "txSender is not manager", txSender)
 			return currentRatio
 		}
@@ -5040,6 +5108,19 @@ func (a *Alien) storageSetRewardRatio(currentRatio []SPRewardRatioRecord, txData
 		log.Warn("storageSetRewardRatio", "StoragePledge is empty", sPRewardRatio.Pledge)
 		return currentRatio
 	}
+	if isGECommissionNoticeEffect(snap.config, number.Uint64()) {
+		entrust := snap.StorageData.StorageEntrust[sPRewardRatio.Pledge]
+		if !checkCommissionChange(entrust.EntrustRate, sPRewardRatio.Rate, sPDistributionDefaultRate, entrust.PendingRate) {
+			log.Warn("storageSetRewardRatio", "rate change not allowed", sPRewardRatio.Rate, "current", entrust.EntrustRate)
+			return currentRatio
+		}
+		for _, item := range currentRatio {
+			if item.Pledge == sPRewardRatio.Pledge {
+				log.Warn("storageSetRewardRatio", "pledge only one in one block", sPRewardRatio.Pledge)
+				return currentRatio
+			}
+		}
+	}
 
 	topics := make([]common.Hash, 3)
 	//web3.sha3("storage Set reward ratio")
@@ -5057,6 +5138,10 @@ func (s *Snapshot) updateSPRewardRatio(sPRewardRatioRecord []SPRewardRatioRecord
 	}
 	for  _,sPRewardRatio :=range sPRewardRatioRecord {
 		if _,ok:=s.StorageData.StorageEntrust[sPRewardRatio.Pledge];ok{
+			if isGECommissionNoticeEffect(s.config, number.Uint64()) {
+				s.StorageData.StorageEntrust[sPRewardRatio.Pledge].PendingRate = s.newCommissionChange(sPRewardRatio.Rate, number.Uint64())
+				continue
+			}
 			s.StorageData.StorageEntrust[sPRewardRatio.Pledge].EntrustRate=new(big.Int).Set(sPRewardRatio.Rate)
 		}
 	}
@@ -5077,7 +5162,7 @@ func (a *Alien) storageSetStoragePools(currentSPPool []SPPoolRecord, txDataInfo
 		return currentSPPool
 	}
 	if _, ok := snap.StorageData.StorageEntrust[sPPool.Pledge]; ok {
-		if snap.StorageData.StorageEntrust[sPPool.Pledge].Manager != txSender {
+		if !a.isManagerSigner(state, snap.StorageData.StorageEntrust[sPPool.Pledge].Manager, txSender, tx, number.Uint64()) {
 			log.Warn("storageSetStoragePools", "txSender is not manager", txSender)
 			return currentSPPool
 		}
@@ -5202,7 +5287,7 @@ func (a *Alien) storageMigration(currentMigration []SPMigrationRecord, currentLo
 		}
 	}
 	manager:=snap.StorageData.StorageEntrust[peledgeAddr].Manager
-	if txSender!=manager{
+	if !a.isManagerSigner(state, manager, txSender, tx, number.Uint64()) {
 		log.Warn("storageMigration", " txSender is not manager", manager)
 		return currentMigration, currentLockReward, currentExchangeSRT
 	}
@@ -6483,6 +6568,9 @@ func (s *StorageData) calcStoragePledgeReward4(ratios map[common.Address]*Storag
 }
 
 func (s *StorageData) calDealLeaseStatus2(number uint64, snap *Snapshot, db ethdb.Database, header *types.Header, revenueStorage map[common.Address]*RevenueParameter) {
+	if isGELeaseAutoRenewEffect(snap.config, number) {
+		s.dealLeaseAutoRenew(number, snap.getBlockPreDay(), snap)
+	}
 	delPledge := make([]common.Address, 0)
 	removePledge:=make([]common.Address, 0)
 	normalExitPledge:=make([]common.Address, 0)
@@ -6560,6 +6648,9 @@ func (s *StorageData) calDealLeaseStatus2(number uint64, snap *Snapshot, db ethd
 		s.deleteSpCapAndRs(delAddr, snap)
 		delete(s.StoragePledge, delAddr)
 	}
+	if isGELeaseAutoRenewEffect(snap.config, number) {
+		s.releaseLeaseAutoRenew(nil)
+	}
 	snap.SpData.accumulateSpDataHash()
 	s.accumulateHeaderHash()
 	return
@@ -6578,7 +6669,7 @@ func (a *Alien) storageExitPool(currentExitPool []common.Address, txDataInfo []s
 	}
 	nilHash := common.Hash{}
 	if _, ok := snap.StorageData.StorageEntrust[target]; ok {
-		if snap.StorageData.StorageEntrust[target].Manager != txSender {
+		if !a.isManagerSigner(state, snap.StorageData.StorageEntrust[target].Manager, txSender, tx, number.Uint64()) {
 			log.Warn("storageExitPool", "txSender is not manager", txSender)
 			return currentExitPool
 		}
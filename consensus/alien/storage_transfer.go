@@ -0,0 +1,170 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/ethdb"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+)
+
+// LeaseTransferRecord hands a lease over to a new tenant.
+type LeaseTransferRecord struct {
+	Address  common.Address `json:"address"`
+	Hash     common.Hash    `json:"hash"`
+	Original common.Address `json:"original"`
+	NewOwner common.Address `json:"newowner"`
+}
+
+// hasSigner reports whether addr signed the transaction sent by txSender.
+func hasSigner(tx *types.Transaction, txSender common.Address, addr common.Address) bool {
+	if txSender == addr {
+		return true
+	}
+	for _, signer := range tx.AllSigners() {
+		if signer == addr {
+			return true
+		}
+	}
+	return false
+}
+
+// processLeaseTransfer handles utg:1:stTransfer:<storage node>:<lease>:<new tenant>.
+// The transaction must be signed by the tenant, the depositor of the lease if
+// another account, and the manager of the storage pledge, usually as a
+// multi-signer transaction. A pending automatic renewal is cancelled and its
+// deposit returned.
+func (a *Alien) processLeaseTransfer(currentTransfer []LeaseTransferRecord, currentAutoRenew []LeaseAutoRenewRecord, currentRescind []LeaseRescindRecord, txDataInfo []string, txSender common.Address, tx *types.Transaction, receipts []*types.Receipt, state *state.StateDB, snap *Snapshot, number uint64) []LeaseTransferRecord {
+	if len(txDataInfo) < 6 {
+		log.Warn("stTransfer", "parameter number", len(txDataInfo))
+		return currentTransfer
+	}
+	record := LeaseTransferRecord{}
+	postion := 3
+	if err := record.Address.UnmarshalText1([]byte(txDataInfo[postion])); err != nil {
+		log.Warn("stTransfer", "address", txDataInfo[postion])
+		return currentTransfer
+	}
+	postion++
+	record.Hash = common.HexToHash(txDataInfo[postion])
+	postion++
+	if err := record.NewOwner.UnmarshalText1([]byte(txDataInfo[postion])); err != nil {
+		log.Warn("stTransfer", "new owner", txDataInfo[postion])
+		return currentTransfer
+	}
+	for _, item := range currentTransfer {
+		if item.Hash == record.Hash {
+			log.Warn("stTransfer", "lease only one in one block", record.Hash)
+			return currentTransfer
+		}
+	}
+	for _, item := range currentAutoRenew {
+		if item.Hash == record.Hash {
+			log.Warn("stTransfer", "automatic renewal changed in this block", record.Hash)
+			return currentTransfer
+		}
+	}
+	for _, item := range currentRescind {
+		if item.Hash == record.Hash {
+			log.Warn("stTransfer", "lease rescinded in this block", record.Hash)
+			return currentTransfer
+		}
+	}
+	original, ok := snap.StorageData.checkLeaseTransfer(record, tx, txSender)
+	if !ok {
+		log.Warn("stTransfer", "checkLeaseTransfer fail", record.Hash)
+		return currentTransfer
+	}
+	record.Original = original
+	if renew, ok := snap.StorageData.LeaseAutoRenew[record.Hash]; ok {
+		if isStorageVerificationCheck(number, snap.Period) {
+			log.Warn("stTransfer", "can not cancel automatic renewal while leases are settled", number)
+			return currentTransfer
+		}
+		state.AddBalance(renew.Owner, renew.Deposit)
+	}
+	topics := make([]common.Hash, 3)
+	topics[0].UnmarshalText([]byte("0xaa33f27fd2ae2bf198c76ac9ee2ebf3cc160efbe338dbe88236221f0870907db")) //web3.sha3("stTransfer(address)")
+	topics[1].SetBytes(record.Hash.Bytes())
+	topics[2].SetBytes(record.NewOwner.Bytes())
+	a.addCustomerTxLog(tx, receipts, topics, original.Hash().Bytes())
+	return append(currentTransfer, record)
+}
+
+func (s *StorageData) checkLeaseTransfer(record LeaseTransferRecord, tx *types.Transaction, txSender common.Address) (common.Address, bool) {
+	nilAddr := common.Address{}
+	pledge, ok := s.StoragePledge[record.Address]
+	if !ok {
+		log.Info("checkLeaseTransfer", "address not exist", record.Address)
+		return nilAddr, false
+	}
+	if pledge.PledgeStatus.Cmp(big.NewInt(SPledgeNormal)) != 0 {
+		log.Info("checkLeaseTransfer", "address PledgeStatus is not normal", record.Address)
+		return nilAddr, false
+	}
+	lease, ok := pledge.Lease[record.Hash]
+	if !ok {
+		log.Info("checkLeaseTransfer", "hash not exist", record.Hash)
+		return nilAddr, false
+	}
+	if lease.Status != LeaseNormal && lease.Status != LeaseBreach {
+		log.Info("checkLeaseTransfer", "lease Status can not transfer", lease.Status)
+		return nilAddr, false
+	}
+	if record.NewOwner == nilAddr || record.NewOwner == lease.Address {
+		log.Info("checkLeaseTransfer", "new owner is not valid", record.NewOwner)
+		return nilAddr, false
+	}
+	entrust, ok := s.StorageEntrust[record.Address]
+	if !ok {
+		log.Info("checkLeaseTransfer", "manager is empty", record.Address)
+		return nilAddr, false
+	}
+	if !hasSigner(tx, txSender, lease.Address) || !hasSigner(tx, txSender, lease.DepositAddress) || !hasSigner(tx, txSender, entrust.Manager) {
+		log.Info("checkLeaseTransfer", "lease renter, depositor and manager must all sign", txSender)
+		return nilAddr, false
+	}
+	return lease.Address, true
+}
+
+// updateLeaseTransfer moves the lease, its details and the claim on its
+// deposit to the new tenant. The lease keeps its hash and verification
+// history, so the revenue of the storage pledge is not affected.
+func (s *Snapshot) updateLeaseTransfer(records []LeaseTransferRecord, number *big.Int, db ethdb.Database) {
+	if records == nil || len(records) == 0 {
+		return
+	}
+	for _, item := range records {
+		delete(s.StorageData.LeaseAutoRenew, item.Hash)
+		pledge, ok := s.StorageData.StoragePledge[item.Address]
+		if !ok {
+			continue
+		}
+		lease, ok := pledge.Lease[item.Hash]
+		if !ok || lease.Address != item.Original || (lease.Status != LeaseNormal && lease.Status != LeaseBreach) {
+			continue
+		}
+		lease.Address = item.NewOwner
+		lease.DepositAddress = item.NewOwner
+		s.StorageData.accumulateLeaseHash(item.Address, lease)
+	}
+	s.StorageData.accumulateHeaderHash()
+}
@@ -0,0 +1,194 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+)
+
+func leaseTransferTx(owner common.Address) string {
+	return "utg:1:" + utgRentTransfer + ":" + renewPledge.Hex() + ":" + renewLease.Hex() + ":" + owner.Hex()
+}
+
+// applyTransfer applies the records of a block including its transfers.
+func applyTransfer(b *renewBlock) {
+	b.apply()
+	b.snap.updateLeaseTransfer(b.extra.LeaseTransfer, new(big.Int).SetUint64(b.number), nil)
+}
+
+// sendSigned runs a multi-signer transaction signed by the given keys, sent by
+// the first of them, through the engine.
+func sendSigned(b *renewBlock, data string, keys ...*ecdsa.PrivateKey) *types.Receipt {
+	chainID := big.NewInt(1337)
+	tx := types.NewMultiSignerTransaction(chainID, uint64(len(b.receipts)), renewPledge, big.NewInt(0), 0, big.NewInt(0), []byte(data))
+	for _, key := range keys {
+		tx, _ = types.SignTx(tx, types.LatestSignerForChainID(chainID), key)
+	}
+	receipt := &types.Receipt{
+		Status:      types.ReceiptStatusSuccessful,
+		TxHash:      tx.Hash(),
+		BlockNumber: new(big.Int).SetUint64(b.number),
+	}
+	b.receipts = append(b.receipts, receipt)
+	sender := crypto.PubkeyToAddress(keys[0].PublicKey)
+	b.extra = (&Alien{}).processStorageCustomTx(strings.Split(data, ":"), b.extra, sender, tx, b.receipts, b.snap, new(big.Int).SetUint64(b.number), b.state, nil)
+	return receipt
+}
+
+// TestLeaseTransfer hands a lease with an instruction over to a new tenant,
+// which cancels the instruction and returns its deposit.
+func TestLeaseTransfer(t *testing.T) {
+	number := upgradeTestNumber + uint64(100)
+	snap := newRenewSnapshot(number - renewBlockPerDay)
+	statedb := newRenewState(t)
+
+	block := &renewBlock{snap: snap, state: statedb, number: number}
+	block.send(renewTenant, autoRenewTx("30", "100"))
+	block.apply()
+
+	block = &renewBlock{snap: snap, state: statedb, number: number + 1}
+	if receipt := block.send(renewBuyer, leaseTransferTx(renewBuyer)); len(receipt.Logs) != 0 {
+		t.Fatalf("transfer without the tenant accepted")
+	}
+	if receipt := block.send(renewTenant, leaseTransferTx(renewTenant)); len(receipt.Logs) != 0 {
+		t.Fatalf("transfer to the tenant accepted")
+	}
+	if receipt := block.send(renewTenant, leaseTransferTx(common.Address{})); len(receipt.Logs) != 0 {
+		t.Fatalf("transfer to the zero address accepted")
+	}
+	if receipt := block.send(renewTenant, leaseTransferTx(renewBuyer)); len(receipt.Logs) != 1 {
+		t.Fatalf("transfer rejected")
+	}
+	if receipt := block.send(renewTenant, autoRenewCancelTx()); len(receipt.Logs) != 0 {
+		t.Fatalf("instruction changed in the block of the transfer")
+	}
+	applyTransfer(block)
+
+	lease := snap.StorageData.StoragePledge[renewPledge].Lease[renewLease]
+	if lease.Address != renewBuyer || lease.DepositAddress != renewBuyer {
+		t.Errorf("lease not transferred: tenant %v, depositor %v", lease.Address, lease.DepositAddress)
+	}
+	if _, ok := snap.StorageData.LeaseAutoRenew[renewLease]; ok {
+		t.Errorf("instruction of the previous tenant kept")
+	}
+	if have := statedb.GetBalance(renewTenant); have.Int64() != 1000 {
+		t.Errorf("deposit not returned: have %v, want 1000", have)
+	}
+	// The new tenant can register its own instruction
+	block = &renewBlock{snap: snap, state: statedb, number: number + 2}
+	if receipt := block.send(renewBuyer, autoRenewTx("30", "50")); len(receipt.Logs) != 1 {
+		t.Fatalf("instruction of the new tenant rejected")
+	}
+}
+
+// TestLeaseTransferSigners checks that the tenant, the depositor and the
+// manager of the storage pledge all sign a transfer, and that the deposit of
+// the instruction goes back to its owner only.
+func TestLeaseTransferSigners(t *testing.T) {
+	var (
+		keys   []*ecdsa.PrivateKey
+		addrs  []common.Address
+		number = upgradeTestNumber + uint64(100)
+	)
+	for i := 0; i < 4; i++ {
+		key, _ := crypto.GenerateKey()
+		keys = append(keys, key)
+		addrs = append(addrs, crypto.PubkeyToAddress(key.PublicKey))
+	}
+	tenant, depositor, manager, buyer := keys[0], keys[1], keys[2], keys[3]
+
+	snap := newRenewSnapshot(number - renewBlockPerDay)
+	lease := snap.StorageData.StoragePledge[renewPledge].Lease[renewLease]
+	lease.Address, lease.DepositAddress = addrs[0], addrs[1]
+	snap.StorageData.StorageEntrust[renewPledge].Manager = addrs[2]
+	snap.StorageData.LeaseAutoRenew = map[common.Hash]*LeaseAutoRenew{
+		renewLease: {
+			Pledge:       renewPledge,
+			Hash:         renewLease,
+			Owner:        addrs[0],
+			Duration:     big.NewInt(30),
+			MaxPrice:     big.NewInt(1),
+			Deposit:      big.NewInt(100),
+			SRTAllowance: big.NewInt(30),
+			LastNumber:   big.NewInt(0),
+		},
+	}
+	statedb := newRenewState(t)
+
+	// A transfer missing any of the three signers is rejected
+	block := &renewBlock{snap: snap, state: statedb, number: number}
+	missing := map[string][]*ecdsa.PrivateKey{
+		"tenant":    {depositor, manager},
+		"depositor": {tenant, manager},
+		"manager":   {tenant, depositor},
+		"all":       {buyer},
+	}
+	for name, signers := range missing {
+		if receipt := sendSigned(block, leaseTransferTx(addrs[3]), signers...); len(receipt.Logs) != 0 {
+			t.Errorf("transfer without the %s accepted", name)
+		}
+	}
+	if len(block.extra.LeaseTransfer) != 0 {
+		t.Fatalf("rejected transfers recorded: %v", block.extra.LeaseTransfer)
+	}
+
+	// Instructions are not cancelled while the leases are settled
+	settlement := (number/renewBlockPerDay+1)*renewBlockPerDay + storageVerificationCheck/snap.Period
+	block = &renewBlock{snap: snap, state: statedb, number: settlement}
+	if receipt := sendSigned(block, leaseTransferTx(addrs[3]), tenant, depositor, manager); len(receipt.Logs) != 0 {
+		t.Errorf("transfer accepted while the leases are settled")
+	}
+
+	// All three sign, in any order, and the deposit goes back to the tenant
+	block = &renewBlock{snap: snap, state: statedb, number: number + 1}
+	receipt := sendSigned(block, leaseTransferTx(addrs[3]), manager, depositor, tenant)
+	if len(receipt.Logs) != 1 {
+		t.Fatalf("transfer signed by all rejected")
+	}
+	if original := common.BytesToAddress(receipt.Logs[0].Data); original != addrs[0] {
+		t.Errorf("original tenant mismatch: have %v, want %v", original, addrs[0])
+	}
+	balances := []int64{100, 0, 0, 0}
+	for i, addr := range addrs {
+		if have := statedb.GetBalance(addr); have.Int64() != balances[i] {
+			t.Errorf("balance of %v: have %v, want %d", addr, have, balances[i])
+		}
+	}
+	applyTransfer(block)
+
+	if lease.Address != addrs[3] || lease.DepositAddress != addrs[3] {
+		t.Errorf("lease not transferred: tenant %v, depositor %v", lease.Address, lease.DepositAddress)
+	}
+	if _, ok := snap.StorageData.LeaseAutoRenew[renewLease]; ok {
+		t.Errorf("instruction of the previous tenant kept")
+	}
+	// The lease without an instruction is handed on without any refund
+	block = &renewBlock{snap: snap, state: statedb, number: number + 2}
+	if receipt := sendSigned(block, leaseTransferTx(addrs[0]), buyer, manager); len(receipt.Logs) != 1 {
+		t.Fatalf("transfer by the new tenant rejected")
+	}
+	if have := statedb.GetBalance(addrs[0]); have.Int64() != 100 {
+		t.Errorf("deposit refunded twice: have %v, want 100", have)
+	}
+}
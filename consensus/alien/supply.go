@@ -0,0 +1,511 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"sort"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/ethdb"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+	"github.com/UltronGlow/UltronGlow-Origin/rlp"
+	"github.com/UltronGlow/UltronGlow-Origin/trie"
+)
+
+const (
+	// supplyReasonTransfer are the funds sent to the burn address by ordinary
+	// transactions.
+	supplyReasonTransfer = "transfer"
+
+	// SupplySectionSize is the number of blocks the supply indexer processes
+	// at once, the statistics of the latest blocks lag behind by up to as many.
+	SupplySectionSize = 16
+
+	inMemorySupplyDeltas = 4096 // Number of finalised blocks to keep the balance changes of
+)
+
+var (
+	supplyStatsPrefix = []byte("alien-supply-")       // supplyStatsPrefix + num (uint64 big endian) + hash -> supply statistics
+	supplyDeltaPrefix = []byte("alien-supply-delta-") // supplyDeltaPrefix + num (uint64 big endian) + parent hash + root -> balance changes
+)
+
+var (
+	// burnAddress receives the burned funds.
+	burnAddress = common.BigToAddress(big.NewInt(0))
+
+	// supplyRewardNames names the lock reward records that mint new funds.
+	supplyRewardNames = map[uint32]string{
+		sscEnumSignerReward:        "signerReward",
+		sscEnumFlwReward:           "flowReward",
+		sscEnumBandwidthReward:     "bandwidthReward",
+		sscSpLockReward:            "spReward",
+		sscSpEntrustLockReward:     "spEntrustReward",
+		sscEnumSTEntrustLockReward: "storageEntrustReward",
+	}
+
+	// supplyDirectMints are the reasons of credits minted straight into the
+	// balance of an account rather than released from custody.
+	supplyDirectMints = map[string]bool{
+		balanceReasonGasRefund:         true,
+		balanceReasonSideChainCharging: true,
+		balanceReasonBridgeMint:        true,
+	}
+
+	// supplyBurnDebits are the reasons of debits whose funds leave the supply
+	// instead of being taken into custody.
+	supplyBurnDebits = map[string]bool{
+		balanceReasonSRTExchange:                         true,
+		balanceReasonCustomTx + ":" + categoryBridgeBurn: true,
+		balanceReasonSideChainGas:                        true,
+	}
+)
+
+// supplyAmount is a named amount of the supply statistics.
+type supplyAmount struct {
+	Name   string
+	Amount *big.Int
+}
+
+// supplyStats are the cumulative supply totals tracked since the genesis, or
+// since the block the tracking was seeded from if the balance changes of an
+// earlier block are unknown. Debits and credits only cover the balance changes
+// of the engine, as transactions move funds between accounts without changing
+// their sum.
+type supplyStats struct {
+	Since        uint64   // block the totals are tracked from, 0 for the genesis
+	Baseline     *big.Int // sum of all balances of the block tracked from
+	BaselineBurn *big.Int // burn address balance of the block tracked from
+	Minted       []supplyAmount
+	Burned       []supplyAmount
+	Credited     *big.Int // credits to accounts other than the burn address
+	Debited      *big.Int // debits from accounts other than the burn address
+	BurnCredited *big.Int // net credits to the burn address
+	BurnBalance  *big.Int // burn address balance
+}
+
+func newSupplyStats(since uint64, baseline *big.Int, baselineBurn *big.Int) *supplyStats {
+	return &supplyStats{
+		Since:        since,
+		Baseline:     new(big.Int).Set(baseline),
+		BaselineBurn: new(big.Int).Set(baselineBurn),
+		Credited:     new(big.Int),
+		Debited:      new(big.Int),
+		BurnCredited: new(big.Int),
+		BurnBalance:  new(big.Int).Set(baselineBurn),
+	}
+}
+
+func supplyKey(number uint64, hash common.Hash) []byte {
+	key := make([]byte, len(supplyStatsPrefix)+8)
+	copy(key, supplyStatsPrefix)
+	binary.BigEndian.PutUint64(key[len(supplyStatsPrefix):], number)
+	return append(key, hash[:]...)
+}
+
+func loadSupplyStats(db ethdb.KeyValueReader, number uint64, hash common.Hash) (*supplyStats, error) {
+	blob, err := db.Get(supplyKey(number, hash))
+	if err != nil {
+		return nil, err
+	}
+	stats := new(supplyStats)
+	if err := json.Unmarshal(blob, stats); err != nil {
+		return nil, err
+	}
+	return stats, nil
+}
+
+func (s *supplyStats) store(db ethdb.KeyValueWriter, number uint64, hash common.Hash) error {
+	blob, err := json.Marshal(s)
+	if err != nil {
+		return err
+	}
+	return db.Put(supplyKey(number, hash), blob)
+}
+
+func addSupplyAmount(amounts []supplyAmount, name string, amount *big.Int) []supplyAmount {
+	for i := range amounts {
+		if amounts[i].Name == name {
+			amounts[i].Amount = new(big.Int).Add(amounts[i].Amount, amount)
+			return amounts
+		}
+	}
+	amounts = append(amounts, supplyAmount{Name: name, Amount: new(big.Int).Set(amount)})
+	sort.Slice(amounts, func(i, j int) bool { return amounts[i].Name < amounts[j].Name })
+	return amounts
+}
+
+func sumSupplyAmounts(amounts []supplyAmount) *big.Int {
+	total := new(big.Int)
+	for _, item := range amounts {
+		total.Add(total, item.Amount)
+	}
+	return total
+}
+
+// apply folds the balance changes and lock rewards of a block into the totals.
+// The signer reward includes the retained part of the gas fees, which is moved
+// into custody rather than minted.
+func (s *supplyStats) apply(changes []consensus.BalanceChange, lockRewards []LockRewardRecord, burnBalance *big.Int) {
+	feeDebited, feeBurned := new(big.Int), new(big.Int)
+	for _, change := range changes {
+		if change.Address == burnAddress {
+			s.BurnCredited.Add(s.BurnCredited, change.Delta)
+			s.Burned = addSupplyAmount(s.Burned, change.Reason, change.Delta)
+			if change.Reason == balanceReasonGasFee {
+				feeBurned.Add(feeBurned, change.Delta)
+			}
+			continue
+		}
+		if change.Delta.Sign() > 0 {
+			s.Credited.Add(s.Credited, change.Delta)
+			if supplyDirectMints[change.Reason] {
+				s.Minted = addSupplyAmount(s.Minted, change.Reason, change.Delta)
+			}
+			continue
+		}
+		amount := new(big.Int).Neg(change.Delta)
+		s.Debited.Add(s.Debited, amount)
+		if supplyBurnDebits[change.Reason] {
+			s.Burned = addSupplyAmount(s.Burned, change.Reason, amount)
+		}
+		if change.Reason == balanceReasonGasFee {
+			feeDebited.Add(feeDebited, amount)
+		}
+	}
+	for _, record := range lockRewards {
+		name, ok := supplyRewardNames[record.IsReward]
+		if !ok || record.Amount == nil {
+			continue
+		}
+		amount := record.Amount
+		if record.IsReward == sscEnumSignerReward && feeDebited.Sign() > 0 {
+			retained := new(big.Int).Sub(feeDebited, feeBurned)
+			amount = new(big.Int).Sub(amount, retained)
+			feeDebited.SetUint64(0)
+		}
+		s.Minted = addSupplyAmount(s.Minted, name, amount)
+	}
+	s.BurnBalance = new(big.Int).Set(burnBalance)
+}
+
+// Supply is the token supply at a block, as reported by alien_getSupply. The
+// totals count from the block Since, whose balances are reported as Genesis.
+type Supply struct {
+	Number      uint64              `json:"number"`
+	Hash        common.Hash         `json:"hash"`
+	Since       uint64              `json:"since"`
+	Genesis     *big.Int            `json:"genesis"`
+	Minted      map[string]*big.Int `json:"minted"`
+	TotalMinted *big.Int            `json:"totalMinted"`
+	Locked      *big.Int            `json:"locked"`
+	Released    *big.Int            `json:"released"`
+	Burned      map[string]*big.Int `json:"burned"`
+	TotalBurned *big.Int            `json:"totalBurned"`
+	Balances    *big.Int            `json:"balances"`
+	Circulating *big.Int            `json:"circulating"`
+}
+
+// supply derives the reported figures from the tracked totals.
+//
+//	balances    = genesis + credited - debited + burnCredited
+//	circulating = balances - burn address balance
+//	locked      = debited + minted - credited - engine burns
+//	released    = credited - direct mints
+func (s *supplyStats) supply(header *types.Header) *Supply {
+	supply := &Supply{
+		Number:      header.Number.Uint64(),
+		Hash:        header.Hash(),
+		Since:       s.Since,
+		Genesis:     new(big.Int).Set(s.Baseline),
+		Minted:      make(map[string]*big.Int),
+		TotalMinted: sumSupplyAmounts(s.Minted),
+		Burned:      make(map[string]*big.Int),
+	}
+	directMinted := new(big.Int)
+	for _, item := range s.Minted {
+		supply.Minted[item.Name] = new(big.Int).Set(item.Amount)
+		if supplyDirectMints[item.Name] {
+			directMinted.Add(directMinted, item.Amount)
+		}
+	}
+	engineBurned := sumSupplyAmounts(s.Burned)
+	for _, item := range s.Burned {
+		supply.Burned[item.Name] = new(big.Int).Set(item.Amount)
+	}
+	transferred := new(big.Int).Sub(s.BurnBalance, s.BaselineBurn)
+	transferred.Sub(transferred, s.BurnCredited)
+	if transferred.Sign() != 0 {
+		supply.Burned[supplyReasonTransfer] = transferred
+	}
+	supply.TotalBurned = new(big.Int).Add(engineBurned, transferred)
+
+	supply.Locked = new(big.Int).Add(s.Debited, supply.TotalMinted)
+	supply.Locked.Sub(supply.Locked, s.Credited)
+	supply.Locked.Sub(supply.Locked, engineBurned)
+	supply.Released = new(big.Int).Sub(s.Credited, directMinted)
+
+	supply.Balances = new(big.Int).Add(s.Baseline, s.Credited)
+	supply.Balances.Sub(supply.Balances, s.Debited)
+	supply.Balances.Add(supply.Balances, s.BurnCredited)
+	supply.Circulating = new(big.Int).Sub(supply.Balances, s.BurnBalance)
+	return supply
+}
+
+// sumBalances iterates the account trie of the given state root and returns
+// the sum of all balances.
+func sumBalances(db state.Database, root common.Hash) (*big.Int, error) {
+	tr, err := db.OpenTrie(root)
+	if err != nil {
+		return nil, err
+	}
+	total := new(big.Int)
+	it := trie.NewIterator(tr.NodeIterator(nil))
+	for it.Next() {
+		var account state.Account
+		if err := rlp.DecodeBytes(it.Value, &account); err != nil {
+			return nil, err
+		}
+		total.Add(total, account.Balance)
+	}
+	return total, it.Err
+}
+
+// seedSupplyStats starts the supply tracking at a block from a full sum of the
+// balances in its state.
+func seedSupplyStats(db state.Database, number uint64, root common.Hash) (*supplyStats, error) {
+	baseline, err := sumBalances(db, root)
+	if err != nil {
+		return nil, err
+	}
+	statedb, err := state.New(root, db, nil)
+	if err != nil {
+		return nil, err
+	}
+	return newSupplyStats(number, baseline, statedb.GetBalance(burnAddress)), nil
+}
+
+// supplyDeltaKey identifies the result of finalising a block. Mining and
+// importing the same block yield the same key, recommits of other contents a
+// different one.
+type supplyDeltaKey struct {
+	number uint64
+	parent common.Hash
+	root   common.Hash
+}
+
+func (k supplyDeltaKey) bytes() []byte {
+	key := make([]byte, len(supplyDeltaPrefix)+8)
+	copy(key, supplyDeltaPrefix)
+	binary.BigEndian.PutUint64(key[len(supplyDeltaPrefix):], k.number)
+	return append(append(key, k.parent[:]...), k.root[:]...)
+}
+
+// supplyDelta are the balance changes of a finalised block, kept until the
+// supply indexer has processed the section of the block.
+type supplyDelta struct {
+	Changes     []consensus.BalanceChange
+	LockRewards []LockRewardRecord
+	BurnBalance *big.Int
+}
+
+// noteSupplyDelta remembers the balance changes of a finalised block in
+// memory. They are written out by StoreSupplyDelta once the block is inserted
+// into the chain, so recommits and discarded blocks never reach the database.
+// Nothing is remembered by engines without a supply indexer, such as the
+// trace engine.
+func (a *Alien) noteSupplyDelta(header *types.Header, statedb *state.StateDB, journal *balanceJournal, lockRewards []LockRewardRecord) {
+	if a.supplyDeltas == nil {
+		return
+	}
+	key := supplyDeltaKey{header.Number.Uint64(), header.ParentHash, header.Root}
+	a.supplyDeltas.Add(key, &supplyDelta{
+		Changes:     journal.changes,
+		LockRewards: lockRewards,
+		BurnBalance: statedb.GetBalance(burnAddress),
+	})
+}
+
+// StoreSupplyDelta writes out the balance changes remembered for a block
+// inserted into the canonical chain, so that they are known to the supply
+// indexer after a restart as well.
+func (a *Alien) StoreSupplyDelta(header *types.Header) {
+	if a.supplyDeltas == nil {
+		return
+	}
+	key := supplyDeltaKey{header.Number.Uint64(), header.ParentHash, header.Root}
+	delta, ok := a.supplyDeltas.Peek(key)
+	if !ok {
+		return
+	}
+	blob, err := json.Marshal(delta)
+	if err == nil {
+		err = a.db.Put(key.bytes(), blob)
+	}
+	if err != nil {
+		log.Warn("Failed to store supply delta", "number", key.number, "err", err)
+	}
+}
+
+// supplyDelta returns the balance changes of a finalised block, or nil if the
+// block was not finalised by this node.
+func (a *Alien) supplyDelta(key supplyDeltaKey) *supplyDelta {
+	if delta, ok := a.supplyDeltas.Get(key); ok {
+		return delta.(*supplyDelta)
+	}
+	blob, err := a.db.Get(key.bytes())
+	if err != nil {
+		return nil
+	}
+	delta := new(supplyDelta)
+	if err := json.Unmarshal(blob, delta); err != nil {
+		log.Warn("Invalid supply delta", "number", key.number, "err", err)
+		return nil
+	}
+	return delta
+}
+
+// SupplyIndexer implements core.ChainIndexerBackend, folding the balance
+// changes of the canonical blocks into the supply statistics once they are
+// committed. The tracking is seeded from the genesis state and accumulates the
+// balance changes of the blocks afterwards. At a block whose changes are
+// unknown, e.g. one synchronised without executing it, the tracking is seeded
+// again from the sum of the balances in its state, or at the first later block
+// whose state is available.
+type SupplyIndexer struct {
+	alien *Alien
+	db    state.Database
+	batch ethdb.Batch
+	stats *supplyStats // Statistics of the last processed block
+	head  common.Hash  // Hash of the last processed block
+}
+
+// NewSupplyIndexer creates the supply indexer backend of the engine.
+func (a *Alien) NewSupplyIndexer() *SupplyIndexer {
+	return &SupplyIndexer{
+		alien: a,
+		db:    state.NewDatabase(a.db),
+	}
+}
+
+// Reset implements core.ChainIndexerBackend, continuing from the statistics
+// of the last block of the previous section.
+func (s *SupplyIndexer) Reset(ctx context.Context, section uint64, prevHead common.Hash) error {
+	s.batch = s.alien.db.NewBatch()
+	if s.stats != nil && s.head == prevHead {
+		return nil
+	}
+	s.stats, s.head = nil, prevHead
+	if section > 0 {
+		if stats, err := loadSupplyStats(s.alien.db, section*SupplySectionSize-1, prevHead); err == nil {
+			s.stats = stats
+		}
+	}
+	return nil
+}
+
+// Process implements core.ChainIndexerBackend, applying the balance changes
+// of the block to the statistics of its parent.
+func (s *SupplyIndexer) Process(ctx context.Context, header *types.Header) error {
+	number := header.Number.Uint64()
+	if s.stats != nil && number > 0 && s.head == header.ParentHash {
+		if delta := s.alien.supplyDelta(supplyDeltaKey{number, header.ParentHash, header.Root}); delta != nil {
+			s.stats.apply(delta.Changes, delta.LockRewards, delta.BurnBalance)
+		} else {
+			s.stats = nil
+		}
+	} else {
+		s.stats = nil
+	}
+	if s.stats == nil {
+		stats, err := seedSupplyStats(s.db, number, header.Root)
+		if err != nil {
+			log.Debug("Supply tracking not seeded, state unavailable", "number", number, "hash", header.Hash(), "err", err)
+		} else if number > 0 {
+			log.Info("Supply tracking seeded from the state", "number", number, "hash", header.Hash())
+		}
+		s.stats = stats
+	}
+	s.head = header.Hash()
+	if s.stats == nil {
+		return nil
+	}
+	return s.stats.store(s.batch, number, s.head)
+}
+
+// Commit implements core.ChainIndexerBackend, writing out the statistics of
+// the section.
+func (s *SupplyIndexer) Commit() error {
+	return s.batch.Write()
+}
+
+// Prune implements core.ChainIndexerBackend, deleting the balance changes of
+// the blocks up to the end of the given section, and their statistics except
+// for the last block of each section.
+func (s *SupplyIndexer) Prune(threshold uint64) error {
+	end := (threshold + 1) * SupplySectionSize
+	batch := s.alien.db.NewBatch()
+	for _, prefix := range [][]byte{supplyDeltaPrefix, supplyStatsPrefix} {
+		it := s.alien.db.NewIterator(prefix, nil)
+		for it.Next() {
+			key := it.Key()
+			if len(key) < len(prefix)+8 {
+				continue
+			}
+			number := binary.BigEndian.Uint64(key[len(prefix):])
+			if number >= end {
+				// Past the blocks of the section or past the statistics,
+				// which are followed by the balance changes and the index
+				break
+			}
+			if bytes.Equal(prefix, supplyStatsPrefix) && number%SupplySectionSize == SupplySectionSize-1 {
+				continue
+			}
+			batch.Delete(key)
+			if batch.ValueSize() >= ethdb.IdealBatchSize {
+				if err := batch.Write(); err != nil {
+					it.Release()
+					return err
+				}
+				batch.Reset()
+			}
+		}
+		err := it.Error()
+		it.Release()
+		if err != nil {
+			return err
+		}
+	}
+	return batch.Write()
+}
+
+// SupplyCheck compares the tracked balances at a block with the sum of the
+// balances in its state.
+type SupplyCheck struct {
+	Number        uint64      `json:"number"`
+	Hash          common.Hash `json:"hash"`
+	Balances      *big.Int    `json:"balances"`
+	StateBalances *big.Int    `json:"stateBalances"`
+	Consistent    bool        `json:"consistent"`
+}
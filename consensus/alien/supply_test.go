@@ -0,0 +1,144 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+// TestSupplyIndexer finalises blocks the way the engine does and checks that
+// only the changes of inserted blocks are stored, that the indexer tracks the
+// supply from the genesis with them, seeds the tracking again from the state
+// once the changes of a block are unknown and prunes them.
+func TestSupplyIndexer(t *testing.T) {
+	var (
+		db      = rawdb.NewMemoryDatabase()
+		sdb     = state.NewDatabase(db)
+		engine  = New(&params.AlienConfig{MinVoterBalance: new(big.Int)}, db)
+		indexer = engine.NewSupplyIndexer()
+		holder  = common.HexToAddress("0x1100000000000000000000000000000000000011")
+		signer  = common.HexToAddress("0x2200000000000000000000000000000000000022")
+	)
+	statedb, _ := state.New(common.Hash{}, sdb, nil)
+	statedb.AddBalance(holder, big.NewInt(1000))
+	commit := func(statedb *state.StateDB) common.Hash {
+		root, err := statedb.Commit(true)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := sdb.TrieDB().Commit(root, false, nil); err != nil {
+			t.Fatal(err)
+		}
+		return root
+	}
+	genesis := &types.Header{Number: big.NewInt(0), Root: commit(statedb)}
+
+	// finalise mints a reward for the signer and burns part of the holder funds
+	finalise := func(parent *types.Header, reward int64, burn int64) *types.Header {
+		statedb, _ := state.New(parent.Root, sdb, nil)
+		journal := beginBalanceJournal(statedb)
+		setBalanceReason(statedb, lockReasonName(sscEnumSignerReward), "")
+		statedb.AddBalance(signer, big.NewInt(reward))
+		setBalanceReason(statedb, balanceReasonSRTExchange, "")
+		statedb.SubBalance(holder, big.NewInt(burn))
+
+		// A reverted change leaves no trace
+		snapshot := statedb.Snapshot()
+		statedb.AddBalance(holder, big.NewInt(500))
+		statedb.RevertToSnapshot(snapshot)
+		statedb.SetBalanceHook(nil)
+
+		header := &types.Header{Number: new(big.Int).Add(parent.Number, common.Big1), ParentHash: parent.Hash(), Root: commit(statedb)}
+		rewards := []LockRewardRecord{{Target: signer, Amount: big.NewInt(reward), IsReward: sscEnumSignerReward}}
+		engine.noteSupplyDelta(header, statedb, journal, rewards)
+		return header
+	}
+	headers := []*types.Header{genesis}
+	for i := 0; i < 4; i++ {
+		headers = append(headers, finalise(headers[i], 10, 3))
+		engine.StoreSupplyDelta(headers[i+1])
+	}
+	// A recommit that is never inserted stays in memory
+	recommit := finalise(genesis, 20, 3)
+	if _, err := db.Get(supplyDeltaKey{1, recommit.ParentHash, recommit.Root}.bytes()); err == nil {
+		t.Error("balance changes of a recommit stored")
+	}
+	// The changes of the inserted blocks are left in the database, as after a
+	// restart, those of the third block are lost altogether
+	engine.supplyDeltas.Purge()
+	third := headers[3]
+	db.Delete(supplyDeltaKey{3, third.ParentHash, third.Root}.bytes())
+
+	if err := indexer.Reset(context.Background(), 0, common.Hash{}); err != nil {
+		t.Fatal(err)
+	}
+	for _, header := range headers {
+		if err := indexer.Process(context.Background(), header); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := indexer.Commit(); err != nil {
+		t.Fatal(err)
+	}
+	// The third block starts over from the sum of its state
+	for number, want := range []struct {
+		since                             uint64
+		genesis, balances, minted, burned int64
+	}{
+		1: {0, 1000, 1007, 10, 3},
+		2: {0, 1000, 1014, 20, 6},
+		3: {3, 1021, 1021, 0, 0},
+		4: {3, 1021, 1028, 10, 3},
+	} {
+		if number == 0 {
+			// The genesis is summed up by the API instead
+			continue
+		}
+		stats, err := loadSupplyStats(db, uint64(number), headers[number].Hash())
+		if err != nil {
+			t.Fatalf("block %d not indexed: %v", number, err)
+		}
+		supply := stats.supply(headers[number])
+		if supply.Since != want.since || supply.Genesis.Int64() != want.genesis || supply.Balances.Int64() != want.balances {
+			t.Errorf("block %d: since %d, genesis %v, balances %v, want %d, %d and %d", number, supply.Since, supply.Genesis, supply.Balances, want.since, want.genesis, want.balances)
+		}
+		if supply.TotalMinted.Int64() != want.minted || supply.TotalBurned.Int64() != want.burned {
+			t.Errorf("block %d: minted %v, burned %v, want %d and %d", number, supply.TotalMinted, supply.TotalBurned, want.minted, want.burned)
+		}
+	}
+	// Pruning the section drops the balance changes and the statistics
+	if err := indexer.Prune(0); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := loadSupplyStats(db, 1, headers[1].Hash()); err == nil {
+		t.Error("statistics of block 1 not pruned")
+	}
+	if engine.supplyDelta(supplyDeltaKey{2, headers[2].ParentHash, headers[2].Root}) != nil {
+		t.Error("balance changes of block 2 not pruned")
+	}
+	if engine.supplyDelta(supplyDeltaKey{4, headers[4].ParentHash, headers[4].Root}) != nil {
+		t.Error("balance changes of block 4 not pruned")
+	}
+}
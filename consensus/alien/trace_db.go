@@ -0,0 +1,244 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"errors"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/UltronGlow/UltronGlow-Origin/ethdb"
+	"github.com/hashicorp/golang-lru"
+)
+
+// errTraceNotFound is returned for keys deleted while tracing.
+var errTraceNotFound = errors.New("not found")
+
+// traceDatabase keeps the writes made while tracing a block in memory on top
+// of the chain database, which is left untouched. Reads see the writes.
+type traceDatabase struct {
+	ethdb.Database
+	lock   sync.RWMutex
+	writes map[string][]byte // Written values, nil for deleted keys
+}
+
+func newTraceDatabase(db ethdb.Database) *traceDatabase {
+	return &traceDatabase{Database: db, writes: make(map[string][]byte)}
+}
+
+// Has implements ethdb.KeyValueReader.
+func (db *traceDatabase) Has(key []byte) (bool, error) {
+	db.lock.RLock()
+	value, ok := db.writes[string(key)]
+	db.lock.RUnlock()
+	if ok {
+		return value != nil, nil
+	}
+	return db.Database.Has(key)
+}
+
+// Get implements ethdb.KeyValueReader.
+func (db *traceDatabase) Get(key []byte) ([]byte, error) {
+	db.lock.RLock()
+	value, ok := db.writes[string(key)]
+	db.lock.RUnlock()
+	if !ok {
+		return db.Database.Get(key)
+	}
+	if value == nil {
+		return nil, errTraceNotFound
+	}
+	return append([]byte{}, value...), nil
+}
+
+// Put implements ethdb.KeyValueWriter.
+func (db *traceDatabase) Put(key []byte, value []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	db.writes[string(key)] = append([]byte{}, value...)
+	return nil
+}
+
+// Delete implements ethdb.KeyValueWriter.
+func (db *traceDatabase) Delete(key []byte) error {
+	db.lock.Lock()
+	defer db.lock.Unlock()
+	db.writes[string(key)] = nil
+	return nil
+}
+
+// NewIterator implements ethdb.Iteratee, merging the writes made while tracing
+// into the iteration of the chain database.
+func (db *traceDatabase) NewIterator(prefix []byte, start []byte) ethdb.Iterator {
+	first := string(prefix) + string(start)
+	it := &traceIterator{base: db.Database.NewIterator(prefix, start)}
+
+	db.lock.RLock()
+	for key := range db.writes {
+		if strings.HasPrefix(key, string(prefix)) && key >= first {
+			it.keys = append(it.keys, key)
+		}
+	}
+	sort.Strings(it.keys)
+	for _, key := range it.keys {
+		it.values = append(it.values, db.writes[key])
+	}
+	db.lock.RUnlock()
+
+	it.baseOk = it.base.Next()
+	return it
+}
+
+// traceIterator iterates the chain database with the writes of a trace
+// database, as they were when the iterator was created, on top.
+type traceIterator struct {
+	base   ethdb.Iterator
+	baseOk bool     // Whether the chain database iterator is at an entry
+	keys   []string // Written keys left to iterate, sorted
+	values [][]byte // Written values of the keys, nil for deleted ones
+	key    []byte
+	value  []byte
+}
+
+// Next implements ethdb.Iterator. Written keys take the place of the same keys
+// in the chain database and deleted keys are skipped.
+func (it *traceIterator) Next() bool {
+	for {
+		switch {
+		case len(it.keys) == 0 && !it.baseOk:
+			it.key, it.value = nil, nil
+			return false
+
+		case len(it.keys) > 0 && (!it.baseOk || it.keys[0] <= string(it.base.Key())):
+			key, value := it.keys[0], it.values[0]
+			it.keys, it.values = it.keys[1:], it.values[1:]
+			if it.baseOk && key == string(it.base.Key()) {
+				it.baseOk = it.base.Next()
+			}
+			if value == nil {
+				continue
+			}
+			it.key, it.value = []byte(key), value
+			return true
+
+		default:
+			it.key = append([]byte{}, it.base.Key()...)
+			it.value = append([]byte{}, it.base.Value()...)
+			it.baseOk = it.base.Next()
+			return true
+		}
+	}
+}
+
+// Error implements ethdb.Iterator.
+func (it *traceIterator) Error() error {
+	return it.base.Error()
+}
+
+// Key implements ethdb.Iterator.
+func (it *traceIterator) Key() []byte {
+	return it.key
+}
+
+// Value implements ethdb.Iterator.
+func (it *traceIterator) Value() []byte {
+	return it.value
+}
+
+// Release implements ethdb.Iterator.
+func (it *traceIterator) Release() {
+	it.base.Release()
+	it.keys, it.values = nil, nil
+}
+
+// NewBatch implements ethdb.Batcher.
+func (db *traceDatabase) NewBatch() ethdb.Batch {
+	return &traceBatch{db: db}
+}
+
+// traceWrite is a write queued in a trace batch.
+type traceWrite struct {
+	key    []byte
+	value  []byte
+	delete bool
+}
+
+// traceBatch queues writes to a trace database.
+type traceBatch struct {
+	db     *traceDatabase
+	writes []traceWrite
+	size   int
+}
+
+// Put implements ethdb.KeyValueWriter.
+func (b *traceBatch) Put(key []byte, value []byte) error {
+	b.writes = append(b.writes, traceWrite{key: append([]byte{}, key...), value: append([]byte{}, value...)})
+	b.size += len(value)
+	return nil
+}
+
+// Delete implements ethdb.KeyValueWriter.
+func (b *traceBatch) Delete(key []byte) error {
+	b.writes = append(b.writes, traceWrite{key: append([]byte{}, key...), delete: true})
+	b.size += len(key)
+	return nil
+}
+
+// ValueSize implements ethdb.Batch.
+func (b *traceBatch) ValueSize() int {
+	return b.size
+}
+
+// Write implements ethdb.Batch, moving the writes into the trace database.
+func (b *traceBatch) Write() error {
+	return b.Replay(b.db)
+}
+
+// Reset implements ethdb.Batch.
+func (b *traceBatch) Reset() {
+	b.writes = b.writes[:0]
+	b.size = 0
+}
+
+// Replay implements ethdb.Batch.
+func (b *traceBatch) Replay(w ethdb.KeyValueWriter) error {
+	for _, write := range b.writes {
+		if write.delete {
+			if err := w.Delete(write.key); err != nil {
+				return err
+			}
+			continue
+		}
+		if err := w.Put(write.key, write.value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// traceEngine returns an engine finalising blocks like this one, but keeping
+// its database writes and snapshot caches to itself. It has no signer.
+func (a *Alien) traceEngine() *Alien {
+	recents, _ := lru.NewARC(inMemorySnapshots)
+	return &Alien{
+		config:     a.config,
+		db:         newTraceDatabase(a.db),
+		recents:    recents,
+		signatures: a.signatures,
+	}
+}
@@ -0,0 +1,206 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+	"reflect"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+// TestTraceDatabase checks that the writes made while tracing are visible to
+// the tracer but never reach the chain database.
+func TestTraceDatabase(t *testing.T) {
+	chainDb := rawdb.NewMemoryDatabase()
+	chainDb.Put([]byte("kept"), []byte{1})
+	chainDb.Put([]byte("deleted"), []byte{2})
+
+	db := newTraceDatabase(chainDb)
+	db.Put([]byte("kept"), []byte{3})
+	batch := db.NewBatch()
+	batch.Put([]byte("added"), []byte{4})
+	batch.Delete([]byte("deleted"))
+	if err := batch.Write(); err != nil {
+		t.Fatal(err)
+	}
+	if value, _ := db.Get([]byte("kept")); !bytes.Equal(value, []byte{3}) {
+		t.Errorf("traced value mismatch: have %x, want 03", value)
+	}
+	if value, _ := db.Get([]byte("added")); !bytes.Equal(value, []byte{4}) {
+		t.Errorf("traced batch value mismatch: have %x, want 04", value)
+	}
+	if has, _ := db.Has([]byte("deleted")); has {
+		t.Errorf("traced deletion not visible")
+	}
+	if value, _ := chainDb.Get([]byte("kept")); !bytes.Equal(value, []byte{1}) {
+		t.Errorf("chain value changed: have %x, want 01", value)
+	}
+	if has, _ := chainDb.Has([]byte("added")); has {
+		t.Errorf("traced write reached the chain database")
+	}
+	if has, _ := chainDb.Has([]byte("deleted")); !has {
+		t.Errorf("traced deletion reached the chain database")
+	}
+}
+
+// TestTraceDatabaseIterator checks that iterating a trace database sees the
+// writes made while tracing in key order.
+func TestTraceDatabaseIterator(t *testing.T) {
+	chainDb := rawdb.NewMemoryDatabase()
+	for _, key := range []string{"a1", "a3", "a5", "b1"} {
+		chainDb.Put([]byte(key), []byte("chain"))
+	}
+	db := newTraceDatabase(chainDb)
+	db.Put([]byte("a0"), []byte("trace"))
+	db.Put([]byte("a3"), []byte("trace"))
+	db.Delete([]byte("a5"))
+	db.Put([]byte("a6"), []byte("trace"))
+	db.Put([]byte("b0"), []byte("trace"))
+
+	iterate := func(prefix string, start string) string {
+		it := db.NewIterator([]byte(prefix), []byte(start))
+		defer it.Release()
+
+		var entries []string
+		for it.Next() {
+			entries = append(entries, fmt.Sprintf("%s=%s", it.Key(), it.Value()))
+		}
+		if err := it.Error(); err != nil {
+			t.Fatal(err)
+		}
+		return fmt.Sprint(entries)
+	}
+	if have, want := iterate("a", ""), "[a0=trace a1=chain a3=trace a6=trace]"; have != want {
+		t.Errorf("prefix iteration mismatch: have %s, want %s", have, want)
+	}
+	if have, want := iterate("a", "2"), "[a3=trace a6=trace]"; have != want {
+		t.Errorf("iteration from start mismatch: have %s, want %s", have, want)
+	}
+	if have, want := iterate("", ""), "[a0=trace a1=chain a3=trace a6=trace b0=trace b1=chain]"; have != want {
+		t.Errorf("full iteration mismatch: have %s, want %s", have, want)
+	}
+}
+
+// traceTestChain serves the genesis header of a chain to finalise the first
+// block on.
+type traceTestChain struct {
+	config  *params.ChainConfig
+	genesis *types.Header
+}
+
+func (c *traceTestChain) Config() *params.ChainConfig  { return c.config }
+func (c *traceTestChain) CurrentHeader() *types.Header { return c.genesis }
+func (c *traceTestChain) GetHeader(hash common.Hash, number uint64) *types.Header {
+	if number == 0 && hash == c.genesis.Hash() {
+		return c.genesis
+	}
+	return nil
+}
+func (c *traceTestChain) GetHeaderByNumber(number uint64) *types.Header {
+	if number == 0 {
+		return c.genesis
+	}
+	return nil
+}
+func (c *traceTestChain) GetHeaderByHash(hash common.Hash) *types.Header {
+	return c.GetHeader(hash, 0)
+}
+
+// TestTraceFinalize finalises the first block of a chain through the tracer
+// and checks the balance changes reported, and that the chain database and
+// the header are left untouched.
+func TestTraceFinalize(t *testing.T) {
+	var (
+		signer = common.HexToAddress("0x3300000000000000000000000000000000000033")
+		conf   = &params.AlienConfig{
+			Period:           3,
+			Epoch:            201600,
+			MaxSignerCount:   3,
+			MinVoterBalance:  big.NewInt(1),
+			GenesisTimestamp: 1,
+			SelfVoteSigners:  []common.UnprefixedAddress{common.UnprefixedAddress(signer)},
+		}
+		config = &params.ChainConfig{
+			ChainID:        big.NewInt(1),
+			HomesteadBlock: big.NewInt(0),
+			EIP150Block:    big.NewInt(0),
+			EIP155Block:    big.NewInt(0),
+			EIP158Block:    big.NewInt(0),
+			Alien:          conf,
+		}
+		db    = rawdb.NewMemoryDatabase()
+		gspec = &core.Genesis{
+			Config:    config,
+			GasLimit:  8000000,
+			ExtraData: make([]byte, extraVanity+extraSeal),
+			Alloc:     core.GenesisAlloc{signer: {Balance: big.NewInt(params.Ether)}},
+		}
+		genesis = gspec.MustCommit(db)
+		chain   = &traceTestChain{config: config, genesis: genesis.Header()}
+		engine  = New(conf, db)
+	)
+	statedb, _ := state.New(genesis.Root(), state.NewDatabase(db), nil)
+	header := &types.Header{
+		ParentHash: genesis.Hash(),
+		Number:     big.NewInt(1),
+		Coinbase:   signer,
+		Time:       genesis.Time() + conf.Period,
+		Difficulty: big.NewInt(1),
+		GasLimit:   gspec.GasLimit,
+	}
+	countKeys := func() (n int) {
+		it := db.NewIterator(nil, nil)
+		defer it.Release()
+		for it.Next() {
+			n++
+		}
+		return n
+	}
+	hash, keys := header.Hash(), countKeys()
+
+	changes, err := engine.TraceFinalize(chain, header, statedb, nil, nil, nil, big.NewInt(1000))
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	// The gas reward is taken from the signer into the signer reward lock
+	want := []consensus.BalanceChange{{Address: signer, Delta: big.NewInt(-1000), Reason: balanceReasonGasFee}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("balance changes mismatch: have %v, want %v", changes, want)
+	}
+	if statedb.BalanceHook() != nil {
+		t.Error("balance journal left on the state")
+	}
+	if header.Hash() != hash {
+		t.Error("traced header modified")
+	}
+	if n := countKeys(); n != keys {
+		t.Errorf("tracing wrote to the chain database: %d keys, want %d", n, keys)
+	}
+	if _, ok := engine.recents.Get(genesis.Hash()); ok {
+		t.Error("tracing cached a snapshot in the engine")
+	}
+}
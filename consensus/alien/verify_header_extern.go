@@ -62,6 +62,18 @@ const (
 	see_s      = "SEExit"
 	post_s     = "POSTransfer"
 	SpBind_s   ="SpBind"
+	lar_s      = "LeaseAutoRenew"
+	ltr_s      = "LeaseTransfer"
+	lch_s      = "LeaseChallenge"
+	lcp_s      = "LeaseChallengeProof"
+	red_s      = "Redelegate"
+	rsp_s      = "RevenueSplit"
+	acp_s      = "AutoCompound"
+	cpd_s      = "Compounded"
+	cfc_s      = "ConfigCancel"
+	srt_s      = "SignerRotate"
+	blk_s      = "BridgeLock"
+	bbn_s      = "BridgeBurn"
 )
 
 func verifyHeaderExtern(currentExtra *HeaderExtra, verifyExtra *HeaderExtra) error {
@@ -384,6 +396,54 @@ func verifyHeaderExtern(currentExtra *HeaderExtra, verifyExtra *HeaderExtra) err
 	if err != nil {
 		return err
 	}
+	err = verifyLeaseAutoRenew(currentExtra.LeaseAutoRenew, verifyExtra.LeaseAutoRenew)
+	if err != nil {
+		return err
+	}
+	err = verifyLeaseTransfer(currentExtra.LeaseTransfer, verifyExtra.LeaseTransfer)
+	if err != nil {
+		return err
+	}
+	err = verifyLeaseChallenge(currentExtra.LeaseChallenge, verifyExtra.LeaseChallenge)
+	if err != nil {
+		return err
+	}
+	err = verifyLeaseChallengeProof(currentExtra.LeaseChallengeProof, verifyExtra.LeaseChallengeProof)
+	if err != nil {
+		return err
+	}
+	err = verifyRedelegate(currentExtra.Redelegate, verifyExtra.Redelegate)
+	if err != nil {
+		return err
+	}
+	err = verifyRevenueSplit(currentExtra.RevenueSplit, verifyExtra.RevenueSplit)
+	if err != nil {
+		return err
+	}
+	err = verifyAutoCompound(currentExtra.AutoCompound, verifyExtra.AutoCompound)
+	if err != nil {
+		return err
+	}
+	err = verifyCompounded(currentExtra.Compounded, verifyExtra.Compounded)
+	if err != nil {
+		return err
+	}
+	err = verifyConfigCancel(currentExtra.ConfigCancel, verifyExtra.ConfigCancel)
+	if err != nil {
+		return err
+	}
+	err = verifySignerRotate(currentExtra.SignerRotate, verifyExtra.SignerRotate)
+	if err != nil {
+		return err
+	}
+	err = verifyBridgeTransfer(blk_s, currentExtra.BridgeLock, verifyExtra.BridgeLock)
+	if err != nil {
+		return err
+	}
+	err = verifyBridgeTransfer(bbn_s, currentExtra.BridgeBurn, verifyExtra.BridgeBurn)
+	if err != nil {
+		return err
+	}
 	return nil
 }
 
@@ -2430,4 +2490,435 @@ func compareSpBind(a []SpBindRecord, b []SpBindRecord) error {
 		}
 	}
 	return nil
+}
+
+func verifyLeaseAutoRenew(current []LeaseAutoRenewRecord, verify []LeaseAutoRenewRecord) error {
+	arrLen, err := verifyArrayBasic(lar_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareLeaseAutoRenew(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareLeaseAutoRenew(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareLeaseAutoRenew(a []LeaseAutoRenewRecord, b []LeaseAutoRenewRecord) error {
+	b2 := make([]LeaseAutoRenewRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Address == v.Address && c.Hash == v.Hash && c.Owner == v.Owner && c.Duration.Cmp(v.Duration) == 0 && c.MaxPrice.Cmp(v.MaxPrice) == 0 && c.Deposit.Cmp(v.Deposit) == 0 && c.SRTAllowance.Cmp(v.SRTAllowance) == 0 {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(lar_s, c)
+		}
+	}
+	return nil
+}
+
+func verifyLeaseTransfer(current []LeaseTransferRecord, verify []LeaseTransferRecord) error {
+	arrLen, err := verifyArrayBasic(ltr_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareLeaseTransfer(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareLeaseTransfer(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareLeaseTransfer(a []LeaseTransferRecord, b []LeaseTransferRecord) error {
+	b2 := make([]LeaseTransferRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Address == v.Address && c.Hash == v.Hash && c.Original == v.Original && c.NewOwner == v.NewOwner {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(ltr_s, c)
+		}
+	}
+	return nil
+}
+
+func verifyLeaseChallenge(current []LeaseChallengeRecord, verify []LeaseChallengeRecord) error {
+	arrLen, err := verifyArrayBasic(lch_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareLeaseChallenge(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareLeaseChallenge(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareLeaseChallenge(a []LeaseChallengeRecord, b []LeaseChallengeRecord) error {
+	b2 := make([]LeaseChallengeRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Address == v.Address && c.Hash == v.Hash && c.Challenger == v.Challenger && c.SeedNumber.Cmp(v.SeedNumber) == 0 {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(lch_s, c)
+		}
+	}
+	return nil
+}
+
+func verifyLeaseChallengeProof(current []LeaseChallengeProofRecord, verify []LeaseChallengeProofRecord) error {
+	arrLen, err := verifyArrayBasic(lcp_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareLeaseChallengeProof(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareLeaseChallengeProof(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareLeaseChallengeProof(a []LeaseChallengeProofRecord, b []LeaseChallengeProofRecord) error {
+	b2 := make([]LeaseChallengeProofRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Address == v.Address && c.Hash == v.Hash && c.SeedNumber.Cmp(v.SeedNumber) == 0 {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(lcp_s, c)
+		}
+	}
+	return nil
+}
+
+func verifyRedelegate(current []RedelegateRecord, verify []RedelegateRecord) error {
+	arrLen, err := verifyArrayBasic(red_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareRedelegate(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareRedelegate(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareRedelegate(a []RedelegateRecord, b []RedelegateRecord) error {
+	b2 := make([]RedelegateRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Address == v.Address && c.Hash == v.Hash && c.SourceType == v.SourceType && c.Source == v.Source && c.SourceHash == v.SourceHash &&
+				c.TargetType == v.TargetType && c.Target == v.Target && c.TargetHash == v.TargetHash && c.Amount.Cmp(v.Amount) == 0 && c.Height.Cmp(v.Height) == 0 {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(red_s, c)
+		}
+	}
+	return nil
+}
+
+func verifyRevenueSplit(current []RevenueSplitRecord, verify []RevenueSplitRecord) error {
+	arrLen, err := verifyArrayBasic(rsp_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareRevenueSplit(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareRevenueSplit(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareRevenueSplit(a []RevenueSplitRecord, b []RevenueSplitRecord) error {
+	b2 := make([]RevenueSplitRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Device == v.Device && c.Type == v.Type && equalRevenueShares(c.Beneficiaries, v.Beneficiaries) {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(rsp_s, c)
+		}
+	}
+	return nil
+}
+
+func equalRevenueShares(a []RevenueShare, b []RevenueShare) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+func verifyAutoCompound(current []AutoCompoundRecord, verify []AutoCompoundRecord) error {
+	arrLen, err := verifyArrayBasic(acp_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareAutoCompound(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareAutoCompound(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareAutoCompound(a []AutoCompoundRecord, b []AutoCompoundRecord) error {
+	b2 := make([]AutoCompoundRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Address == v.Address && c.TargetType == v.TargetType && c.Target == v.Target && c.Threshold.Cmp(v.Threshold) == 0 {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(acp_s, c)
+		}
+	}
+	return nil
+}
+
+func verifyCompounded(current []CompoundRecord, verify []CompoundRecord) error {
+	arrLen, err := verifyArrayBasic(cpd_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareCompounded(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareCompounded(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareCompounded(a []CompoundRecord, b []CompoundRecord) error {
+	b2 := make([]CompoundRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Address == v.Address && c.TargetType == v.TargetType && c.Target == v.Target && c.Hash == v.Hash && c.Amount.Cmp(v.Amount) == 0 {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(cpd_s, c)
+		}
+	}
+	return nil
+}
+
+func verifyConfigCancel(current []ConfigCancelRecord, verify []ConfigCancelRecord) error {
+	arrLen, err := verifyArrayBasic(cfc_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareConfigCancel(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareConfigCancel(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareConfigCancel(a []ConfigCancelRecord, b []ConfigCancelRecord) error {
+	b2 := make([]ConfigCancelRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Hash == v.Hash && c.Signer == v.Signer {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(cfc_s, c)
+		}
+	}
+	return nil
+}
+
+func verifySignerRotate(current []SignerRotateRecord, verify []SignerRotateRecord) error {
+	arrLen, err := verifyArrayBasic(srt_s, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareSignerRotate(current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareSignerRotate(verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareSignerRotate(a []SignerRotateRecord, b []SignerRotateRecord) error {
+	b2 := make([]SignerRotateRecord, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.Signer == v.Signer && c.NewSigner == v.NewSigner && c.ActiveNumber == v.ActiveNumber {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(srt_s, c)
+		}
+	}
+	return nil
+}
+
+func verifyBridgeTransfer(name string, current []BridgeTransfer, verify []BridgeTransfer) error {
+	arrLen, err := verifyArrayBasic(name, current, verify)
+	if err != nil {
+		return err
+	}
+	if arrLen == 0 {
+		return nil
+	}
+	err = compareBridgeTransfer(name, current, verify)
+	if err != nil {
+		return err
+	}
+	err = compareBridgeTransfer(name, verify, current)
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+func compareBridgeTransfer(name string, a []BridgeTransfer, b []BridgeTransfer) error {
+	b2 := make([]BridgeTransfer, len(b))
+	copy(b2, b)
+	for _, c := range a {
+		find := false
+		for i, v := range b2 {
+			if c.equal(&v) {
+				find = true
+				b2 = append(b2[:i], b2[i+1:]...)
+				break
+			}
+		}
+		if !find {
+			return errorsMsg4(name, c)
+		}
+	}
+	return nil
 }
\ No newline at end of file
@@ -0,0 +1,506 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math/big"
+	"net"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/event"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+	"github.com/UltronGlow/UltronGlow-Origin/metrics"
+)
+
+const (
+	watchdogChainHeadChanSize = 10              // Size of the channel listening to new chain heads
+	watchdogWebhookQueueSize  = 64              // Alert batches waiting for the webhook
+	watchdogWebhookTimeout    = 5 * time.Second // Timeout of one webhook post
+	watchdogCriticalDays      = 7               // Days before a penalty turning the alert critical
+)
+
+// Kinds and levels of the operator alerts
+const (
+	alertKindMissedSlots         = "missedslots"
+	alertKindStorageVerification = "storageverification"
+	alertKindBandwidth           = "bandwidth"
+	alertKindAutoExit            = "autoexit"
+
+	alertLevelWarning  = "warning"
+	alertLevelCritical = "critical"
+	alertLevelResolved = "resolved"
+)
+
+var (
+	errWatchdogDisabled  = errors.New("operator watchdog not configured")
+	errWatchdogEngine    = errors.New("operator watchdog requires the alien engine")
+	errWatchdogAddresses = errors.New("operator watchdog without addresses")
+	errWatchdogWebhook   = errors.New("operator watchdog webhook must be a local http url")
+)
+
+var (
+	watchdogAlertsGauge   = metrics.NewRegisteredGauge("alien/watchdog/alerts", nil)
+	watchdogCriticalGauge = metrics.NewRegisteredGauge("alien/watchdog/critical", nil)
+	watchdogWebhookMeter  = metrics.NewRegisteredMeter("alien/watchdog/webhook/failed", nil)
+)
+
+// WatchdogConfig configures the operator watchdog.
+type WatchdogConfig struct {
+	Addresses []common.Address `toml:",omitempty"` // Signer, candidate and storage pledge addresses of the operator
+	Webhook   string           `toml:",omitempty"` // Local URL the changed alerts are posted to
+}
+
+// Alert warns the operator of a penalty ahead for one of the watched addresses.
+type Alert struct {
+	Address  common.Address `json:"address"`
+	Kind     string         `json:"kind"`
+	Level    string         `json:"level"`
+	Since    uint64         `json:"since"`              // block the alert was raised at
+	Number   uint64         `json:"number"`             // block the alert was last evaluated at
+	Deadline uint64         `json:"deadline,omitempty"` // block the penalty is expected at
+	Value    uint64         `json:"value"`              // punished credit, failed days, bandwidth or days left
+	Message  string         `json:"message"`
+}
+
+type watchdogKey struct {
+	address common.Address
+	kind    string
+}
+
+// WatchdogChain is the chain the watchdog evaluates the heads of, delivering
+// each new head on the channels subscribed to it.
+type WatchdogChain interface {
+	consensus.ChainHeaderReader
+	SubscribeNewHead(ch chan<- *types.Header) event.Subscription
+}
+
+// Watchdog evaluates the snapshot of each new head for the signer and storage
+// obligations of the operator addresses, and warns about missed slots, failing
+// storage verification, bandwidth at the punish line and a coming candidate
+// auto exit before the penalties are applied. The alerts are logged, exported
+// as metrics, served by alien_getAlerts and optionally posted to a webhook.
+type Watchdog struct {
+	engine *Alien
+	chain  WatchdogChain
+	config WatchdogConfig
+	client *http.Client
+
+	headSub   event.Subscription
+	webhookCh chan []*Alert
+	quit      chan struct{}
+	wg        sync.WaitGroup
+
+	lock   sync.RWMutex
+	alerts map[watchdogKey]*Alert
+}
+
+// NewWatchdog creates the operator watchdog of the engine, to be registered as
+// a lifecycle of the node.
+func NewWatchdog(chain WatchdogChain, engine consensus.Engine, config WatchdogConfig) (*Watchdog, error) {
+	alien, ok := engine.(*Alien)
+	if !ok {
+		return nil, errWatchdogEngine
+	}
+	if len(config.Addresses) == 0 {
+		return nil, errWatchdogAddresses
+	}
+	if config.Webhook != "" {
+		if err := validateWebhook(config.Webhook); err != nil {
+			return nil, err
+		}
+	}
+	w := &Watchdog{
+		engine: alien,
+		chain:  chain,
+		config: config,
+		client: &http.Client{Timeout: watchdogWebhookTimeout},
+		quit:   make(chan struct{}),
+		alerts: make(map[watchdogKey]*Alert),
+	}
+	if config.Webhook != "" {
+		w.webhookCh = make(chan []*Alert, watchdogWebhookQueueSize)
+	}
+	alien.lock.Lock()
+	alien.watchdog = w
+	alien.lock.Unlock()
+	return w, nil
+}
+
+// validateWebhook checks that the webhook is an http url of the local host, so
+// the alerts never leave the machine of the operator.
+func validateWebhook(webhook string) error {
+	u, err := url.Parse(webhook)
+	if err != nil {
+		return err
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return errWatchdogWebhook
+	}
+	host := u.Hostname()
+	if host == "localhost" {
+		return nil
+	}
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		return nil
+	}
+	return errWatchdogWebhook
+}
+
+// Start implements node.Lifecycle, starting to evaluate the new heads.
+func (w *Watchdog) Start() error {
+	headCh := make(chan *types.Header, watchdogChainHeadChanSize)
+	w.headSub = w.chain.SubscribeNewHead(headCh)
+
+	w.wg.Add(1)
+	go w.loop(headCh)
+	if w.webhookCh != nil {
+		w.wg.Add(1)
+		go w.webhookLoop()
+	}
+	log.Info("Operator watchdog started", "addresses", len(w.config.Addresses), "webhook", w.config.Webhook)
+	return nil
+}
+
+// Stop implements node.Lifecycle, terminating the watchdog.
+func (w *Watchdog) Stop() error {
+	w.headSub.Unsubscribe()
+	close(w.quit)
+	w.wg.Wait()
+	log.Info("Operator watchdog stopped")
+	return nil
+}
+
+func (w *Watchdog) loop(headCh chan *types.Header) {
+	defer w.wg.Done()
+
+	if header := w.chain.CurrentHeader(); header != nil {
+		w.evaluate(header)
+	}
+	for {
+		select {
+		case header := <-headCh:
+			w.evaluate(header)
+		case <-w.headSub.Err():
+			return
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// evaluate updates the alerts with the snapshot of the head.
+func (w *Watchdog) evaluate(header *types.Header) {
+	number := header.Number.Uint64()
+	snap, err := w.engine.snapshot(w.chain, number, header.Hash(), nil, nil, defaultLoopCntRecalculateSigners)
+	if err != nil {
+		log.Warn("Fail to evaluate operator alerts", "number", number, "err", err)
+		return
+	}
+	changed := w.update(snap.operatorAlerts(w.config.Addresses, number), number)
+	if len(changed) == 0 || w.webhookCh == nil {
+		return
+	}
+	select {
+	case w.webhookCh <- changed:
+	default:
+		watchdogWebhookMeter.Mark(int64(len(changed)))
+		log.Warn("Operator alert webhook queue full", "dropped", len(changed))
+	}
+}
+
+// update replaces the alerts with the ones of the head, and returns the alerts
+// raised, resolved or changed in level by it.
+func (w *Watchdog) update(alerts []*Alert, number uint64) []*Alert {
+	w.lock.Lock()
+	defer w.lock.Unlock()
+
+	var (
+		changed  []*Alert
+		current  = make(map[watchdogKey]*Alert, len(alerts))
+		critical = int64(0)
+	)
+	for _, alert := range alerts {
+		key := watchdogKey{alert.Address, alert.Kind}
+		alert.Since = number
+		if prev, ok := w.alerts[key]; ok {
+			alert.Since = prev.Since
+			if prev.Level == alert.Level {
+				current[key] = alert
+				continue
+			}
+		}
+		current[key] = alert
+		changed = append(changed, alert)
+		log.Warn("Operator alert", "address", alert.Address, "kind", alert.Kind, "level", alert.Level, "value", alert.Value, "deadline", alert.Deadline, "message", alert.Message)
+	}
+	for key, prev := range w.alerts {
+		if _, ok := current[key]; ok {
+			continue
+		}
+		resolved := *prev
+		resolved.Level = alertLevelResolved
+		resolved.Number = number
+		changed = append(changed, &resolved)
+		watchdogAlertGauge(key.address, key.kind).Update(0)
+		log.Info("Operator alert resolved", "address", key.address, "kind", key.kind, "since", prev.Since)
+	}
+	for key, alert := range current {
+		if alert.Level == alertLevelCritical {
+			critical++
+		}
+		watchdogAlertGauge(key.address, key.kind).Update(int64(alert.Value))
+	}
+	watchdogAlertsGauge.Update(int64(len(current)))
+	watchdogCriticalGauge.Update(critical)
+	w.alerts = current
+	return changed
+}
+
+// Alerts returns the alerts of the last evaluated head, ordered by address and
+// kind.
+func (w *Watchdog) Alerts() []*Alert {
+	w.lock.RLock()
+	defer w.lock.RUnlock()
+
+	alerts := make([]*Alert, 0, len(w.alerts))
+	for _, alert := range w.alerts {
+		cpy := *alert
+		alerts = append(alerts, &cpy)
+	}
+	sort.Slice(alerts, func(i, j int) bool {
+		if alerts[i].Address != alerts[j].Address {
+			return bytes.Compare(alerts[i].Address[:], alerts[j].Address[:]) < 0
+		}
+		return alerts[i].Kind < alerts[j].Kind
+	})
+	return alerts
+}
+
+func (w *Watchdog) webhookLoop() {
+	defer w.wg.Done()
+
+	for {
+		select {
+		case alerts := <-w.webhookCh:
+			if err := w.post(alerts); err != nil {
+				watchdogWebhookMeter.Mark(int64(len(alerts)))
+				log.Warn("Fail to post operator alerts", "url", w.config.Webhook, "err", err)
+			}
+		case <-w.quit:
+			return
+		}
+	}
+}
+
+// post sends the alerts to the webhook as a json array.
+func (w *Watchdog) post(alerts []*Alert) error {
+	body, err := json.Marshal(alerts)
+	if err != nil {
+		return err
+	}
+	resp, err := w.client.Post(w.config.Webhook, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode < http.StatusOK || resp.StatusCode >= http.StatusMultipleChoices {
+		return fmt.Errorf("webhook response %s", resp.Status)
+	}
+	return nil
+}
+
+// watchdogAlertGauge returns the gauge with the value of the alert of kind for
+// the address.
+func watchdogAlertGauge(address common.Address, kind string) metrics.Gauge {
+	return metrics.GetOrRegisterGauge("alien/watchdog/"+strings.ToLower(address.Hex())+"/"+kind, nil)
+}
+
+// operatorAlerts returns the alerts of the addresses at the block number.
+func (s *Snapshot) operatorAlerts(addresses []common.Address, number uint64) []*Alert {
+	var alerts []*Alert
+	for _, address := range addresses {
+		if alert := s.missedSlotsAlert(address); alert != nil {
+			alerts = append(alerts, alert)
+		}
+		if alert := s.autoExitAlert(address, number); alert != nil {
+			alerts = append(alerts, alert)
+		}
+		if s.StorageData == nil {
+			continue
+		}
+		pledge, ok := s.StorageData.StoragePledge[address]
+		if !ok || pledge.PledgeStatus == nil || pledge.PledgeStatus.Cmp(big.NewInt(SPledgeExit)) == 0 {
+			continue
+		}
+		if alert := s.storageVerificationAlert(address, pledge); alert != nil {
+			alerts = append(alerts, alert)
+		}
+		if alert := s.bandwidthAlert(address, pledge); alert != nil {
+			alerts = append(alerts, alert)
+		}
+	}
+	for _, alert := range alerts {
+		alert.Number = number
+	}
+	return alerts
+}
+
+// missedSlotsAlert warns about the credit punished for missed slots, critical
+// once the signer is left out of the signer queue for it.
+func (s *Snapshot) missedSlotsAlert(address common.Address) *Alert {
+	credit, ok := s.Punished[address]
+	if !ok || credit == 0 {
+		return nil
+	}
+	alert := &Alert{
+		Address: address,
+		Kind:    alertKindMissedSlots,
+		Level:   alertLevelWarning,
+		Value:   credit,
+		Message: fmt.Sprintf("punished credit %d of %d for missed slots", credit, defaultFullCredit),
+	}
+	if credit >= minCalSignerQueueCredit {
+		alert.Level = alertLevelCritical
+		alert.Message += ", left out of the signer queue"
+	}
+	return alert
+}
+
+// autoExitAlert counts down the days to the auto exit of a punished PoS
+// candidate, as applied by checkCandidateAutoExit.
+func (s *Snapshot) autoExitAlert(address common.Address, number uint64) *Alert {
+	pledge, ok := s.PosPledge[address]
+	if !ok || pledge.LastPunish == 0 {
+		return nil
+	}
+	blockPerDay := s.getBlockPreDay()
+	deadline := pledge.LastPunish + maxPosContinueDayFail*blockPerDay
+	daysLeft := uint64(0)
+	if deadline > number {
+		daysLeft = (deadline - number) / blockPerDay
+	}
+	alert := &Alert{
+		Address:  address,
+		Kind:     alertKindAutoExit,
+		Level:    alertLevelWarning,
+		Deadline: deadline,
+		Value:    daysLeft,
+		Message:  fmt.Sprintf("punished since block %d, auto exit in %d days", pledge.LastPunish, daysLeft),
+	}
+	if daysLeft <= watchdogCriticalDays {
+		alert.Level = alertLevelCritical
+	}
+	return alert
+}
+
+// storageVerificationAlert warns about the days of failed storage verification
+// of a pledge, which is removed after maxStgVerContinueDayFail of them.
+func (s *Snapshot) storageVerificationAlert(address common.Address, pledge *SPledge) *Alert {
+	if pledge.PledgeStatus.Cmp(big.NewInt(SPledgeRemoving)) == 0 {
+		return &Alert{
+			Address: address,
+			Kind:    alertKindStorageVerification,
+			Level:   alertLevelCritical,
+			Value:   maxStgVerContinueDayFail,
+			Message: fmt.Sprintf("storage pledge removing after %d days of failed verification", maxStgVerContinueDayFail),
+		}
+	}
+	if pledge.LastVerificationTime == nil || pledge.LastVerificationSuccessTime == nil || pledge.LastVerificationTime.Cmp(pledge.LastVerificationSuccessTime) <= 0 {
+		return nil
+	}
+	blockPerDay := s.getBlockPreDay()
+	lastSuccess := pledge.LastVerificationSuccessTime.Uint64()
+	failedDays := (pledge.LastVerificationTime.Uint64() - lastSuccess) / blockPerDay
+	if failedDays == 0 {
+		return nil
+	}
+	daysLeft := uint64(0)
+	if failedDays < maxStgVerContinueDayFail {
+		daysLeft = maxStgVerContinueDayFail - failedDays
+	}
+	alert := &Alert{
+		Address:  address,
+		Kind:     alertKindStorageVerification,
+		Level:    alertLevelWarning,
+		Deadline: lastSuccess + (maxStgVerContinueDayFail+1)*blockPerDay,
+		Value:    failedDays,
+		Message:  fmt.Sprintf("storage verification failed for %d days, pledge removed in %d days", failedDays, daysLeft),
+	}
+	if daysLeft <= watchdogCriticalDays {
+		alert.Level = alertLevelCritical
+	}
+	return alert
+}
+
+// bandwidthAlert warns about a pledge at the bandwidth punish line, whose
+// rewards are burned by the bandwidth ratio, and critically about a pledge to
+// be cut down to the line for not making up its deposit.
+func (s *Snapshot) bandwidthAlert(address common.Address, pledge *SPledge) *Alert {
+	if pledge.Bandwidth == nil {
+		return nil
+	}
+	if makeup, ok := s.STGBandwidthMakeup[address]; ok && isGTBandwidthPunishLine(makeup) && pledge.Bandwidth.Cmp(makeup.OldBandwidth) == 0 {
+		return &Alert{
+			Address: address,
+			Kind:    alertKindBandwidth,
+			Level:   alertLevelCritical,
+			Value:   pledge.Bandwidth.Uint64(),
+			Message: fmt.Sprintf("bandwidth %s to be cut to the punish line %s without a deposit makeup", pledge.Bandwidth, bandwidthPunishLine),
+		}
+	}
+	if pledge.Bandwidth.Cmp(bandwidthPunishLine) <= 0 {
+		return &Alert{
+			Address: address,
+			Kind:    alertKindBandwidth,
+			Level:   alertLevelWarning,
+			Value:   pledge.Bandwidth.Uint64(),
+			Message: fmt.Sprintf("bandwidth %s at the punish line %s", pledge.Bandwidth, bandwidthPunishLine),
+		}
+	}
+	return nil
+}
+
+// operatorWatchdog returns the operator watchdog of the engine, if configured.
+func (a *Alien) operatorWatchdog() *Watchdog {
+	a.lock.RLock()
+	defer a.lock.RUnlock()
+
+	return a.watchdog
+}
+
+// GetAlerts returns the operator alerts of the last head evaluated by the
+// watchdog.
+func (api *API) GetAlerts() ([]*Alert, error) {
+	log.Info("api GetAlerts")
+	watchdog := api.alien.operatorWatchdog()
+	if watchdog == nil {
+		return nil, errWatchdogDisabled
+	}
+	return watchdog.Alerts(), nil
+}
@@ -0,0 +1,133 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alien
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+func TestWatchdogAlerts(t *testing.T) {
+	var (
+		signer   = common.HexToAddress("0xa100000000000000000000000000000000000001")
+		failing  = common.HexToAddress("0xb200000000000000000000000000000000000002")
+		removing = common.HexToAddress("0xc300000000000000000000000000000000000003")
+		makeup   = common.HexToAddress("0xd400000000000000000000000000000000000004")
+		healthy  = common.HexToAddress("0xe500000000000000000000000000000000000005")
+
+		blockPerDay = uint64(secondsPerDay / 10)
+		number      = 1000 + 25*blockPerDay
+	)
+	pledge := func(status int64, bandwidth int64, lastSuccess uint64, last uint64) *SPledge {
+		return &SPledge{
+			Bandwidth:                   big.NewInt(bandwidth),
+			LastVerificationTime:        new(big.Int).SetUint64(last),
+			LastVerificationSuccessTime: new(big.Int).SetUint64(lastSuccess),
+			PledgeStatus:                big.NewInt(status),
+		}
+	}
+	snap := &Snapshot{
+		config:   &params.AlienConfig{Period: 10, MaxSignerCount: 3},
+		Punished: map[common.Address]uint64{signer: 40},
+		PosPledge: map[common.Address]*PosPledgeItem{
+			signer:  {LastPunish: 1000},
+			healthy: {},
+		},
+		StorageData: &StorageData{StoragePledge: map[common.Address]*SPledge{
+			failing:  pledge(SPledgeNormal, 20, blockPerDay, 4*blockPerDay),
+			removing: pledge(SPledgeRemoving, 100, 0, 0),
+			makeup:   pledge(SPledgeNormal, 50, blockPerDay, blockPerDay),
+			healthy:  pledge(SPledgeNormal, 100, blockPerDay, blockPerDay),
+		}},
+		STGBandwidthMakeup: map[common.Address]*BandwidthMakeup{makeup: {OldBandwidth: big.NewInt(50)}},
+	}
+	addresses := []common.Address{signer, failing, removing, makeup, healthy}
+
+	want := []struct {
+		address  common.Address
+		kind     string
+		level    string
+		value    uint64
+		deadline uint64
+	}{
+		{signer, alertKindMissedSlots, alertLevelCritical, 40, 0},
+		{signer, alertKindAutoExit, alertLevelCritical, 5, 1000 + maxPosContinueDayFail*blockPerDay},
+		{failing, alertKindStorageVerification, alertLevelWarning, 3, (maxStgVerContinueDayFail + 2) * blockPerDay},
+		{failing, alertKindBandwidth, alertLevelWarning, 20, 0},
+		{removing, alertKindStorageVerification, alertLevelCritical, maxStgVerContinueDayFail, 0},
+		{makeup, alertKindBandwidth, alertLevelCritical, 50, 0},
+	}
+	alerts := snap.operatorAlerts(addresses, number)
+	if len(alerts) != len(want) {
+		t.Fatalf("alert count mismatch: have %d, want %d", len(alerts), len(want))
+	}
+	for i, alert := range alerts {
+		if alert.Address != want[i].address || alert.Kind != want[i].kind || alert.Level != want[i].level ||
+			alert.Value != want[i].value || alert.Deadline != want[i].deadline || alert.Number != number {
+			t.Errorf("alert %d mismatch: have %+v, want %+v", i, alert, want[i])
+		}
+	}
+
+	// Only raised, resolved and changed levels are reported
+	w := &Watchdog{alerts: make(map[watchdogKey]*Alert)}
+	if changed := w.update(alerts, number); len(changed) != len(want) {
+		t.Fatalf("raised alerts mismatch: have %d, want %d", len(changed), len(want))
+	}
+	snap.Punished[signer] = 39
+	if changed := w.update(snap.operatorAlerts(addresses, number+1), number+1); len(changed) != 0 {
+		t.Fatalf("unchanged alerts reported: %v", changed)
+	}
+	snap.Punished[signer] = 10
+	delete(snap.PosPledge, signer)
+	changed := w.update(snap.operatorAlerts(addresses, number+2), number+2)
+	if len(changed) != 2 {
+		t.Fatalf("changed alerts mismatch: have %d, want 2", len(changed))
+	}
+	if changed[0].Kind != alertKindMissedSlots || changed[0].Level != alertLevelWarning || changed[0].Since != number {
+		t.Errorf("missed slots alert mismatch: %+v", changed[0])
+	}
+	if changed[1].Kind != alertKindAutoExit || changed[1].Level != alertLevelResolved || changed[1].Number != number+2 {
+		t.Errorf("auto exit alert mismatch: %+v", changed[1])
+	}
+	current := w.Alerts()
+	if len(current) != len(want)-1 || current[0].Address != signer || current[len(current)-1].Address != makeup {
+		t.Errorf("current alerts mismatch: %v", current)
+	}
+}
+
+func TestWatchdogWebhook(t *testing.T) {
+	tests := []struct {
+		webhook string
+		valid   bool
+	}{
+		{"http://localhost:8080/alerts", true},
+		{"http://127.0.0.1:9000", true},
+		{"https://[::1]/hook", true},
+		{"http://192.168.1.10/hook", false},
+		{"http://example.com/hook", false},
+		{"ftp://localhost/hook", false},
+		{"localhost:8080", false},
+	}
+	for _, test := range tests {
+		if err := validateWebhook(test.webhook); (err == nil) != test.valid {
+			t.Errorf("%s: have %v, want valid %v", test.webhook, err, test.valid)
+		}
+	}
+}
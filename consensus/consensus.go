@@ -19,12 +19,15 @@ package consensus
 
 import (
 	"bytes"
+	"errors"
 	"math/big"
 
 	"github.com/UltronGlow/UltronGlow-Origin/common"
 	"github.com/UltronGlow/UltronGlow-Origin/core/state"
 	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
 	"github.com/UltronGlow/UltronGlow-Origin/params"
+	"github.com/UltronGlow/UltronGlow-Origin/rlp"
 	"github.com/UltronGlow/UltronGlow-Origin/rpc"
 )
 
@@ -71,6 +74,56 @@ type MultiSignatureData struct {
 	MultiSigners []common.Address
 }
 
+// errNotMultiSignature is returned if an address does not hold multi-signature data.
+var errNotMultiSignature = errors.New("not a multi-signature address")
+
+// MultiSignatureState is the part of the state needed to read the data of a
+// multi-signature address. Both *state.StateDB and vm.StateDB satisfy it.
+type MultiSignatureState interface {
+	Empty(common.Address) bool
+	GetNonce(common.Address) uint64
+	GetCodeHash(common.Address) common.Hash
+	GetCode(common.Address) []byte
+}
+
+// ReadMultiSignatureData returns the owners and threshold stored in the code of
+// a multi-signature address created by a UTG:1:Multi transaction.
+func ReadMultiSignatureData(state MultiSignatureState, address common.Address) (*MultiSignatureData, error) {
+	if state.Empty(address) {
+		return nil, errNotMultiSignature
+	}
+	contractHash := state.GetCodeHash(address)
+	if state.GetNonce(address) != 1 || contractHash == (common.Hash{}) || contractHash == crypto.Keccak256Hash(nil) {
+		return nil, errNotMultiSignature
+	}
+	var parameter MultiSignatureData
+	if err := rlp.DecodeBytes(state.GetCode(address), &parameter); nil != err {
+		return nil, errNotMultiSignature
+	}
+	return &parameter, nil
+}
+
+// Signed returns the owners found among signers, each counted once.
+func (m *MultiSignatureData) Signed(signers []common.Address) []common.Address {
+	owners := make(map[common.Address]bool)
+	for _, owner := range m.MultiSigners {
+		owners[owner] = true
+	}
+	var signed []common.Address
+	for _, signer := range signers {
+		if owners[signer] {
+			signed = append(signed, signer)
+			delete(owners, signer)
+		}
+	}
+	return signed
+}
+
+// Satisfied reports whether signers contain at least Threshold distinct owners.
+func (m *MultiSignatureData) Satisfied(signers []common.Address) bool {
+	return len(m.Signed(signers)) >= int(m.Threshold)
+}
+
 // ChainHeaderReader defines a small collection of methods needed to access the local
 // blockchain during header verification.
 type ChainHeaderReader interface {
@@ -175,3 +228,22 @@ type PoW interface {
 	// Hashrate returns the current mining hashrate of a PoW consensus engine.
 	Hashrate() float64
 }
+
+// BalanceChange is a balance movement performed by the consensus engine outside
+// of the EVM, tagged with the reason and the record it originates from.
+type BalanceChange struct {
+	Address common.Address `json:"address"`
+	Delta   *big.Int       `json:"delta"`
+	Reason  string         `json:"reason"`
+	Source  string         `json:"source,omitempty"`
+}
+
+// BalanceTracer is a consensus engine able to report the balance changes it
+// makes while finalising a block.
+type BalanceTracer interface {
+	Engine
+
+	// TraceFinalize grants the profits and finalises the block on top of the
+	// state with its transactions applied, returning the balance changes made.
+	TraceFinalize(chain ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt, gasReward *big.Int) ([]BalanceChange, error)
+}
@@ -65,6 +65,13 @@ func (v *BlockValidator) ValidateBody(block *types.Block) error {
 	if hash := types.DeriveSha(block.Transactions(), trie.NewStackTrie(nil)); hash != header.TxHash {
 		return fmt.Errorf("transaction root hash mismatch: have %x, want %x", hash, header.TxHash)
 	}
+	if !v.config.IsMultiSigSpend(header.Number) {
+		for i, tx := range block.Transactions() {
+			if tx.MultiSig() != nil {
+				return fmt.Errorf("%w: transaction %d", ErrMultiSigNotActive, i)
+			}
+		}
+	}
 	if !v.bc.HasBlockAndState(block.ParentHash(), block.NumberU64()-1) {
 		if !v.bc.HasBlock(block.ParentHash(), block.NumberU64()-1) {
 			return consensus.ErrUnknownAncestor
@@ -87,4 +87,16 @@ var (
 	// ErrFeeCapTooLow is returned if the transaction fee cap is less than the
 	// the base fee of the block.
 	ErrFeeCapTooLow = errors.New("max fee per gas less than block base fee")
+
+	// ErrMultiSigUnauthorized is returned if the signers of a transaction spending
+	// from a multi-signature account don't meet the threshold of the account.
+	ErrMultiSigUnauthorized = errors.New("multi-signature threshold not met")
+
+	// ErrMultiSigCreation is returned if a multi-signature account is used to
+	// deploy a contract, which would change the nonce of the account.
+	ErrMultiSigCreation = errors.New("multi-signature account can't create contracts")
+
+	// ErrMultiSigNotActive is returned if a transaction spends from a
+	// multi-signature account before the multi-signature spend fork.
+	ErrMultiSigNotActive = errors.New("multi-signature spend not activated")
 )
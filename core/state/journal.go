@@ -103,6 +103,7 @@ type (
 	balanceChange struct {
 		account *common.Address
 		prev    *big.Int
+		index   int // position in the journal, reported to the balance hook
 	}
 	nonceChange struct {
 		account *common.Address
@@ -182,7 +183,11 @@ func (ch touchChange) dirtied() *common.Address {
 }
 
 func (ch balanceChange) revert(s *StateDB) {
-	s.getStateObject(*ch.account).setBalance(ch.prev)
+	obj := s.getStateObject(*ch.account)
+	if s.balanceHook != nil {
+		s.balanceHook.BalanceReverted(ch.index, *ch.account, obj.data.Balance, ch.prev)
+	}
+	obj.setBalance(ch.prev)
 }
 
 func (ch balanceChange) dirtied() *common.Address {
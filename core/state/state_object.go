@@ -439,10 +439,15 @@ func (s *stateObject) SubBalance(amount *big.Int) {
 }
 
 func (s *stateObject) SetBalance(amount *big.Int) {
-	s.db.journal.append(balanceChange{
+	change := balanceChange{
 		account: &s.address,
 		prev:    new(big.Int).Set(s.data.Balance),
-	})
+		index:   s.db.journal.length(),
+	}
+	s.db.journal.append(change)
+	if s.db.balanceHook != nil {
+		s.db.balanceHook.BalanceChanged(change.index, s.address, s.data.Balance, amount)
+	}
 	s.setBalance(amount)
 }
 
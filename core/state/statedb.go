@@ -45,6 +45,17 @@ var (
 	emptyRoot = common.HexToHash("56e81f171bcc55a6ff8345e692c0f86e5b48e01b996cadc001622fb5e363b421")
 )
 
+// BalanceHook observes balance changes made through a StateDB. It is called
+// with the index of the change in the state journal and the previous and the
+// new balance, neither of which may be modified.
+type BalanceHook interface {
+	BalanceChanged(index int, addr common.Address, prev, balance *big.Int)
+
+	// BalanceReverted is called when a snapshot revert undoes a balance
+	// change, in the reverse order of the changes and with the same index.
+	BalanceReverted(index int, addr common.Address, balance, prev *big.Int)
+}
+
 type proofList [][]byte
 
 func (n *proofList) Put(key []byte, value []byte) error {
@@ -105,6 +116,9 @@ type StateDB struct {
 	validRevisions []revision
 	nextRevisionId int
 
+	// Observer of balance changes, not carried over by Copy.
+	balanceHook BalanceHook
+
 	// Measurements gathered during execution for debugging purposes
 	AccountReads         time.Duration
 	AccountHashes        time.Duration
@@ -405,6 +419,17 @@ func (s *StateDB) SetBalance(addr common.Address, amount *big.Int) {
 	}
 }
 
+// SetBalanceHook installs an observer that is notified of every subsequent
+// balance change, or removes it if hook is nil.
+func (s *StateDB) SetBalanceHook(hook BalanceHook) {
+	s.balanceHook = hook
+}
+
+// BalanceHook returns the installed balance observer, if any.
+func (s *StateDB) BalanceHook() BalanceHook {
+	return s.balanceHook
+}
+
 func (s *StateDB) SetNonce(addr common.Address, nonce uint64) {
 	stateObject := s.GetOrNewStateObject(addr)
 	if stateObject != nil {
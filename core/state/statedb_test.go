@@ -915,3 +915,58 @@ func TestStateDBAccessList(t *testing.T) {
 		t.Fatalf("expected empty, got %d", got)
 	}
 }
+
+type balanceRecorder []*big.Int
+
+func (r *balanceRecorder) BalanceChanged(index int, addr common.Address, prev, balance *big.Int) {
+	*r = append(*r, new(big.Int).Sub(balance, prev))
+}
+
+func (r *balanceRecorder) BalanceReverted(index int, addr common.Address, balance, prev *big.Int) {
+	*r = append(*r, new(big.Int).Sub(prev, balance))
+}
+
+func TestBalanceHook(t *testing.T) {
+	state, _ := New(common.Hash{}, NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	addr := common.BytesToAddress([]byte{0x01})
+
+	recorder := new(balanceRecorder)
+	state.SetBalanceHook(recorder)
+	state.AddBalance(addr, big.NewInt(10))
+	state.SubBalance(addr, big.NewInt(3))
+	state.SetBalance(addr, big.NewInt(2))
+	state.AddBalance(addr, new(big.Int))
+
+	want := []*big.Int{big.NewInt(10), big.NewInt(-3), big.NewInt(-5)}
+	if len(*recorder) != len(want) {
+		t.Fatalf("change count mismatch: have %d, want %d", len(*recorder), len(want))
+	}
+	for i, delta := range *recorder {
+		if delta.Cmp(want[i]) != 0 {
+			t.Errorf("change %d mismatch: have %v, want %v", i, delta, want[i])
+		}
+	}
+	// Reverted changes are reported in reverse order
+	snapshot := state.Snapshot()
+	state.AddBalance(addr, big.NewInt(4))
+	state.SubBalance(addr, big.NewInt(1))
+	state.RevertToSnapshot(snapshot)
+
+	want = append(want, big.NewInt(4), big.NewInt(-1), big.NewInt(1), big.NewInt(-4))
+	if len(*recorder) != len(want) {
+		t.Fatalf("change count after revert mismatch: have %d, want %d", len(*recorder), len(want))
+	}
+	for i, delta := range *recorder {
+		if delta.Cmp(want[i]) != 0 {
+			t.Errorf("change %d mismatch: have %v, want %v", i, delta, want[i])
+		}
+	}
+	if state.Copy().BalanceHook() != nil {
+		t.Error("balance hook carried over by copy")
+	}
+	state.SetBalanceHook(nil)
+	state.AddBalance(addr, big.NewInt(1))
+	if len(*recorder) != len(want) {
+		t.Error("balance change recorded after removing the hook")
+	}
+}
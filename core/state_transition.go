@@ -23,6 +23,7 @@ import (
 
 	"github.com/UltronGlow/UltronGlow-Origin/common"
 	cmath "github.com/UltronGlow/UltronGlow-Origin/common/math"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
 	"github.com/UltronGlow/UltronGlow-Origin/core/types"
 	"github.com/UltronGlow/UltronGlow-Origin/core/vm"
 	"github.com/UltronGlow/UltronGlow-Origin/params"
@@ -76,6 +77,14 @@ type Message interface {
 	AccessList() types.AccessList
 }
 
+// multiSigMessage is implemented by messages which may spend from a
+// multi-signature account. The gas and the nonce of such a message belong to
+// the payer, while the value and the call are made by From.
+type multiSigMessage interface {
+	Payer() common.Address
+	Signers() []common.Address
+}
+
 // ExecutionResult includes all output after executing given evm
 // message no matter the execution itself is successful or not.
 type ExecutionResult struct {
@@ -190,6 +199,48 @@ func (st *StateTransition) to() common.Address {
 	return *st.msg.To()
 }
 
+// multiSigSpend returns the message if it spends from a multi-signature account.
+func (st *StateTransition) multiSigSpend() (multiSigMessage, bool) {
+	msg, ok := st.msg.(multiSigMessage)
+	if !ok || msg.Payer() == st.msg.From() {
+		return nil, false
+	}
+	return msg, true
+}
+
+// payer returns the account paying the gas and providing the nonce. Before the
+// multi-signature spend fork it is always the sender.
+func (st *StateTransition) payer() common.Address {
+	if msg, ok := st.multiSigSpend(); ok && st.evm.ChainConfig().IsMultiSigSpend(st.evm.Context.BlockNumber) {
+		return msg.Payer()
+	}
+	return st.msg.From()
+}
+
+// verifyMultiSig checks that a message spending from a multi-signature account
+// is allowed at the current block and signed by enough owners of the account.
+func (st *StateTransition) verifyMultiSig() error {
+	msg, ok := st.multiSigSpend()
+	if !ok {
+		return nil
+	}
+	if !st.evm.ChainConfig().IsMultiSigSpend(st.evm.Context.BlockNumber) {
+		return fmt.Errorf("%w: address %v", ErrMultiSigNotActive, st.msg.From().Hex())
+	}
+	if st.msg.To() == nil {
+		return fmt.Errorf("%w: address %v", ErrMultiSigCreation, st.msg.From().Hex())
+	}
+	parameter, err := consensus.ReadMultiSignatureData(st.state, st.msg.From())
+	if err != nil {
+		return fmt.Errorf("%w: address %v: %v", ErrMultiSigUnauthorized, st.msg.From().Hex(), err)
+	}
+	if !parameter.Satisfied(msg.Signers()) {
+		return fmt.Errorf("%w: address %v, signed: %d threshold: %d", ErrMultiSigUnauthorized,
+			st.msg.From().Hex(), len(parameter.Signed(msg.Signers())), parameter.Threshold)
+	}
+	return nil
+}
+
 func (st *StateTransition) buyGas() error {
 	mgval := new(big.Int).SetUint64(st.msg.Gas())
 	mgval = mgval.Mul(mgval, st.gasPrice)
@@ -198,8 +249,8 @@ func (st *StateTransition) buyGas() error {
 		balanceCheck = new(big.Int).SetUint64(st.msg.Gas())
 		balanceCheck = balanceCheck.Mul(balanceCheck, st.gasFeeCap)
 	}
-	if have, want := st.state.GetBalance(st.msg.From()), balanceCheck; have.Cmp(want) < 0 {
-		return fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientFunds, st.msg.From().Hex(), have, want)
+	if have, want := st.state.GetBalance(st.payer()), balanceCheck; have.Cmp(want) < 0 {
+		return fmt.Errorf("%w: address %v have %v want %v", ErrInsufficientFunds, st.payer().Hex(), have, want)
 	}
 	if err := st.gp.SubGas(st.msg.Gas()); err != nil {
 		return err
@@ -207,20 +258,24 @@ func (st *StateTransition) buyGas() error {
 	st.gas += st.msg.Gas()
 
 	st.initialGas = st.msg.Gas()
-	st.state.SubBalance(st.msg.From(), mgval)
+	st.state.SubBalance(st.payer(), mgval)
 	return nil
 }
 
 func (st *StateTransition) preCheck() error {
+	// Make sure a multi-signature spend is active and authorised by the owners.
+	if err := st.verifyMultiSig(); err != nil {
+		return err
+	}
 	// Make sure this transaction's nonce is correct.
 	if st.msg.CheckNonce() {
-		stNonce := st.state.GetNonce(st.msg.From())
+		stNonce := st.state.GetNonce(st.payer())
 		if msgNonce := st.msg.Nonce(); stNonce < msgNonce {
 			return fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooHigh,
-				st.msg.From().Hex(), msgNonce, stNonce)
+				st.payer().Hex(), msgNonce, stNonce)
 		} else if stNonce > msgNonce {
 			return fmt.Errorf("%w: address %v, tx: %d state: %d", ErrNonceTooLow,
-				st.msg.From().Hex(), msgNonce, stNonce)
+				st.payer().Hex(), msgNonce, stNonce)
 		}
 	}
 	// Make sure that transaction gasFeeCap is greater than the baseFee (post london)
@@ -311,7 +366,7 @@ func (st *StateTransition) TransitionDb() (*ExecutionResult, error) {
 		ret, _, st.gas, vmerr = st.evm.Create(sender, st.data, st.gas, st.value)
 	} else {
 		// Increment the nonce for the next transaction
-		st.state.SetNonce(msg.From(), st.state.GetNonce(sender.Address())+1)
+		st.state.SetNonce(st.payer(), st.state.GetNonce(st.payer())+1)
 		ret, st.gas, vmerr = st.evm.Call(sender, st.to(), st.data, st.gas, st.value)
 	}
 	if !st.evm.ChainConfig().IsLondon(st.evm.Context.BlockNumber) {
@@ -369,7 +424,7 @@ func (st *StateTransition) refundGas(refundQuotient uint64) {
 
 	// Return ETH for remaining gas, exchanged at the original rate.
 	remaining := new(big.Int).Mul(new(big.Int).SetUint64(st.gas), st.gasPrice)
-	st.state.AddBalance(st.msg.From(), remaining)
+	st.state.AddBalance(st.payer(), remaining)
 
 	// Also return remaining gas to the block gas counter so it is
 	// available for the next transaction.
@@ -0,0 +1,163 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"bytes"
+	"errors"
+	"math/big"
+	"strings"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/metrics"
+)
+
+var (
+	// ErrCustomTxRateLimit is returned if an account sends more transactions of
+	// a custom category than allowed between two chain heads.
+	ErrCustomTxRateLimit = errors.New("custom transaction rate limit reached")
+
+	// ErrCustomTxAccountLimit is returned if an account already has as many
+	// transactions of a custom category in the pool as allowed.
+	ErrCustomTxAccountLimit = errors.New("too many custom transactions of the account")
+
+	// ErrCustomTxUnderpriced is returned if a transaction of a heavy custom
+	// category is under the gas price required for the category.
+	ErrCustomTxUnderpriced = errors.New("custom transaction underpriced")
+)
+
+var (
+	// Metrics for the custom transactions dropped by the category limits
+	customRateLimitMeter    = metrics.NewRegisteredMeter("txpool/custom/ratelimit", nil)
+	customAccountLimitMeter = metrics.NewRegisteredMeter("txpool/custom/accountlimit", nil)
+	customUnderpricedMeter  = metrics.NewRegisteredMeter("txpool/custom/underpriced", nil)
+)
+
+// CustomTxLimit limits the custom transactions of one category, a zero field
+// leaving the matching limit off.
+type CustomTxLimit struct {
+	PerBlock        uint64 // Transactions accepted per account between two chain heads
+	AccountPending  uint64 // Transactions an account may have in the pool, executable or not
+	PriceMultiplier uint64 // Minimum gas price of remote transactions, as a multiple of the pool price limit
+}
+
+// customTxCategory returns the category of a custom transaction as used by the
+// CustomLimits of the config, like "UTG:1:stReq" or "ufo:1:event:proposal",
+// together with its limit. The longer category wins.
+func (pool *TxPool) customTxCategory(tx *types.Transaction) (string, CustomTxLimit, bool) {
+	fields := bytes.SplitN(tx.Data(), []byte(":"), 5)
+	for n := 4; n >= 3; n-- {
+		if len(fields) < n {
+			continue
+		}
+		category := string(bytes.Join(fields[:n], []byte(":")))
+		if limit, ok := pool.config.CustomLimits[category]; ok {
+			return category, limit, true
+		}
+	}
+	return "", CustomTxLimit{}, false
+}
+
+// validateCustomTx checks a transaction against the limits of its custom
+// category. The price limit exempts local transactions like the pool price,
+// the per block limit exempts replacements of pooled transactions.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) validateCustomTx(from common.Address, tx *types.Transaction, local bool) error {
+	if len(pool.config.CustomLimits) == 0 {
+		return nil
+	}
+	category, limit, ok := pool.customTxCategory(tx)
+	if !ok {
+		return nil
+	}
+	if limit.PriceMultiplier > 1 && !local {
+		minPrice := new(big.Int).Mul(pool.gasPrice, new(big.Int).SetUint64(limit.PriceMultiplier))
+		if tx.GasTipCapIntCmp(minPrice) < 0 {
+			customUnderpricedMeter.Mark(1)
+			customCategoryMeter(category, "underpriced").Mark(1)
+			return ErrCustomTxUnderpriced
+		}
+	}
+	if limit.PerBlock > 0 && pool.customCounts[from][category] >= limit.PerBlock && !pool.pooledNonce(from, tx) {
+		customRateLimitMeter.Mark(1)
+		customCategoryMeter(category, "ratelimit").Mark(1)
+		return ErrCustomTxRateLimit
+	}
+	if limit.AccountPending > 0 {
+		count := uint64(0)
+		for _, list := range []*txList{pool.pending[from], pool.queue[from]} {
+			if list == nil {
+				continue
+			}
+			for _, pooled := range list.txs.items {
+				// A replacement takes the slot of the transaction it replaces
+				if pooled.Nonce() == tx.Nonce() {
+					continue
+				}
+				if pooledCategory, _, ok := pool.customTxCategory(pooled); ok && pooledCategory == category {
+					count++
+				}
+			}
+		}
+		if count >= limit.AccountPending {
+			customAccountLimitMeter.Mark(1)
+			customCategoryMeter(category, "accountlimit").Mark(1)
+			return ErrCustomTxAccountLimit
+		}
+	}
+	return nil
+}
+
+// pooledNonce reports whether the pool already holds a transaction of from
+// with the nonce of tx, which tx would replace.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) pooledNonce(from common.Address, tx *types.Transaction) bool {
+	if list := pool.pending[from]; list != nil && list.Overlaps(tx) {
+		return true
+	}
+	if list := pool.queue[from]; list != nil && list.Overlaps(tx) {
+		return true
+	}
+	return false
+}
+
+// countCustomTx counts a transaction accepted into the pool against the per
+// block limit of its custom category.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) countCustomTx(from common.Address, tx *types.Transaction) {
+	if len(pool.config.CustomLimits) == 0 {
+		return
+	}
+	category, limit, ok := pool.customTxCategory(tx)
+	if !ok || limit.PerBlock == 0 {
+		return
+	}
+	if pool.customCounts[from] == nil {
+		pool.customCounts[from] = make(map[string]uint64)
+	}
+	pool.customCounts[from][category]++
+}
+
+// customCategoryMeter returns the meter of the transactions of a custom
+// category dropped for reason.
+func customCategoryMeter(category string, reason string) metrics.Meter {
+	return metrics.GetOrRegisterMeter("txpool/custom/"+strings.ReplaceAll(category, ":", "/")+"/"+reason, nil)
+}
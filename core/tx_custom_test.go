@@ -0,0 +1,108 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+)
+
+func customTransaction(nonce uint64, gasprice *big.Int, key *ecdsa.PrivateKey, data string) *types.Transaction {
+	tx, _ := types.SignTx(types.NewTransaction(nonce, common.Address{}, big.NewInt(0), 100000, gasprice, []byte(data)), types.HomesteadSigner{}, key)
+	return tx
+}
+
+// Tests that the custom transactions of a category are limited per block and
+// per account, and that heavy categories need a higher gas price.
+func TestCustomTransactionLimits(t *testing.T) {
+	t.Parallel()
+
+	pool, key := setupTxPool()
+	defer pool.Stop()
+
+	pool.config.CustomLimits = map[string]CustomTxLimit{
+		"UTG:1:stRent":         {PerBlock: 2, AccountPending: 3},
+		"UTG:1:stReq":          {PriceMultiplier: 10},
+		"ufo:1:event:proposal": {PerBlock: 1},
+	}
+	from := crypto.PubkeyToAddress(key.PublicKey)
+	testAddBalance(pool, from, big.NewInt(1e18))
+	price := new(big.Int).Set(pool.gasPrice)
+
+	// Two rents fit in a block, the third waits for the next one
+	if err := pool.AddLocal(customTransaction(0, price, key, "UTG:1:stRent:a")); err != nil {
+		t.Fatalf("first rent rejected: %v", err)
+	}
+	if err := pool.AddLocal(customTransaction(1, price, key, "UTG:1:stRent:b")); err != nil {
+		t.Fatalf("second rent rejected: %v", err)
+	}
+	if err := pool.AddLocal(customTransaction(2, price, key, "UTG:1:stRent:c")); !errors.Is(err, ErrCustomTxRateLimit) {
+		t.Fatalf("third rent in the block: have %v, want %v", err, ErrCustomTxRateLimit)
+	}
+	// A replacement of a pooled rent is not counted against the block
+	if err := pool.AddLocal(customTransaction(1, new(big.Int).Mul(price, big.NewInt(2)), key, "UTG:1:stRent:b")); err != nil {
+		t.Fatalf("rent replacement at the block limit rejected: %v", err)
+	}
+	// Other transactions of the account are not limited
+	if err := pool.AddLocal(customTransaction(2, price, key, "UTG:1:Bind:a")); err != nil {
+		t.Fatalf("bind rejected: %v", err)
+	}
+	<-pool.requestReset(nil, nil)
+
+	if err := pool.AddLocal(customTransaction(3, price, key, "UTG:1:stRent:c")); err != nil {
+		t.Fatalf("rent of the next block rejected: %v", err)
+	}
+	if err := pool.AddLocal(customTransaction(4, price, key, "UTG:1:stRent:d")); !errors.Is(err, ErrCustomTxAccountLimit) {
+		t.Fatalf("fourth pooled rent: have %v, want %v", err, ErrCustomTxAccountLimit)
+	}
+	// A replacement takes the slot of the replaced rent
+	<-pool.requestReset(nil, nil)
+	bumped := new(big.Int).Mul(price, big.NewInt(2))
+	if err := pool.AddLocal(customTransaction(3, bumped, key, "UTG:1:stRent:e")); err != nil {
+		t.Fatalf("rent replacement rejected: %v", err)
+	}
+
+	// The longer category is matched
+	if err := pool.AddLocal(customTransaction(4, price, key, "ufo:1:event:proposal:a")); err != nil {
+		t.Fatalf("first proposal rejected: %v", err)
+	}
+	if err := pool.AddLocal(customTransaction(5, price, key, "ufo:1:event:proposal:b")); !errors.Is(err, ErrCustomTxRateLimit) {
+		t.Fatalf("second proposal in the block: have %v, want %v", err, ErrCustomTxRateLimit)
+	}
+
+	// Heavy categories need the multiplied gas price from remote senders
+	remote, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(remote.PublicKey), big.NewInt(1e18))
+	if err := pool.AddRemote(customTransaction(0, price, remote, "UTG:1:stReq:a")); !errors.Is(err, ErrCustomTxUnderpriced) {
+		t.Fatalf("cheap remote declaration: have %v, want %v", err, ErrCustomTxUnderpriced)
+	}
+	if err := pool.AddRemote(customTransaction(0, new(big.Int).Mul(price, big.NewInt(10)), remote, "UTG:1:stReq:a")); err != nil {
+		t.Fatalf("priced remote declaration rejected: %v", err)
+	}
+	if err := pool.AddLocal(customTransaction(6, price, key, "UTG:1:stReq:a")); err != nil {
+		t.Fatalf("local declaration rejected: %v", err)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
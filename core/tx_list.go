@@ -254,6 +254,8 @@ type txList struct {
 
 	costcap *big.Int // Price of the highest costing transaction (reset only if exceeds balance)
 	gascap  uint64   // Gas limit of the highest spending transaction (reset only if exceeds block limit)
+
+	multiSig bool // Whether a transaction spending from a multi-signature account was added
 }
 
 // newTxList create a new transaction list for maintaining nonce-indexable fast,
@@ -303,12 +305,15 @@ func (l *txList) Add(tx *types.Transaction, priceBump uint64) (bool, *types.Tran
 	}
 	// Otherwise overwrite the old transaction with the current one
 	l.txs.Put(tx)
-	if cost := tx.Cost(); l.costcap.Cmp(cost) < 0 {
+	if cost := initiatorCost(tx); l.costcap.Cmp(cost) < 0 {
 		l.costcap = cost
 	}
 	if gas := tx.Gas(); l.gascap < gas {
 		l.gascap = gas
 	}
+	if tx.MultiSig() != nil {
+		l.multiSig = true
+	}
 	return true, old
 }
 
@@ -328,17 +333,26 @@ func (l *txList) Forward(threshold uint64) types.Transactions {
 // a point in calculating all the costs or if the balance covers all. If the threshold
 // is lower than the costgas cap, the caps will be reset to a new high after removing
 // the newly invalidated transactions.
-func (l *txList) Filter(costLimit *big.Int, gasLimit uint64) (types.Transactions, types.Transactions) {
+//
+// The value of a transaction spending from a multi-signature account is not part
+// of its cost, it is checked against the balance of that account instead, as
+// returned by multiSigBalance.
+func (l *txList) Filter(costLimit *big.Int, gasLimit uint64, multiSigBalance func(common.Address) *big.Int) (types.Transactions, types.Transactions) {
 	// If all transactions are below the threshold, short circuit
-	if l.costcap.Cmp(costLimit) <= 0 && l.gascap <= gasLimit {
+	capped := l.costcap.Cmp(costLimit) <= 0 && l.gascap <= gasLimit
+	if capped && !l.multiSig {
 		return nil, nil
 	}
-	l.costcap = new(big.Int).Set(costLimit) // Lower the caps to the thresholds
-	l.gascap = gasLimit
-
+	if !capped {
+		l.costcap = new(big.Int).Set(costLimit) // Lower the caps to the thresholds
+		l.gascap = gasLimit
+	}
 	// Filter out all the transactions above the account's funds
 	removed := l.txs.Filter(func(tx *types.Transaction) bool {
-		return tx.Gas() > gasLimit || tx.Cost().Cmp(costLimit) > 0 || 0 > tx.GasPrice().Cmp(big.NewInt(params.GGasPrice))
+		if multiSig := tx.MultiSig(); multiSig != nil && tx.Value().Cmp(multiSigBalance(*multiSig)) > 0 {
+			return true
+		}
+		return !capped && (tx.Gas() > gasLimit || initiatorCost(tx).Cmp(costLimit) > 0 || 0 > tx.GasPrice().Cmp(big.NewInt(params.GGasPrice)))
 	})
 
 	if len(removed) == 0 {
@@ -0,0 +1,251 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"errors"
+	"math/big"
+	"time"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/log"
+)
+
+const (
+	// maxHeldMultiSigTxs caps the number of partially signed transactions kept
+	// in the holding area of the pool.
+	maxHeldMultiSigTxs = 1024
+
+	// maxHeldPerInitiator caps the partially signed transactions held for a
+	// single initiator.
+	maxHeldPerInitiator = 16
+
+	// maxHeldPerMultiSig caps the partially signed transactions held for a
+	// single multi-signature address.
+	maxHeldPerMultiSig = 64
+)
+
+var (
+	// ErrNotMultiSignerTx is returned if a partially signed transaction is not
+	// of the multi-signer type.
+	ErrNotMultiSignerTx = errors.New("not a multi-signer transaction")
+
+	// ErrMultiSigMismatch is returned if co-signatures for the same transaction
+	// are submitted for a different multi-signature address.
+	ErrMultiSigMismatch = errors.New("multi-signature address mismatch")
+
+	// ErrMultiSigHoldingFull is returned if the holding area can't accept
+	// another partially signed transaction.
+	ErrMultiSigHoldingFull = errors.New("multi-signature holding area is full")
+
+	// ErrMultiSigHoldingLimit is returned if the initiator or the
+	// multi-signature address already have their share of the holding area.
+	ErrMultiSigHoldingLimit = errors.New("multi-signature holding limit reached")
+
+	// ErrMultiSigNoOwner is returned if none of the signers of a partially
+	// signed transaction owns the multi-signature address.
+	ErrMultiSigNoOwner = errors.New("no multi-signature owner signed")
+)
+
+// heldMultiSigTx is a partially signed transaction in the holding area.
+type heldMultiSigTx struct {
+	ptx  *types.PartiallySignedTx
+	from common.Address // Initiator paying for the gas
+	time time.Time      // Time the transaction was first held
+}
+
+// AddPartiallySigned merges the signatures of a partially signed multi-signer
+// transaction with any earlier submission of the same transaction. Once the
+// signers satisfy the threshold of the multi-signature address the transaction
+// leaves the holding area and is added as a local transaction, which broadcasts
+// it. The merged transaction is returned together with whether it was promoted.
+//
+// The holding area is open to anyone, so a transaction is only held if an owner
+// of the address signed it, it pays the pool gas price and its initiator can
+// pay for it. Each initiator and each address get a share of the area, and the
+// transactions are dropped after the lifetime of the queue.
+func (pool *TxPool) AddPartiallySigned(ptx *types.PartiallySignedTx) (*types.PartiallySignedTx, bool, error) {
+	if ptx.Tx.Type() != types.MultiSignerTxType {
+		return nil, false, ErrNotMultiSignerTx
+	}
+	from, err := types.Sender(pool.signer, ptx.Tx)
+	if err != nil {
+		return nil, false, ErrInvalidSender
+	}
+	if !ptx.Consistent() {
+		return nil, false, ErrMultiSigMismatch
+	}
+	key := pool.signer.Hash(ptx.Tx)
+
+	pool.mu.Lock()
+	if ptx.Tx.MultiSig() != nil && !pool.multiSigSpend {
+		pool.mu.Unlock()
+		return nil, false, ErrMultiSigNotActive
+	}
+	added := time.Now()
+	if held, ok := pool.held[key]; ok {
+		if held.ptx.MultiSig != ptx.MultiSig {
+			pool.mu.Unlock()
+			return nil, false, ErrMultiSigMismatch
+		}
+		merged, err := held.ptx.Tx.CombineSignatures(ptx.Tx)
+		if err != nil {
+			pool.mu.Unlock()
+			return nil, false, err
+		}
+		ptx = &types.PartiallySignedTx{MultiSig: ptx.MultiSig, Tx: merged}
+		added = held.time
+
+		// The submission may carry the signature of another initiator
+		if from, err = types.Sender(pool.signer, merged); err != nil {
+			pool.mu.Unlock()
+			return nil, false, ErrInvalidSender
+		}
+	}
+	if ptx.Tx.GasTipCapIntCmp(pool.gasPrice) < 0 {
+		pool.mu.Unlock()
+		return nil, false, ErrUnderpriced
+	}
+	if pool.currentState.GetBalance(from).Cmp(initiatorCost(ptx.Tx)) < 0 {
+		pool.mu.Unlock()
+		return nil, false, ErrInsufficientFunds
+	}
+	parameter, err := consensus.ReadMultiSignatureData(pool.currentState, ptx.MultiSig)
+	if err != nil {
+		pool.mu.Unlock()
+		return nil, false, err
+	}
+	signers := ptx.Tx.AllSigners()
+	if len(parameter.Signed(signers)) == 0 {
+		pool.mu.Unlock()
+		return nil, false, ErrMultiSigNoOwner
+	}
+	if !parameter.Satisfied(signers) {
+		if held, ok := pool.held[key]; !ok || held.from != from {
+			if len(pool.held) >= maxHeldMultiSigTxs {
+				pool.mu.Unlock()
+				return nil, false, ErrMultiSigHoldingFull
+			}
+			initiated, spent := pool.heldCounts(from, ptx.MultiSig)
+			if initiated >= maxHeldPerInitiator || spent >= maxHeldPerMultiSig {
+				pool.mu.Unlock()
+				return nil, false, ErrMultiSigHoldingLimit
+			}
+		}
+		pool.held[key] = &heldMultiSigTx{ptx: ptx, from: from, time: added}
+		pool.mu.Unlock()
+		log.Debug("Holding partially signed transaction", "hash", key, "multisig", ptx.MultiSig, "signers", len(signers), "threshold", parameter.Threshold)
+		return ptx, false, nil
+	}
+	delete(pool.held, key)
+	pool.mu.Unlock()
+
+	if err := pool.AddLocal(ptx.Tx); err != nil {
+		return nil, false, err
+	}
+	return ptx, true, nil
+}
+
+// PartiallySigned returns the held transaction with the given signing hash, or
+// nil if it is not in the holding area.
+func (pool *TxPool) PartiallySigned(hash common.Hash) *types.PartiallySignedTx {
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+
+	if held, ok := pool.held[hash]; ok {
+		return held.ptx
+	}
+	return nil
+}
+
+// heldCounts returns the number of held transactions of the initiator and of
+// the multi-signature address. The pool lock must be held.
+func (pool *TxPool) heldCounts(from common.Address, multiSig common.Address) (int, int) {
+	var initiated, spent int
+	for _, held := range pool.held {
+		if held.from == from {
+			initiated++
+		}
+		if held.ptx.MultiSig == multiSig {
+			spent++
+		}
+	}
+	return initiated, spent
+}
+
+// demoteHeld drops the held transactions whose initiator nonce has been used
+// by a mined transaction. The pool lock must be held.
+func (pool *TxPool) demoteHeld() {
+	for key, held := range pool.held {
+		if pool.currentState.GetNonce(held.from) > held.ptx.Tx.Nonce() {
+			log.Debug("Dropping stale partially signed transaction", "hash", key, "from", held.from)
+			delete(pool.held, key)
+		}
+	}
+}
+
+// evictHeld drops the held transactions that waited for their co-signatures
+// longer than the lifetime of the queue. The pool lock must be held.
+func (pool *TxPool) evictHeld() {
+	for key, held := range pool.held {
+		if time.Since(held.time) > pool.config.Lifetime {
+			log.Debug("Evicting expired partially signed transaction", "hash", key, "from", held.from)
+			delete(pool.held, key)
+		}
+	}
+}
+
+// initiatorCost returns the funds the sender of a pooled transaction must hold,
+// gas * gasPrice + value. The value of a multi-signature spend is paid by the
+// account instead, the pool only admits such spends from the fork on.
+func initiatorCost(tx *types.Transaction) *big.Int {
+	if tx.MultiSig() == nil {
+		return tx.Cost()
+	}
+	return new(big.Int).Mul(tx.GasPrice(), new(big.Int).SetUint64(tx.Gas()))
+}
+
+// validateMultiSigSpend checks a transaction spending from a multi-signature
+// account against the current state: the fork must be active, the signers must
+// meet the threshold of the account, the payer must cover the gas and the
+// account must hold the value. The pool lock must be held.
+func (pool *TxPool) validateMultiSigSpend(payer common.Address, tx *types.Transaction) error {
+	multiSig := tx.MultiSig()
+	if multiSig == nil {
+		return nil
+	}
+	if !pool.multiSigSpend {
+		return ErrMultiSigNotActive
+	}
+	if tx.To() == nil {
+		return ErrMultiSigCreation
+	}
+	parameter, err := consensus.ReadMultiSignatureData(pool.currentState, *multiSig)
+	if err != nil || !parameter.Satisfied(tx.AllSigners()) {
+		return ErrMultiSigUnauthorized
+	}
+	gas := new(big.Int).Mul(tx.GasFeeCap(), new(big.Int).SetUint64(tx.Gas()))
+	if pool.currentState.GetBalance(payer).Cmp(gas) < 0 {
+		return ErrInsufficientFunds
+	}
+	if pool.currentState.GetBalance(*multiSig).Cmp(tx.Value()) < 0 {
+		return ErrInsufficientFundsForTransfer
+	}
+	return nil
+}
@@ -0,0 +1,412 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/core/vm"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+	"github.com/UltronGlow/UltronGlow-Origin/rlp"
+)
+
+// multiSigConfig is a chain config with multi-signature spends enabled at block 0.
+func multiSigConfig() *params.ChainConfig {
+	cpy := *eip1559Config
+	cpy.MultiSigSpendBlock = common.Big0
+	return &cpy
+}
+
+// testCreateMultiSig installs the data of a multi-signature address in the pool
+// state, like a UTG:1:Multi transaction does.
+func testCreateMultiSig(pool *TxPool, addr common.Address, threshold uint32, owners ...common.Address) {
+	code, _ := rlp.EncodeToBytes(&consensus.MultiSignatureData{Threshold: threshold, MultiSigners: owners})
+
+	pool.mu.Lock()
+	pool.currentState.CreateAccount(addr)
+	pool.currentState.SetNonce(addr, 1)
+	pool.currentState.SetCode(addr, code)
+	pool.mu.Unlock()
+}
+
+// coSign signs the partially signed transaction with each key independently.
+func coSign(t *testing.T, pool *TxPool, ptx *types.PartiallySignedTx, keys ...*ecdsa.PrivateKey) []*types.PartiallySignedTx {
+	signed := make([]*types.PartiallySignedTx, len(keys))
+	for i, key := range keys {
+		tx, err := types.SignTx(ptx.Tx, pool.signer, key)
+		if err != nil {
+			t.Fatal(err)
+		}
+		signed[i] = &types.PartiallySignedTx{MultiSig: ptx.MultiSig, Tx: tx}
+	}
+	return signed
+}
+
+// Tests that partially signed transactions are held until the owners of the
+// multi-signature address meet the threshold, and are then promoted.
+func TestPartiallySignedTransactions(t *testing.T) {
+	t.Parallel()
+
+	pool, initiator := setupTxPoolWithConfig(multiSigConfig())
+	defer pool.Stop()
+
+	owner1, _ := crypto.GenerateKey()
+	owner2, _ := crypto.GenerateKey()
+	var (
+		from     = crypto.PubkeyToAddress(initiator.PublicKey)
+		multiSig = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		to       = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	)
+	testCreateMultiSig(pool, multiSig, 2, crypto.PubkeyToAddress(owner1.PublicKey), crypto.PubkeyToAddress(owner2.PublicKey))
+	testAddBalance(pool, from, big.NewInt(1e18))
+	testAddBalance(pool, multiSig, big.NewInt(1000))
+
+	spend := types.NewMultiSigSpendTransaction(pool.chainconfig.ChainID, 0, multiSig, to, big.NewInt(100), 50000, big.NewInt(params.GGasPrice), nil)
+	initiated, err := types.SignTx(spend, pool.signer, initiator)
+	if err != nil {
+		t.Fatal(err)
+	}
+	cosigned := coSign(t, pool, &types.PartiallySignedTx{MultiSig: multiSig, Tx: initiated}, owner1, owner2)
+
+	// One owner doesn't meet the threshold, the transaction is held
+	held, promoted, err := pool.AddPartiallySigned(cosigned[0])
+	if err != nil || promoted {
+		t.Fatalf("first co-signature: promoted %v, err %v", promoted, err)
+	}
+	if pool.PartiallySigned(pool.signer.Hash(initiated)) == nil {
+		t.Fatalf("transaction not held")
+	}
+	if pending, _ := pool.Stats(); pending != 0 {
+		t.Fatalf("pending transactions mismatch: have %d, want 0", pending)
+	}
+	if len(held.Tx.AllSigners()) != 2 {
+		t.Fatalf("held signers mismatch: have %d, want 2", len(held.Tx.AllSigners()))
+	}
+	// The co-signature for another address is refused
+	if _, _, err := pool.AddPartiallySigned(&types.PartiallySignedTx{MultiSig: to, Tx: cosigned[1].Tx}); err != ErrMultiSigMismatch {
+		t.Fatalf("mismatching address: have %v, want %v", err, ErrMultiSigMismatch)
+	}
+	// The second owner is merged with the first and the transaction promoted
+	merged, promoted, err := pool.AddPartiallySigned(cosigned[1])
+	if err != nil || !promoted {
+		t.Fatalf("second co-signature: promoted %v, err %v", promoted, err)
+	}
+	if len(merged.Tx.AllSigners()) != 3 {
+		t.Fatalf("merged signers mismatch: have %d, want 3", len(merged.Tx.AllSigners()))
+	}
+	if pool.PartiallySigned(pool.signer.Hash(initiated)) != nil {
+		t.Fatalf("promoted transaction still held")
+	}
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatch: have %d, want 1", pending)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that held transactions are dropped once the nonce of the initiator is
+// used by another transaction.
+func TestPartiallySignedDemotion(t *testing.T) {
+	t.Parallel()
+
+	pool, initiator := setupTxPoolWithConfig(multiSigConfig())
+	defer pool.Stop()
+
+	owner, _ := crypto.GenerateKey()
+	var (
+		from     = crypto.PubkeyToAddress(initiator.PublicKey)
+		multiSig = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		to       = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	)
+	testCreateMultiSig(pool, multiSig, 2, from, crypto.PubkeyToAddress(owner.PublicKey))
+	testAddBalance(pool, from, big.NewInt(1e18))
+
+	var hashes []common.Hash
+	for nonce := uint64(0); nonce < 2; nonce++ {
+		tx, _ := types.SignTx(types.NewMultiSigSpendTransaction(pool.chainconfig.ChainID, nonce, multiSig, to, big.NewInt(0), 50000, big.NewInt(params.GGasPrice), nil), pool.signer, initiator)
+		if _, promoted, err := pool.AddPartiallySigned(&types.PartiallySignedTx{MultiSig: multiSig, Tx: tx}); err != nil || promoted {
+			t.Fatalf("nonce %d: promoted %v, err %v", nonce, promoted, err)
+		}
+		hashes = append(hashes, pool.signer.Hash(tx))
+	}
+	// A mined transaction uses the first nonce
+	testSetNonce(pool, from, 1)
+	<-pool.requestReset(nil, nil)
+
+	if pool.PartiallySigned(hashes[0]) != nil {
+		t.Errorf("stale transaction still held")
+	}
+	if pool.PartiallySigned(hashes[1]) == nil {
+		t.Errorf("executable transaction dropped")
+	}
+}
+
+// Tests that the holding area only takes transactions signed by an owner, paying
+// the pool gas price and funded by their initiator, caps the transactions of
+// each initiator and address, and evicts them after the queue lifetime.
+func TestPartiallySignedAdmission(t *testing.T) {
+	t.Parallel()
+
+	pool, initiator := setupTxPoolWithConfig(multiSigConfig())
+	defer pool.Stop()
+
+	owner, _ := crypto.GenerateKey()
+	var (
+		from     = crypto.PubkeyToAddress(initiator.PublicKey)
+		multiSig = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		to       = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	)
+	testCreateMultiSig(pool, multiSig, 3, from, crypto.PubkeyToAddress(owner.PublicKey), to)
+
+	hold := func(key *ecdsa.PrivateKey, nonce uint64, price int64) (*types.Transaction, error) {
+		tx, _ := types.SignTx(types.NewMultiSigSpendTransaction(pool.chainconfig.ChainID, nonce, multiSig, to, big.NewInt(0), 50000, big.NewInt(price), nil), pool.signer, key)
+		_, _, err := pool.AddPartiallySigned(&types.PartiallySignedTx{MultiSig: multiSig, Tx: tx})
+		return tx, err
+	}
+	// Initiators that can't pay for the gas or pay too little are refused
+	if _, err := hold(initiator, 0, params.GGasPrice); err != ErrInsufficientFunds {
+		t.Fatalf("unfunded initiator: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	testAddBalance(pool, from, big.NewInt(1e18))
+	if _, err := hold(initiator, 0, 1); err != ErrUnderpriced {
+		t.Fatalf("underpriced transaction: have %v, want %v", err, ErrUnderpriced)
+	}
+	// Transactions no owner signed are refused
+	stranger, _ := crypto.GenerateKey()
+	testAddBalance(pool, crypto.PubkeyToAddress(stranger.PublicKey), big.NewInt(1e18))
+	if _, err := hold(stranger, 0, params.GGasPrice); err != ErrMultiSigNoOwner {
+		t.Fatalf("no owner signature: have %v, want %v", err, ErrMultiSigNoOwner)
+	}
+	// An initiator gets its share of the holding area only
+	for nonce := uint64(0); nonce < maxHeldPerInitiator; nonce++ {
+		if _, err := hold(initiator, nonce, params.GGasPrice); err != nil {
+			t.Fatalf("nonce %d: %v", nonce, err)
+		}
+	}
+	if _, err := hold(initiator, maxHeldPerInitiator, params.GGasPrice); err != ErrMultiSigHoldingLimit {
+		t.Fatalf("initiator over its share: have %v, want %v", err, ErrMultiSigHoldingLimit)
+	}
+	// So does an address, whoever initiates its transactions
+	initiators := []*ecdsa.PrivateKey{owner}
+	for len(initiators) < maxHeldPerMultiSig/maxHeldPerInitiator {
+		key, _ := crypto.GenerateKey()
+		initiators = append(initiators, key)
+	}
+	testAddBalance(pool, crypto.PubkeyToAddress(owner.PublicKey), big.NewInt(1e18))
+	for _, key := range initiators[1:] {
+		testAddBalance(pool, crypto.PubkeyToAddress(key.PublicKey), big.NewInt(1e18))
+	}
+	for i, key := range initiators[1:] {
+		for nonce := uint64(0); nonce < maxHeldPerInitiator; nonce++ {
+			tx, _ := types.SignTx(types.NewMultiSigSpendTransaction(pool.chainconfig.ChainID, nonce, multiSig, to, big.NewInt(int64(i+1)), 50000, big.NewInt(params.GGasPrice), nil), pool.signer, key)
+			tx, _ = types.SignTx(tx, pool.signer, owner)
+			if _, _, err := pool.AddPartiallySigned(&types.PartiallySignedTx{MultiSig: multiSig, Tx: tx}); err != nil {
+				t.Fatalf("nonce %d: %v", nonce, err)
+			}
+		}
+	}
+	if _, err := hold(owner, maxHeldPerInitiator, params.GGasPrice); err != ErrMultiSigHoldingLimit {
+		t.Fatalf("address over its share: have %v, want %v", err, ErrMultiSigHoldingLimit)
+	}
+	// Transactions waiting longer than the queue lifetime are evicted
+	pool.mu.Lock()
+	for _, held := range pool.held {
+		held.time = time.Now().Add(-2 * pool.config.Lifetime)
+	}
+	pool.evictHeld()
+	pool.mu.Unlock()
+
+	if _, err := hold(owner, maxHeldPerInitiator, params.GGasPrice); err != nil {
+		t.Fatalf("transaction after eviction: %v", err)
+	}
+	pool.mu.RLock()
+	defer pool.mu.RUnlock()
+	if len(pool.held) != 1 {
+		t.Fatalf("held transactions mismatch: have %d, want 1", len(pool.held))
+	}
+}
+
+// Tests that spends from a multi-signature address are refused before the fork
+// and that the initiator has to pay for the gas.
+func TestMultiSigSpendAdmission(t *testing.T) {
+	t.Parallel()
+
+	owner, _ := crypto.GenerateKey()
+	var (
+		multiSig = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		to       = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	)
+	sign := func(pool *TxPool, key *ecdsa.PrivateKey) *types.Transaction {
+		tx, _ := types.SignTx(types.NewMultiSigSpendTransaction(pool.chainconfig.ChainID, 0, multiSig, to, big.NewInt(100), 50000, big.NewInt(1), nil), pool.signer, key)
+		tx, _ = types.SignTx(tx, pool.signer, owner)
+		return tx
+	}
+	// Before the fork the spend is refused
+	pool, initiator := setupTxPoolWithConfig(eip1559Config)
+	defer pool.Stop()
+
+	from := crypto.PubkeyToAddress(initiator.PublicKey)
+	testCreateMultiSig(pool, multiSig, 2, from, crypto.PubkeyToAddress(owner.PublicKey))
+	testAddBalance(pool, from, big.NewInt(1e18))
+	testAddBalance(pool, multiSig, big.NewInt(1000))
+	if err := pool.AddLocal(sign(pool, initiator)); err != ErrMultiSigNotActive {
+		t.Fatalf("spend before the fork: have %v, want %v", err, ErrMultiSigNotActive)
+	}
+	tx, _ := types.SignTx(types.NewMultiSigSpendTransaction(pool.chainconfig.ChainID, 0, multiSig, to, big.NewInt(100), 50000, big.NewInt(1), nil), pool.signer, initiator)
+	if _, _, err := pool.AddPartiallySigned(&types.PartiallySignedTx{MultiSig: multiSig, Tx: tx}); err != ErrMultiSigNotActive {
+		t.Fatalf("held spend before the fork: have %v, want %v", err, ErrMultiSigNotActive)
+	}
+	// After the fork the initiator must cover the gas, the account the value
+	forked, initiator := setupTxPoolWithConfig(multiSigConfig())
+	defer forked.Stop()
+
+	from = crypto.PubkeyToAddress(initiator.PublicKey)
+	testCreateMultiSig(forked, multiSig, 2, from, crypto.PubkeyToAddress(owner.PublicKey))
+	testAddBalance(forked, from, big.NewInt(49999))
+	testAddBalance(forked, multiSig, big.NewInt(1000))
+	if err := forked.AddLocal(sign(forked, initiator)); err != ErrInsufficientFunds {
+		t.Fatalf("unfunded initiator: have %v, want %v", err, ErrInsufficientFunds)
+	}
+	testAddBalance(forked, from, big.NewInt(1))
+	if err := forked.AddLocal(sign(forked, initiator)); err != nil {
+		t.Fatalf("funded spend rejected: %v", err)
+	}
+}
+
+// Tests that a pending spend is dropped once the multi-signature account can
+// no longer cover its value, even though the initiator still pays the gas.
+func TestMultiSigSpendFilter(t *testing.T) {
+	t.Parallel()
+
+	pool, initiator := setupTxPoolWithConfig(multiSigConfig())
+	defer pool.Stop()
+
+	owner, _ := crypto.GenerateKey()
+	var (
+		from     = crypto.PubkeyToAddress(initiator.PublicKey)
+		multiSig = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		to       = common.HexToAddress("0x2000000000000000000000000000000000000002")
+	)
+	testCreateMultiSig(pool, multiSig, 2, from, crypto.PubkeyToAddress(owner.PublicKey))
+	testAddBalance(pool, from, big.NewInt(1e18))
+	testAddBalance(pool, multiSig, big.NewInt(1000))
+
+	tx, _ := types.SignTx(types.NewMultiSigSpendTransaction(pool.chainconfig.ChainID, 0, multiSig, to, big.NewInt(100), 50000, big.NewInt(params.GGasPrice), nil), pool.signer, initiator)
+	tx, _ = types.SignTx(tx, pool.signer, owner)
+	if err := pool.AddLocal(tx); err != nil {
+		t.Fatalf("spend rejected: %v", err)
+	}
+	<-pool.requestReset(nil, nil)
+	if pending, _ := pool.Stats(); pending != 1 {
+		t.Fatalf("pending transactions mismatch: have %d, want 1", pending)
+	}
+	// The account is drained by another spend
+	pool.mu.Lock()
+	pool.currentState.SubBalance(multiSig, big.NewInt(950))
+	pool.mu.Unlock()
+	<-pool.requestReset(nil, nil)
+
+	if pending, queued := pool.Stats(); pending != 0 || queued != 0 {
+		t.Fatalf("unfunded spend kept: pending %d, queued %d", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+}
+
+// Tests that applying a spend from a multi-signature account takes the gas and
+// the nonce from the initiator and the value from the account.
+func TestApplyMultiSigSpend(t *testing.T) {
+	t.Parallel()
+
+	initiator, _ := crypto.GenerateKey()
+	owner, _ := crypto.GenerateKey()
+	var (
+		config   = multiSigConfig()
+		signer   = types.LatestSigner(config)
+		from     = crypto.PubkeyToAddress(initiator.PublicKey)
+		multiSig = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		to       = common.HexToAddress("0x2000000000000000000000000000000000000002")
+		baseFee  = big.NewInt(1)
+		balance  = big.NewInt(1e18)
+	)
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	code, _ := rlp.EncodeToBytes(&consensus.MultiSignatureData{Threshold: 2, MultiSigners: []common.Address{from, crypto.PubkeyToAddress(owner.PublicKey)}})
+	statedb.CreateAccount(multiSig)
+	statedb.SetNonce(multiSig, 1)
+	statedb.SetCode(multiSig, code)
+	statedb.AddBalance(multiSig, big.NewInt(1000))
+	statedb.AddBalance(from, balance)
+	statedb.SetNonce(from, 3)
+
+	tx, _ := types.SignTx(types.NewMultiSigSpendTransaction(config.ChainID, 3, multiSig, to, big.NewInt(100), 50000, big.NewInt(2), nil), signer, initiator)
+	tx, _ = types.SignTx(tx, signer, owner)
+	msg, err := tx.AsMessage(signer, baseFee)
+	if err != nil {
+		t.Fatal(err)
+	}
+	blockContext := vm.BlockContext{
+		CanTransfer: CanTransfer,
+		Transfer:    Transfer,
+		BlockNumber: big.NewInt(1),
+		Time:        big.NewInt(0),
+		Difficulty:  big.NewInt(1),
+		GasLimit:    1000000,
+		BaseFee:     baseFee,
+	}
+	evm := vm.NewEVM(blockContext, NewEVMTxContext(msg), statedb, config, vm.Config{})
+	result, err := ApplyMessage(evm, msg, new(GasPool).AddGas(blockContext.GasLimit))
+	if err != nil {
+		t.Fatalf("spend failed: %v", err)
+	}
+	if result.Failed() {
+		t.Fatalf("spend reverted: %v", result.Err)
+	}
+	if have := statedb.GetBalance(to); have.Int64() != 100 {
+		t.Errorf("recipient balance mismatch: have %v, want 100", have)
+	}
+	if have := statedb.GetBalance(multiSig); have.Int64() != 900 {
+		t.Errorf("multi-signature balance mismatch: have %v, want 900", have)
+	}
+	fee := new(big.Int).Mul(big.NewInt(2), new(big.Int).SetUint64(result.UsedGas))
+	if have, want := statedb.GetBalance(from), new(big.Int).Sub(balance, fee); have.Cmp(want) != 0 {
+		t.Errorf("initiator balance mismatch: have %v, want %v", have, want)
+	}
+	if have := statedb.GetNonce(from); have != 4 {
+		t.Errorf("initiator nonce mismatch: have %d, want 4", have)
+	}
+	if have := statedb.GetNonce(multiSig); have != 1 {
+		t.Errorf("multi-signature nonce mismatch: have %d, want 1", have)
+	}
+	// A spend signed below the threshold of the account is not applied
+	single, _ := types.SignTx(types.NewMultiSigSpendTransaction(config.ChainID, 4, multiSig, to, big.NewInt(100), 50000, big.NewInt(2), nil), signer, initiator)
+	msg, _ = single.AsMessage(signer, baseFee)
+	if _, err := ApplyMessage(evm, msg, new(GasPool).AddGas(blockContext.GasLimit)); !errors.Is(err, ErrMultiSigUnauthorized) {
+		t.Fatalf("unauthorised spend: have %v, want %v", err, ErrMultiSigUnauthorized)
+	}
+}
@@ -155,6 +155,11 @@ type TxPoolConfig struct {
 	AccountQueue uint64 // Maximum number of non-executable transaction slots permitted per account
 	GlobalQueue  uint64 // Maximum number of non-executable transaction slots for all accounts
 
+	PrioritySlots       uint64 // Maximum number of priority lane transactions per account
+	PriorityGlobalSlots uint64 // Maximum number of priority lane transactions for all accounts
+
+	CustomLimits map[string]CustomTxLimit // Limits of the custom transactions by category
+
 	Lifetime time.Duration // Maximum amount of time non-executable transaction are queued
 }
 
@@ -172,6 +177,16 @@ var DefaultTxPoolConfig = TxPoolConfig{
 	AccountQueue: 64,
 	GlobalQueue:  1024,
 
+	PrioritySlots:       4,
+	PriorityGlobalSlots: 256,
+
+	CustomLimits: map[string]CustomTxLimit{
+		"UTG:1:stReq":          {PerBlock: 1, AccountPending: 2, PriceMultiplier: 10},
+		"UTG:1:setsp":          {PerBlock: 1, AccountPending: 2, PriceMultiplier: 10},
+		"UTG:1:stRent":         {PerBlock: 4, AccountPending: 16},
+		"ufo:1:event:proposal": {PerBlock: 2, AccountPending: 8},
+	},
+
 	Lifetime: 3 * time.Hour,
 }
 
@@ -207,6 +222,14 @@ func (config *TxPoolConfig) sanitize() TxPoolConfig {
 		log.Warn("Sanitizing invalid txpool global queue", "provided", conf.GlobalQueue, "updated", DefaultTxPoolConfig.GlobalQueue)
 		conf.GlobalQueue = DefaultTxPoolConfig.GlobalQueue
 	}
+	if conf.PrioritySlots < 1 {
+		log.Warn("Sanitizing invalid txpool priority slots", "provided", conf.PrioritySlots, "updated", DefaultTxPoolConfig.PrioritySlots)
+		conf.PrioritySlots = DefaultTxPoolConfig.PrioritySlots
+	}
+	if conf.PriorityGlobalSlots < 1 {
+		log.Warn("Sanitizing invalid txpool priority global slots", "provided", conf.PriorityGlobalSlots, "updated", DefaultTxPoolConfig.PriorityGlobalSlots)
+		conf.PriorityGlobalSlots = DefaultTxPoolConfig.PriorityGlobalSlots
+	}
 	if conf.Lifetime < 1 {
 		log.Warn("Sanitizing invalid txpool lifetime", "provided", conf.Lifetime, "updated", DefaultTxPoolConfig.Lifetime)
 		conf.Lifetime = DefaultTxPoolConfig.Lifetime
@@ -235,6 +258,8 @@ type TxPool struct {
 	eip2718  bool // Fork indicator whether we are using EIP-2718 type transactions.
 	eip1559  bool // Fork indicator whether we are using EIP-1559 type transactions.
 
+	multiSigSpend bool // Fork indicator whether multi-signature accounts can spend.
+
 	currentState  *state.StateDB // Current state in the blockchain head
 	pendingNonces *txNoncer      // Pending state tracking virtual nonces
 	currentMaxGas uint64         // Current gas limit for transaction caps
@@ -248,6 +273,12 @@ type TxPool struct {
 	all     *txLookup                    // All transactions to allow lookups
 	priced  *txPricedList                // All transactions sorted by price
 
+	held map[common.Hash]*heldMultiSigTx // Multi-signer transactions awaiting co-signatures
+
+	priority     TxPriorityFilter                     // Selects the transactions of the priority lane
+	priorityLane *txPriorityLane                      // Transactions admitted to the priority lane
+	customCounts map[common.Address]map[string]uint64 // Custom transactions accepted since the chain head, by category
+
 	chainHeadCh     chan ChainHeadEvent
 	chainHeadSub    event.Subscription
 	reqResetCh      chan *txpoolResetRequest
@@ -278,6 +309,9 @@ func NewTxPool(config TxPoolConfig, chainconfig *params.ChainConfig, chain block
 		queue:           make(map[common.Address]*txList),
 		beats:           make(map[common.Address]time.Time),
 		all:             newTxLookup(),
+		held:            make(map[common.Hash]*heldMultiSigTx),
+		customCounts:    make(map[common.Address]map[string]uint64),
+		priorityLane:    newTxPriorityLane(),
 		chainHeadCh:     make(chan ChainHeadEvent, chainHeadChanSize),
 		reqResetCh:      make(chan *txpoolResetRequest),
 		reqPromoteCh:    make(chan *accountSet),
@@ -380,6 +414,7 @@ func (pool *TxPool) loop() {
 					queuedEvictionMeter.Mark(int64(len(list)))
 				}
 			}
+			pool.evictHeld()
 			pool.mu.Unlock()
 
 		// Handle local transaction journal rotation
@@ -602,10 +637,18 @@ func (pool *TxPool) validateTx(tx *types.Transaction, local bool) error {
 		return ErrNonceTooLow
 	}
 	// Transactor should have enough funds to cover the costs
-	// cost == V + GP * GL
-	if pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
+	// cost == V + GP * GL, the value of a multi-signature spend is checked below
+	if tx.MultiSig() == nil && pool.currentState.GetBalance(from).Cmp(tx.Cost()) < 0 {
 		return ErrInsufficientFunds
 	}
+	// A multi-signature spend must be authorised and funded by the account
+	if err := pool.validateMultiSigSpend(from, tx); err != nil {
+		return err
+	}
+	// Custom transactions must stay within the limits of their category
+	if err := pool.validateCustomTx(from, tx, local); err != nil {
+		return err
+	}
 	// Ensure the transaction has more gas than the basic tx fee.
 	intrGas, err := IntrinsicGas(tx.Data(), tx.AccessList(), tx.To() == nil, true, pool.istanbul)
 	if err != nil {
@@ -642,8 +685,13 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		invalidTxMeter.Mark(1)
 		return false, err
 	}
-	// If the transaction pool is full, discard underpriced transactions
-	if uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
+	// Transactions of the priority lane get into a full pool without evicting
+	// cheaper ones, within the caps of the lane.
+	isPriority := pool.admitPriority(tx)
+
+	// If the transaction pool is full, discard underpriced transactions. The
+	// priority lane has its own caps and never evicts other transactions.
+	if !isPriority && uint64(pool.all.Slots()+numSlots(tx)) > pool.config.GlobalSlots+pool.config.GlobalQueue {
 		// If the new transaction is underpriced, don't accept it
 		if !isLocal && pool.priced.Underpriced(tx) {
 			log.Trace("Discarding underpriced transaction", "hash", hash, "gasTipCap", tx.GasTipCap(), "gasFeeCap", tx.GasFeeCap())
@@ -687,6 +735,12 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		pool.priced.Put(tx, isLocal)
 		pool.journalTx(from, tx)
 		pool.queueTxEvent(tx)
+		if old == nil {
+			pool.countCustomTx(from, tx)
+		}
+		if isPriority {
+			pool.priorityLane.add(from, tx)
+		}
 		log.Trace("Pooled new executable transaction", "hash", hash, "from", from, "to", tx.To())
 
 		// Successful promotion, bump the heartbeat
@@ -698,6 +752,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 	if err != nil {
 		return false, err
 	}
+	if isPriority {
+		pool.priorityLane.add(from, tx)
+	}
 	// Mark local addresses and journal local transactions
 	if local && !pool.locals.contains(from) {
 		log.Info("Setting new local account", "address", from)
@@ -708,6 +765,9 @@ func (pool *TxPool) add(tx *types.Transaction, local bool) (replaced bool, err e
 		localGauge.Inc(1)
 	}
 	pool.journalTx(from, tx)
+	if !replaced {
+		pool.countCustomTx(from, tx)
+	}
 
 	log.Trace("Pooled new future transaction", "hash", hash, "from", from, "to", tx.To())
 	return replaced, nil
@@ -1144,6 +1204,7 @@ func (pool *TxPool) runReorg(done chan struct{}, reset *txpoolResetRequest, dirt
 	// Ensure pool.queue and pool.pending sizes stay within the configured limits.
 	pool.truncatePending()
 	pool.truncateQueue()
+	pool.priorityLane.prune(pool.all)
 
 	// Update all accounts to the latest known pending nonce
 	for addr, list := range pool.pending {
@@ -1247,17 +1308,23 @@ func (pool *TxPool) reset(oldHead, newHead *types.Header) {
 	pool.currentState = statedb
 	pool.pendingNonces = newTxNoncer(statedb)
 	pool.currentMaxGas = newHead.GasLimit
+	pool.demoteHeld()
 
 	// Inject any transactions discarded due to reorgs
 	log.Debug("Reinjecting stale transactions", "count", len(reinject))
 	senderCacher.recover(pool.signer, reinject)
 	pool.addTxsLocked(reinject, false)
 
+	// The per block limits of the custom transactions start over, the
+	// reinjected transactions were already accepted once
+	pool.customCounts = make(map[common.Address]map[string]uint64)
+
 	// Update all fork indicator by next pending block number.
 	next := new(big.Int).Add(newHead.Number, big.NewInt(1))
 	pool.istanbul = pool.chainconfig.IsIstanbul(next)
 	pool.eip2718 = pool.chainconfig.IsBerlin(next)
 	pool.eip1559 = pool.chainconfig.IsLondon(next)
+	pool.multiSigSpend = pool.chainconfig.IsMultiSigSpend(next)
 }
 
 // promoteExecutables moves transactions that have become processable from the
@@ -1281,7 +1348,7 @@ func (pool *TxPool) promoteExecutables(accounts []common.Address) []*types.Trans
 		}
 		log.Trace("Removed old queued transactions", "count", len(forwards))
 		// Drop all transactions that are too costly (low balance or out of gas)
-		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
+		drops, _ := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas, pool.currentState.GetBalance)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			pool.all.Remove(hash)
@@ -1478,7 +1545,7 @@ func (pool *TxPool) demoteUnexecutables() {
 			log.Trace("Removed old pending transaction", "hash", hash)
 		}
 		// Drop all transactions that are too costly (low balance or out of gas), and queue any invalids back for later
-		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas)
+		drops, invalids := list.Filter(pool.currentState.GetBalance(addr), pool.currentMaxGas, pool.currentState.GetBalance)
 		for _, tx := range drops {
 			hash := tx.Hash()
 			log.Trace("Removed unpayable pending transaction", "hash", hash)
@@ -0,0 +1,148 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+)
+
+// TxPriorityFilter reports whether a transaction of the sender belongs in the
+// priority lane of the pool, such as the consensus-critical custom
+// transactions of registered storage pledges and signers.
+type TxPriorityFilter func(from common.Address, tx *types.Transaction) bool
+
+// txPriorityLane is the sub-queue of the pool holding the transactions the
+// priority filter admitted. They pay the price limit like other remote
+// transactions, but enter a full pool without evicting cheaper ones, so the
+// lane is capped on its own, per account by PrioritySlots and for all accounts
+// by PriorityGlobalSlots.
+type txPriorityLane struct {
+	txs   map[common.Address]map[common.Hash]*types.Transaction
+	count int
+}
+
+func newTxPriorityLane() *txPriorityLane {
+	return &txPriorityLane{txs: make(map[common.Address]map[common.Hash]*types.Transaction)}
+}
+
+func (l *txPriorityLane) add(from common.Address, tx *types.Transaction) {
+	if l.txs[from] == nil {
+		l.txs[from] = make(map[common.Hash]*types.Transaction)
+	}
+	if _, ok := l.txs[from][tx.Hash()]; !ok {
+		l.txs[from][tx.Hash()] = tx
+		l.count++
+	}
+}
+
+func (l *txPriorityLane) contains(from common.Address, hash common.Hash) bool {
+	_, ok := l.txs[from][hash]
+	return ok
+}
+
+// slots returns the lane transactions of the account and of all accounts,
+// leaving out the one a transaction with the nonce replaces.
+func (l *txPriorityLane) slots(from common.Address, nonce uint64) (int, int) {
+	account, all := len(l.txs[from]), l.count
+	for _, tx := range l.txs[from] {
+		if tx.Nonce() == nonce {
+			account--
+			all--
+			break
+		}
+	}
+	return account, all
+}
+
+// prune drops the transactions which left the pool, whichever way they left.
+func (l *txPriorityLane) prune(all *txLookup) {
+	for from, txs := range l.txs {
+		for hash := range txs {
+			if all.Get(hash) == nil {
+				delete(txs, hash)
+				l.count--
+			}
+		}
+		if len(txs) == 0 {
+			delete(l.txs, from)
+		}
+	}
+}
+
+// SetPriorityFilter sets the filter selecting the transactions of the priority
+// lane. A nil filter leaves the lane empty.
+func (pool *TxPool) SetPriorityFilter(filter TxPriorityFilter) {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.priority = filter
+}
+
+// admitPriority reports whether a new transaction goes into the priority lane:
+// the filter accepts it and the lane has room for it.
+//
+// Note, this method assumes the pool lock is held!
+func (pool *TxPool) admitPriority(tx *types.Transaction) bool {
+	if pool.priority == nil {
+		return false
+	}
+	from, err := types.Sender(pool.signer, tx)
+	if err != nil {
+		return false
+	}
+	full := func() bool {
+		account, all := pool.priorityLane.slots(from, tx.Nonce())
+		return uint64(account) >= pool.config.PrioritySlots || uint64(all) >= pool.config.PriorityGlobalSlots
+	}
+	// The lane is pruned on every reorg, prune it here only if it looks full
+	if full() {
+		pool.priorityLane.prune(pool.all)
+		if full() {
+			return false
+		}
+	}
+	return pool.priority(from, tx)
+}
+
+// Priority retrieves the executable transactions of the priority lane, grouped
+// by origin account and sorted by nonce. Only the leading pending transactions
+// of an account are taken, so the lane never skips the nonce of an ordinary
+// transaction. The returned transaction set is a copy and can be freely
+// modified by calling code.
+func (pool *TxPool) Priority() map[common.Address]types.Transactions {
+	pool.mu.Lock()
+	defer pool.mu.Unlock()
+
+	pool.priorityLane.prune(pool.all)
+	priority := make(map[common.Address]types.Transactions)
+	for addr := range pool.priorityLane.txs {
+		list := pool.pending[addr]
+		if list == nil {
+			continue
+		}
+		txs := list.Flatten()
+		count := 0
+		for count < len(txs) && pool.priorityLane.contains(addr, txs[count].Hash()) {
+			count++
+		}
+		if count > 0 {
+			priority[addr] = txs[:count]
+		}
+	}
+	return priority
+}
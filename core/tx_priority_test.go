@@ -0,0 +1,105 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package core
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
+	"github.com/UltronGlow/UltronGlow-Origin/core/state"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+	"github.com/UltronGlow/UltronGlow-Origin/event"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+// Tests that the priority lane admits the transactions of the filter into a
+// full pool without evicting cheaper ones, within caps of its own.
+func TestPriorityLane(t *testing.T) {
+	t.Parallel()
+
+	statedb, _ := state.New(common.Hash{}, state.NewDatabase(rawdb.NewMemoryDatabase()), nil)
+	blockchain := &testBlockChain{statedb, 1000000, new(event.Feed)}
+
+	config := testTxPoolConfig
+	config.GlobalSlots = 2
+	config.GlobalQueue = 2
+	config.PrioritySlots = 2
+	config.PriorityGlobalSlots = 3
+
+	pool := NewTxPool(config, params.TestChainConfig, blockchain)
+	defer pool.Stop()
+	pool.SetPriorityFilter(func(from common.Address, tx *types.Transaction) bool {
+		return strings.HasPrefix(string(tx.Data()), "UTG:1:stProof")
+	})
+
+	keys := make([]*ecdsa.PrivateKey, 3)
+	for i := 0; i < len(keys); i++ {
+		keys[i], _ = crypto.GenerateKey()
+		testAddBalance(pool, crypto.PubkeyToAddress(keys[i].PublicKey), big.NewInt(1e18))
+	}
+	add := func(tx *types.Transaction) error {
+		return pool.AddRemotesSync([]*types.Transaction{tx})[0]
+	}
+	// Fill the pool with ordinary transactions
+	price := new(big.Int).Mul(pool.gasPrice, big.NewInt(2))
+	for nonce := uint64(0); nonce < 4; nonce++ {
+		if err := add(customTransaction(nonce, price, keys[0], "")); err != nil {
+			t.Fatalf("ordinary transaction %d rejected: %v", nonce, err)
+		}
+	}
+	cheap := pool.gasPrice
+	if err := add(customTransaction(0, cheap, keys[1], "UTG:1:Bind:a")); !errors.Is(err, ErrUnderpriced) {
+		t.Fatalf("cheap ordinary transaction: have %v, want %v", err, ErrUnderpriced)
+	}
+	// The lane keeps the price limit of the pool
+	if err := add(customTransaction(0, new(big.Int).Sub(cheap, common.Big1), keys[1], "UTG:1:stProof:a")); !errors.Is(err, ErrUnderpriced) {
+		t.Fatalf("priority transaction under the price limit: have %v, want %v", err, ErrUnderpriced)
+	}
+	// The lane takes cheap transactions of the filter into the full pool
+	if err := add(customTransaction(0, cheap, keys[1], "UTG:1:stProof:a")); err != nil {
+		t.Fatalf("priority transaction rejected: %v", err)
+	}
+	if err := add(customTransaction(1, cheap, keys[1], "UTG:1:stProof:b")); err != nil {
+		t.Fatalf("second priority transaction rejected: %v", err)
+	}
+	if err := add(customTransaction(2, cheap, keys[1], "UTG:1:stProof:c")); !errors.Is(err, ErrUnderpriced) {
+		t.Fatalf("priority transaction over the account slots: have %v, want %v", err, ErrUnderpriced)
+	}
+	if err := add(customTransaction(0, cheap, keys[2], "UTG:1:stProof:d")); err != nil {
+		t.Fatalf("priority transaction of another account rejected: %v", err)
+	}
+	if err := add(customTransaction(1, cheap, keys[2], "UTG:1:stProof:e")); !errors.Is(err, ErrUnderpriced) {
+		t.Fatalf("priority transaction over the global slots: have %v, want %v", err, ErrUnderpriced)
+	}
+	// No ordinary transaction was evicted for the lane
+	if pending, queued := pool.Stats(); pending != 7 || queued != 0 {
+		t.Fatalf("pool stats: have %d pending, %d queued, want 7, 0", pending, queued)
+	}
+	if err := validateTxPoolInternals(pool); err != nil {
+		t.Fatalf("pool internal state corrupted: %v", err)
+	}
+	priority := pool.Priority()
+	if len(priority) != 2 || len(priority[crypto.PubkeyToAddress(keys[1].PublicKey)]) != 2 || len(priority[crypto.PubkeyToAddress(keys[2].PublicKey)]) != 1 {
+		t.Fatalf("priority lane mismatch: %v", priority)
+	}
+}
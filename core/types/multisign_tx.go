@@ -1,7 +1,9 @@
 package types
 
 import (
+	"errors"
 	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/rlp"
 	"math/big"
 )
 
@@ -22,6 +24,11 @@ type MultiSignerTx struct {
 	AccessList AccessList
 	SignerList SignerList      // EIP-2930 access list
 	V, R, S    *big.Int        // signature values
+
+	// MultiSig is the multi-signature account spending Value, nil if the
+	// initiator spends from its own account. Blocks before the multi-signature
+	// spend fork of the chain config must not contain transactions setting it.
+	MultiSig *common.Address `rlp:"optional"`
 }
 
 func NewMultiSignerTransaction(chainId *big.Int, nonce uint64, to common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
@@ -36,6 +43,23 @@ func NewMultiSignerTransaction(chainId *big.Int, nonce uint64, to common.Address
 	})
 }
 
+// NewMultiSigSpendTransaction creates a multi-signer transaction which moves
+// amount out of, or calls to on behalf of, the multi-signature account multiSig.
+// The initiator pays the gas and provides the nonce; the owners of multiSig
+// have to co-sign until the threshold of the account is met.
+func NewMultiSigSpendTransaction(chainId *big.Int, nonce uint64, multiSig common.Address, to common.Address, amount *big.Int, gasLimit uint64, gasPrice *big.Int, data []byte) *Transaction {
+	return NewTx(&MultiSignerTx{
+		ChainID:  chainId,
+		Nonce:    nonce,
+		GasPrice: gasPrice,
+		Gas:      gasLimit,
+		To:       &to,
+		Value:    amount,
+		Data:     data,
+		MultiSig: &multiSig,
+	})
+}
+
 func (tx *MultiSignerTx) copy() TxData {
 	cpy := &MultiSignerTx{
 		ChainID:    new(big.Int),
@@ -51,6 +75,7 @@ func (tx *MultiSignerTx) copy() TxData {
 		V:          new(big.Int),
 		R:          new(big.Int),
 		S:          new(big.Int),
+		MultiSig:   copyAddressPtr(tx.MultiSig),
 	}
 	if tx.ChainID != nil {
 		cpy.ChainID.Set(tx.ChainID)
@@ -90,6 +115,7 @@ func (tx *MultiSignerTx) innerCopy(v, r, s *big.Int) TxData {
 		V:          new(big.Int),
 		R:          new(big.Int),
 		S:          new(big.Int),
+		MultiSig:   copyAddressPtr(tx.MultiSig),
 	}
 	if tx.ChainID != nil {
 		cpy.ChainID.Set(tx.ChainID)
@@ -190,4 +216,79 @@ func (tx *MultiSignerTx) getAllSigners() []common.Address {
 	}
 
 	return allSigners
-}
\ No newline at end of file
+}
+
+// MultiSig returns the multi-signature account spending the value of the
+// transaction, or nil if the transaction is spent by its sender.
+func (tx *Transaction) MultiSig() *common.Address {
+	if inner, ok := tx.inner.(*MultiSignerTx); ok && inner.MultiSig != nil {
+		addr := *inner.MultiSig
+		return &addr
+	}
+	return nil
+}
+
+// PartiallySignedTx is the portable form of a MultiSignerTx that is still
+// collecting co-signatures. Besides the transaction it names the multi-signature
+// address whose owners are expected to sign. A transaction spending from that
+// address carries it as well, see MultiSig, and the two must match.
+type PartiallySignedTx struct {
+	MultiSig common.Address
+	Tx       *Transaction
+}
+
+// Consistent reports whether the transaction spends from the multi-signature
+// address named, or from its own sender.
+func (p *PartiallySignedTx) Consistent() bool {
+	multiSig := p.Tx.MultiSig()
+	return multiSig == nil || *multiSig == p.MultiSig
+}
+
+// MarshalBinary returns the RLP encoding of the partially signed transaction.
+func (p *PartiallySignedTx) MarshalBinary() ([]byte, error) {
+	return rlp.EncodeToBytes(p)
+}
+
+// UnmarshalBinary decodes a partially signed transaction produced by MarshalBinary.
+func (p *PartiallySignedTx) UnmarshalBinary(b []byte) error {
+	var dec PartiallySignedTx
+	if err := rlp.DecodeBytes(b, &dec); err != nil {
+		return err
+	}
+	if dec.Tx == nil || dec.Tx.Type() != MultiSignerTxType {
+		return ErrTxTypeNotSupported
+	}
+	*p = dec
+	return nil
+}
+
+// CombineSignatures returns a copy of tx carrying the initiator and co-signer
+// signatures of both tx and other. Both must be MultiSignerTx transactions with
+// the same signing hash.
+func (tx *Transaction) CombineSignatures(other *Transaction) (*Transaction, error) {
+	if tx.Type() != MultiSignerTxType || other.Type() != MultiSignerTxType {
+		return nil, ErrTxTypeNotSupported
+	}
+	signer := LatestSignerForChainID(tx.ChainId())
+	if signer.Hash(tx) != signer.Hash(other) {
+		return nil, errors.New("transactions differ in signed content")
+	}
+	cpy := tx.inner.copy()
+	inner := other.inner.(*MultiSignerTx)
+	if inner.V != nil && inner.R != nil && inner.S != nil {
+		cpy.setSignatureValues(inner.ChainID, inner.V, inner.R, inner.S)
+	}
+	for _, sign := range inner.SignerList {
+		cpy.setSignatureValues(inner.ChainID, sign.V, sign.R, sign.S)
+	}
+	return &Transaction{inner: cpy, time: tx.time}, nil
+}
+
+// copyAddressPtr copies an address.
+func copyAddressPtr(a *common.Address) *common.Address {
+	if a == nil {
+		return nil
+	}
+	cpy := *a
+	return &cpy
+}
@@ -0,0 +1,165 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package types
+
+import (
+	"crypto/ecdsa"
+	"math/big"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+)
+
+func TestPartiallySignedTx(t *testing.T) {
+	var (
+		chainID  = big.NewInt(18)
+		signer   = LatestSignerForChainID(chainID)
+		multiSig = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		keys     = make([]*ecdsa.PrivateKey, 3)
+		addrs    = make([]common.Address, 3)
+	)
+	for i := range keys {
+		keys[i], _ = crypto.GenerateKey()
+		addrs[i] = crypto.PubkeyToAddress(keys[i].PublicKey)
+	}
+	unsigned := NewMultiSignerTransaction(chainID, 0, multiSig, big.NewInt(1), 50000, big.NewInt(1), nil)
+	initiated, err := SignTx(unsigned, signer, keys[0])
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Two owners co-sign independently, starting from the same encoding.
+	enc, err := (&PartiallySignedTx{MultiSig: multiSig, Tx: initiated}).MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var copies [2]*Transaction
+	for i := range copies {
+		ptx := new(PartiallySignedTx)
+		if err := ptx.UnmarshalBinary(enc); err != nil {
+			t.Fatal(err)
+		}
+		if ptx.MultiSig != multiSig {
+			t.Fatalf("multisig mismatch: have %v, want %v", ptx.MultiSig, multiSig)
+		}
+		if copies[i], err = SignTx(ptx.Tx, signer, keys[i+1]); err != nil {
+			t.Fatal(err)
+		}
+	}
+	combined, err := copies[0].CombineSignatures(copies[1])
+	if err != nil {
+		t.Fatal(err)
+	}
+	signers := combined.AllSigners()
+	if len(signers) != 3 {
+		t.Fatalf("signer count mismatch: have %d, want 3", len(signers))
+	}
+	for i, addr := range addrs {
+		if signers[i] != addr {
+			t.Errorf("signer %d mismatch: have %v, want %v", i, signers[i], addr)
+		}
+	}
+	if from, err := Sender(signer, combined); err != nil || from != addrs[0] {
+		t.Errorf("initiator mismatch: have %v (%v), want %v", from, err, addrs[0])
+	}
+	// Combining transactions with different content must fail.
+	other, _ := SignTx(NewMultiSignerTransaction(chainID, 1, multiSig, big.NewInt(1), 50000, big.NewInt(1), nil), signer, keys[1])
+	if _, err := combined.CombineSignatures(other); err == nil {
+		t.Error("expected error combining different transactions")
+	}
+	// Only multi-signer transactions can be encoded.
+	legacy, _ := SignTx(NewTransaction(0, multiSig, new(big.Int), 0, new(big.Int), nil), signer, keys[0])
+	enc, _ = (&PartiallySignedTx{MultiSig: multiSig, Tx: legacy}).MarshalBinary()
+	if err := new(PartiallySignedTx).UnmarshalBinary(enc); err != ErrTxTypeNotSupported {
+		t.Errorf("expected ErrTxTypeNotSupported, have %v", err)
+	}
+}
+
+func TestMultiSigSpend(t *testing.T) {
+	var (
+		chainID   = big.NewInt(18)
+		signer    = LatestSignerForChainID(chainID)
+		multiSig  = common.HexToAddress("0x1000000000000000000000000000000000000001")
+		to        = common.HexToAddress("0x2000000000000000000000000000000000000002")
+		key, _    = crypto.GenerateKey()
+		initiator = crypto.PubkeyToAddress(key.PublicKey)
+	)
+	plain := NewMultiSignerTransaction(chainID, 5, to, big.NewInt(100), 21000, big.NewInt(2), nil)
+	spend := NewMultiSigSpendTransaction(chainID, 5, multiSig, to, big.NewInt(100), 21000, big.NewInt(2), nil)
+	if plain.MultiSig() != nil {
+		t.Fatal("plain transaction reports a multi-signature account")
+	}
+	if signer.Hash(plain) == signer.Hash(spend) {
+		t.Fatal("spending account not covered by the signing hash")
+	}
+	if have, want := spend.Cost(), plain.Cost(); have.Cmp(want) != 0 {
+		t.Errorf("cost mismatch: have %v, want %v", have, want)
+	}
+	signed, err := SignTx(spend, signer, key)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// The account must survive both encodings.
+	enc, err := signed.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var dec Transaction
+	if err := dec.UnmarshalBinary(enc); err != nil {
+		t.Fatal(err)
+	}
+	if m := dec.MultiSig(); m == nil || *m != multiSig {
+		t.Fatalf("binary round trip lost the account: %v", m)
+	}
+	js, err := signed.MarshalJSON()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var jsDec Transaction
+	if err := jsDec.UnmarshalJSON(js); err != nil {
+		t.Fatal(err)
+	}
+	if jsDec.Hash() != signed.Hash() {
+		t.Errorf("json round trip hash mismatch: have %x, want %x", jsDec.Hash(), signed.Hash())
+	}
+	msg, err := dec.AsMessage(signer, nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if msg.From() != multiSig || msg.Payer() != initiator {
+		t.Errorf("message accounts mismatch: from %v payer %v", msg.From(), msg.Payer())
+	}
+	if len(msg.Signers()) != 1 || msg.Signers()[0] != initiator {
+		t.Errorf("message signers mismatch: %v", msg.Signers())
+	}
+	// A partially signed transaction must name the account it spends from.
+	if !(&PartiallySignedTx{MultiSig: multiSig, Tx: signed}).Consistent() {
+		t.Error("matching account reported inconsistent")
+	}
+	if (&PartiallySignedTx{MultiSig: to, Tx: signed}).Consistent() {
+		t.Error("mismatching account reported consistent")
+	}
+	// Transactions without the account keep their previous encoding.
+	plainSigned, _ := SignTx(plain, signer, key)
+	enc, _ = plainSigned.MarshalBinary()
+	if err := dec.UnmarshalBinary(enc); err != nil || dec.MultiSig() != nil {
+		t.Errorf("plain round trip failed: %v", err)
+	}
+	if msg, _ := plainSigned.AsMessage(signer, nil); msg.Payer() != msg.From() {
+		t.Errorf("plain payer mismatch: have %v, want %v", msg.Payer(), msg.From())
+	}
+}
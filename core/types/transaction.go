@@ -591,6 +591,9 @@ type Message struct {
 	data       []byte
 	accessList AccessList
 	checkNonce bool
+
+	payer   common.Address   // initiator paying the gas, set if from is a multi-signature account
+	signers []common.Address // all signers of a multi-signature spend
 }
 
 func NewMessage(from common.Address, to *common.Address, nonce uint64, amount *big.Int, gasLimit uint64, gasPrice, gasFeeCap, gasTipCap *big.Int, data []byte, accessList AccessList, checkNonce bool) Message {
@@ -629,10 +632,27 @@ func (tx *Transaction) AsMessage(s Signer, baseFee *big.Int) (Message, error) {
 	}
 	var err error
 	msg.from, err = Sender(s, tx)
+	if multiSig := tx.MultiSig(); multiSig != nil && err == nil {
+		msg.payer, msg.from = msg.from, *multiSig
+		msg.signers = tx.AllSigners()
+	}
 	return msg, err
 }
 
 func (m Message) From() common.Address   { return m.from }
+
+// Payer returns the account paying the gas and providing the nonce. It is the
+// initiator of a multi-signature spend, and From otherwise.
+func (m Message) Payer() common.Address {
+	if m.payer != (common.Address{}) {
+		return m.payer
+	}
+	return m.from
+}
+
+// Signers returns the signers of a multi-signature spend, nil otherwise.
+func (m Message) Signers() []common.Address { return m.signers }
+
 func (m Message) To() *common.Address    { return m.to }
 func (m Message) GasPrice() *big.Int     { return m.gasPrice }
 func (m Message) GasFeeCap() *big.Int    { return m.gasFeeCap }
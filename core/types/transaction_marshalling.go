@@ -47,6 +47,9 @@ type txJSON struct {
 	AccessList *AccessList  `json:"accessList,omitempty"`
 	SignerList *SignerList  `json:"signerList,omitempty"`
 
+	// Multi-signer transaction fields:
+	MultiSig *common.Address `json:"multiSig,omitempty"`
+
 	// Only used for encoding:
 	Hash common.Hash `json:"hash"`
 }
@@ -99,6 +102,7 @@ func (t *Transaction) MarshalJSON() ([]byte, error) {
 		enc.ChainID = (*hexutil.Big)(tx.ChainID)
 		enc.AccessList = &tx.AccessList
 		enc.SignerList = &tx.SignerList
+		enc.MultiSig = tx.MultiSig
 		enc.Nonce = (*hexutil.Uint64)(&tx.Nonce)
 		enc.Gas = (*hexutil.Uint64)(&tx.Gas)
 		enc.GasPrice = (*hexutil.Big)(tx.GasPrice)
@@ -230,6 +234,7 @@ func (t *Transaction) UnmarshalJSON(input []byte) error {
 		if dec.SignerList != nil {
 			itx.SignerList = *dec.SignerList
 		}
+		itx.MultiSig = dec.MultiSig
 		if dec.ChainID == nil {
 			return errors.New("missing required field 'chainId' in transaction")
 		}
@@ -316,18 +316,22 @@ func (s eip2930Signer) Hash(tx *Transaction) common.Hash {
 			s.chainId, uint(0), uint(0),
 		})
 	case AccessListTxType, MultiSignerTxType:
-		return prefixedRlpHash(
-			tx.Type(),
-			[]interface{}{
-				s.chainId,
-				tx.Nonce(),
-				tx.GasPrice(),
-				tx.Gas(),
-				tx.To(),
-				tx.Value(),
-				tx.Data(),
-				tx.AccessList(),
-			})
+		fields := []interface{}{
+			s.chainId,
+			tx.Nonce(),
+			tx.GasPrice(),
+			tx.Gas(),
+			tx.To(),
+			tx.Value(),
+			tx.Data(),
+			tx.AccessList(),
+		}
+		// The spending account is signed by every owner, but only when set so
+		// that the hash of plain multi-signer transactions is unchanged.
+		if multiSig := tx.MultiSig(); multiSig != nil {
+			fields = append(fields, multiSig)
+		}
+		return prefixedRlpHash(tx.Type(), fields)
 	default:
 		// This _should_ not happen, but in case someone sends in a bad
 		// json struct via RPC, it's probably more prudent to return an
@@ -56,6 +56,108 @@ func (api *PublicEthereumAPI) IsMultiSignatureAddress(address common.Address) bo
 	return api.e.IsMultiSignatureAddress(address)
 }
 
+// MultiSignature is the current owner set of a multi-signature address.
+type MultiSignature struct {
+	Address   common.Address   `json:"address"`
+	Threshold hexutil.Uint     `json:"threshold"`
+	Owners    []common.Address `json:"owners"`
+}
+
+// GetMultiSignature returns the threshold and the owners of a multi-signature
+// address at the current head.
+func (api *PublicEthereumAPI) GetMultiSignature(address common.Address) (*MultiSignature, error) {
+	parameter, err := api.e.MultiSignatureData(address)
+	if err != nil {
+		return nil, err
+	}
+	return &MultiSignature{
+		Address:   address,
+		Threshold: hexutil.Uint(parameter.Threshold),
+		Owners:    parameter.MultiSigners,
+	}, nil
+}
+
+// MultiSigOwner reports whether an owner of a multi-signature address has
+// signed a partially signed transaction.
+type MultiSigOwner struct {
+	Address common.Address `json:"address"`
+	Signed  bool           `json:"signed"`
+}
+
+// MultiSigStatus is the signing progress of a partially signed transaction.
+type MultiSigStatus struct {
+	MultiSig  common.Address  `json:"multiSig"`
+	Hash      common.Hash     `json:"hash"`
+	SigHash   common.Hash     `json:"sigHash"`
+	Threshold hexutil.Uint    `json:"threshold"`
+	Signed    hexutil.Uint    `json:"signed"`
+	Owners    []MultiSigOwner `json:"owners"`
+	Complete  bool            `json:"complete"`
+	Encoded   hexutil.Bytes   `json:"encoded"`
+}
+
+// GetMultiSigStatus returns which owners of the multi-signature address have
+// signed the given partially signed transaction. Signatures already held by
+// the local transaction pool are taken into account.
+func (api *PublicEthereumAPI) GetMultiSigStatus(encoded hexutil.Bytes) (*MultiSigStatus, error) {
+	ptx := new(types.PartiallySignedTx)
+	if err := ptx.UnmarshalBinary(encoded); err != nil {
+		return nil, err
+	}
+	signer := types.LatestSignerForChainID(ptx.Tx.ChainId())
+	if held := api.e.TxPool().PartiallySigned(signer.Hash(ptx.Tx)); held != nil && held.MultiSig == ptx.MultiSig {
+		merged, err := held.Tx.CombineSignatures(ptx.Tx)
+		if err != nil {
+			return nil, err
+		}
+		ptx = &types.PartiallySignedTx{MultiSig: ptx.MultiSig, Tx: merged}
+	}
+	return api.multiSigStatus(ptx)
+}
+
+// SubmitPartiallySignedTransaction adds the partially signed transaction to
+// the holding area of the transaction pool. The transaction is broadcast as
+// soon as the collected signatures satisfy the threshold.
+func (api *PublicEthereumAPI) SubmitPartiallySignedTransaction(encoded hexutil.Bytes) (*MultiSigStatus, error) {
+	ptx := new(types.PartiallySignedTx)
+	if err := ptx.UnmarshalBinary(encoded); err != nil {
+		return nil, err
+	}
+	merged, _, err := api.e.TxPool().AddPartiallySigned(ptx)
+	if err != nil {
+		return nil, err
+	}
+	return api.multiSigStatus(merged)
+}
+
+func (api *PublicEthereumAPI) multiSigStatus(ptx *types.PartiallySignedTx) (*MultiSigStatus, error) {
+	parameter, err := api.e.MultiSignatureData(ptx.MultiSig)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := ptx.MarshalBinary()
+	if err != nil {
+		return nil, err
+	}
+	signed := make(map[common.Address]bool)
+	for _, addr := range parameter.Signed(ptx.Tx.AllSigners()) {
+		signed[addr] = true
+	}
+	status := &MultiSigStatus{
+		MultiSig:  ptx.MultiSig,
+		Hash:      ptx.Tx.Hash(),
+		SigHash:   types.LatestSignerForChainID(ptx.Tx.ChainId()).Hash(ptx.Tx),
+		Threshold: hexutil.Uint(parameter.Threshold),
+		Signed:    hexutil.Uint(len(signed)),
+		Complete:  parameter.Satisfied(ptx.Tx.AllSigners()),
+		Encoded:   encoded,
+	}
+	for _, owner := range parameter.MultiSigners {
+		status.Owners = append(status.Owners, MultiSigOwner{Address: owner, Signed: signed[owner]})
+	}
+	return status, nil
+}
+
 // Etherbase is the address that mining rewards will be send to
 func (api *PublicEthereumAPI) Etherbase() (common.Address, error) {
 	return api.e.Etherbase()
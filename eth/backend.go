@@ -20,7 +20,6 @@ package eth
 import (
 	"errors"
 	"fmt"
-	"github.com/UltronGlow/UltronGlow-Origin/crypto"
 	"math/big"
 	"runtime"
 	"sync"
@@ -83,6 +82,8 @@ type Ethereum struct {
 
 	bloomRequests     chan chan *bloombits.Retrieval // Channel receiving bloom data retrieval requests
 	bloomIndexer      *core.ChainIndexer             // Bloom indexer operating during block imports
+	supplyIndexer     *core.ChainIndexer             // Supply indexer of the alien engine, if any
+	supplySub         event.Subscription             // Chain events storing the supply deltas of inserted blocks
 	closeBloomHandler chan struct{}
 
 	APIBackend *EthAPIBackend
@@ -209,11 +210,21 @@ func New(stack *node.Node, config *ethconfig.Config) (*Ethereum, error) {
 		rawdb.WriteChainConfig(chainDb, genesisHash, chainConfig)
 	}
 	eth.bloomIndexer.Start(eth.blockchain)
+	if engine, ok := eth.engine.(*alien.Alien); ok {
+		eth.supplyIndexer = core.NewChainIndexer(chainDb, rawdb.NewTable(chainDb, "alien-supply-index-"), engine.NewSupplyIndexer(), alien.SupplySectionSize, 0, 0, "supply")
+		eth.supplyIndexer.Start(eth.blockchain)
+		eth.storeSupplyDeltas(engine)
+	}
 
 	if config.TxPool.Journal != "" {
 		config.TxPool.Journal = stack.ResolvePath(config.TxPool.Journal)
 	}
 	eth.txPool = core.NewTxPool(config.TxPool, chainConfig, eth.blockchain)
+	if engine, ok := eth.engine.(*alien.Alien); ok {
+		eth.txPool.SetPriorityFilter(func(from common.Address, tx *types.Transaction) bool {
+			return engine.IsPriorityTx(eth.blockchain, from, tx)
+		})
+	}
 
 	// Permit the downloader to use the trie cache allowance during fast sync
 	cacheLimit := cacheConfig.TrieCleanLimit + cacheConfig.TrieDirtyLimit + cacheConfig.SnapshotLimit
@@ -361,19 +372,18 @@ func (s *Ethereum) ResetWithGenesisBlock(gb *types.Block) {
 }
 
 func (s *Ethereum) IsMultiSignatureAddress(address common.Address) bool {
+	_, err := s.MultiSignatureData(address)
+	return err == nil
+}
+
+// MultiSignatureData returns the owners and threshold of a multi-signature
+// address at the current head.
+func (s *Ethereum) MultiSignatureData(address common.Address) (*consensus.MultiSignatureData, error) {
 	state, err := s.blockchain.State()
-	if nil != err || state.Empty(address) {
-		return false
-	}
-	contractHash := state.GetCodeHash(address)
-	if state.GetNonce(address) != 1 || contractHash == (common.Hash{}) || contractHash == crypto.Keccak256Hash(nil) {
-		return false
-	}
-	var parameter consensus.MultiSignatureData
-	if err := rlp.DecodeBytes(state.GetCode(address), &parameter); nil != err {
-		return false
+	if err != nil {
+		return nil, err
 	}
-	return true
+	return consensus.ReadMultiSignatureData(state, address)
 }
 
 func (s *Ethereum) Etherbase() (eb common.Address, err error) {
@@ -549,6 +559,27 @@ func (s *Ethereum) Synced() bool                       { return atomic.LoadUint3
 func (s *Ethereum) ArchiveMode() bool                  { return s.config.NoPruning }
 func (s *Ethereum) BloomIndexer() *core.ChainIndexer   { return s.bloomIndexer }
 
+// supplyChainEventChanSize is the size of the channel listening to the blocks
+// inserted into the chain for the supply indexer.
+const supplyChainEventChanSize = 10
+
+// storeSupplyDeltas writes out the balance changes the alien engine remembered
+// for each block inserted into the canonical chain, for its supply indexer.
+func (s *Ethereum) storeSupplyDeltas(engine *alien.Alien) {
+	chainCh := make(chan core.ChainEvent, supplyChainEventChanSize)
+	s.supplySub = s.blockchain.SubscribeChainEvent(chainCh)
+	go func() {
+		for {
+			select {
+			case ev := <-chainCh:
+				engine.StoreSupplyDelta(ev.Block.Header())
+			case <-s.supplySub.Err():
+				return
+			}
+		}
+	}()
+}
+
 // Protocols returns all the currently configured
 // network protocols to start.
 func (s *Ethereum) Protocols() []p2p.Protocol {
@@ -590,6 +621,10 @@ func (s *Ethereum) Stop() error {
 
 	// Then stop everything else.
 	s.bloomIndexer.Close()
+	if s.supplyIndexer != nil {
+		s.supplySub.Unsubscribe()
+		s.supplyIndexer.Close()
+	}
 	close(s.closeBloomHandler)
 	s.txPool.Stop()
 	s.miner.Stop()
@@ -80,10 +80,11 @@ var Defaults = Config{
 	TrieTimeout:             60 * time.Minute,
 	SnapshotCache:           102,
 	Miner: miner.Config{
-		GasFloor: 8000000,
-		GasCeil:  8000000,
-		GasPrice: big.NewInt(params.GGasPrice),
-		Recommit: 3 * time.Second,
+		GasFloor:   8000000,
+		GasCeil:    8000000,
+		GasPrice:   big.NewInt(params.GGasPrice),
+		Recommit:   3 * time.Second,
+		ReserveGas: 1000000,
 	},
 	TxPool:      core.DefaultTxPoolConfig,
 	RPCGasCap:   50000000,
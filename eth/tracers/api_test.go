@@ -591,6 +591,61 @@ func TestTraceBlock(t *testing.T) {
 	}
 }
 
+// balanceTracer is an engine reporting the state it is asked to finalise a
+// block on as a balance change.
+type balanceTracer struct {
+	consensus.Engine
+	watch    common.Address
+	receipts []*types.Receipt
+	reward   *big.Int
+}
+
+func (e *balanceTracer) TraceFinalize(chain consensus.ChainHeaderReader, header *types.Header, state *state.StateDB, txs []*types.Transaction, uncles []*types.Header, receipts []*types.Receipt, gasReward *big.Int) ([]consensus.BalanceChange, error) {
+	e.receipts, e.reward = receipts, gasReward
+	return []consensus.BalanceChange{{Address: e.watch, Delta: state.GetBalance(e.watch), Reason: "watch", Source: header.Hash().Hex()}}, nil
+}
+
+func TestTraceConsensus(t *testing.T) {
+	t.Parallel()
+
+	accounts := newAccounts(2)
+	genesis := &core.Genesis{Alloc: core.GenesisAlloc{
+		accounts[0].addr: {Balance: big.NewInt(params.Ether)},
+	}}
+	genBlocks := 3
+	signer := types.HomesteadSigner{}
+	backend := newTestBackend(t, genBlocks, genesis, func(i int, b *core.BlockGen) {
+		// Transfer 1000 wei from account[0] to account[1] twice a block
+		for j := 0; j < 2; j++ {
+			tx, _ := types.SignTx(types.NewTransaction(uint64(2*i+j), accounts[1].addr, big.NewInt(1000), params.TxGas, big.NewInt(0), nil), signer, accounts[0].key)
+			b.AddTx(tx)
+		}
+	})
+	api := NewAPI(backend)
+	block := backend.chain.GetBlockByNumber(2)
+	if _, err := api.TraceConsensus(context.Background(), block.Hash(), nil); err == nil {
+		t.Fatal("traced with an engine not supporting balance tracing")
+	}
+	engine := &balanceTracer{Engine: backend.engine, watch: accounts[1].addr}
+	backend.engine = engine
+
+	if _, err := api.TraceConsensus(context.Background(), backend.chain.Genesis().Hash(), nil); err == nil {
+		t.Error("genesis traced")
+	}
+	changes, err := api.TraceConsensus(context.Background(), block.Hash(), nil)
+	if err != nil {
+		t.Fatalf("failed to trace block: %v", err)
+	}
+	// The engine finalises on the state with the transactions of the block
+	want := []consensus.BalanceChange{{Address: accounts[1].addr, Delta: big.NewInt(4000), Reason: "watch", Source: block.Hash().Hex()}}
+	if !reflect.DeepEqual(changes, want) {
+		t.Errorf("balance changes mismatch: have %v, want %v", changes, want)
+	}
+	if len(engine.receipts) != 2 || engine.receipts[1].CumulativeGasUsed != 2*params.TxGas || engine.reward == nil {
+		t.Errorf("finalised with receipts %v and gas reward %v", engine.receipts, engine.reward)
+	}
+}
+
 type Account struct {
 	key  *ecdsa.PrivateKey
 	addr common.Address
@@ -0,0 +1,389 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package alienclient provides a client for the alien consensus RPC API.
+package alienclient
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/common/hexutil"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus/alien"
+	"github.com/UltronGlow/UltronGlow-Origin/rpc"
+)
+
+// Client defines typed wrappers for the alien RPC API.
+type Client struct {
+	c *rpc.Client
+}
+
+// Dial connects a client to the given URL.
+func Dial(rawurl string) (*Client, error) {
+	return DialContext(context.Background(), rawurl)
+}
+
+// DialContext connects a client to the given URL with context.
+func DialContext(ctx context.Context, rawurl string) (*Client, error) {
+	c, err := rpc.DialContext(ctx, rawurl)
+	if err != nil {
+		return nil, err
+	}
+	return NewClient(c), nil
+}
+
+// NewClient creates a client that uses the given RPC client.
+func NewClient(c *rpc.Client) *Client {
+	return &Client{c}
+}
+
+// Close closes the underlying RPC connection.
+func (ac *Client) Close() {
+	ac.c.Close()
+}
+
+// Snapshots
+
+// Snapshot returns the consensus snapshot at the given block number.
+// If number is nil, the latest known snapshot is returned.
+func (ac *Client) Snapshot(ctx context.Context, number *big.Int) (*alien.Snapshot, error) {
+	var result *alien.Snapshot
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshot", toBlockNumArg(number))
+	return result, err
+}
+
+// SnapshotAtHash returns the consensus snapshot at the given block hash.
+func (ac *Client) SnapshotAtHash(ctx context.Context, hash common.Hash) (*alien.Snapshot, error) {
+	var result *alien.Snapshot
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotAtHash", hash)
+	return result, err
+}
+
+// SnapshotAtNumber returns the consensus snapshot at the given block number.
+func (ac *Client) SnapshotAtNumber(ctx context.Context, number uint64) (*alien.Snapshot, error) {
+	var result *alien.Snapshot
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotAtNumber", number)
+	return result, err
+}
+
+// SnapshotSignerAtNumber returns the signer queue and its pledge information.
+func (ac *Client) SnapshotSignerAtNumber(ctx context.Context, number uint64) (*alien.SnapshotSign, error) {
+	var result *alien.SnapshotSign
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotSignerAtNumber", number)
+	return result, err
+}
+
+// SnapshotReleaseAtNumber returns the locked and released rewards. part selects
+// the lock category, see the API documentation for the accepted values.
+func (ac *Client) SnapshotReleaseAtNumber(ctx context.Context, number uint64, part string) (*alien.SnapshotRelease, error) {
+	var result *alien.SnapshotRelease
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotReleaseAtNumber", number, part)
+	return result, err
+}
+
+// SnapshotReleaseAtNumber2 is like SnapshotReleaseAtNumber but restricts the
+// result to lock records created between startLNum and endLNum.
+func (ac *Client) SnapshotReleaseAtNumber2(ctx context.Context, number uint64, part string, startLNum uint64, endLNum uint64) (*alien.SnapshotRelease, error) {
+	var result *alien.SnapshotRelease
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotReleaseAtNumber2", number, part, startLNum, endLNum)
+	return result, err
+}
+
+// SnapshotFlowAtNumber returns the flow locks recorded in the snapshot.
+func (ac *Client) SnapshotFlowAtNumber(ctx context.Context, number uint64) (*alien.SnapshotFlow, error) {
+	var result *alien.SnapshotFlow
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotFlowAtNumber", number)
+	return result, err
+}
+
+// SnapshotFlowMinerAtNumber returns the flow miner reports of the current period.
+func (ac *Client) SnapshotFlowMinerAtNumber(ctx context.Context, number uint64) (*alien.SnapshotFlowMiner, error) {
+	var result *alien.SnapshotFlowMiner
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotFlowMinerAtNumber", number)
+	return result, err
+}
+
+// SnapshotFlowReportAtNumber returns the flow reports of the given block.
+func (ac *Client) SnapshotFlowReportAtNumber(ctx context.Context, number uint64) (*alien.SnapshotFlowReport, error) {
+	var result *alien.SnapshotFlowReport
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotFlowReportAtNumber", number)
+	return result, err
+}
+
+// SnapshotTLSAtNumber returns the total locked amounts recorded in the snapshot.
+func (ac *Client) SnapshotTLSAtNumber(ctx context.Context, number uint64) (*alien.SnapshotTLS, error) {
+	var result *alien.SnapshotTLS
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotTLSAtNumber", number)
+	return result, err
+}
+
+// SnapshotRewardBalanceV1 returns the reward balances of the given lock category.
+func (ac *Client) SnapshotRewardBalanceV1(ctx context.Context, number uint64, part string) (*alien.SnapshotRewardBalanceV1, error) {
+	var result *alien.SnapshotRewardBalanceV1
+	err := ac.c.CallContext(ctx, &result, "alien_getSnapshotRewardBalanceV1", number, part)
+	return result, err
+}
+
+// Rewards
+
+// LockRewardAtNumber returns the lock reward records produced by the given block.
+func (ac *Client) LockRewardAtNumber(ctx context.Context, number uint64) ([]alien.LockRewardRecord, error) {
+	var result []alien.LockRewardRecord
+	err := ac.c.CallContext(ctx, &result, "alien_getLockRewardAtNumber", number)
+	return result, err
+}
+
+// GrantProfitAtNumber returns the released rewards paid out by the given block.
+func (ac *Client) GrantProfitAtNumber(ctx context.Context, number uint64) ([]consensus.GrantProfitRecord, error) {
+	var result []consensus.GrantProfitRecord
+	err := ac.c.CallContext(ctx, &result, "alien_getGrantProfitAtNumber", number)
+	return result, err
+}
+
+// CandidateAutoExitAtNumber returns the candidates scheduled for automatic exit.
+func (ac *Client) CandidateAutoExitAtNumber(ctx context.Context, number uint64) (*alien.SnapCanAutoExit, error) {
+	var result *alien.SnapCanAutoExit
+	err := ac.c.CallContext(ctx, &result, "alien_getCandidateAutoExitAtNumber", number)
+	return result, err
+}
+
+// SRT
+
+// SRTBalAtNumber returns every SRT balance recorded in the snapshot.
+func (ac *Client) SRTBalAtNumber(ctx context.Context, number uint64) (*alien.SnapshotSRT, error) {
+	var result *alien.SnapshotSRT
+	err := ac.c.CallContext(ctx, &result, "alien_getSRTBalAtNumber", number)
+	return result, err
+}
+
+// SRTBalanceAtNumber returns the SRT balance of the account at the given block.
+func (ac *Client) SRTBalanceAtNumber(ctx context.Context, address common.Address, number uint64) (*alien.SnapshotAddrSRT, error) {
+	var result *alien.SnapshotAddrSRT
+	err := ac.c.CallContext(ctx, &result, "alien_getSRTBalanceAtNumber", address, number)
+	return result, err
+}
+
+// SRTBalance returns the SRT balance of the account at the latest block.
+func (ac *Client) SRTBalance(ctx context.Context, address common.Address) (*alien.SnapshotAddrSRT, error) {
+	var result *alien.SnapshotAddrSRT
+	err := ac.c.CallContext(ctx, &result, "alien_getSRTBalance", address)
+	return result, err
+}
+
+// RevertSRTAtNumber returns the SRT revert records of the given block.
+func (ac *Client) RevertSRTAtNumber(ctx context.Context, number uint64) (*alien.SnapshotRevertSRT, error) {
+	var result *alien.SnapshotRevertSRT
+	err := ac.c.CallContext(ctx, &result, "alien_getRevertSRTAtNumber", number)
+	return result, err
+}
+
+// Storage
+
+// SPledgeAtNumber returns the storage pledges and their leases.
+func (ac *Client) SPledgeAtNumber(ctx context.Context, number uint64) (*alien.SnapshotSPledge, error) {
+	var result *alien.SnapshotSPledge
+	err := ac.c.CallContext(ctx, &result, "alien_getSPledgeAtNumber", number)
+	return result, err
+}
+
+// SPledgeInfoByAddr returns the storage pledge of the given address at the latest block.
+func (ac *Client) SPledgeInfoByAddr(ctx context.Context, address common.Address) (*alien.SnapshotSPledgeInfo, error) {
+	var result *alien.SnapshotSPledgeInfo
+	err := ac.c.CallContext(ctx, &result, "alien_getSPledgeInfoByAddr", address)
+	return result, err
+}
+
+// SPledgeCapVerAtNumber returns the verified capacity of every storage pledge.
+func (ac *Client) SPledgeCapVerAtNumber(ctx context.Context, number uint64) (*alien.SnapshotSPledgeCapVer, error) {
+	var result *alien.SnapshotSPledgeCapVer
+	err := ac.c.CallContext(ctx, &result, "alien_getSPledgeCapVerAtNumber", number)
+	return result, err
+}
+
+// StorageRewardAtNumber returns the storage rewards of the given reward category.
+func (ac *Client) StorageRewardAtNumber(ctx context.Context, number uint64, part string) (*alien.SnapshotStorageReward, error) {
+	var result *alien.SnapshotStorageReward
+	err := ac.c.CallContext(ctx, &result, "alien_getStorageRewardAtNumber", number, part)
+	return result, err
+}
+
+// StorageRatiosAtNumber returns the storage reward ratios.
+func (ac *Client) StorageRatiosAtNumber(ctx context.Context, number uint64) (*alien.SnapshotStorageRatios, error) {
+	var result *alien.SnapshotStorageRatios
+	err := ac.c.CallContext(ctx, &result, "alien_getStorageRatiosAtNumber", number)
+	return result, err
+}
+
+// StorageValueAtNumber returns the storage values of the given category.
+func (ac *Client) StorageValueAtNumber(ctx context.Context, number uint64, part string) (*alien.SnapshotSPledgeValue, error) {
+	var result *alien.SnapshotSPledgeValue
+	err := ac.c.CallContext(ctx, &result, "alien_getStorageValueAtNumber", number, part)
+	return result, err
+}
+
+// StorageDecimalValueAtNumber is like StorageValueAtNumber but returns decimal values.
+func (ac *Client) StorageDecimalValueAtNumber(ctx context.Context, number uint64, part string) (*alien.SnapshotSPledgeDecimalValue, error) {
+	var result *alien.SnapshotSPledgeDecimalValue
+	err := ac.c.CallContext(ctx, &result, "alien_getStorageDecimalValueAtNumber", number, part)
+	return result, err
+}
+
+// StorageRatioValueAtNumber returns the share of value allotted to each storage pledge.
+func (ac *Client) StorageRatioValueAtNumber(ctx context.Context, number uint64, value *big.Int, part string) (*alien.SnapshotSPledgeRatioValue, error) {
+	var result *alien.SnapshotSPledgeRatioValue
+	err := ac.c.CallContext(ctx, &result, "alien_getStorageRatioValueAtNumber", number, value, part)
+	return result, err
+}
+
+// SucSPledgeAtNumber returns the storage pledges that passed verification.
+func (ac *Client) SucSPledgeAtNumber(ctx context.Context, number uint64) (*alien.SnapshotSucSPledge, error) {
+	var result *alien.SnapshotSucSPledge
+	err := ac.c.CallContext(ctx, &result, "alien_getSucSPledgeAtNumber", number)
+	return result, err
+}
+
+// RentSucAtNumber returns the leases that passed verification.
+func (ac *Client) RentSucAtNumber(ctx context.Context, number uint64) (*alien.SnapshotRentSuc, error) {
+	var result *alien.SnapshotRentSuc
+	err := ac.c.CallContext(ctx, &result, "alien_getRentSucAtNumber", number)
+	return result, err
+}
+
+// CapSuccAddrsAtNumber returns the capacity of the pledges that passed verification.
+func (ac *Client) CapSuccAddrsAtNumber(ctx context.Context, number uint64) (*alien.SnapshotCapSuccAddrs, error) {
+	var result *alien.SnapshotCapSuccAddrs
+	err := ac.c.CallContext(ctx, &result, "alien_getCapSuccAddrsAtNumber", number)
+	return result, err
+}
+
+// STGBandwidthMakeup returns the bandwidth makeup table.
+func (ac *Client) STGBandwidthMakeup(ctx context.Context) (*alien.SnapshotSTGbwMakeup, error) {
+	var result *alien.SnapshotSTGbwMakeup
+	err := ac.c.CallContext(ctx, &result, "alien_getSTGBandwidthMakeup")
+	return result, err
+}
+
+// SPoolAtNumber returns the storage pools recorded in the snapshot.
+func (ac *Client) SPoolAtNumber(ctx context.Context, number uint64) (*alien.SpDataApi, error) {
+	var result *alien.SpDataApi
+	err := ac.c.CallContext(ctx, &result, "alien_getSPoolAtNumber", number)
+	return result, err
+}
+
+// Supply
+
+// Supply returns the token supply at the given block number.
+// If number is nil, the supply at the latest block is returned.
+func (ac *Client) Supply(ctx context.Context, number *big.Int) (*alien.Supply, error) {
+	var result *alien.Supply
+	err := ac.c.CallContext(ctx, &result, "alien_getSupply", toBlockNumArg(number))
+	return result, err
+}
+
+// VerifySupply compares the tracked balances at the given block number with
+// the sum of the balances in its state.
+func (ac *Client) VerifySupply(ctx context.Context, number *big.Int) (*alien.SupplyCheck, error) {
+	var result *alien.SupplyCheck
+	err := ac.c.CallContext(ctx, &result, "alien_verifySupply", toBlockNumArg(number))
+	return result, err
+}
+
+// Storage offers
+
+// FindStorageOffers returns the storage pledges able to take a rent request
+// matching the criteria, best first.
+func (ac *Client) FindStorageOffers(ctx context.Context, criteria *alien.StorageOfferCriteria) (*alien.StorageOffers, error) {
+	var result *alien.StorageOffers
+	err := ac.c.CallContext(ctx, &result, "alien_findStorageOffers", criteria)
+	return result, err
+}
+
+// PoS candidates
+
+// SignerRotations returns the PoS signer key rotations waiting for their loop
+// boundary, the earliest first.
+func (ac *Client) SignerRotations(ctx context.Context) ([]*alien.SignerRotation, error) {
+	var result []*alien.SignerRotation
+	err := ac.c.CallContext(ctx, &result, "alien_getSignerRotations")
+	return result, err
+}
+
+// Storage pools
+
+// StoragePool returns the storage pool identified by hash with its members
+// and pending exits.
+func (ac *Client) StoragePool(ctx context.Context, hash common.Hash) (*alien.StoragePool, error) {
+	var result *alien.StoragePool
+	err := ac.c.CallContext(ctx, &result, "alien_getStoragePool", hash)
+	return result, err
+}
+
+// ListStoragePools returns a page of the storage pools ordered by sortBy.
+func (ac *Client) ListStoragePools(ctx context.Context, sortBy string, page uint64) (*alien.StoragePools, error) {
+	var result *alien.StoragePools
+	err := ac.c.CallContext(ctx, &result, "alien_listStoragePools", sortBy, page)
+	return result, err
+}
+
+// Side chains
+
+// SideChain returns the side chain identified by hash with its rents,
+// confirmations, coinbases and accrued rewards.
+func (ac *Client) SideChain(ctx context.Context, hash common.Hash) (*alien.SideChainStatus, error) {
+	var result *alien.SideChainStatus
+	err := ac.c.CallContext(ctx, &result, "alien_getSideChain", hash)
+	return result, err
+}
+
+// ListSideChains returns every side chain recorded by the main chain.
+func (ac *Client) ListSideChains(ctx context.Context) (*alien.SideChains, error) {
+	var result *alien.SideChains
+	err := ac.c.CallContext(ctx, &result, "alien_listSideChains")
+	return result, err
+}
+
+// System configuration
+
+// PendingConfigChanges returns the passed system configuration changes that
+// are waiting for their activation block, the earliest first.
+func (ac *Client) PendingConfigChanges(ctx context.Context) ([]*alien.PendingConfigChange, error) {
+	var result []*alien.PendingConfigChange
+	err := ac.c.CallContext(ctx, &result, "alien_getPendingConfigChanges")
+	return result, err
+}
+
+// Operator watchdog
+
+// Alerts returns the alerts of the operator watchdog for the last head, an
+// error if the node runs without the watchdog.
+func (ac *Client) Alerts(ctx context.Context) ([]*alien.Alert, error) {
+	var result []*alien.Alert
+	err := ac.c.CallContext(ctx, &result, "alien_getAlerts")
+	return result, err
+}
+
+func toBlockNumArg(number *big.Int) string {
+	if number == nil {
+		return "latest"
+	}
+	pending := big.NewInt(-1)
+	if number.Cmp(pending) == 0 {
+		return "pending"
+	}
+	return hexutil.EncodeBig(number)
+}
@@ -0,0 +1,358 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alienclient
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus/alien"
+	"github.com/UltronGlow/UltronGlow-Origin/core"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+	"github.com/UltronGlow/UltronGlow-Origin/eth"
+	"github.com/UltronGlow/UltronGlow-Origin/eth/ethconfig"
+	"github.com/UltronGlow/UltronGlow-Origin/ethclient"
+	"github.com/UltronGlow/UltronGlow-Origin/node"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+var (
+	testKey, _  = crypto.HexToECDSA("b71c71a67e1177ad4e901695e1b4b9ee17ae16c6668d313eac2f96dbcda3f291")
+	testAddr    = crypto.PubkeyToAddress(testKey.PublicKey)
+	testBalance = big.NewInt(2e18)
+)
+
+func newTestBackend(t *testing.T) *node.Node {
+	config := *params.AllAlienProtocolChanges
+	alienConfig := *config.Alien
+	alienConfig.SelfVoteSigners = []common.UnprefixedAddress{common.UnprefixedAddress(testAddr)}
+	config.Alien = &alienConfig
+
+	genesis := &core.Genesis{
+		Config:    &config,
+		Alloc:     core.GenesisAlloc{testAddr: {Balance: testBalance}},
+		ExtraData: make([]byte, 32+65),
+		GasLimit:  params.GenesisGasLimit,
+	}
+	n, err := node.New(&node.Config{})
+	if err != nil {
+		t.Fatalf("can't create new node: %v", err)
+	}
+	if _, err := eth.New(n, &ethconfig.Config{Genesis: genesis}); err != nil {
+		t.Fatalf("can't create new utg service: %v", err)
+	}
+	if err := n.Start(); err != nil {
+		t.Fatalf("can't start test node: %v", err)
+	}
+	return n
+}
+
+func TestAlienClient(t *testing.T) {
+	backend := newTestBackend(t)
+	rpcClient, _ := backend.Attach()
+	defer backend.Close()
+	defer rpcClient.Close()
+
+	client := NewClient(rpcClient)
+	ctx := context.Background()
+
+	snap, err := client.SnapshotAtNumber(ctx, 0)
+	if err != nil {
+		t.Fatalf("SnapshotAtNumber: %v", err)
+	}
+	if snap.Number != 0 {
+		t.Errorf("snapshot number mismatch: have %d, want 0", snap.Number)
+	}
+	if _, ok := snap.Tally[testAddr]; !ok {
+		t.Errorf("genesis signer %v missing from tally", testAddr)
+	}
+	latest, err := client.Snapshot(ctx, nil)
+	if err != nil {
+		t.Fatalf("Snapshot: %v", err)
+	}
+	if latest.Hash != snap.Hash {
+		t.Errorf("latest snapshot hash mismatch: have %x, want %x", latest.Hash, snap.Hash)
+	}
+	if _, err := client.SnapshotAtHash(ctx, snap.Hash); err != nil {
+		t.Errorf("SnapshotAtHash: %v", err)
+	}
+	if _, err := client.SnapshotSignerAtNumber(ctx, 0); err != nil {
+		t.Errorf("SnapshotSignerAtNumber: %v", err)
+	}
+	if _, err := client.SRTBalAtNumber(ctx, 0); err != nil {
+		t.Errorf("SRTBalAtNumber: %v", err)
+	}
+	if _, err := client.SPledgeAtNumber(ctx, 0); err != nil {
+		t.Errorf("SPledgeAtNumber: %v", err)
+	}
+	if _, err := client.SnapshotAtNumber(ctx, 1); err == nil {
+		t.Errorf("expected error for unknown block")
+	}
+	supply, err := client.Supply(ctx, big.NewInt(0))
+	if err != nil {
+		t.Fatalf("Supply: %v", err)
+	}
+	if supply.Genesis == nil || supply.Genesis.Cmp(testBalance) != 0 {
+		t.Errorf("genesis allocation mismatch: have %v, want %v", supply.Genesis, testBalance)
+	}
+	if supply.Circulating.Cmp(testBalance) != 0 {
+		t.Errorf("circulating supply mismatch: have %v, want %v", supply.Circulating, testBalance)
+	}
+	check, err := client.VerifySupply(ctx, nil)
+	if err != nil {
+		t.Fatalf("VerifySupply: %v", err)
+	}
+	if !check.Consistent {
+		t.Errorf("supply inconsistent: tracked %v, state %v", check.Balances, check.StateBalances)
+	}
+	offers, err := client.FindStorageOffers(ctx, &alien.StorageOfferCriteria{SortBy: "bandwidth"})
+	if err != nil {
+		t.Fatalf("FindStorageOffers: %v", err)
+	}
+	if offers.Total != 0 || len(offers.Offers) != 0 {
+		t.Errorf("unexpected storage offers: %d", offers.Total)
+	}
+	if _, err := client.FindStorageOffers(ctx, &alien.StorageOfferCriteria{SortBy: "size"}); err == nil {
+		t.Errorf("expected error for unknown sort")
+	}
+	if _, err := client.FindStorageOffers(ctx, &alien.StorageOfferCriteria{Capacity: big.NewInt(1)}); err == nil {
+		t.Errorf("expected error for capacity below minimum rent space")
+	}
+	pools, err := client.ListStoragePools(ctx, "verification", 0)
+	if err != nil {
+		t.Fatalf("ListStoragePools: %v", err)
+	}
+	if pools.Total != 0 || len(pools.Pools) != 0 {
+		t.Errorf("unexpected storage pools: %d", pools.Total)
+	}
+	if _, err := client.ListStoragePools(ctx, "size", 0); err == nil {
+		t.Errorf("expected error for unknown sort")
+	}
+	if _, err := client.StoragePool(ctx, common.HexToHash("0x01")); err == nil {
+		t.Errorf("expected error for unknown storage pool")
+	}
+	rotations, err := client.SignerRotations(ctx)
+	if err != nil {
+		t.Fatalf("SignerRotations: %v", err)
+	}
+	if len(rotations) != 0 {
+		t.Errorf("unexpected signer rotations: %d", len(rotations))
+	}
+	sideChains, err := client.ListSideChains(ctx)
+	if err != nil {
+		t.Fatalf("ListSideChains: %v", err)
+	}
+	if len(sideChains.SideChains) != 0 {
+		t.Errorf("unexpected side chains: %d", len(sideChains.SideChains))
+	}
+	if _, err := client.SideChain(ctx, common.HexToHash("0x01")); err == nil {
+		t.Errorf("expected error for unknown side chain")
+	}
+	changes, err := client.PendingConfigChanges(ctx)
+	if err != nil {
+		t.Fatalf("PendingConfigChanges: %v", err)
+	}
+	if len(changes) != 0 {
+		t.Errorf("unexpected pending config changes: %d", len(changes))
+	}
+	if _, err := client.Alerts(ctx); err == nil {
+		t.Errorf("expected error without operator watchdog")
+	}
+}
+
+func TestCustomTxBuilders(t *testing.T) {
+	opts := &TxOpts{Key: testKey, ChainID: big.NewInt(1337), Nonce: 3}
+	miner := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	hash := common.HexToHash("0x01")
+
+	tests := []struct {
+		build func() (*types.Transaction, error)
+		data  []string
+	}{
+		{
+			func() (*types.Transaction, error) {
+				return NewBindTx(opts, miner, RevenueTypeStorage, common.Address{}, common.Address{})
+			},
+			[]string{"UTG", "1", "Bind", miner.String(), "1", "", ""},
+		},
+		{
+			func() (*types.Transaction, error) {
+				return NewCandidateEntrustTx(opts, miner, big.NewInt(255))
+			},
+			[]string{"UTG", "1", "CandEntrust", miner.String(), "0xff"},
+		},
+		{
+			func() (*types.Transaction, error) {
+				return NewRevenueSplitTx(opts, miner, RevenueTypePoS, []RevenueShare{{Address: miner, Share: 2500}})
+			},
+			[]string{"UTG", "1", "BindSplit", miner.String(), "0", miner.String(), "2500"},
+		},
+		{
+			func() (*types.Transaction, error) {
+				return NewAutoCompoundTx(opts, DelegationStorage, miner, big.NewInt(7))
+			},
+			[]string{"UTG", "1", "AutoCompound", "SN", miner.String(), "7"},
+		},
+		{
+			func() (*types.Transaction, error) {
+				return NewBridgeLockTx(opts, hash, miner, big.NewInt(9))
+			},
+			[]string{"UTG", "1", "BridgeLock", hash.Hex(), miner.String(), "9"},
+		},
+		{
+			func() (*types.Transaction, error) {
+				return NewRedelegateTx(opts, DelegationPoS, miner.String(), hash, DelegationPool, hash.Hex())
+			},
+			[]string{"UTG", "1", "Redelegate", "PoS", miner.String(), hash.Hex(), "SP", hash.Hex()},
+		},
+		{
+			func() (*types.Transaction, error) {
+				return NewLeaseRequestTx(opts, miner, big.NewInt(1024), 30, big.NewInt(7))
+			},
+			[]string{"UTG", "1", "stRent", miner.String(), "1024", "30", "7"},
+		},
+		{
+			func() (*types.Transaction, error) { return NewLeaseChallengeTx(opts, miner, hash, 120) },
+			[]string{"UTG", "1", "stChallenge", miner.String(), hash.Hex(), "120"},
+		},
+		{
+			func() (*types.Transaction, error) { return NewStoragePriceTx(opts, miner, big.NewInt(500)) },
+			[]string{"UTG", "1", "chPrice", miner.String(), "500"},
+		},
+		{
+			func() (*types.Transaction, error) { return NewStorageSetPoolTx(opts, miner, hash) },
+			[]string{"UTG", "1", "setsp", miner.String(), hash.Hex()},
+		},
+		{
+			func() (*types.Transaction, error) {
+				return NewStoragePledgeTx(opts, &StoragePledge{
+					Pledge: miner, Price: big.NewInt(500), Capacity: big.NewInt(1 << 40),
+					StartPackage: "1", PackageNonce: 2, PackageBlock: hash, VerifyData: "a,b",
+					Bandwidth: 100, PledgeRate: 50, EntrustRate: 60,
+				})
+			},
+			[]string{"UTG", "1", "stReq", miner.String(), "500", "1099511627776", "1", "2", hash.Hex(), "a,b", "100", "50", "60"},
+		},
+		{
+			func() (*types.Transaction, error) { return NewPoolEntrustExitTx(opts, hash, hash) },
+			[]string{"UTG", "1", "spwtexit", hash.Hex(), hash.Hex()},
+		},
+	}
+	signer := types.NewEIP155Signer(opts.ChainID)
+	for i, tt := range tests {
+		tx, err := tt.build()
+		if err != nil {
+			t.Fatalf("test %d: build failed: %v", i, err)
+		}
+		if have := strings.Split(string(tx.Data()), ":"); strings.Join(have, "|") != strings.Join(tt.data, "|") {
+			t.Errorf("test %d: payload mismatch: have %q, want %q", i, have, tt.data)
+		}
+		from, err := types.Sender(signer, tx)
+		if err != nil || from != testAddr {
+			t.Errorf("test %d: sender mismatch: have %v (%v), want %v", i, from, err, testAddr)
+		}
+		if tx.Nonce() != opts.Nonce || *tx.To() != testAddr || tx.Gas() != DefaultCustomTxGas {
+			t.Errorf("test %d: unexpected transaction fields", i)
+		}
+	}
+	if _, err := NewStoragePledgeTx(opts, &StoragePledge{Pledge: miner}); err == nil {
+		t.Errorf("expected error for incomplete storage pledge")
+	}
+	if _, err := NewPoolExitTx(&TxOpts{}, hash); err != errNoKey {
+		t.Errorf("expected errNoKey, have %v", err)
+	}
+	// Lease transfers are co-signed by the storage pledge manager.
+	managerKey, _ := crypto.GenerateKey()
+	manager := crypto.PubkeyToAddress(managerKey.PublicKey)
+	tx, err := NewLeaseTransferTx(opts, miner, hash, manager)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if have, want := string(tx.Data()), "UTG:1:stTransfer:"+miner.String()+":"+hash.Hex()+":"+manager.String(); have != want {
+		t.Errorf("payload mismatch: have %q, want %q", have, want)
+	}
+	cosigned, err := types.SignTx(tx, types.LatestSignerForChainID(opts.ChainID), managerKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signers := cosigned.AllSigners(); len(signers) != 2 || signers[0] != testAddr || signers[1] != manager {
+		t.Errorf("signers mismatch: %v", signers)
+	}
+}
+
+func TestCustomTxBuildersOnNode(t *testing.T) {
+	backend := newTestBackend(t)
+	rpcClient, _ := backend.Attach()
+	defer backend.Close()
+	defer rpcClient.Close()
+
+	client := ethclient.NewClient(rpcClient)
+	ctx := context.Background()
+	chainID, err := client.ChainID(ctx)
+	if err != nil {
+		t.Fatalf("ChainID: %v", err)
+	}
+	opts := &TxOpts{Key: testKey, ChainID: chainID, GasPrice: big.NewInt(params.GWei)}
+	miner := common.HexToAddress("0x1000000000000000000000000000000000000001")
+	hash := common.HexToHash("0x01")
+
+	builders := []func() (*types.Transaction, error){
+		func() (*types.Transaction, error) {
+			return NewBindTx(opts, miner, RevenueTypeStorage, common.Address{}, common.Address{})
+		},
+		func() (*types.Transaction, error) { return NewCandidateEntrustTx(opts, miner, big.NewInt(1)) },
+		func() (*types.Transaction, error) {
+			return NewStoragePledgeTx(opts, &StoragePledge{
+				Pledge: miner, Price: big.NewInt(500), Capacity: big.NewInt(1 << 40),
+				StartPackage: "1", PackageNonce: 2, PackageBlock: hash, VerifyData: "a,b",
+				Bandwidth: 100, PledgeRate: 50, EntrustRate: 60,
+			})
+		},
+		func() (*types.Transaction, error) { return NewStoragePriceTx(opts, miner, big.NewInt(500)) },
+		func() (*types.Transaction, error) {
+			return NewLeaseRequestTx(opts, miner, big.NewInt(1024), 30, big.NewInt(7))
+		},
+	}
+	for i, build := range builders {
+		opts.Nonce = uint64(i)
+		tx, err := build()
+		if err != nil {
+			t.Fatalf("builder %d: %v", i, err)
+		}
+		if err := client.SendTransaction(ctx, tx); err != nil {
+			t.Fatalf("builder %d: node rejected transaction: %v", i, err)
+		}
+		pending, isPending, err := client.TransactionByHash(ctx, tx.Hash())
+		if err != nil || !isPending {
+			t.Fatalf("builder %d: transaction not pending: %v", i, err)
+		}
+		if string(pending.Data()) != string(tx.Data()) {
+			t.Errorf("builder %d: payload mismatch: have %q, want %q", i, pending.Data(), tx.Data())
+		}
+	}
+	nonce, err := client.PendingNonceAt(ctx, testAddr)
+	if err != nil {
+		t.Fatalf("PendingNonceAt: %v", err)
+	}
+	if nonce != uint64(len(builders)) {
+		t.Errorf("pending nonce mismatch: have %d, want %d", nonce, len(builders))
+	}
+}
@@ -0,0 +1,365 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package alienclient
+
+import (
+	"crypto/ecdsa"
+	"errors"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/common/hexutil"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
+	"github.com/UltronGlow/UltronGlow-Origin/crypto"
+)
+
+// Custom transaction prefixes and categories understood by the alien engine.
+// They mirror the unexported constants of the consensus/alien package.
+const (
+	utgPrefix  = "UTG"
+	utgVersion = "1"
+
+	categoryBind            = "Bind"
+	categoryUnbind          = "Unbind"
+	categoryBindSplit       = "BindSplit"
+	categoryCandReq         = "CandReq"
+	categoryCandExit        = "CandExit"
+	categoryCandEntrust     = "CandEntrust"
+	categoryCandEntrustExit = "CandETExit"
+	categoryRedelegate      = "Redelegate"
+	categoryAutoCompound    = "AutoCompound"
+	categorySignerRotate    = "SignerRotate"
+	categoryBridgeLock      = "BridgeLock"
+	categoryBridgeBurn      = "BridgeBurn"
+	categoryStorageDeclare  = "stReq"
+	categoryStorageExit     = "stExit"
+	categoryStoragePrice    = "chPrice"
+	categoryStorageBw       = "chbw"
+	categoryStorageRatio    = "stwtreward"
+	categoryStorageSetPool  = "setsp"
+	categoryRentRequest     = "stRent"
+	categoryRentReNew       = "stReNew"
+	categoryRentRescind     = "stRescind"
+	categoryRentAutoReNew   = "stAutoReNew"
+	categoryRentTransfer    = "stTransfer"
+	categoryRentChallenge   = "stChallenge"
+	categoryChallengeProof  = "stChallengeProof"
+	categoryStorageEntrust  = "stwtpg"
+	categoryStorageEtExit   = "wtpgexit"
+	categoryPoolEntrust     = "spwtpg"
+	categoryPoolEntrustExit = "spwtexit"
+	categoryPoolExit        = "spexit"
+)
+
+// Revenue types accepted by NewBindTx and NewUnbindTx.
+const (
+	RevenueTypePoS     = 0
+	RevenueTypeStorage = 1
+)
+
+// Delegation kinds accepted by NewRedelegateTx and NewAutoCompoundTx. PoS
+// candidates and storage nodes are given by address, pools by their hash.
+const (
+	DelegationPoS     = "PoS"
+	DelegationStorage = "SN"
+	DelegationPool    = "SP"
+)
+
+// DefaultCustomTxGas is the gas limit used when TxOpts.Gas is zero. It covers
+// the intrinsic cost of every payload built by this package.
+const DefaultCustomTxGas = 60000
+
+var errNoKey = errors.New("alienclient: no signing key")
+
+// TxOpts is the collection of parameters needed to create a signed custom
+// transaction. The transaction is sent to the signer's own address without
+// value; the payload alone carries the operation.
+type TxOpts struct {
+	Key      *ecdsa.PrivateKey // Private key used to sign the transaction
+	ChainID  *big.Int          // Chain ID for EIP155 replay protection
+	Nonce    uint64            // Account nonce of the signer
+	GasPrice *big.Int          // Gas price to pay
+	Gas      uint64            // Gas limit, DefaultCustomTxGas if zero
+}
+
+// NewCustomTx signs a custom transaction whose payload is the UTG prefix,
+// version and category followed by the given fields, colon separated.
+func NewCustomTx(opts *TxOpts, category string, fields ...string) (*types.Transaction, error) {
+	return newCustomTx(opts, false, category, fields...)
+}
+
+// NewMultiSignerCustomTx is like NewCustomTx, but creates a multi-signer
+// transaction for operations that need the consent of several accounts.
+// opts.Key initiates the transaction and pays for it.
+func NewMultiSignerCustomTx(opts *TxOpts, category string, fields ...string) (*types.Transaction, error) {
+	return newCustomTx(opts, true, category, fields...)
+}
+
+func newCustomTx(opts *TxOpts, multiSigner bool, category string, fields ...string) (*types.Transaction, error) {
+	if opts == nil || opts.Key == nil {
+		return nil, errNoKey
+	}
+	data := strings.Join(append([]string{utgPrefix, utgVersion, category}, fields...), ":")
+	gas := opts.Gas
+	if gas == 0 {
+		gas = DefaultCustomTxGas
+	}
+	gasPrice := opts.GasPrice
+	if gasPrice == nil {
+		gasPrice = new(big.Int)
+	}
+	from := crypto.PubkeyToAddress(opts.Key.PublicKey)
+	if multiSigner {
+		tx := types.NewMultiSignerTransaction(opts.ChainID, opts.Nonce, from, new(big.Int), gas, gasPrice, []byte(data))
+		return types.SignTx(tx, types.LatestSignerForChainID(opts.ChainID), opts.Key)
+	}
+	tx := types.NewTransaction(opts.Nonce, from, new(big.Int), gas, gasPrice, []byte(data))
+	return types.SignTx(tx, types.NewEIP155Signer(opts.ChainID), opts.Key)
+}
+
+// NewBindTx binds the revenue of a PoS (RevenueTypePoS) or storage
+// (RevenueTypeStorage) device to the signer. contract and multiSign may be the
+// zero address, in which case they are left empty in the payload.
+func NewBindTx(opts *TxOpts, device common.Address, revenueType uint64, contract, multiSign common.Address) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryBind, device.String(), strconv.FormatUint(revenueType, 10), optionalAddress(contract), optionalAddress(multiSign))
+}
+
+// NewUnbindTx releases the revenue binding of the device.
+func NewUnbindTx(opts *TxOpts, device common.Address, revenueType uint64) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryUnbind, device.String(), strconv.FormatUint(revenueType, 10), "", "")
+}
+
+// RevenueShare pays Share basis points of the revenue of a binding to Address.
+type RevenueShare struct {
+	Address common.Address
+	Share   uint64
+}
+
+// NewRevenueSplitTx replaces the beneficiaries of the revenue binding of the
+// device. The shares must total less than 10000 basis points, the rest stays
+// with the revenue address. No shares pay everything to the revenue address.
+func NewRevenueSplitTx(opts *TxOpts, device common.Address, revenueType uint64, shares []RevenueShare) (*types.Transaction, error) {
+	fields := []string{device.String(), strconv.FormatUint(revenueType, 10)}
+	for _, share := range shares {
+		fields = append(fields, share.Address.String(), strconv.FormatUint(share.Share, 10))
+	}
+	return NewCustomTx(opts, categoryBindSplit, fields...)
+}
+
+// NewCandidatePledgeTx pledges the configured candidate deposit for miner,
+// making the signer its manager.
+func NewCandidatePledgeTx(opts *TxOpts, miner common.Address) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryCandReq, miner.String())
+}
+
+// NewCandidateExitTx withdraws the candidate miner and all its delegations.
+func NewCandidateExitTx(opts *TxOpts, miner common.Address) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryCandExit, miner.String())
+}
+
+// NewCandidateEntrustTx delegates amount (in wei) to the PoS candidate miner.
+func NewCandidateEntrustTx(opts *TxOpts, miner common.Address, amount *big.Int) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryCandEntrust, miner.String(), hexutil.EncodeBig(amount))
+}
+
+// NewCandidateEntrustExitTx withdraws the delegation identified by hash from miner.
+func NewCandidateEntrustExitTx(opts *TxOpts, miner common.Address, hash common.Hash) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryCandEntrustExit, miner.String(), hash.Hex())
+}
+
+// NewRedelegateTx moves the delegation identified by position from source to
+// target without unbonding. The delegation keeps its original height and can
+// not be moved again for seven days.
+func NewRedelegateTx(opts *TxOpts, sourceType, source string, position common.Hash, targetType, target string) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryRedelegate, sourceType, source, position.Hex(), targetType, target)
+}
+
+// NewAutoCompoundTx asks the engine to delegate the rewards released to the
+// signer to the PoS candidate (DelegationPoS) or inactive storage node
+// (DelegationStorage) target whenever a payout reaches threshold (in wei).
+func NewAutoCompoundTx(opts *TxOpts, targetType string, target common.Address, threshold *big.Int) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryAutoCompound, targetType, target.String(), threshold.String())
+}
+
+// NewAutoCompoundCancelTx stops the compounding of the released rewards of the
+// signer. target must be the one of the instruction.
+func NewAutoCompoundCancelTx(opts *TxOpts, targetType string, target common.Address) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryAutoCompound, targetType, target.String(), "0")
+}
+
+// NewSignerRotateTx moves the PoS candidate signer, with its pledge,
+// delegations and tally, to newSigner from the start of the loop after the
+// next one. It returns a multi-signer transaction signed by opts.Key, which
+// the current signer and the manager of the candidate must both sign before
+// it is sent, see Transaction.CombineSignatures.
+func NewSignerRotateTx(opts *TxOpts, signer common.Address, newSigner common.Address) (*types.Transaction, error) {
+	return NewMultiSignerCustomTx(opts, categorySignerRotate, signer.String(), newSigner.String())
+}
+
+// NewBridgeLockTx locks amount (in wei) of the signer on the main chain and
+// has it minted to recipient on the side chain scHash, once the signers of
+// the side chain confirmed the lock.
+func NewBridgeLockTx(opts *TxOpts, scHash common.Hash, recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryBridgeLock, scHash.Hex(), recipient.String(), amount.String())
+}
+
+// NewBridgeBurnTx burns amount (in wei) of the signer on a side chain and has
+// it unlocked to recipient on the main chain, once the side chain block is
+// confirmed there. It is sent to the side chain.
+func NewBridgeBurnTx(opts *TxOpts, recipient common.Address, amount *big.Int) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryBridgeBurn, recipient.String(), amount.String())
+}
+
+// StoragePledge describes the capacity a storage node declares with
+// NewStoragePledgeTx. The package fields and VerifyData are the proof of the
+// sealed capacity produced by the storage software.
+type StoragePledge struct {
+	Pledge       common.Address // Address of the storage node
+	Price        *big.Int       // Rent asked per TB per day, in wei
+	Capacity     *big.Int       // Declared capacity in bytes
+	StartPackage string         // First sealed package
+	PackageNonce uint64         // Nonce of the sealed packages
+	PackageBlock common.Hash    // Block whose hash seeded the packages
+	VerifyData   string         // Comma separated proof of the sealed capacity
+	Bandwidth    uint64         // Bandwidth in Mbps
+	PledgeRate   uint64         // Percentage of the pledge paid by the signer
+	EntrustRate  uint64         // Percentage of the revenue kept for delegators
+}
+
+// NewStoragePledgeTx declares a storage node and pays its pledge. The signer
+// becomes the manager of the node.
+func NewStoragePledgeTx(opts *TxOpts, pledge *StoragePledge) (*types.Transaction, error) {
+	if pledge == nil || pledge.Price == nil || pledge.Capacity == nil {
+		return nil, errors.New("alienclient: incomplete storage pledge")
+	}
+	return NewCustomTx(opts, categoryStorageDeclare, pledge.Pledge.String(), pledge.Price.String(), pledge.Capacity.String(),
+		pledge.StartPackage, strconv.FormatUint(pledge.PackageNonce, 10), pledge.PackageBlock.Hex(), pledge.VerifyData,
+		strconv.FormatUint(pledge.Bandwidth, 10), strconv.FormatUint(pledge.PledgeRate, 10), strconv.FormatUint(pledge.EntrustRate, 10))
+}
+
+// NewStoragePriceTx changes the rent asked by the storage pledge, in wei per
+// TB per day. It must be sent by the manager of the pledge.
+func NewStoragePriceTx(opts *TxOpts, pledge common.Address, price *big.Int) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryStoragePrice, pledge.String(), price.String())
+}
+
+// NewStorageBandwidthTx changes the declared bandwidth (in Mbps) of the
+// storage pledge. It must be sent by the manager of the pledge.
+func NewStorageBandwidthTx(opts *TxOpts, pledge common.Address, bandwidth uint64) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryStorageBw, pledge.String(), strconv.FormatUint(bandwidth, 10))
+}
+
+// NewStorageRewardRatioTx sets the percentage of the storage pledge revenue
+// kept for its delegators.
+func NewStorageRewardRatioTx(opts *TxOpts, pledge common.Address, ratio uint64) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryStorageRatio, pledge.String(), strconv.FormatUint(ratio, 10))
+}
+
+// NewStorageSetPoolTx joins the storage pledge to the storage pool spHash.
+func NewStorageSetPoolTx(opts *TxOpts, pledge common.Address, spHash common.Hash) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryStorageSetPool, pledge.String(), spHash.Hex())
+}
+
+// NewStorageExitTx withdraws the storage pledge of the given address.
+func NewStorageExitTx(opts *TxOpts, pledge common.Address) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryStorageExit, pledge.String())
+}
+
+// NewStorageEntrustTx delegates amount (in wei) to the storage pledge.
+func NewStorageEntrustTx(opts *TxOpts, pledge common.Address, amount *big.Int) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryStorageEntrust, pledge.String(), amount.String())
+}
+
+// NewStorageEntrustExitTx withdraws the storage delegation identified by hash.
+func NewStorageEntrustExitTx(opts *TxOpts, pledge common.Address, hash common.Hash) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryStorageEtExit, pledge.String(), hash.Hex())
+}
+
+// NewLeaseRequestTx requests capacity bytes from the storage pledge for
+// duration days at price per TB per day.
+func NewLeaseRequestTx(opts *TxOpts, pledge common.Address, capacity *big.Int, duration uint64, price *big.Int) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryRentRequest, pledge.String(), capacity.String(), strconv.FormatUint(duration, 10), price.String())
+}
+
+// NewLeaseRenewalTx requests to extend the lease identified by hash by duration days.
+func NewLeaseRenewalTx(opts *TxOpts, pledge common.Address, hash common.Hash, duration uint64) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryRentReNew, pledge.String(), hash.Hex(), strconv.FormatUint(duration, 10))
+}
+
+// NewLeaseAutoRenewTx asks the engine to renew the lease identified by hash
+// by duration days whenever its renewal window opens, as long as the unit
+// price is at most maxPrice. The deposit (in wei) is paid now and spent on
+// the renewals, whose rent is burnt from the SRT of the tenant up to srtAllowance.
+func NewLeaseAutoRenewTx(opts *TxOpts, pledge common.Address, hash common.Hash, duration uint64, maxPrice *big.Int, deposit *big.Int, srtAllowance *big.Int) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryRentAutoReNew, pledge.String(), hash.Hex(), strconv.FormatUint(duration, 10), maxPrice.String(), deposit.String(), srtAllowance.String())
+}
+
+// NewLeaseAutoRenewCancelTx cancels the automatic renewal of the lease
+// identified by hash and returns the deposit left.
+func NewLeaseAutoRenewCancelTx(opts *TxOpts, pledge common.Address, hash common.Hash) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryRentAutoReNew, pledge.String(), hash.Hex(), "0")
+}
+
+// NewLeaseTransferTx hands the lease identified by hash over to newOwner. It
+// returns a multi-signer transaction signed by opts.Key, which the tenant, the
+// depositor and the manager of the storage pledge must all sign before it is
+// sent, see Transaction.CombineSignatures.
+func NewLeaseTransferTx(opts *TxOpts, pledge common.Address, hash common.Hash, newOwner common.Address) (*types.Transaction, error) {
+	return NewMultiSignerCustomTx(opts, categoryRentTransfer, pledge.String(), hash.Hex(), newOwner.String())
+}
+
+// NewLeaseChallengeTx asks the storage pledge to prove that it still holds
+// the data of the lease identified by hash. The proof is sampled with the hash
+// of block seed, which must be after the block including the challenge.
+func NewLeaseChallengeTx(opts *TxOpts, pledge common.Address, hash common.Hash, seed uint64) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryRentChallenge, pledge.String(), hash.Hex(), strconv.FormatUint(seed, 10))
+}
+
+// NewLeaseChallengeProofTx answers the open challenge on the lease identified
+// by hash with a storage proof in the format of the daily storage proof.
+func NewLeaseChallengeProofTx(opts *TxOpts, pledge common.Address, hash common.Hash, proof string) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryChallengeProof, pledge.String(), hash.Hex(), proof)
+}
+
+// NewLeaseRescindTx cancels the lease identified by hash.
+func NewLeaseRescindTx(opts *TxOpts, pledge common.Address, hash common.Hash) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryRentRescind, pledge.String(), hash.Hex())
+}
+
+// NewPoolEntrustTx delegates amount (in wei) to the storage pool identified by spHash.
+func NewPoolEntrustTx(opts *TxOpts, spHash common.Hash, amount *big.Int) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryPoolEntrust, spHash.Hex(), amount.String())
+}
+
+// NewPoolEntrustExitTx withdraws the pool delegation etHash from the pool spHash.
+func NewPoolEntrustExitTx(opts *TxOpts, spHash common.Hash, etHash common.Hash) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryPoolEntrustExit, spHash.Hex(), etHash.Hex())
+}
+
+// NewPoolExitTx closes the storage pool identified by spHash.
+func NewPoolExitTx(opts *TxOpts, spHash common.Hash) (*types.Transaction, error) {
+	return NewCustomTx(opts, categoryPoolExit, spHash.Hex())
+}
+
+func optionalAddress(addr common.Address) string {
+	if addr == (common.Address{}) {
+		return ""
+	}
+	return addr.String()
+}
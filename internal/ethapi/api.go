@@ -46,6 +46,7 @@ import (
 	"github.com/UltronGlow/UltronGlow-Origin/params"
 	"github.com/UltronGlow/UltronGlow-Origin/rlp"
 	"github.com/UltronGlow/UltronGlow-Origin/rpc"
+	"github.com/UltronGlow/UltronGlow-Origin/signer/customtx"
 	"github.com/tyler-smith/go-bip39"
 )
 
@@ -438,7 +439,40 @@ func (s *PrivateAccountAPI) SignTransaction(ctx context.Context, args Transactio
 	if err != nil {
 		return nil, err
 	}
-	return &SignTransactionResult{data, signed}, nil
+	return newSignTransactionResult(data, signed), nil
+}
+
+// CoSignTransaction adds the signature of from to a partially signed
+// multi-signer transaction, as produced by eth_getMultiSigStatus or an earlier
+// co-signer, and returns the updated encoding. The transaction content is not
+// changed, so every owner signs the same hash. The key used to calculate the
+// signature is decrypted with the given password.
+func (s *PrivateAccountAPI) CoSignTransaction(ctx context.Context, encoded hexutil.Bytes, from common.Address, passwd string) (hexutil.Bytes, error) {
+	ptx := new(types.PartiallySignedTx)
+	if err := ptx.UnmarshalBinary(encoded); err != nil {
+		return nil, err
+	}
+	if chainID := s.b.ChainConfig().ChainID; chainID.Cmp(ptx.Tx.ChainId()) != 0 {
+		return nil, fmt.Errorf("chain id mismatch: have %d, want %d", ptx.Tx.ChainId(), chainID)
+	}
+	if !ptx.Consistent() {
+		return nil, fmt.Errorf("multi-signature address mismatch: have %s, want %s", ptx.MultiSig, ptx.Tx.MultiSig())
+	}
+	if err := checkTxFee(ptx.Tx.GasPrice(), ptx.Tx.Gas(), s.b.RPCTxFeeCap()); err != nil {
+		return nil, err
+	}
+	account := accounts.Account{Address: from}
+	wallet, err := s.am.Find(account)
+	if err != nil {
+		return nil, err
+	}
+	signed, err := wallet.SignTxWithPassphrase(account, passwd, ptx.Tx, s.b.ChainConfig().ChainID)
+	if err != nil {
+		log.Warn("Failed transaction co-sign attempt", "from", from, "to", ptx.Tx.To(), "multisig", ptx.MultiSig, "err", err)
+		return nil, err
+	}
+	ptx.Tx = signed
+	return ptx.MarshalBinary()
 }
 
 // Sign calculates an utg ECDSA signature for:
@@ -1232,6 +1266,7 @@ type RPCTransaction struct {
 	Accesses         *types.AccessList `json:"accessList,omitempty"`
 	Signatures       *types.SignerList `json:"SignatureList,omitempty"`
 	Signers          []common.Address  `json:"signerList,omitempty"`
+	MultiSig         *common.Address   `json:"multiSig,omitempty"`
 	ChainID          *hexutil.Big      `json:"chainId,omitempty"`
 	V                *hexutil.Big      `json:"v"`
 	R                *hexutil.Big      `json:"r"`
@@ -1283,6 +1318,7 @@ func newRPCTransaction(tx *types.Transaction, blockHash common.Hash, blockNumber
 		sl := tx.SignerList()
 		result.Signatures = &sl
 		result.Signers = tx.AllSigners()
+		result.MultiSig = tx.MultiSig()
 		result.ChainID = (*hexutil.Big)(tx.ChainId())
 	case types.DynamicFeeTxType:
 		al := tx.AccessList()
@@ -1707,7 +1743,7 @@ func (s *PublicTransactionPoolAPI) FillTransaction(ctx context.Context, args Tra
 	if err != nil {
 		return nil, err
 	}
-	return &SignTransactionResult{data, tx}, nil
+	return newSignTransactionResult(data, tx), nil
 }
 
 // SendRawTransaction will add the signed transaction to the transaction pool.
@@ -1747,8 +1783,19 @@ func (s *PublicTransactionPoolAPI) Sign(addr common.Address, data hexutil.Bytes)
 
 // SignTransactionResult represents a RLP encoded signed transaction.
 type SignTransactionResult struct {
-	Raw hexutil.Bytes      `json:"raw"`
-	Tx  *types.Transaction `json:"tx"`
+	Raw    hexutil.Bytes      `json:"raw"`
+	Tx     *types.Transaction `json:"tx"`
+	Custom *customtx.Decoded  `json:"custom,omitempty"`
+}
+
+// newSignTransactionResult wraps a signed transaction, decoding its payload
+// if it carries an alien custom transaction.
+func newSignTransactionResult(raw hexutil.Bytes, tx *types.Transaction) *SignTransactionResult {
+	result := &SignTransactionResult{Raw: raw, Tx: tx}
+	if decoded, err := customtx.Decode(tx.Data()); err == nil {
+		result.Custom = decoded
+	}
+	return result
 }
 
 // SignTransaction will sign the given transaction with the from account.
@@ -1779,7 +1826,7 @@ func (s *PublicTransactionPoolAPI) SignTransaction(ctx context.Context, args Tra
 	if err != nil {
 		return nil, err
 	}
-	return &SignTransactionResult{data, tx}, nil
+	return newSignTransactionResult(data, tx), nil
 }
 
 // PendingTransactions returns the transactions that are in the transaction pool
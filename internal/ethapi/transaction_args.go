@@ -52,6 +52,9 @@ type TransactionArgs struct {
 	// For non-legacy transactions
 	AccessList *types.AccessList `json:"accessList,omitempty"`
 	ChainID    *hexutil.Big      `json:"chainId,omitempty"`
+
+	// For spends from a multi-signature account, paid and signed by From
+	MultiSig *common.Address `json:"multiSig,omitempty"`
 }
 
 // from retrieves the transaction sender address.
@@ -168,6 +171,10 @@ func (args *TransactionArgs) ToMessage(globalGasCap uint64, baseFee *big.Int) (t
 	}
 	// Set sender address or use zero address if none specified.
 	addr := args.from()
+	if args.MultiSig != nil {
+		// Calls are executed on behalf of the spending account.
+		addr = *args.MultiSig
+	}
 
 	// Set default gas & gas price if none were set
 	gas := globalGasCap
@@ -234,6 +241,26 @@ func (args *TransactionArgs) ToMessage(globalGasCap uint64, baseFee *big.Int) (t
 func (args *TransactionArgs) toTransaction() *types.Transaction {
 	var data types.TxData
 	switch {
+	case args.MultiSig != nil:
+		al := types.AccessList{}
+		if args.AccessList != nil {
+			al = *args.AccessList
+		}
+		gasPrice := (*big.Int)(args.GasPrice)
+		if gasPrice == nil {
+			gasPrice = (*big.Int)(args.MaxFeePerGas)
+		}
+		data = &types.MultiSignerTx{
+			To:         args.To,
+			ChainID:    (*big.Int)(args.ChainID),
+			Nonce:      uint64(*args.Nonce),
+			Gas:        uint64(*args.Gas),
+			GasPrice:   gasPrice,
+			Value:      (*big.Int)(args.Value),
+			Data:       args.data(),
+			AccessList: al,
+			MultiSig:   args.MultiSig,
+		}
 	case args.MaxFeePerGas != nil:
 		al := types.AccessList{}
 		if args.AccessList != nil {
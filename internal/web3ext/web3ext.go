@@ -233,6 +233,58 @@ web3._extend({
 			call: 'alien_getSnapshotRewardBalanceV1',
 			params: 2
 		}),
+		new web3._extend.Method({
+			name: 'getSupply',
+			call: 'alien_getSupply',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'verifySupply',
+			call: 'alien_verifySupply',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputBlockNumberFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'findStorageOffers',
+			call: 'alien_findStorageOffers',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'getStoragePool',
+			call: 'alien_getStoragePool',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'listStoragePools',
+			call: 'alien_listStoragePools',
+			params: 2
+		}),
+		new web3._extend.Method({
+			name: 'getSignerRotations',
+			call: 'alien_getSignerRotations',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getSideChain',
+			call: 'alien_getSideChain',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'listSideChains',
+			call: 'alien_listSideChains',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getPendingConfigChanges',
+			call: 'alien_getPendingConfigChanges',
+			params: 0
+		}),
+		new web3._extend.Method({
+			name: 'getAlerts',
+			call: 'alien_getAlerts',
+			params: 0
+		}),
 	]
 });
 `
@@ -607,6 +659,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [null, null]
 		}),
+		new web3._extend.Method({
+			name: 'traceConsensus',
+			call: 'debug_traceConsensus',
+			params: 2,
+			inputFormatter: [null, null]
+		}),
 		new web3._extend.Method({
 			name: 'traceTransaction',
 			call: 'debug_traceTransaction',
@@ -684,6 +742,22 @@ web3._extend({
 			params: 1,
 			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
 		}),
+		new web3._extend.Method({
+			name: 'getMultiSignature',
+			call: 'eth_getMultiSignature',
+			params: 1,
+			inputFormatter: [web3._extend.formatters.inputAddressFormatter]
+		}),
+		new web3._extend.Method({
+			name: 'getMultiSigStatus',
+			call: 'eth_getMultiSigStatus',
+			params: 1
+		}),
+		new web3._extend.Method({
+			name: 'submitPartiallySignedTransaction',
+			call: 'eth_submitPartiallySignedTransaction',
+			params: 1
+		}),
 		new web3._extend.Method({
 			name: 'signTransaction',
 			call: 'eth_signTransaction',
@@ -880,6 +954,12 @@ web3._extend({
 			params: 2,
 			inputFormatter: [web3._extend.formatters.inputTransactionFormatter, null]
 		}),
+		new web3._extend.Method({
+			name: 'coSignTransaction',
+			call: 'personal_coSignTransaction',
+			params: 3,
+			inputFormatter: [null, web3._extend.formatters.inputAddressFormatter, null]
+		}),
 		new web3._extend.Method({
 			name: 'unpair',
 			call: 'personal_unpair',
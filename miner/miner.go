@@ -52,6 +52,7 @@ type Config struct {
 	GasCeil    uint64         // Target gas ceiling for mined blocks.
 	GasPrice   *big.Int       // Minimum gas price for mining a transaction
 	Recommit   time.Duration  // The time interval for miner to re-create mining work.
+	ReserveGas uint64         // Gas reserved at the top of each block for the priority lane of the txpool
 	Noverify   bool           // Disable remote mining solution verification(only useful in ethash).
 }
 
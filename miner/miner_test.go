@@ -21,7 +21,9 @@ import (
 	"testing"
 	"time"
 
+	"github.com/UltronGlow/UltronGlow-Origin/accounts"
 	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus"
 	"github.com/UltronGlow/UltronGlow-Origin/consensus/alien"
 	"github.com/UltronGlow/UltronGlow-Origin/consensus/clique"
 	"github.com/UltronGlow/UltronGlow-Origin/core"
@@ -55,6 +57,10 @@ func (m *mockBackend) TxPool() *core.TxPool {
 	return m.txPool
 }
 
+func (m *mockBackend) AccountManager() *accounts.Manager {
+	return nil
+}
+
 type testBlockChain struct {
 	statedb       *state.StateDB
 	gasLimit      uint64
@@ -244,10 +250,12 @@ func createMiner(t *testing.T) (*Miner, *event.TypeMux) {
 		t.Fatalf("can't create new chain config: %v", err)
 	}
 	// Create consensus engine
-	if chainConfig.Alien != nil
-	    engine := alien.New(chainConfig.Alien, chainDB)
-	else
-	    engine := clique.New(chainConfig.Clique, chainDB)
+	var engine consensus.Engine
+	if chainConfig.Alien != nil {
+		engine = alien.New(chainConfig.Alien, chainDB)
+	} else {
+		engine = clique.New(chainConfig.Clique, chainDB)
+	}
 	// Create utg backend
 	bc, err := core.NewBlockChain(chainDB, nil, chainConfig, engine, vm.Config{}, nil, nil)
 	if err != nil {
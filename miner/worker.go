@@ -386,7 +386,7 @@ func (w *worker) newWorkLoop(recommit time.Duration) {
 		case <-timer.C:
 			// If mining is running resubmit a new work cycle periodically to pull in
 			// higher priced transactions. Disable this overhead for pending blocks.
-			if w.isRunning() && ((w.chainConfig.Alien == nil && w.chainConfig.Clique == nil) || w.chainConfig.Alien.Period > 0 || w.chainConfig.Clique.Period > 0) {
+			if w.isRunning() && ((w.chainConfig.Alien == nil && w.chainConfig.Clique == nil) || (w.chainConfig.Alien != nil && w.chainConfig.Alien.Period > 0) || (w.chainConfig.Clique != nil && w.chainConfig.Clique.Period > 0)) {
 				// Short circuit if no new transaction arrives.
 				if atomic.LoadInt32(&w.newTxs) == 0 {
 					timer.Reset(recommit)
@@ -929,6 +929,39 @@ func (w *worker) commitTransactions(txs *types.TransactionsByPriceAndNonce, coin
 	return false
 }
 
+// commitPriorityTransactions commits the transactions of the txpool priority
+// lane with the gas reserved for them, then hands the rest of the block gas to
+// the other transactions. The committed transactions are removed from pending,
+// the ones left over compete with the ordinary transactions.
+func (w *worker) commitPriorityTransactions(priority map[common.Address]types.Transactions, pending map[common.Address]types.Transactions, interrupt *int32) bool {
+	gasLimit := w.current.header.GasLimit
+	reserved := w.config.ReserveGas
+	if reserved > gasLimit {
+		reserved = gasLimit
+	}
+	w.current.gasPool = new(core.GasPool).AddGas(reserved)
+
+	txs := types.NewTransactionsByPriceAndNonce(w.current.signer, priority, w.current.header.BaseFee)
+	if w.commitTransactions(txs, w.coinbase, interrupt) {
+		return true
+	}
+	w.current.gasPool.AddGas(gasLimit - reserved)
+
+	for addr := range priority {
+		nonce := w.current.state.GetNonce(addr)
+		rest := pending[addr]
+		for len(rest) > 0 && rest[0].Nonce() < nonce {
+			rest = rest[1:]
+		}
+		if len(rest) == 0 {
+			delete(pending, addr)
+		} else {
+			pending[addr] = rest
+		}
+	}
+	return false
+}
+
 // commitNewWork generates several new sealing tasks based on the parent block.
 func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64) {
 	w.mu.RLock()
@@ -1031,13 +1064,23 @@ func (w *worker) commitNewWork(interrupt *int32, noempty bool, timestamp int64)
 		log.Error("Failed to fetch pending transactions", "err", err)
 		return
 	}
+	var priority map[common.Address]types.Transactions
+	if w.config.ReserveGas > 0 {
+		priority = w.eth.TxPool().Priority()
+	}
 	// Short circuit if there is no available pending transactions.
 	// But if we disable empty precommit already, ignore it. Since
 	// empty block is necessary to keep the liveness of the network.
-	if len(pending) == 0 && atomic.LoadUint32(&w.noempty) == 0 {
+	if len(pending) == 0 && len(priority) == 0 && atomic.LoadUint32(&w.noempty) == 0 {
 		w.updateSnapshot()
 		return
 	}
+	// Commit the priority lane first, within the gas reserved for it
+	if len(priority) > 0 {
+		if w.commitPriorityTransactions(priority, pending, interrupt) {
+			return
+		}
+	}
 	// Split the pending transactions into locals and remotes
 	localTxs, remoteTxs := make(map[common.Address]types.Transactions), pending
 	for _, account := range w.eth.TxPool().Locals() {
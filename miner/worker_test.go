@@ -28,6 +28,7 @@ import (
 	"github.com/UltronGlow/UltronGlow-Origin/consensus"
 	"github.com/UltronGlow/UltronGlow-Origin/consensus/clique"
 	"github.com/UltronGlow/UltronGlow-Origin/consensus/ethash"
+	"github.com/UltronGlow/UltronGlow-Origin/consensus/misc"
 	"github.com/UltronGlow/UltronGlow-Origin/core"
 	"github.com/UltronGlow/UltronGlow-Origin/core/rawdb"
 	"github.com/UltronGlow/UltronGlow-Origin/core/types"
@@ -75,14 +76,20 @@ var (
 func init() {
 	testTxPoolConfig = core.DefaultTxPoolConfig
 	testTxPoolConfig.Journal = ""
-	ethashChainConfig = params.TestChainConfig
-	cliqueChainConfig = params.TestChainConfig
+	// The workers run ethash and clique over typed transactions, while the
+	// test chain config comes with the alien engine and without Berlin.
+	ethashChainConfig = new(params.ChainConfig)
+	*ethashChainConfig = *params.TestChainConfig
+	ethashChainConfig.BerlinBlock = big.NewInt(0)
+	ethashChainConfig.Alien = nil
+	cliqueChainConfig = new(params.ChainConfig)
+	*cliqueChainConfig = *ethashChainConfig
 	cliqueChainConfig.Clique = &params.CliqueConfig{
 		Period: 10,
 		Epoch:  30000,
 	}
 
-	signer := types.LatestSigner(params.TestChainConfig)
+	signer := types.LatestSignerForChainID(params.TestChainConfig.ChainID)
 	tx1 := types.MustSignNewTx(testBankKey, signer, &types.AccessListTx{
 		ChainID:  params.TestChainConfig.ChainID,
 		Nonce:    0,
@@ -165,6 +172,9 @@ func newTestWorkerBackend(t *testing.T, chainConfig *params.ChainConfig, engine
 
 func (b *testWorkerBackend) BlockChain() *core.BlockChain { return b.chain }
 func (b *testWorkerBackend) TxPool() *core.TxPool         { return b.txPool }
+func (b *testWorkerBackend) AccountManager() *accounts.Manager {
+	return nil
+}
 
 func (b *testWorkerBackend) newRandomUncle() *types.Block {
 	var parent *types.Block
@@ -224,6 +234,7 @@ func testGenerateBlockAndImport(t *testing.T, isClique bool) {
 		engine = ethash.NewFaker()
 	}
 
+	chainConfig.BerlinBlock = big.NewInt(0)
 	chainConfig.LondonBlock = big.NewInt(0)
 	w, b := newTestWorker(t, chainConfig, engine, db, 0)
 	defer w.close()
@@ -520,3 +531,59 @@ func testAdjustInterval(t *testing.T, chainConfig *params.ChainConfig, engine co
 		t.Error("interval reset timeout")
 	}
 }
+
+// Tests that the priority lane of the txpool is committed within the gas
+// reserved for it, and that the other transactions get the rest of the block.
+func TestCommitPriorityTransactionsReserveGas(t *testing.T) {
+	var (
+		db          = rawdb.NewMemoryDatabase()
+		chainConfig = params.AllEthashProtocolChanges
+		engine      = ethash.NewFaker()
+	)
+	b := newTestWorkerBackend(t, chainConfig, engine, db, 0)
+	config := *testConfig
+	config.ReserveGas = 2 * params.TxGas
+	w := newWorker(&config, chainConfig, engine, b, new(event.TypeMux), nil, false)
+	defer w.close()
+
+	parent := b.chain.CurrentBlock()
+	header := &types.Header{
+		ParentHash: parent.Hash(),
+		Number:     new(big.Int).Add(parent.Number(), common.Big1),
+		GasLimit:   5 * params.TxGas,
+		Time:       parent.Time() + 1,
+		Coinbase:   testUserAddress,
+		Difficulty: big.NewInt(1),
+		BaseFee:    misc.CalcBaseFee(chainConfig, parent.Header()),
+	}
+	if err := w.makeCurrent(parent, header); err != nil {
+		t.Fatalf("failed to prepare the block: %v", err)
+	}
+	var txs types.Transactions
+	for nonce := uint64(0); nonce < 5; nonce++ {
+		tx, _ := types.SignTx(types.NewTransaction(nonce, testUserAddress, big.NewInt(1000), params.TxGas, big.NewInt(params.InitialBaseFee), nil), types.HomesteadSigner{}, testBankKey)
+		txs = append(txs, tx)
+	}
+	// Three leading transactions are in the priority lane, two fit in the reserve
+	priority := map[common.Address]types.Transactions{testBankAddress: txs[:3]}
+	pending := map[common.Address]types.Transactions{testBankAddress: txs}
+	if w.commitPriorityTransactions(priority, pending, nil) {
+		t.Fatalf("priority lane interrupted")
+	}
+	if len(w.current.txs) != 2 {
+		t.Fatalf("priority transactions committed: have %d, want 2", len(w.current.txs))
+	}
+	if gas := w.current.gasPool.Gas(); gas != 3*params.TxGas {
+		t.Fatalf("gas left for the other transactions: have %d, want %d", gas, 3*params.TxGas)
+	}
+	if rest := pending[testBankAddress]; len(rest) != 3 || rest[0].Nonce() != 2 {
+		t.Fatalf("committed transactions left in pending: %v", rest)
+	}
+	// The priority transaction left over competes with the others
+	if w.commitTransactions(types.NewTransactionsByPriceAndNonce(w.current.signer, pending, header.BaseFee), w.coinbase, nil) {
+		t.Fatalf("pending transactions interrupted")
+	}
+	if len(w.current.txs) != 5 || w.current.gasPool.Gas() != 0 {
+		t.Fatalf("block not filled: %d transactions, %d gas left", len(w.current.txs), w.current.gasPool.Gas())
+	}
+}
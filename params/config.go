@@ -210,23 +210,23 @@ var (
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, new(EthashConfig), nil, nil}
+	AllEthashProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, new(EthashConfig), nil, nil}
 
 	// AllCliqueProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the utg core developers into the Clique consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
+	AllCliqueProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, &CliqueConfig{Period: 0, Epoch: 30000}, nil}
 
 	// AllAlienProtocolChanges contains every protocol change (EIPs) introduced
 	// and accepted by the Ethereum core developers into the Alien consensus.
 	//
 	// This configuration is intentionally not using keyed fields to force anyone
 	// adding flags to the config to also have to set these fields.
-	AllAlienProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, &AlienConfig{Period: 10, Epoch: 30000, MaxSignerCount: 21, MinVoterBalance: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1000000000000000000)), GenesisTimestamp: 0, SelfVoteSigners: []common.UnprefixedAddress{}}}
+	AllAlienProtocolChanges = &ChainConfig{big.NewInt(1337), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, big.NewInt(0), nil, nil, &AlienConfig{Period: 10, Epoch: 30000, MaxSignerCount: 21, MinVoterBalance: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1000000000000000000)), GenesisTimestamp: 0, SelfVoteSigners: []common.UnprefixedAddress{}}}
 
-	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, &AlienConfig{Period: 5, Epoch: 30000, MaxSignerCount: 21, MinVoterBalance: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1000000000000000000)), GenesisTimestamp: 0, SelfVoteSigners: []common.UnprefixedAddress{}}}
+	TestChainConfig = &ChainConfig{big.NewInt(1), big.NewInt(0), nil, false, big.NewInt(0), common.Hash{}, big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), big.NewInt(0), nil, nil, nil, nil, nil, nil, nil, nil, &AlienConfig{Period: 5, Epoch: 30000, MaxSignerCount: 21, MinVoterBalance: new(big.Int).Mul(big.NewInt(10000), big.NewInt(1000000000000000000)), GenesisTimestamp: 0, SelfVoteSigners: []common.UnprefixedAddress{}}}
 	TestRules       = TestChainConfig.Rules(new(big.Int))
 )
 
@@ -309,6 +309,8 @@ type ChainConfig struct {
 	EWASMBlock    *big.Int `json:"ewasmBlock,omitempty"`    // EWASM switch block (nil = no fork, 0 = already activated)
 	CatalystBlock *big.Int `json:"catalystBlock,omitempty"` // Catalyst switch block (nil = no fork, 0 = already on catalyst)
 
+	MultiSigSpendBlock *big.Int `json:"multiSigSpendBlock,omitempty"` // Multi-signature account spend switch block (nil = no fork, 0 = already activated)
+
 	// Various consensus engines
 	Ethash *EthashConfig `json:"ethash,omitempty"`
 	Clique *CliqueConfig `json:"clique,omitempty"`
@@ -346,7 +348,22 @@ type AlienConfig struct {
 
 	TrantorBlock  *big.Int          `json:"trantorBlock,omitempty"`  // Trantor switch block (nil = no fork)
 	TerminusBlock *big.Int          `json:"terminusBlock,omitempty"` // Terminus switch block (nil = no fork)
-	LightConfig   *AlienLightConfig `json:"lightConfig,omitempty"`
+
+	LeaseAutoRenewBlock   *big.Int `json:"leaseAutoRenewBlock,omitempty"`   // Lease auto renewal switch block (nil = no fork)
+	LeaseTransferBlock    *big.Int `json:"leaseTransferBlock,omitempty"`    // Lease transfer switch block (nil = no fork)
+	LeaseChallengeBlock   *big.Int `json:"leaseChallengeBlock,omitempty"`   // Lease challenge switch block (nil = no fork)
+	RedelegateBlock       *big.Int `json:"redelegateBlock,omitempty"`       // Redelegation switch block (nil = no fork)
+	RevenueSplitBlock     *big.Int `json:"revenueSplitBlock,omitempty"`     // Revenue split switch block (nil = no fork)
+	AutoCompoundBlock     *big.Int `json:"autoCompoundBlock,omitempty"`     // Auto compounding switch block (nil = no fork)
+	ConfigProposalBlock   *big.Int `json:"configProposalBlock,omitempty"`   // Configuration proposal switch block (nil = no fork)
+	ConfigQueueBlock      *big.Int `json:"configQueueBlock,omitempty"`      // Configuration queue switch block (nil = no fork)
+	ManagerMultiSignBlock *big.Int `json:"managerMultiSignBlock,omitempty"` // Multi-signature manager switch block (nil = no fork)
+	SignerRotateBlock     *big.Int `json:"signerRotateBlock,omitempty"`     // Signer key rotation switch block (nil = no fork)
+	CommissionNoticeBlock *big.Int `json:"commissionNoticeBlock,omitempty"` // Commission notice switch block (nil = no fork)
+	BridgeBlock           *big.Int `json:"bridgeBlock,omitempty"`           // Cross-chain bridge switch block (nil = no fork)
+	SideChainHistoryBlock *big.Int `json:"sideChainHistoryBlock,omitempty"` // Side chain history switch block (nil = no fork)
+
+	LightConfig *AlienLightConfig `json:"lightConfig,omitempty"`
 }
 
 // String implements the stringer interface, returning the consensus engine details.
@@ -388,7 +405,7 @@ func (c *ChainConfig) String() string {
 	default:
 		engine = "unknown"
 	}
-	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Berlin: %v, London: %v, Engine: %v}",
+	return fmt.Sprintf("{ChainID: %v Homestead: %v DAO: %v DAOSupport: %v EIP150: %v EIP155: %v EIP158: %v Byzantium: %v Constantinople: %v Petersburg: %v Istanbul: %v, Muir Glacier: %v, Berlin: %v, London: %v, MultiSigSpend: %v, Engine: %v}",
 		c.ChainID,
 		c.HomesteadBlock,
 		c.DAOForkBlock,
@@ -403,6 +420,7 @@ func (c *ChainConfig) String() string {
 		c.MuirGlacierBlock,
 		c.BerlinBlock,
 		c.LondonBlock,
+		c.MultiSigSpendBlock,
 		engine,
 	)
 }
@@ -474,6 +492,12 @@ func (c *ChainConfig) IsCatalyst(num *big.Int) bool {
 	return isForked(c.CatalystBlock, num)
 }
 
+// IsMultiSigSpend returns whether num is either equal to the multi-signature
+// account spend fork block or greater.
+func (c *ChainConfig) IsMultiSigSpend(num *big.Int) bool {
+	return isForked(c.MultiSigSpendBlock, num)
+}
+
 // IsEWASM returns whether num represents a block number after the EWASM fork
 func (c *ChainConfig) IsEWASM(num *big.Int) bool {
 	return isForked(c.EWASMBlock, num)
@@ -591,6 +615,9 @@ func (c *ChainConfig) checkCompatible(newcfg *ChainConfig, head *big.Int) *Confi
 	if isForkIncompatible(c.EWASMBlock, newcfg.EWASMBlock, head) {
 		return newCompatError("ewasm fork block", c.EWASMBlock, newcfg.EWASMBlock)
 	}
+	if isForkIncompatible(c.MultiSigSpendBlock, newcfg.MultiSigSpendBlock, head) {
+		return newCompatError("multi-signature spend fork block", c.MultiSigSpendBlock, newcfg.MultiSigSpendBlock)
+	}
 	return nil
 }
 
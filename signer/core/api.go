@@ -31,8 +31,10 @@ import (
 	"github.com/UltronGlow/UltronGlow-Origin/accounts/usbwallet"
 	"github.com/UltronGlow/UltronGlow-Origin/common"
 	"github.com/UltronGlow/UltronGlow-Origin/common/hexutil"
+	"github.com/UltronGlow/UltronGlow-Origin/core/types"
 	"github.com/UltronGlow/UltronGlow-Origin/internal/ethapi"
 	"github.com/UltronGlow/UltronGlow-Origin/log"
+	"github.com/UltronGlow/UltronGlow-Origin/signer/customtx"
 	"github.com/UltronGlow/UltronGlow-Origin/signer/storage"
 )
 
@@ -40,7 +42,7 @@ const (
 	// numberOfAccountsToDerive For hardware wallets, the number of accounts to derive
 	numberOfAccountsToDerive = 10
 	// ExternalAPIVersion -- see extapi_changelog.md
-	ExternalAPIVersion = "6.1.0"
+	ExternalAPIVersion = "6.2.0"
 	// InternalAPIVersion -- see intapi_changelog.md
 	InternalAPIVersion = "7.0.1"
 )
@@ -63,6 +65,8 @@ type ExternalAPI interface {
 	Version(ctx context.Context) (string, error)
 	// SignGnosisSafeTransaction signs/confirms a gnosis-safe multisig transaction
 	SignGnosisSafeTx(ctx context.Context, signerAddress common.MixedcaseAddress, gnosisTx GnosisSafeTx, methodSelector *string) (*GnosisSafeTx, error)
+	// CoSignTransaction adds a co-signature to a partially signed multi-signer transaction
+	CoSignTransaction(ctx context.Context, signerAddress common.MixedcaseAddress, encoded hexutil.Bytes, methodSelector *string) (hexutil.Bytes, error)
 }
 
 // UIClientAPI specifies what method a UI needs to implement to be able to be used as a
@@ -604,6 +608,9 @@ func (api *SignerAPI) SignTransaction(ctx context.Context, args SendTxArgs, meth
 		return nil, err
 	}
 	response := ethapi.SignTransactionResult{Raw: data, Tx: signedTx}
+	if decoded, err := customtx.Decode(signedTx.Data()); err == nil {
+		response.Custom = decoded
+	}
 
 	// Finally, send the signed tx to the UI
 	api.UI.OnApprovedTx(response)
@@ -639,6 +646,87 @@ func (api *SignerAPI) SignGnosisSafeTx(ctx context.Context, signerAddress common
 	return &gnosisTx, nil
 }
 
+// CoSignTransaction adds the signature of signerAddress to a partially signed
+// multi-signer transaction and returns the updated encoding. The request is
+// shown to the UI like any other transaction, but since the other owners have
+// already signed it the UI is not allowed to modify it.
+func (api *SignerAPI) CoSignTransaction(ctx context.Context, signerAddress common.MixedcaseAddress, encoded hexutil.Bytes, methodSelector *string) (hexutil.Bytes, error) {
+	ptx := new(types.PartiallySignedTx)
+	if err := ptx.UnmarshalBinary(encoded); err != nil {
+		return nil, err
+	}
+	tx := ptx.Tx
+	if api.chainID.Cmp(tx.ChainId()) != 0 {
+		log.Error("Co-signing request with wrong chain id", "requested", tx.ChainId(), "configured", api.chainID)
+		return nil, fmt.Errorf("requested chainid %d does not match the configuration of the signer", tx.ChainId())
+	}
+	if !ptx.Consistent() {
+		log.Error("Co-signing request for another multi-signature address", "named", ptx.MultiSig, "spent", tx.MultiSig())
+		return nil, fmt.Errorf("multi-signature address %s does not match the spending account %s", ptx.MultiSig, tx.MultiSig())
+	}
+	var (
+		data       = hexutil.Bytes(tx.Data())
+		accessList = tx.AccessList()
+		args       = SendTxArgs{
+			From:       signerAddress,
+			Gas:        hexutil.Uint64(tx.Gas()),
+			GasPrice:   (*hexutil.Big)(tx.GasPrice()),
+			Value:      hexutil.Big(*tx.Value()),
+			Nonce:      hexutil.Uint64(tx.Nonce()),
+			Data:       &data,
+			AccessList: &accessList,
+			ChainID:    (*hexutil.Big)(tx.ChainId()),
+		}
+	)
+	if to := tx.To(); to != nil {
+		mixed := common.NewMixedcaseAddress(*to)
+		args.To = &mixed
+	}
+	msgs, err := api.validator.ValidateTransaction(methodSelector, &args)
+	if err != nil {
+		return nil, err
+	}
+	// If we are in 'rejectMode', then reject rather than show the user warnings
+	if api.rejectMode {
+		if err := msgs.getWarnings(); err != nil {
+			return nil, err
+		}
+	}
+	msgs.Info(fmt.Sprintf("Co-signing for multi-signature address %s", ptx.MultiSig.String()))
+	req := SignTxRequest{
+		Transaction: args,
+		Meta:        MetadataFromContext(ctx),
+		Callinfo:    msgs.Messages,
+	}
+	result, err := api.UI.ApproveTx(&req)
+	if err != nil {
+		return nil, err
+	}
+	if !result.Approved {
+		return nil, ErrRequestDenied
+	}
+	if result.Transaction.String() != args.String() {
+		return nil, errors.New("partially signed transactions can not be modified")
+	}
+	acc := accounts.Account{Address: signerAddress.Address()}
+	wallet, err := api.am.Find(acc)
+	if err != nil {
+		return nil, err
+	}
+	pw, err := api.lookupOrQueryPassword(acc.Address, "Account password",
+		fmt.Sprintf("Please enter the password for account %s", acc.Address.String()))
+	if err != nil {
+		return nil, err
+	}
+	signedTx, err := wallet.SignTxWithPassphrase(acc, pw, tx, api.chainID)
+	if err != nil {
+		api.UI.ShowError(err.Error())
+		return nil, err
+	}
+	ptx.Tx = signedTx
+	return ptx.MarshalBinary()
+}
+
 // Returns the external api version. This method does not require user acceptance. Available methods are
 // available via enumeration anyway, and this info does not contain user-specific data
 func (api *SignerAPI) Version(ctx context.Context) (string, error) {
@@ -88,6 +88,18 @@ func (l *AuditLogger) SignGnosisSafeTx(ctx context.Context, addr common.Mixedcas
 	return res, e
 }
 
+func (l *AuditLogger) CoSignTransaction(ctx context.Context, addr common.MixedcaseAddress, encoded hexutil.Bytes, methodSelector *string) (hexutil.Bytes, error) {
+	sel := "<nil>"
+	if methodSelector != nil {
+		sel = *methodSelector
+	}
+	l.log.Info("CoSignTransaction", "type", "request", "metadata", MetadataFromContext(ctx).String(),
+		"addr", addr.String(), "data", common.Bytes2Hex(encoded), "selector", sel)
+	b, e := l.api.CoSignTransaction(ctx, addr, encoded, methodSelector)
+	l.log.Info("CoSignTransaction", "type", "response", "data", common.Bytes2Hex(b), "error", e)
+	return b, e
+}
+
 func (l *AuditLogger) SignTypedData(ctx context.Context, addr common.MixedcaseAddress, data TypedData) (hexutil.Bytes, error) {
 	l.log.Info("SignTypedData", "type", "request", "metadata", MetadataFromContext(ctx).String(),
 		"addr", addr.String(), "data", data)
@@ -28,6 +28,7 @@ import (
 	"github.com/UltronGlow/UltronGlow-Origin/console/prompt"
 	"github.com/UltronGlow/UltronGlow-Origin/internal/ethapi"
 	"github.com/UltronGlow/UltronGlow-Origin/log"
+	"github.com/UltronGlow/UltronGlow-Origin/signer/customtx"
 )
 
 type CommandlineUI struct {
@@ -139,6 +140,9 @@ func (ui *CommandlineUI) ApproveTx(request *SignTxRequest) (SignTxResponse, erro
 		d := *request.Transaction.Data
 		if len(d) > 0 {
 			fmt.Printf("data:     %v\n", hexutil.Encode(d))
+			if decoded, err := customtx.Decode(d); err == nil {
+				fmt.Printf("\nCustom transaction:\n%v", decoded)
+			}
 		}
 	}
 	if request.Callinfo != nil {
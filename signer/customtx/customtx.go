@@ -0,0 +1,493 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+// Package customtx decodes the colon separated ufo/UTG/SSC payloads of alien
+// custom transactions into labelled fields, so that signers can show the user
+// what a transaction actually does before it is signed.
+package customtx
+
+import (
+	"errors"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+	"github.com/UltronGlow/UltronGlow-Origin/common/hexutil"
+	"github.com/UltronGlow/UltronGlow-Origin/params"
+)
+
+const (
+	ufoPrefix = "ufo"
+	utgPrefix = "UTG"
+	sscPrefix = "SSC"
+	version   = "1"
+
+	posPrefix   = 0
+	posVersion  = 1
+	posCategory = 2
+	posFirst    = 3
+)
+
+// ErrNotCustomTx is returned by Decode if the data is not a custom transaction payload.
+var ErrNotCustomTx = errors.New("not a custom transaction")
+
+// Field is a single labelled value of a decoded custom transaction.
+type Field struct {
+	Name  string `json:"name"`
+	Value string `json:"value"`
+}
+
+// Decoded is the human readable form of a custom transaction payload.
+type Decoded struct {
+	Prefix      string   `json:"prefix"`
+	Category    string   `json:"category"`
+	Description string   `json:"description"`
+	Fields      []Field  `json:"fields"`
+	Warnings    []string `json:"warnings,omitempty"`
+}
+
+// String renders the decoded transaction on multiple lines.
+func (d *Decoded) String() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s:%s  %s\n", d.Prefix, d.Category, d.Description)
+	for _, f := range d.Fields {
+		fmt.Fprintf(&b, "  %-18s %s\n", f.Name+":", f.Value)
+	}
+	for _, w := range d.Warnings {
+		fmt.Fprintf(&b, "  WARNING: %s\n", w)
+	}
+	return b.String()
+}
+
+// fieldKind selects how a raw payload field is validated and rendered.
+type fieldKind int
+
+const (
+	kindText      fieldKind = iota // free text, rendered as is
+	kindAddress                    // account address
+	kindHash                       // 32 byte hash
+	kindHexAmount                  // hex encoded wei amount
+	kindDecAmount                  // decimal wei amount
+	kindUint                       // decimal integer
+	kindHexUint                    // hex integer without prefix
+	kindBigUint                    // decimal integer of any size
+)
+
+type fieldSpec struct {
+	name     string
+	kind     fieldKind
+	optional bool
+}
+
+// category describes the payload layout of one custom transaction category.
+// Fields beyond the described ones are listed as numbered rest fields.
+type category struct {
+	description string
+	fields      []fieldSpec
+	warn        func(v []string) []string
+}
+
+// restLabels names the repeated trailing fields of variable length categories.
+var restLabels = map[string]string{
+	"Multi":     "owner",
+	"BindSplit": "split",
+}
+
+// pledgeLockDays is the pledge lock period of the default engine configuration.
+const pledgeLockDays = 180
+
+var managerNames = map[string]string{
+	"0": "exchange rate",
+	"1": "system",
+	"2": "bandwidth punishment",
+	"3": "flow report",
+}
+
+var revenueTypes = map[string]string{
+	"0": "PoS",
+	"1": "storage",
+}
+
+func revenueType(v string) string {
+	if name, ok := revenueTypes[v]; ok {
+		return name
+	}
+	return "type " + v
+}
+
+func lockWarning(what string) string {
+	return fmt.Sprintf("%s will be locked for %d days", what, pledgeLockDays)
+}
+
+func commissionWarning() string {
+	return fmt.Sprintf("the new rate takes effect after %d days and may differ by at most %d percentage points from the current one", params.CommissionNoticeDay, params.CommissionMaxChangePercent)
+}
+
+var (
+	addr  = func(name string) fieldSpec { return fieldSpec{name: name, kind: kindAddress} }
+	hash  = func(name string) fieldSpec { return fieldSpec{name: name, kind: kindHash} }
+	text  = func(name string) fieldSpec { return fieldSpec{name: name, kind: kindText} }
+	hexV  = func(name string) fieldSpec { return fieldSpec{name: name, kind: kindHexAmount} }
+	decV  = func(name string) fieldSpec { return fieldSpec{name: name, kind: kindDecAmount} }
+	uintF = func(name string) fieldSpec { return fieldSpec{name: name, kind: kindUint} }
+	hexU  = func(name string) fieldSpec { return fieldSpec{name: name, kind: kindHexUint} }
+	bigF  = func(name string) fieldSpec { return fieldSpec{name: name, kind: kindBigUint} }
+	opt   = func(f fieldSpec) fieldSpec { f.optional = true; return f }
+)
+
+// utgCategories lists the UTG:1 categories understood by the alien engine.
+var utgCategories = map[string]category{
+	"Exch": {"Exchange UTG for SRT", []fieldSpec{addr("SRT receiver"), hexV("amount")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this burns UTG and credits the SRT to %s", v[0])}
+		}},
+	"Multi": {"Create multi-signature address", []fieldSpec{uintF("threshold")}, nil},
+	"MultiAdd": {"Add multi-signature owner", []fieldSpec{addr("multi-signature"), addr("owner"), opt(uintF("threshold"))},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this adds %s as an owner of %s", v[1], v[0])}
+		}},
+	"MultiDel": {"Remove multi-signature owner", []fieldSpec{addr("multi-signature"), addr("owner"), opt(uintF("threshold"))},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this removes %s from the owners of %s", v[1], v[0])}
+		}},
+	"MultiThr": {"Change multi-signature threshold", []fieldSpec{addr("multi-signature"), uintF("threshold")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this changes the threshold of %s to %s", v[0], v[1])}
+		}},
+	"Bind": {"Bind device revenue", []fieldSpec{addr("device"), text("revenue type"), addr("contract"), addr("multi-signature"), opt(addr("revenue address"))},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this sends the %s revenue of %s to %s", revenueType(v[1]), v[0], revenueReceiver(v, 4))}
+		}},
+	"Unbind": {"Unbind device revenue", []fieldSpec{addr("device"), text("revenue type"), opt(addr("contract")), opt(addr("multi-signature"))},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this removes the %s revenue binding of %s", revenueType(v[1]), v[0])}
+		}},
+	"Rebind": {"Rebind device revenue", []fieldSpec{addr("device"), text("revenue type"), addr("contract"), addr("multi-signature"), addr("revenue address")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this redirects the %s revenue of %s to %s", revenueType(v[1]), v[0], revenueReceiver(v, 4))}
+		}},
+	"BindSplit": {"Split device revenue", []fieldSpec{addr("device"), text("revenue type")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this replaces the beneficiaries sharing the %s revenue of %s", revenueType(v[1]), v[0])}
+		}},
+	"CandReq": {"Pledge PoS candidate", []fieldSpec{addr("candidate")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this makes you the manager of candidate %s", v[0]), lockWarning("the candidate deposit")}
+		}},
+	"CandExit": {"Exit PoS candidate", []fieldSpec{addr("candidate")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this withdraws candidate %s together with all its delegations", v[0]), lockWarning("the returned pledge")}
+		}},
+	"CandPnsh": {"Pay PoS candidate punishment", []fieldSpec{addr("candidate")}, nil},
+	"FlwReq": {"Pledge flow miner", []fieldSpec{addr("miner"), hexU("ISP QoS id"), hexU("bandwidth")},
+		func(v []string) []string { return []string{lockWarning("the flow pledge")} }},
+	"FlwExit": {"Exit flow miner", []fieldSpec{addr("miner")}, nil},
+	"CandEntrust": {"Delegate to PoS candidate", []fieldSpec{addr("candidate"), hexV("amount")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this delegates %s to PoS candidate %s", v[1], v[0]), lockWarning("the delegated value")}
+		}},
+	"CandETExit": {"Withdraw PoS delegation", []fieldSpec{addr("candidate"), hash("delegation")},
+		func(v []string) []string { return []string{lockWarning("the withdrawn delegation")} }},
+	"CandChaRate": {"Change PoS distribution rate", []fieldSpec{addr("candidate"), hexU("rate")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this changes the revenue share paid to the delegators of %s", v[0]), commissionWarning()}
+		}},
+	"PoSwtfd": {"Transfer PoS delegation", []fieldSpec{addr("from candidate"), text("target type"), text("target")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this transfers your PoS delegation to %s %s", v[1], v[2])}
+		}},
+	"Redelegate": {"Redelegate", []fieldSpec{text("source type"), text("source"), hash("delegation"), text("target type"), text("target")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this moves delegation %s to %s %s, it can not be moved again for %d days", v[2], v[3], v[4], params.RedelegateCooldownDay)}
+		}},
+	"SignerRotate": {"Rotate PoS signer key", []fieldSpec{addr("signer"), addr("new signer")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this moves candidate %s with its pledge and delegations to signer %s from a later loop, the new key must seal from then on", v[0], v[1])}
+		}},
+	"AutoCompound": {"Set reward compounding", []fieldSpec{text("target type"), text("target"), decV("threshold")},
+		func(v []string) []string {
+			if v[2] == formatAmount(big.NewInt(0)) {
+				return []string{"this stops delegating your released rewards"}
+			}
+			return []string{fmt.Sprintf("this delegates your released rewards to %s %s whenever a payout reaches %s", v[0], v[1], v[2]), lockWarning("the delegated rewards")}
+		}},
+	"BridgeLock": {"Lock value for a side chain", []fieldSpec{hash("side chain"), addr("recipient"), decV("amount")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this locks %s on the main chain and mints it to %s on side chain %s once the side chain signers confirm it", v[2], v[1], v[0])}
+		}},
+	"BridgeBurn": {"Burn value for the main chain", []fieldSpec{addr("recipient"), decV("amount")},
+		func(v []string) []string {
+			return []string{fmt.Sprintf("this burns %s on the side chain and unlocks it to %s on the main chain once the side chain block is confirmed there", v[1], v[0])}
+		}},
+
+	"stReq":     {"Declare storage pledge", []fieldSpec{addr("storage node"), decV("price"), uintF("capacity"), text("start package"), text("package nonce"), hash("package block"), text("verify data")}, nil},
+	"stExit":    {"Exit storage pledge", []fieldSpec{addr("storage node")}, func(v []string) []string { return []string{lockWarning("the storage pledge")} }},
+	"stRent":    {"Request lease", []fieldSpec{addr("storage node"), uintF("capacity"), uintF("days"), decV("price")}, nil},
+	"stRentPg":  {"Pledge lease", []fieldSpec{addr("storage node"), hash("lease"), uintF("capacity"), hash("root hash"), uintF("left capacity"), hash("left root hash")}, nil},
+	"stReNew":   {"Request lease renewal", []fieldSpec{addr("storage node"), hash("lease"), uintF("days")}, nil},
+	"stReNewPg": {"Pledge lease renewal", []fieldSpec{addr("storage node"), hash("lease"), uintF("capacity"), hash("root hash")}, nil},
+	"stAutoReNew": {"Set automatic lease renewal", []fieldSpec{addr("storage node"), hash("lease"), uintF("days"), opt(bigF("max price")), opt(decV("deposit")), opt(bigF("SRT allowance"))}, func(v []string) []string {
+		if v[2] == "0" {
+			return []string{fmt.Sprintf("this cancels the automatic renewal of lease %s and returns its deposit", v[1])}
+		}
+		if v[3] == "" || v[4] == "" || v[5] == "" {
+			return []string{"the max price, deposit and SRT allowance are required to set an automatic renewal, the transaction will be ignored"}
+		}
+		return []string{fmt.Sprintf("this pays %s now to renew lease %s automatically", v[4], v[1])}
+	}},
+	"stTransfer": {"Transfer lease", []fieldSpec{addr("storage node"), hash("lease"), addr("new tenant")}, func(v []string) []string {
+		return []string{fmt.Sprintf("this hands lease %s and its deposit over to %s", v[1], v[2])}
+	}},
+	"stChallengeProof": {"Answer lease challenge", []fieldSpec{addr("storage node"), hash("lease"), text("proof")}, nil},
+	"stChallenge": {"Challenge lease storage", []fieldSpec{addr("storage node"), hash("lease"), uintF("seed block")}, func(v []string) []string {
+		return []string{fmt.Sprintf("storage node %s must prove it still holds lease %s once block %s is known", v[0], v[1], v[2])}
+	}},
+	"stRescind": {"Rescind lease", []fieldSpec{addr("storage node"), hash("lease")}, func(v []string) []string { return []string{fmt.Sprintf("this terminates lease %s", v[1])} }},
+	"stReValid": {"Recover storage validity", []fieldSpec{addr("storage node"), text("leases"), text("verify data")}, nil},
+	"stProof":   {"Storage proof", []fieldSpec{addr("storage node"), text("lease"), uintF("capacity"), text("verify data")}, nil},
+	"chPrice":   {"Change storage price", []fieldSpec{addr("storage node"), decV("price")}, nil},
+	"chbw":      {"Change storage bandwidth", []fieldSpec{addr("storage node"), uintF("bandwidth")}, nil},
+	"stCatchUp": {"Pay storage pledge shortfall", []fieldSpec{addr("storage node")}, nil},
+	"editmgaddr": {"Change storage manager", []fieldSpec{addr("storage node"), addr("new manager")}, func(v []string) []string {
+		return []string{fmt.Sprintf("this changes storage manager of %s to %s", v[0], v[1])}
+	}},
+	"stchpg": {"Complete storage pledge", []fieldSpec{addr("storage node"), decV("amount")}, func(v []string) []string { return []string{lockWarning("the pledged value")} }},
+	"stwtreward": {"Set storage delegator reward ratio", []fieldSpec{addr("storage node"), uintF("ratio")}, func(v []string) []string {
+		return []string{fmt.Sprintf("this changes the revenue share paid to the delegators of %s", v[0]), commissionWarning()}
+	}},
+	"setsp": {"Join storage pool", []fieldSpec{addr("storage node"), hash("pool")}, func(v []string) []string {
+		return []string{fmt.Sprintf("this moves storage node %s into pool %s", v[0], v[1])}
+	}},
+	"exitsp":    {"Leave storage pool", []fieldSpec{addr("storage node")}, nil},
+	"streplace": {"Migrate storage pledge", []fieldSpec{addr("storage node"), uintF("capacity")}, nil},
+	"stwtpg": {"Delegate to storage node", []fieldSpec{addr("storage node"), decV("amount")}, func(v []string) []string {
+		return []string{fmt.Sprintf("this delegates %s to storage node %s", v[1], v[0]), lockWarning("the delegated value")}
+	}},
+	"wtfd": {"Transfer storage delegation", []fieldSpec{addr("from storage node"), text("target type"), text("target")}, func(v []string) []string {
+		return []string{fmt.Sprintf("this transfers your storage delegation to %s %s", v[1], v[2])}
+	}},
+	"wtpgexit": {"Withdraw storage delegation", []fieldSpec{addr("storage node"), hash("delegation")}, func(v []string) []string { return []string{lockWarning("the withdrawn delegation")} }},
+
+	"addsp":      {"Create storage pool", []fieldSpec{decV("pledge"), uintF("fee"), uintF("entrust rate"), addr("revenue address")}, func(v []string) []string { return []string{lockWarning("the pool pledge")} }},
+	"spchpg":     {"Adjust storage pool pledge", []fieldSpec{hash("pool"), decV("amount")}, nil},
+	"spremovesn": {"Remove storage node from pool", []fieldSpec{hash("pool"), addr("storage node")}, nil},
+	"spwtpg": {"Delegate to storage pool", []fieldSpec{hash("pool"), decV("amount")}, func(v []string) []string {
+		return []string{fmt.Sprintf("this delegates %s to storage pool %s", v[1], v[0]), lockWarning("the delegated value")}
+	}},
+	"spwtfd": {"Transfer storage pool delegation", []fieldSpec{hash("pool"), text("target type"), text("target")}, func(v []string) []string {
+		return []string{fmt.Sprintf("this transfers your pool delegation to %s %s", v[1], v[2])}
+	}},
+	"spwtexit": {"Withdraw storage pool delegation", []fieldSpec{hash("pool"), hash("delegation")}, func(v []string) []string { return []string{lockWarning("the withdrawn delegation")} }},
+	"spexit": {"Exit storage pool", []fieldSpec{hash("pool")}, func(v []string) []string {
+		return []string{"this closes the pool and returns the pledges of all its delegators"}
+	}},
+	"spfee": {"Set storage pool fee", []fieldSpec{hash("pool"), uintF("fee")}, func(v []string) []string { return []string{commissionWarning()} }},
+	"spetrate": {"Set storage pool entrust rate", []fieldSpec{hash("pool"), uintF("rate")}, func(v []string) []string {
+		return []string{"this changes the revenue share paid to the pool delegators", commissionWarning()}
+	}},
+	"sprvebind": {"Bind storage pool revenue", []fieldSpec{hash("pool"), text("type"), addr("revenue address")}, func(v []string) []string { return []string{fmt.Sprintf("this sends the pool revenue to %s", v[2])} }},
+}
+
+// sscCategories lists the SSC:1 system configuration categories. They are only
+// accepted from the configured manager addresses, and apart from WdthPnsh they
+// are replaced by signer-voted proposals after the config proposal fork.
+var sscCategories = map[string]category{
+	"ExchRate": {"Set UTG/SRT exchange rate", []fieldSpec{uintF("rate")}, nil},
+	"Deposit":  {"Set system deposit", []fieldSpec{hexV("value"), uintF("id")}, nil},
+	"CndLock":  {"Set candidate lock parameters", []fieldSpec{hexU("lock period"), hexU("release period"), hexU("interval")}, nil},
+	"FlwLock":  {"Set flow lock parameters", []fieldSpec{hexU("lock period"), hexU("release period"), hexU("interval")}, nil},
+	"RwdLock":  {"Set reward lock parameters", []fieldSpec{hexU("lock period"), hexU("release period"), hexU("interval")}, nil},
+	"OffLine":  {"Set offline threshold", []fieldSpec{uintF("value")}, nil},
+	"QOS":      {"Set ISP QoS", []fieldSpec{uintF("ISP id"), uintF("value")}, nil},
+	"WdthPnsh": {"Punish flow bandwidth", []fieldSpec{addr("miner"), hexU("bandwidth")}, nil},
+	"Manager": {"Change manager address", []fieldSpec{uintF("manager id"), addr("address")},
+		func(v []string) []string {
+			name, ok := managerNames[v[0]]
+			if !ok {
+				name = "id " + v[0]
+			}
+			return []string{fmt.Sprintf("this changes the %s manager to %s", name, v[1])}
+		}},
+	"CancelConfig": {"Cancel pending config change", []fieldSpec{hash("change")}, func(v []string) []string {
+		return []string{fmt.Sprintf("this withdraws the pending system config change %s when sent by its issuer, or votes to withdraw it, which happens once more than two thirds of the signers voted", v[0])}
+	}},
+}
+
+// ufoCategories lists the ufo:1 events, keyed by category and event name.
+var ufoCategories = map[string]category{
+	"event:vote":     {"Vote for signer candidate", nil, func(v []string) []string { return []string{"the recipient of this transaction receives your vote"} }},
+	"event:confirm":  {"Confirm block", []fieldSpec{uintF("number")}, nil},
+	"event:proposal": {"Submit proposal", nil, nil},
+	"event:declare":  {"Declare on proposal", nil, nil},
+	"sc:confirm":     {"Confirm side chain block", []fieldSpec{hash("side chain"), uintF("number"), uintF("time"), text("loop info"), text("charging info")}, nil},
+	"sc:setcb": {"Set side chain coinbase", []fieldSpec{hash("side chain")}, func(v []string) []string {
+		return []string{"the recipient of this transaction becomes your side chain coinbase"}
+	}},
+	"sc:delcb":   {"Remove side chain coinbase", []fieldSpec{hash("side chain")}, nil},
+	"sc:flwrpt":  {"Flow report", nil, nil},
+	"sc:flwrptm": {"Flow report by manager", nil, nil},
+}
+
+func revenueReceiver(v []string, pos int) string {
+	if pos < len(v) && v[pos] != "" {
+		return v[pos]
+	}
+	return "the sender"
+}
+
+// IsCustomTx reports whether data carries a custom transaction payload.
+func IsCustomTx(data []byte) bool {
+	info := strings.SplitN(string(data), ":", posFirst+1)
+	if len(info) < posFirst || info[posVersion] != version {
+		return false
+	}
+	switch info[posPrefix] {
+	case ufoPrefix, utgPrefix, sscPrefix:
+		return true
+	}
+	return false
+}
+
+// Decode parses a custom transaction payload. Unknown categories of a known
+// prefix are decoded with positional fields and a warning; payloads without a
+// custom prefix return ErrNotCustomTx.
+func Decode(data []byte) (*Decoded, error) {
+	if !IsCustomTx(data) {
+		return nil, ErrNotCustomTx
+	}
+	info := strings.Split(string(data), ":")
+	decoded := &Decoded{
+		Prefix:   info[posPrefix],
+		Category: info[posCategory],
+	}
+	args := info[posFirst:]
+
+	var (
+		spec  category
+		known bool
+	)
+	switch decoded.Prefix {
+	case utgPrefix:
+		spec, known = utgCategories[decoded.Category]
+	case sscPrefix:
+		spec, known = sscCategories[decoded.Category]
+		if decoded.Category != "CancelConfig" {
+			decoded.Warnings = append(decoded.Warnings, "this changes a system parameter and is only accepted from its manager address until system parameters are changed by signer-voted proposals")
+		}
+	case ufoPrefix:
+		event := ""
+		if len(args) > 0 {
+			event, args = args[0], args[1:]
+		}
+		spec, known = ufoCategories[decoded.Category+":"+event]
+		if known && event != "" {
+			decoded.Category += ":" + event
+		}
+	}
+	if !known {
+		decoded.Description = "Unknown custom transaction"
+		decoded.Warnings = append(decoded.Warnings, fmt.Sprintf("category %q is not known to this signer", decoded.Category))
+	} else {
+		decoded.Description = spec.description
+	}
+	values := make([]string, len(spec.fields))
+	rest, ok := restLabels[decoded.Category]
+	if !ok {
+		rest = "param"
+	}
+	for i, arg := range args {
+		if i >= len(spec.fields) {
+			decoded.Fields = append(decoded.Fields, Field{fmt.Sprintf("%s %d", rest, i-len(spec.fields)+1), arg})
+			continue
+		}
+		value, err := render(spec.fields[i].kind, arg)
+		if err != nil {
+			decoded.Warnings = append(decoded.Warnings, fmt.Sprintf("field %q is malformed: %v", spec.fields[i].name, err))
+			value = arg
+		}
+		values[i] = value
+		if arg != "" {
+			decoded.Fields = append(decoded.Fields, Field{spec.fields[i].name, value})
+		}
+	}
+	for i := len(args); i < len(spec.fields); i++ {
+		if !spec.fields[i].optional {
+			decoded.Warnings = append(decoded.Warnings, fmt.Sprintf("field %q is missing, the transaction will be ignored", spec.fields[i].name))
+			return decoded, nil
+		}
+	}
+	if spec.warn != nil {
+		decoded.Warnings = append(decoded.Warnings, spec.warn(values)...)
+	}
+	return decoded, nil
+}
+
+func render(kind fieldKind, v string) (string, error) {
+	if v == "" {
+		return v, nil
+	}
+	switch kind {
+	case kindAddress:
+		var a common.Address
+		if err := a.UnmarshalText1([]byte(v)); err != nil {
+			return "", err
+		}
+		return a.String(), nil
+	case kindHash:
+		var h common.Hash
+		if err := h.UnmarshalText1([]byte(v)); err != nil {
+			return "", err
+		}
+		return h.Hex(), nil
+	case kindHexAmount:
+		n, err := hexutil.UnmarshalText1([]byte(v))
+		if err != nil {
+			return "", err
+		}
+		return formatAmount(n), nil
+	case kindDecAmount:
+		n, ok := new(big.Int).SetString(v, 10)
+		if !ok {
+			return "", fmt.Errorf("invalid decimal %q", v)
+		}
+		return formatAmount(n), nil
+	case kindUint:
+		if _, err := strconv.ParseUint(v, 10, 64); err != nil {
+			return "", err
+		}
+	case kindBigUint:
+		n, ok := new(big.Int).SetString(v, 10)
+		if !ok || n.Sign() < 0 {
+			return "", fmt.Errorf("invalid decimal %q", v)
+		}
+		return n.String(), nil
+	case kindHexUint:
+		n, err := strconv.ParseUint(v, 16, 64)
+		if err != nil {
+			return "", err
+		}
+		return strconv.FormatUint(n, 10), nil
+	}
+	return v, nil
+}
+
+// formatAmount renders a wei amount together with its value in UTG.
+func formatAmount(n *big.Int) string {
+	utg := new(big.Rat).SetFrac(n, big.NewInt(1e18))
+	return fmt.Sprintf("%s wei (%s UTG)", n, strings.TrimRight(strings.TrimRight(utg.FloatString(18), "0"), "."))
+}
@@ -0,0 +1,211 @@
+// Copyright 2021 The utg Authors
+// This file is part of the utg library.
+//
+// The utg library is free software: you can redistribute it and/or modify
+// it under the terms of the GNU Lesser General Public License as published by
+// the Free Software Foundation, either version 3 of the License, or
+// (at your option) any later version.
+//
+// The utg library is distributed in the hope that it will be useful,
+// but WITHOUT ANY WARRANTY; without even the implied warranty of
+// MERCHANTABILITY or FITNESS FOR A PARTICULAR PURPOSE. See the
+// GNU Lesser General Public License for more details.
+//
+// You should have received a copy of the GNU Lesser General Public License
+// along with the utg library. If not, see <http://www.gnu.org/licenses/>.
+
+package customtx
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/UltronGlow/UltronGlow-Origin/common"
+)
+
+func TestDecode(t *testing.T) {
+	var (
+		miner  = "ux1000000000000000000000000000000000000001"
+		target = "ux2000000000000000000000000000000000000002"
+		pool   = "0x00000000000000000000000000000000000000000000000000000000000000aa"
+
+		minerHex  = common.HexToAddress(miner).String()
+		targetHex = common.HexToAddress(target).String()
+	)
+	tests := []struct {
+		data     string
+		desc     string
+		fields   []Field
+		warnings []string
+	}{
+		{
+			data:     "UTG:1:PoSwtfd:" + miner + ":PoS:" + target,
+			desc:     "Transfer PoS delegation",
+			warnings: []string{"this transfers your PoS delegation to PoS " + target},
+		},
+		{
+			data:     "UTG:1:BindSplit:" + miner + ":0:" + target + ":2500",
+			desc:     "Split device revenue",
+			fields:   []Field{{"device", minerHex}, {"revenue type", "0"}, {"split 1", target}, {"split 2", "2500"}},
+			warnings: []string{"this replaces the beneficiaries sharing the PoS revenue of " + minerHex},
+		},
+		{
+			data:     "UTG:1:AutoCompound:PoS:" + miner + ":1000000000000000000",
+			desc:     "Set reward compounding",
+			fields:   []Field{{"target type", "PoS"}, {"target", miner}, {"threshold", "1000000000000000000 wei (1 UTG)"}},
+			warnings: []string{"this delegates your released rewards to PoS " + miner + " whenever a payout reaches 1000000000000000000 wei (1 UTG)", "the delegated rewards will be locked for 180 days"},
+		},
+		{
+			data:     "UTG:1:AutoCompound:PoS:" + miner + ":0",
+			desc:     "Set reward compounding",
+			warnings: []string{"this stops delegating your released rewards"},
+		},
+		{
+			data:     "UTG:1:Redelegate:SN:" + miner + ":" + pool + ":SP:" + pool,
+			desc:     "Redelegate",
+			fields:   []Field{{"source type", "SN"}, {"source", miner}, {"delegation", pool}, {"target type", "SP"}, {"target", pool}},
+			warnings: []string{"this moves delegation " + pool + " to SP " + pool + ", it can not be moved again for 7 days"},
+		},
+		{
+			data:     "UTG:1:BridgeLock:" + pool + ":" + target + ":1000000000000000000",
+			desc:     "Lock value for a side chain",
+			fields:   []Field{{"side chain", pool}, {"recipient", targetHex}, {"amount", "1000000000000000000 wei (1 UTG)"}},
+			warnings: []string{"this locks 1000000000000000000 wei (1 UTG) on the main chain and mints it to " + targetHex + " on side chain " + pool + " once the side chain signers confirm it"},
+		},
+		{
+			data:     "UTG:1:SignerRotate:" + miner + ":" + target,
+			desc:     "Rotate PoS signer key",
+			fields:   []Field{{"signer", minerHex}, {"new signer", targetHex}},
+			warnings: []string{"this moves candidate " + minerHex + " with its pledge and delegations to signer " + targetHex + " from a later loop, the new key must seal from then on"},
+		},
+		{
+			data:     "UTG:1:editmgaddr:" + miner + ":" + target,
+			desc:     "Change storage manager",
+			warnings: []string{"this changes storage manager of " + minerHex + " to " + targetHex},
+		},
+		{
+			data:     "UTG:1:CandEntrust:" + miner + ":0xde0b6b3a7640000",
+			desc:     "Delegate to PoS candidate",
+			fields:   []Field{{"candidate", minerHex}, {"amount", "1000000000000000000 wei (1 UTG)"}},
+			warnings: []string{"value will be locked for 180 days"},
+		},
+		{
+			data:     "UTG:1:spfee:" + pool + ":15",
+			desc:     "Set storage pool fee",
+			fields:   []Field{{"pool", pool}, {"fee", "15"}},
+			warnings: []string{"the new rate takes effect after 7 days and may differ by at most 10 percentage points from the current one"},
+		},
+		{
+			data:     "SSC:1:CancelConfig:" + pool,
+			desc:     "Cancel pending config change",
+			fields:   []Field{{"change", pool}},
+			warnings: []string{"this withdraws the pending system config change " + pool + " when sent by its issuer, or votes to withdraw it, which happens once more than two thirds of the signers voted"},
+		},
+		{
+			data:     "SSC:1:Manager:1:" + target,
+			desc:     "Change manager address",
+			warnings: []string{"this changes the system manager to " + targetHex},
+		},
+		{
+			data:   "UTG:1:spwtpg:" + pool + ":1500000000000000000",
+			desc:   "Delegate to storage pool",
+			fields: []Field{{"pool", pool}, {"amount", "1500000000000000000 wei (1.5 UTG)"}},
+		},
+		{
+			data:   "UTG:1:Multi:2:" + miner + ":" + target,
+			desc:   "Create multi-signature address",
+			fields: []Field{{"threshold", "2"}, {"owner 1", miner}, {"owner 2", target}},
+		},
+		{
+			data:     "UTG:1:MultiDel:" + miner + ":" + target,
+			desc:     "Remove multi-signature owner",
+			fields:   []Field{{"multi-signature", minerHex}, {"owner", targetHex}},
+			warnings: []string{"this removes " + targetHex + " from the owners of " + minerHex},
+		},
+		{
+			data:     "UTG:1:stAutoReNew:" + miner + ":" + pool + ":30:4:1000000000000000000:120",
+			desc:     "Set automatic lease renewal",
+			fields:   []Field{{"storage node", minerHex}, {"lease", pool}, {"days", "30"}, {"max price", "4"}, {"deposit", "1000000000000000000 wei (1 UTG)"}, {"SRT allowance", "120"}},
+			warnings: []string{"this pays 1000000000000000000 wei (1 UTG) now to renew lease " + pool + " automatically"},
+		},
+		{
+			data:     "UTG:1:stAutoReNew:" + miner + ":" + pool + ":30:40000000000000000000000:1000000000000000000:36893488147419103232",
+			desc:     "Set automatic lease renewal",
+			fields:   []Field{{"storage node", minerHex}, {"lease", pool}, {"days", "30"}, {"max price", "40000000000000000000000"}, {"deposit", "1000000000000000000 wei (1 UTG)"}, {"SRT allowance", "36893488147419103232"}},
+			warnings: []string{"this pays 1000000000000000000 wei (1 UTG) now to renew lease " + pool + " automatically"},
+		},
+		{
+			data:     "UTG:1:stAutoReNew:" + miner + ":" + pool + ":30",
+			desc:     "Set automatic lease renewal",
+			fields:   []Field{{"storage node", minerHex}, {"lease", pool}, {"days", "30"}},
+			warnings: []string{"the max price, deposit and SRT allowance are required to set an automatic renewal, the transaction will be ignored"},
+		},
+		{
+			data:     "UTG:1:stAutoReNew:" + miner + ":" + pool + ":0",
+			desc:     "Set automatic lease renewal",
+			warnings: []string{"this cancels the automatic renewal of lease " + pool + " and returns its deposit"},
+		},
+		{
+			data:     "UTG:1:stChallenge:" + miner + ":" + pool + ":120",
+			desc:     "Challenge lease storage",
+			fields:   []Field{{"storage node", minerHex}, {"lease", pool}, {"seed block", "120"}},
+			warnings: []string{"storage node " + minerHex + " must prove it still holds lease " + pool + " once block 120 is known"},
+		},
+		{
+			data:     "UTG:1:stRent:" + miner,
+			desc:     "Request lease",
+			warnings: []string{`field "capacity" is missing`},
+		},
+		{
+			data:     "UTG:1:CandEntrust:" + miner + ":zz",
+			desc:     "Delegate to PoS candidate",
+			warnings: []string{`field "amount" is malformed`},
+		},
+		{
+			data:     "UTG:1:noSuchThing:1",
+			desc:     "Unknown custom transaction",
+			warnings: []string{`category "noSuchThing" is not known`},
+		},
+		{
+			data: "ufo:1:event:vote",
+			desc: "Vote for signer candidate",
+		},
+	}
+	for i, tt := range tests {
+		decoded, err := Decode([]byte(tt.data))
+		if err != nil {
+			t.Fatalf("test %d: decode failed: %v", i, err)
+		}
+		if decoded.Description != tt.desc {
+			t.Errorf("test %d: description mismatch: have %q, want %q", i, decoded.Description, tt.desc)
+		}
+		for j, want := range tt.fields {
+			if j >= len(decoded.Fields) || decoded.Fields[j] != want {
+				t.Errorf("test %d: field %d mismatch: have %v, want %v", i, j, decoded.Fields, want)
+				break
+			}
+		}
+		for _, want := range tt.warnings {
+			found := false
+			for _, have := range decoded.Warnings {
+				if strings.Contains(have, want) {
+					found = true
+				}
+			}
+			if !found {
+				t.Errorf("test %d: warning %q missing from %q", i, want, decoded.Warnings)
+			}
+		}
+	}
+}
+
+func TestDecodeNotCustom(t *testing.T) {
+	for _, data := range []string{"", "UTG", "UTG:2:Bind", "\xa9\x05\x9c\xbb", "utg:1:Bind"} {
+		if IsCustomTx([]byte(data)) {
+			t.Errorf("%q reported as custom transaction", data)
+		}
+		if _, err := Decode([]byte(data)); err != ErrNotCustomTx {
+			t.Errorf("%q: expected ErrNotCustomTx, have %v", data, err)
+		}
+	}
+}
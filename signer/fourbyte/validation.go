@@ -24,6 +24,7 @@ import (
 
 	"github.com/UltronGlow/UltronGlow-Origin/common"
 	"github.com/UltronGlow/UltronGlow-Origin/signer/core"
+	"github.com/UltronGlow/UltronGlow-Origin/signer/customtx"
 )
 
 // ValidateTransaction does a number of checks on the supplied transaction, and
@@ -95,6 +96,11 @@ func (db *Database) ValidateCallData(selector *string, data []byte, messages *co
 	if len(data) == 0 {
 		return
 	}
+	// Alien custom transactions carry a plain text payload instead of ABI call data
+	if customtx.IsCustomTx(data) {
+		validateCustomTx(data, messages)
+		return
+	}
 	// Validate the call data that it has the 4byte prefix and the rest divisible by 32 bytes
 	if len(data) < 4 {
 		messages.Warn("Transaction data is not valid ABI (missing the 4 byte call prefix)")
@@ -125,3 +131,20 @@ func (db *Database) ValidateCallData(selector *string, data []byte, messages *co
 		messages.Info(fmt.Sprintf("Transaction invokes the following method: %q", info.String()))
 	}
 }
+
+// validateCustomTx decodes a custom transaction payload into informational
+// messages, one per field, and surfaces its warnings.
+func validateCustomTx(data []byte, messages *core.ValidationMessages) {
+	decoded, err := customtx.Decode(data)
+	if err != nil {
+		messages.Warn(fmt.Sprintf("Transaction contains custom data that could not be decoded: %v", err))
+		return
+	}
+	messages.Info(fmt.Sprintf("Transaction is a custom %s:%s transaction: %s", decoded.Prefix, decoded.Category, decoded.Description))
+	for _, field := range decoded.Fields {
+		messages.Info(fmt.Sprintf("%s: %s", field.Name, field.Value))
+	}
+	for _, warning := range decoded.Warnings {
+		messages.Warn(warning)
+	}
+}